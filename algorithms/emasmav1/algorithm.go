@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"math"
 	"strconv"
-	"strings"
 	"sync"
+	"time"
 
 	"github.com/markcheno/go-talib"
 
@@ -26,18 +26,22 @@ const (
 	cfgRsiBuyMax = "Ema/Sma.rsi_buy_max"
 	cfgRsiSell   = "Ema/Sma.rsi_sell"
 	cfgBacktest  = "Ema/Sma.backtest"
+	cfgShorts    = "Ema/Sma.shorts"
 )
 
-var defaultConfig types.AlgorithmConfig = types.AlgorithmConfig{
-	cfgSmaLen:    "15",
-	cfgEmaLen:    "7",
-	cfgRsiLen:    "14",
-	cfgRsiBuyMin: "45.0",
-	cfgRsiBuyMax: "70.0",
-	cfgRsiSell:   "90.0",
-	cfgBacktest:  "false",
+var schema types.AlgorithmSchema = types.AlgorithmSchema{
+	{Name: cfgSmaLen, Type: types.ParamInt, Default: "15", Min: types.ParamLimit(2), Description: "Length of the SMA"},
+	{Name: cfgEmaLen, Type: types.ParamInt, Default: "7", Min: types.ParamLimit(2), Description: "Length of the EMA"},
+	{Name: cfgRsiLen, Type: types.ParamInt, Default: "14", Min: types.ParamLimit(2), Description: "Length of the RSI"},
+	{Name: cfgRsiBuyMin, Type: types.ParamFloat, Default: "45.0", Min: types.ParamLimit(0), Max: types.ParamLimit(100), Description: "Min RSI to buy on the EMA crossing over the SMA"},
+	{Name: cfgRsiBuyMax, Type: types.ParamFloat, Default: "70.0", Min: types.ParamLimit(0), Max: types.ParamLimit(100), Description: "Max RSI to buy on the EMA crossing over the SMA"},
+	{Name: cfgRsiSell, Type: types.ParamFloat, Default: "90.0", Min: types.ParamLimit(0), Max: types.ParamLimit(100), Description: "Sell when the RSI drops below this value"},
+	{Name: cfgBacktest, Type: types.ParamBool, Default: "false", Description: "Check every candle of each received series instead of the last one"},
+	{Name: cfgShorts, Type: types.ParamBool, Default: "false", Description: "Also open a short position on the sell signals and close it on the buy signals"},
 }
 
+var defaultConfig types.AlgorithmConfig = schema.Defaults()
+
 func init() {
 	algorithms.RegisterAlgorithm(name, createAlgorithm)
 }
@@ -51,6 +55,7 @@ type Algorithm struct {
 	rsiBuyMax     float64
 	rsiSell       float64
 	backtest      bool
+	shorts        bool
 	seriesChannel types.SeriesChannel
 	signalChannel types.SignalChannel
 	lastBuyPrice  float64
@@ -82,16 +87,22 @@ func (a Algorithm) DefaultConfig() types.AlgorithmConfig {
 	return defaultConfig
 }
 
+// Schema returns the description of the configuration parameters of the algorithm
+func (a Algorithm) Schema() types.AlgorithmSchema {
+	return schema
+}
+
 // Config returns the current configuration of the algorithm
 func (a Algorithm) Config() types.AlgorithmConfig {
 	return types.AlgorithmConfig{
 		cfgSmaLen:    fmt.Sprintf("%d", a.smaLen),
 		cfgEmaLen:    fmt.Sprintf("%d", a.emaLen),
 		cfgRsiLen:    fmt.Sprintf("%d", a.rsiLen),
-		cfgRsiBuyMin: fmt.Sprintf("%d", a.rsiBuyMin),
+		cfgRsiBuyMin: fmt.Sprintf("%f", a.rsiBuyMin),
 		cfgRsiBuyMax: fmt.Sprintf("%f", a.rsiBuyMax),
 		cfgRsiSell:   fmt.Sprintf("%f", a.rsiSell),
 		cfgBacktest:  fmt.Sprintf("%t", a.backtest),
+		cfgShorts:    fmt.Sprintf("%t", a.shorts),
 	}
 }
 
@@ -114,11 +125,25 @@ func (a *Algorithm) emit(signal types.Signal) {
 	a.signalChannel <- signal
 }
 
+// emitSignal emits the signal for the position on the candle
+func (a *Algorithm) emitSignal(series types.Series, side types.Side, position types.PositionSide, candleTime time.Time) {
+	if a.backtest {
+		a.emit(types.NewBacktestPositionSignal(name, series.Symbol, side, position, candleTime))
+		return
+	}
+	a.emit(types.NewPositionSignal(name, series.Symbol, side, position))
+}
+
 func (a *Algorithm) check(ctx context.Context, series types.Series) {
 	var sma, ema, rsi []float64
 	var in_rsi_range, buySignal, sellSignal1, sellSignal2, sellSignal3 bool
 	var calcSeries types.Series
 
+	if series.Length() < a.minSampleLen() {
+		logger.Debugf("Algorithm[%s]::check Not enough candles: %d\n", name, series.Length())
+		return
+	}
+
 	calcSeries = series.SubSeries(0, series.Length()-1)
 
 	sma = talib.Sma(calcSeries.Close(), a.smaLen)
@@ -132,41 +157,48 @@ func (a *Algorithm) check(ctx context.Context, series types.Series) {
 	sellSignal2 = ema[len(ema)-3] > ema[len(ema)-2] && ema[len(ema)-2] > ema[len(ema)-1] && calcSeries.CurrentClose() > a.lastBuyPrice
 	sellSignal3 = rsi[len(rsi)-2] > a.rsiSell && rsi[len(rsi)-1] <= a.rsiSell
 
+	// The short position is closed before the long position is opened and vice versa
 	if buySignal {
 		logger.Debugf("EMIT BUY")
 		a.lastBuyPrice = calcSeries.CurrentClose()
-		if a.backtest {
-			a.emit(types.NewBacktestSignal(name, series.Symbol, types.Buy, calcSeries.CurrentCandleTime()))
-		} else {
-			a.emit(types.NewSignal(name, series.Symbol, types.Buy))
+		if a.shorts {
+			a.emitSignal(series, types.Buy, types.Short, calcSeries.CurrentCandleTime())
 		}
+		a.emitSignal(series, types.Buy, types.Long, calcSeries.CurrentCandleTime())
 	}
 
 	if sellSignal1 || sellSignal2 || sellSignal3 {
 		logger.Debugf("EMIT SELL")
 		a.lastBuyPrice = 0.0
-		if a.backtest {
-			a.emit(types.NewBacktestSignal(name, series.Symbol, types.Sell, calcSeries.CurrentCandleTime()))
-		} else {
-			a.emit(types.NewSignal(name, series.Symbol, types.Sell))
+		a.emitSignal(series, types.Sell, types.Long, calcSeries.CurrentCandleTime())
+		if a.shorts {
+			a.emitSignal(series, types.Sell, types.Short, calcSeries.CurrentCandleTime())
 		}
 	}
 }
 
 func (a *Algorithm) checkBacktest(ctx context.Context, series types.Series) {
-	var minSampleLen, length int
+	var length int
 	var subSeries types.Series
 
-	minSampleLen = int(math.Max(math.Max(float64(a.smaLen), float64(a.emaLen)), float64(a.rsiLen))) + 2
-
-	for length = minSampleLen; length <= series.Length(); length++ {
+	for length = a.minSampleLen(); length <= series.Length(); length++ {
 		subSeries = series.SubSeries(0, length)
 		a.check(ctx, subSeries)
 	}
 }
 
+// minSampleLen returns the minimum number of finished candles needed to calculate the indicators
+func (a *Algorithm) minSampleLen() int {
+	return int(math.Max(math.Max(float64(a.smaLen), float64(a.emaLen)), float64(a.rsiLen))) + 2
+}
+
 func (a *Algorithm) configure(config types.AlgorithmConfig) (err error) {
 	var key, value string
+
+	if err = schema.Validate(config); err != nil {
+		return
+	}
+
 	for key, value = range config {
 		switch key {
 		case cfgSmaLen:
@@ -194,9 +226,20 @@ func (a *Algorithm) configure(config types.AlgorithmConfig) (err error) {
 				return
 			}
 		case cfgBacktest:
-			a.backtest = strings.ToLower(value) == "true"
+			if a.backtest, err = strconv.ParseBool(value); err != nil {
+				return
+			}
+		case cfgShorts:
+			if a.shorts, err = strconv.ParseBool(value); err != nil {
+				return
+			}
 		}
 	}
+
+	if a.rsiBuyMin >= a.rsiBuyMax {
+		err = fmt.Errorf("%s (%f) must be below %s (%f)", cfgRsiBuyMin, a.rsiBuyMin, cfgRsiBuyMax, a.rsiBuyMax)
+		return
+	}
 	return
 }
 
@@ -2,9 +2,12 @@ package algorithms
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/mhereman/cryptotrader/interfaces"
 	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
 )
 
 var algoFactory map[string]func() (interfaces.IAlgorithm, error) = make(map[string]func() (interfaces.IAlgorithm, error))
@@ -30,3 +33,46 @@ func GetAlgorithm(name string) (algo interfaces.IAlgorithm, err error) {
 	algo, err = fn()
 	return
 }
+
+// GetAlgorithmNames returns the sorted names of the registered algorithms
+func GetAlgorithmNames() (names []string) {
+	var name string
+
+	for name = range algoFactory {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// ValidateConfig validates the configuration against the schema of the algorithm
+// The arguments of other registered algorithms ('<name>.<param>') are left out of the returned
+// configuration, so one set of arguments can configure several algorithms.
+func ValidateConfig(algo interfaces.IAlgorithm, config types.AlgorithmConfig) (out types.AlgorithmConfig, err error) {
+	var key, value string
+
+	out = make(types.AlgorithmConfig)
+	for key, value = range config {
+		if isOtherAlgorithmParam(algo.Name(), key) {
+			continue
+		}
+		out[key] = value
+	}
+
+	if err = algo.Schema().Validate(out); err != nil {
+		err = fmt.Errorf("Invalid configuration of algorithm %s: %v", algo.Name(), err)
+		return
+	}
+	return
+}
+
+func isOtherAlgorithmParam(name string, key string) bool {
+	var other string
+
+	for other = range algoFactory {
+		if other != name && strings.HasPrefix(key, other+".") {
+			return true
+		}
+	}
+	return false
+}
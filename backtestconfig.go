@@ -0,0 +1,93 @@
+package cryptotrader
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// BacktestConfig represents the config for an offline backtest
+type BacktestConfig struct {
+	// DataFile path of the csv file containing the candles to backtest on
+	// If empty the candles are read from the candle store of the exchange
+	DataFile string
+
+	// Exchange to read the candles from, used if no data file is configured
+	Exchange ExchangeConfig
+
+	// Download the candles missing from the candle store from the exchange
+	Download bool
+
+	// Start open time of the first candle to backtest on, zero for the first available candle
+	Start time.Time
+
+	// End open time of the last candle to backtest on, zero for the last available candle
+	End time.Time
+
+	// Capital starting balance in quote asset
+	Capital float64
+
+	// MakerCommission commission for maker orders
+	// 0.1% = 0.001
+	MakerCommission float64
+
+	// TakerCommission commission for taker orders
+	// 0.1% = 0.001
+	TakerCommission float64
+}
+
+// NewBacktestConfigFromFlags creates a new BacktestConfig instance from the cmdline argument values
+func NewBacktestConfigFromFlags(dataFile string, exchangeConfig ExchangeConfig, download bool, start string, end string, capital float64, makerCommission float64, takerCommission float64) (bc BacktestConfig, err error) {
+	if dataFile == "" && exchangeConfig.CandleStore == "" {
+		err = fmt.Errorf("No backtest data file or candle store configured")
+		return
+	}
+	if bc.Start, err = parseBacktestTime(start); err != nil {
+		err = fmt.Errorf("Invalid backtest start: %s %v", start, err)
+		return
+	}
+	if bc.End, err = parseBacktestTime(end); err != nil {
+		err = fmt.Errorf("Invalid backtest end: %s %v", end, err)
+		return
+	}
+	if !bc.Start.IsZero() && !bc.End.IsZero() && !bc.End.After(bc.Start) {
+		err = fmt.Errorf("Backtest end %s is not after start %s", end, start)
+		return
+	}
+	if capital <= 0.0 {
+		err = fmt.Errorf("Invalid backtest capital: %f", capital)
+		return
+	}
+
+	bc.DataFile = dataFile
+	bc.Exchange = exchangeConfig
+	bc.Download = download
+	bc.Capital = capital
+	bc.MakerCommission = makerCommission
+	bc.TakerCommission = takerCommission
+	return
+}
+
+// AccountInfo returns the simulated account to start the backtest with
+func (bc BacktestConfig) AccountInfo(symbol types.Symbol) types.AccountInfo {
+	return types.NewAccountInfo(
+		bc.MakerCommission,
+		bc.TakerCommission,
+		0.0,
+		0.0,
+		[]types.AccountBalance{types.NewAccountBalance(symbol.Quote(), bc.Capital, 0.0)},
+	)
+}
+
+// parseBacktestTime parses a date or RFC3339 time, an empty string results in the zero time
+func parseBacktestTime(in string) (t time.Time, err error) {
+	if in == "" {
+		return
+	}
+	if t, err = time.Parse("2006-01-02", in); err == nil {
+		return
+	}
+	t, err = time.Parse(time.RFC3339, in)
+	return
+}
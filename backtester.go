@@ -0,0 +1,180 @@
+package cryptotrader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mhereman/cryptotrader/algorithms"
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// backtestLookback is the max number of candles passed to the algorithm on every step,
+// this matches the number of candles a live exchange returns by default
+const backtestLookback = 500
+
+// Backtester replays a series through an algorithm and simulates the resulting trades
+type Backtester struct {
+	algoCfg     AlgorithmConfig
+	tradeCfg    TradeConfig
+	accountInfo types.AccountInfo
+}
+
+// NewBacktester creates a new Backtester instance
+// The free balance of the quote asset in the account info is used as starting capital
+// and its maker and taker commissions are applied to the simulated fills.
+func NewBacktester(algorithmConfig AlgorithmConfig, tradeConfig TradeConfig, accountInfo types.AccountInfo) (bt *Backtester) {
+	bt = &Backtester{
+		algoCfg:     algorithmConfig,
+		tradeCfg:    tradeConfig,
+		accountInfo: accountInfo,
+	}
+	return
+}
+
+// Run replays the series candle by candle through a new instance of the configured algorithm
+// Signals computed on the finished candles are executed at the open price of the next candle.
+func (bt *Backtester) Run(ctx context.Context, series types.Series) (result BacktestResult, err error) {
+	result, err = bt.RunFrom(ctx, series, 1)
+	return
+}
+
+// RunFrom replays the series like Run, but only simulates the trades from the candle at index start
+// The candles before start only warm up the indicators of the algorithm.
+func (bt *Backtester) RunFrom(ctx context.Context, series types.Series, start int) (result BacktestResult, err error) {
+	var algorithm interfaces.IAlgorithm
+	var algoConfig types.AlgorithmConfig
+	var runCtx context.Context
+	var cancelFn context.CancelFunc
+	var wg sync.WaitGroup
+	var seriesChannel types.SeriesChannel
+	var signalChannel types.SignalChannel
+	var signals []types.Signal
+	var sim *backtestSimulation
+	var index int
+	var stopped bool
+
+	if series.Length() < 2 {
+		err = fmt.Errorf("Series %s[%s] contains less than 2 candles", series.Symbol.String(), series.Timeframe.String())
+		logger.Errorf("Backtester::Run Error %v\n", err)
+		return
+	}
+	if start < 1 || start >= series.Length() {
+		err = fmt.Errorf("Backtest start %d outside of series %s[%s] of %d candles", start, series.Symbol.String(), series.Timeframe.String(), series.Length())
+		logger.Errorf("Backtester::Run Error %v\n", err)
+		return
+	}
+
+	if algorithm, err = algorithms.GetAlgorithm(bt.algoCfg.Name); err != nil {
+		logger.Errorf("Backtester::Run Error %v\n", err)
+		return
+	}
+	if algoConfig, err = algorithms.ValidateConfig(algorithm, bt.algoCfg.Config); err != nil {
+		logger.Errorf("Backtester::Run Error %v\n", err)
+		return
+	}
+
+	runCtx, cancelFn = context.WithCancel(ctx)
+	seriesChannel = make(types.SeriesChannel)
+	signalChannel = make(types.SignalChannel)
+	if err = algorithm.RunAsync(runCtx, algoConfig, seriesChannel, signalChannel, &wg); err != nil {
+		cancelFn()
+		logger.Errorf("Backtester::Run Error %v\n", err)
+		return
+	}
+	defer func() {
+		if !stopped {
+			stopAlgorithm(cancelFn, &wg, signalChannel)
+		}
+	}()
+
+	sim = newBacktestSimulation(bt.tradeCfg, bt.accountInfo, series)
+
+	// The algorithm handles the series sequentially, so all signals for a window
+	// have been received once it accepts the next window.
+	if _, err = feedAlgorithm(runCtx, seriesChannel, signalChannel, backtestWindow(series, 1)); err != nil {
+		return
+	}
+	for index = 1; index < series.Length(); index++ {
+		if index+1 < series.Length() {
+			if signals, err = feedAlgorithm(runCtx, seriesChannel, signalChannel, backtestWindow(series, index+1)); err != nil {
+				return
+			}
+		} else {
+			signals = stopAlgorithm(cancelFn, &wg, signalChannel)
+			stopped = true
+		}
+
+		if index < start {
+			continue
+		}
+		sim.executeSignals(signals, index)
+		sim.markCandle(index)
+	}
+
+	result = sim.finish()
+	return
+}
+
+// backtestWindow returns the series as the algorithm would receive it when the candle at index is the active candle
+// The active candle only contains its open price.
+func backtestWindow(series types.Series, index int) (window types.Series) {
+	var start int
+	var candles []types.OHLC
+	var active types.OHLC
+
+	start = index + 1 - backtestLookback
+	if start < 0 {
+		start = 0
+	}
+
+	candles = make([]types.OHLC, index+1-start)
+	copy(candles, series.Candles[start:index+1])
+
+	active = candles[len(candles)-1]
+	candles[len(candles)-1] = types.NewOHLC(active.Open, active.Open, active.Open, active.Open, 0.0, active.OpenTime, active.CloseTime)
+
+	window = types.NewSeries(series.Symbol, series.Timeframe, candles)
+	return
+}
+
+// feedAlgorithm passes the window to the algorithm and collects the signals emitted while waiting
+func feedAlgorithm(ctx context.Context, seriesChannel types.SeriesChannel, signalChannel types.SignalChannel, window types.Series) (signals []types.Signal, err error) {
+	var signal types.Signal
+
+	for {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case seriesChannel <- window:
+			return
+		case signal = <-signalChannel:
+			signals = append(signals, signal)
+		}
+	}
+}
+
+// stopAlgorithm stops the algorithm and collects the signals it emits until it has finished
+func stopAlgorithm(cancelFn context.CancelFunc, wg *sync.WaitGroup, signalChannel types.SignalChannel) (signals []types.Signal) {
+	var done chan struct{}
+	var signal types.Signal
+
+	done = make(chan struct{})
+	cancelFn()
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		case signal = <-signalChannel:
+			signals = append(signals, signal)
+		}
+	}
+}
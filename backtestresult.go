@@ -0,0 +1,534 @@
+package cryptotrader
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// BacktestExitReason represents the reason a backtest trade was closed
+type BacktestExitReason int
+
+const (
+	// ExitSignal the trade was closed by a signal of the algorithm
+	ExitSignal BacktestExitReason = iota
+
+	// ExitStopLoss the trade was closed by the stop loss
+	ExitStopLoss
+
+	// ExitEndOfData the trade was still open at the end of the series
+	ExitEndOfData
+
+	// ExitTakeProfit the trade was closed by the take profit target
+	ExitTakeProfit
+
+	// ExitLiquidation the leveraged trade lost its margin
+	ExitLiquidation
+)
+
+// String returns the string representation of the BacktestExitReason
+func (r BacktestExitReason) String() string {
+	switch r {
+	case ExitSignal:
+		return "signal"
+	case ExitStopLoss:
+		return "stoploss"
+	case ExitTakeProfit:
+		return "takeprofit"
+	case ExitLiquidation:
+		return "liquidation"
+	default:
+		return "end of data"
+	}
+}
+
+// BacktestTrade represents a simulated round trip trade
+type BacktestTrade struct {
+	// Symbol of the trade
+	Symbol types.Symbol
+
+	// Position side of the trade
+	Position types.PositionSide
+
+	// EntryTime time the position was opened
+	EntryTime time.Time
+
+	// EntryPrice average price of the order opening the position in quote asset
+	EntryPrice float64
+
+	// ExitTime time the position was closed
+	ExitTime time.Time
+
+	// ExitPrice average price of the order closing the position in quote asset
+	ExitPrice float64
+
+	// Quantity of the trade in base asset
+	Quantity float64
+
+	// Fees payed for opening and closing the position in quote asset
+	Fees float64
+
+	// Profit net profit (or loss) of the trade in quote asset
+	Profit float64
+
+	// Return net profit relative to the margin of the trade, without leverage the cost of the buy
+	Return float64
+
+	// ExitReason the reason the trade was closed
+	ExitReason BacktestExitReason
+}
+
+// EquityPoint represents the value of the account at the close of a candle
+type EquityPoint struct {
+	// Time of the candle close
+	Time time.Time
+
+	// Equity quote asset balance plus the margin and the profit of the open position
+	Equity float64
+}
+
+// BacktestStatistics represents the summary of a backtest
+type BacktestStatistics struct {
+	// InitialCapital in quote asset
+	InitialCapital float64
+
+	// FinalEquity in quote asset
+	FinalEquity float64
+
+	// NetProfit in quote asset
+	NetProfit float64
+
+	// NetProfitPct net profit relative to the initial capital
+	NetProfitPct float64
+
+	// NumTrades number of round trip trades
+	NumTrades int
+
+	// WinningTrades number of trades with a profit
+	WinningTrades int
+
+	// LosingTrades number of trades without a profit
+	LosingTrades int
+
+	// WinRate ratio of winning trades
+	WinRate float64
+
+	// GrossProfit sum of the profits of the winning trades
+	GrossProfit float64
+
+	// GrossLoss sum of the losses of the losing trades (positive number)
+	GrossLoss float64
+
+	// ProfitFactor gross profit divided by gross loss
+	ProfitFactor float64
+
+	// TotalFees payed in quote asset
+	TotalFees float64
+
+	// MaxDrawdown largest peak to valley decline of the equity in quote asset
+	MaxDrawdown float64
+
+	// MaxDrawdownPct largest peak to valley decline of the equity relative to the peak
+	MaxDrawdownPct float64
+
+	// SharpeRatio annualized sharpe ratio of the per candle equity returns (risk free rate of 0)
+	SharpeRatio float64
+}
+
+// BacktestResult represents the outcome of a backtest
+type BacktestResult struct {
+	// Symbol of the backtested series
+	Symbol types.Symbol
+
+	// Timeframe of the backtested series
+	Timeframe types.Timeframe
+
+	// Trades the simulated trades
+	Trades []BacktestTrade
+
+	// EquityCurve equity at the close of every simulated candle
+	EquityCurve []EquityPoint
+
+	// Statistics summary of the backtest
+	Statistics BacktestStatistics
+}
+
+// WriteReport writes a human readable report of the backtest
+func (r BacktestResult) WriteReport(w io.Writer) (err error) {
+	var tw *tabwriter.Writer
+	var trade BacktestTrade
+	var stats BacktestStatistics
+	var index int
+
+	stats = r.Statistics
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Backtest %s[%s]\n\n", r.Symbol.String(), r.Timeframe.String())
+	if len(r.Trades) > 0 {
+		fmt.Fprintf(tw, "#\tPosition\tEntry time\tEntry price\tExit time\tExit price\tQuantity\tFees\tProfit\tReturn\tExit reason\n")
+		for index, trade = range r.Trades {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%f\t%s\t%f\t%f\t%f\t%f\t%.2f%%\t%s\n",
+				index+1,
+				trade.Position.String(),
+				trade.EntryTime.UTC().Format(time.RFC3339),
+				trade.EntryPrice,
+				trade.ExitTime.UTC().Format(time.RFC3339),
+				trade.ExitPrice,
+				trade.Quantity,
+				trade.Fees,
+				trade.Profit,
+				trade.Return*100.0,
+				trade.ExitReason.String(),
+			)
+		}
+		fmt.Fprintln(tw)
+	}
+
+	writeStatistics(tw, stats)
+
+	err = tw.Flush()
+	return
+}
+
+// writeStatistics writes the statistics as tab separated lines
+func writeStatistics(w io.Writer, stats BacktestStatistics) {
+	fmt.Fprintf(w, "Initial capital:\t%f\n", stats.InitialCapital)
+	fmt.Fprintf(w, "Final equity:\t%f\n", stats.FinalEquity)
+	fmt.Fprintf(w, "Net profit:\t%f (%.2f%%)\n", stats.NetProfit, stats.NetProfitPct*100.0)
+	fmt.Fprintf(w, "Trades:\t%d (won: %d, lost: %d)\n", stats.NumTrades, stats.WinningTrades, stats.LosingTrades)
+	fmt.Fprintf(w, "Win rate:\t%.2f%%\n", stats.WinRate*100.0)
+	fmt.Fprintf(w, "Gross profit:\t%f\n", stats.GrossProfit)
+	fmt.Fprintf(w, "Gross loss:\t%f\n", stats.GrossLoss)
+	fmt.Fprintf(w, "Profit factor:\t%.2f\n", stats.ProfitFactor)
+	fmt.Fprintf(w, "Fees:\t%f\n", stats.TotalFees)
+	fmt.Fprintf(w, "Max drawdown:\t%f (%.2f%%)\n", stats.MaxDrawdown, stats.MaxDrawdownPct*100.0)
+	fmt.Fprintf(w, "Sharpe ratio:\t%.2f\n", stats.SharpeRatio)
+}
+
+type backtestSimulation struct {
+	tradeCfg        TradeConfig
+	series          types.Series
+	makerCommission float64
+	takerCommission float64
+	initialCapital  float64
+	quote           float64
+	position        *BacktestTrade
+	margin          float64
+	stopLoss        float64
+	stopLossLimit   float64
+	highPrice       float64
+	takeProfit      float64
+	liquidation     float64
+	trades          []BacktestTrade
+	equityCurve     []EquityPoint
+}
+
+func newBacktestSimulation(tradeConfig TradeConfig, accountInfo types.AccountInfo, series types.Series) (sim *backtestSimulation) {
+	sim = &backtestSimulation{
+		tradeCfg:        tradeConfig,
+		series:          series,
+		makerCommission: accountInfo.MakerCommission,
+		takerCommission: accountInfo.TakerCommission,
+		trades:          make([]BacktestTrade, 0),
+		equityCurve:     make([]EquityPoint, 0, series.Length()),
+	}
+	sim.quote, _ = accountInfo.GetAssetQuantity(series.Symbol.Quote())
+	sim.initialCapital = sim.quote
+	return
+}
+
+func (sim *backtestSimulation) executeSignals(signals []types.Signal, index int) {
+	var signal types.Signal
+	var candle types.OHLC
+
+	candle = sim.series.Candles[index]
+	for _, signal = range signals {
+		if signal.Position == types.Short && !sim.tradeCfg.Shorts {
+			continue
+		}
+		if signal.Opens() {
+			sim.open(candle, signal.Position)
+		} else if sim.position == nil || sim.position.Position == signal.Position {
+			sim.close(candle.OpenTime, candle.Open, sim.takerCommission, ExitSignal)
+		}
+	}
+}
+
+func (sim *backtestSimulation) open(candle types.OHLC, position types.PositionSide) {
+	var orderQuantity, price, baseQuantity, cost, margin, fees, leverage float64
+
+	if sim.position != nil {
+		logger.Debugf("Backtester: Trade still open for symbol: %s\n", sim.series.Symbol.String())
+		return
+	}
+
+	if sim.tradeCfg.TradeVolumeType == TVTFixed {
+		orderQuantity = sim.tradeCfg.Volume
+		if (sim.quote * maxPctVolume) < orderQuantity {
+			if sim.tradeCfg.Reduce == false {
+				logger.Debugf("Backtester: Insufficient funds to initiate %s position (required: %f, available: %f)\n", position.String(), orderQuantity, sim.quote*maxPctVolume)
+				return
+			}
+			orderQuantity = sim.quote * maxPctVolume
+		}
+	} else {
+		orderQuantity = sim.quote * sim.tradeCfg.Volume
+	}
+
+	// A limit order with max slippage is assumed to be filled at its limit price
+	price = candle.Open * (1.0 + sim.tradeCfg.MaxSlippage)
+	if position == types.Short {
+		price = candle.Open * (1.0 - sim.tradeCfg.MaxSlippage)
+	}
+	baseQuantity = normalizeQuantity(sim.tradeCfg.PositionSize(orderQuantity) / price)
+	cost = baseQuantity * price
+	leverage = sim.tradeCfg.PositionSize(1.0)
+	margin = cost / leverage
+	fees = cost * sim.takerCommission
+	if baseQuantity <= 0.0 || margin+fees > sim.quote {
+		logger.Debugf("Backtester: Insufficient funds to initiate %s position (required: %f, available: %f)\n", position.String(), margin+fees, sim.quote)
+		return
+	}
+
+	sim.quote -= margin + fees
+	sim.margin = margin
+	sim.position = &BacktestTrade{
+		Symbol:     sim.series.Symbol,
+		Position:   position,
+		EntryTime:  candle.OpenTime,
+		EntryPrice: price,
+		Quantity:   baseQuantity,
+		Fees:       fees,
+	}
+
+	sim.stopLoss = 0.0
+	sim.stopLossLimit = 0.0
+	sim.highPrice = price
+	sim.takeProfit = sim.tradeCfg.TakeProfitPrice(price, position)
+	if sim.tradeCfg.StopLoss > 0.0 {
+		sim.stopLoss, sim.stopLossLimit = sim.tradeCfg.StopLossPrices(price, sim.tradeCfg.StopLoss, position)
+	}
+
+	// Without maintenance margin the position is liquidated when its loss equals its margin
+	sim.liquidation = 0.0
+	if leverage > 1.0 {
+		sim.liquidation = price * (1.0 - 1.0/leverage)
+		if position == types.Short {
+			sim.liquidation = price * (1.0 + 1.0/leverage)
+		}
+	}
+}
+
+func (sim *backtestSimulation) close(tm time.Time, price float64, commission float64, reason BacktestExitReason) {
+	var trade BacktestTrade
+	var profit, fees float64
+
+	if sim.position == nil {
+		logger.Debugf("Backtester: No trade to close for symbol: %s\n", sim.series.Symbol.String())
+		return
+	}
+
+	trade = *sim.position
+	profit = sim.profit(price)
+	fees = trade.Quantity * price * commission
+	sim.quote += sim.margin + profit - fees
+
+	trade.ExitTime = tm
+	trade.ExitPrice = price
+	trade.Fees += fees
+	trade.Profit = profit - trade.Fees
+	trade.Return = trade.Profit / sim.margin
+	trade.ExitReason = reason
+
+	sim.trades = append(sim.trades, trade)
+	sim.position = nil
+	sim.margin = 0.0
+}
+
+// profit returns the profit of the open position at the price, before fees
+func (sim *backtestSimulation) profit(price float64) float64 {
+	if sim.position.Position == types.Short {
+		return sim.position.Quantity * (sim.position.EntryPrice - price)
+	}
+	return sim.position.Quantity * (price - sim.position.EntryPrice)
+}
+
+func (sim *backtestSimulation) markCandle(index int) {
+	var candle types.OHLC
+	var equity float64
+
+	candle = sim.series.Candles[index]
+	if sim.position != nil {
+		sim.checkExits(candle)
+	}
+
+	equity = sim.quote
+	if sim.position != nil {
+		equity += sim.margin + sim.profit(candle.Close)
+	}
+	sim.equityCurve = append(sim.equityCurve, EquityPoint{
+		Time:   candle.CloseTime,
+		Equity: equity,
+	})
+}
+
+// checkExits closes the position if the candle reached its stop loss, liquidation price or take profit
+// The extreme of the candle against the position is its low for a long position and its high for a short position.
+// The moment within the candle the price was reached is unknown, so the exit is stamped with the close time
+// of the candle, unless the candle opened beyond the exit price.
+func (sim *backtestSimulation) checkExits(candle types.OHLC) {
+	var position types.PositionSide
+	var adverse, favorable, price float64
+	var tm time.Time
+
+	position = sim.position.Position
+	adverse, favorable = candle.Low, candle.High
+	if position == types.Short {
+		adverse, favorable = candle.High, candle.Low
+	}
+
+	// A stop loss beyond the liquidation price is never reached
+	if sim.stopLoss > 0.0 && !priceImproves(position, adverse, sim.stopLoss) &&
+		(sim.liquidation <= 0.0 || priceImproves(position, sim.stopLoss, sim.liquidation)) {
+		// On a gap beyond the stop the position is closed at the open price
+		price, tm = sim.stopLossLimit, candle.CloseTime
+		if priceImproves(position, sim.stopLoss, candle.Open) {
+			price, tm = candle.Open, candle.OpenTime
+		}
+		sim.close(tm, price, sim.makerCommission, ExitStopLoss)
+	}
+
+	if sim.position != nil && sim.liquidation > 0.0 && !priceImproves(position, adverse, sim.liquidation) {
+		tm = candle.CloseTime
+		if !priceImproves(position, candle.Open, sim.liquidation) {
+			tm = candle.OpenTime
+		}
+		sim.close(tm, sim.liquidation, sim.takerCommission, ExitLiquidation)
+	}
+
+	// When both the stop loss and the take profit are within the candle, the stop loss is assumed to fill first
+	if sim.position != nil && sim.takeProfit > 0.0 && !priceImproves(position, sim.takeProfit, favorable) {
+		// On a gap beyond the target the position is closed at the open price
+		price, tm = sim.takeProfit, candle.CloseTime
+		if priceImproves(position, candle.Open, sim.takeProfit) {
+			price, tm = candle.Open, candle.OpenTime
+		}
+		sim.close(tm, price, sim.makerCommission, ExitTakeProfit)
+	}
+
+	// The stop only trails the high after the candle has been checked against the current stop,
+	// the order of the high and the low within the candle is unknown
+	if sim.position != nil && sim.tradeCfg.TrailingStop > 0.0 {
+		sim.trailStopLoss(favorable)
+	}
+}
+
+// trailStopLoss moves the stop loss when the price made a new high, or a new low for a short position
+func (sim *backtestSimulation) trailStopLoss(price float64) {
+	var position types.PositionSide
+	var stopLoss, stopLossLimit float64
+
+	position = sim.position.Position
+	if !priceImproves(position, price, sim.highPrice) {
+		return
+	}
+	sim.highPrice = price
+
+	stopLoss, stopLossLimit = sim.tradeCfg.StopLossPrices(price, sim.tradeCfg.TrailingStop, position)
+	if sim.stopLoss <= 0.0 || priceImproves(position, stopLoss, sim.stopLoss) {
+		sim.stopLoss = stopLoss
+		sim.stopLossLimit = stopLossLimit
+	}
+}
+
+func (sim *backtestSimulation) finish() (result BacktestResult) {
+	var last types.OHLC
+
+	if sim.position != nil {
+		last = sim.series.Candles[sim.series.Length()-1]
+		sim.close(last.CloseTime, last.Close, sim.takerCommission, ExitEndOfData)
+		sim.equityCurve[len(sim.equityCurve)-1].Equity = sim.quote
+	}
+
+	result.Symbol = sim.series.Symbol
+	result.Timeframe = sim.series.Timeframe
+	result.Trades = sim.trades
+	result.EquityCurve = sim.equityCurve
+	result.Statistics = calculateStatistics(sim.initialCapital, sim.trades, sim.equityCurve, sim.series.Timeframe)
+	return
+}
+
+func calculateStatistics(initialCapital float64, trades []BacktestTrade, equityCurve []EquityPoint, timeframe types.Timeframe) (stats BacktestStatistics) {
+	var trade BacktestTrade
+	var point EquityPoint
+	var peak, drawdown, previous, ret, sum, sumSquares, mean, variance, periodsPerYear float64
+	var numReturns int
+
+	stats.InitialCapital = initialCapital
+	stats.FinalEquity = initialCapital
+	if len(equityCurve) > 0 {
+		stats.FinalEquity = equityCurve[len(equityCurve)-1].Equity
+	}
+	stats.NetProfit = stats.FinalEquity - initialCapital
+	if initialCapital > 0.0 {
+		stats.NetProfitPct = stats.NetProfit / initialCapital
+	}
+
+	stats.NumTrades = len(trades)
+	for _, trade = range trades {
+		stats.TotalFees += trade.Fees
+		if trade.Profit > 0.0 {
+			stats.WinningTrades++
+			stats.GrossProfit += trade.Profit
+		} else {
+			stats.LosingTrades++
+			stats.GrossLoss -= trade.Profit
+		}
+	}
+	if stats.NumTrades > 0 {
+		stats.WinRate = float64(stats.WinningTrades) / float64(stats.NumTrades)
+	}
+	if stats.GrossLoss > 0.0 {
+		stats.ProfitFactor = stats.GrossProfit / stats.GrossLoss
+	} else if stats.GrossProfit > 0.0 {
+		stats.ProfitFactor = math.Inf(1)
+	}
+
+	peak = initialCapital
+	previous = initialCapital
+	for _, point = range equityCurve {
+		if point.Equity > peak {
+			peak = point.Equity
+		}
+		drawdown = peak - point.Equity
+		if drawdown > stats.MaxDrawdown {
+			stats.MaxDrawdown = drawdown
+		}
+		if peak > 0.0 && drawdown/peak > stats.MaxDrawdownPct {
+			stats.MaxDrawdownPct = drawdown / peak
+		}
+
+		if previous > 0.0 {
+			ret = point.Equity/previous - 1.0
+			sum += ret
+			sumSquares += ret * ret
+			numReturns++
+		}
+		previous = point.Equity
+	}
+
+	if numReturns > 1 && timeframe.Duration() > 0 {
+		mean = sum / float64(numReturns)
+		variance = (sumSquares - float64(numReturns)*mean*mean) / float64(numReturns-1)
+		if variance > 0.0 {
+			periodsPerYear = float64(time.Hour*24*365) / float64(timeframe.Duration())
+			stats.SharpeRatio = mean / math.Sqrt(variance) * math.Sqrt(periodsPerYear)
+		}
+	}
+	return
+}
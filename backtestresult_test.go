@@ -0,0 +1,193 @@
+package cryptotrader
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	testMakerCommission = 0.001
+	testTakerCommission = 0.002
+)
+
+// backtestSeries creates an hourly series of the test symbol from open, high, low, close prices
+func backtestSeries(prices [][4]float64) types.Series {
+	var candles []types.OHLC
+	var openTime time.Time
+	var index int
+
+	for index = range prices {
+		openTime = testOpenTime.Add(time.Hour * time.Duration(index))
+		candles = append(candles, types.NewOHLC(prices[index][0], prices[index][1], prices[index][2], prices[index][3], 1.0, openTime, openTime.Add(time.Hour-time.Millisecond)))
+	}
+	return types.NewSeries(testSymbol, types.NewTimeframe(1, types.TuHour), candles)
+}
+
+func almostEqual(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestBacktestSimulation(t *testing.T) {
+	var tests = []struct {
+		name     string
+		tradeCfg TradeConfig
+		prices   [][4]float64
+		signals  map[int]types.Signal
+		// expected trade
+		entry      float64
+		exit       float64
+		exitIndex  int
+		exitAtOpen bool
+		fees       float64
+		profit     float64
+		reason     BacktestExitReason
+		// expected statistics
+		netProfit   float64
+		winRate     float64
+		maxDrawdown float64
+	}{
+		{
+			name:     "signal round trip with slippage",
+			tradeCfg: TradeConfig{TradeVolumeType: TVTFixed, Volume: 125.0, MaxSlippage: 0.25},
+			prices:   [][4]float64{{100, 100, 100, 100}, {100, 130, 100, 130}, {130, 150, 120, 140}, {150, 150, 120, 120}},
+			signals: map[int]types.Signal{
+				1: types.NewBacktestSignal("test", testSymbol, types.Buy, testOpenTime),
+				3: types.NewBacktestSignal("test", testSymbol, types.Sell, testOpenTime),
+			},
+			// Bought 1 at 125 (taker 0.25), sold at the open 150 without slippage (taker 0.3)
+			entry: 125.0, exit: 150.0, exitIndex: 3, exitAtOpen: true, fees: 0.55, profit: 24.45, reason: ExitSignal,
+			netProfit: 24.45, winRate: 1.0, maxDrawdown: 0.0,
+		},
+		{
+			name:     "stop loss within the candle",
+			tradeCfg: TradeConfig{TradeVolumeType: TVTFixed, Volume: 100.0, StopLoss: 0.1},
+			prices:   [][4]float64{{100, 100, 100, 100}, {100, 102, 95, 100}, {98, 99, 85, 88}, {88, 88, 88, 88}},
+			signals: map[int]types.Signal{
+				1: types.NewBacktestSignal("test", testSymbol, types.Buy, testOpenTime),
+			},
+			// Bought 1 at 100 (taker 0.2), stopped at 90 (maker 0.09)
+			entry: 100.0, exit: 90.0, exitIndex: 2, exitAtOpen: false, fees: 0.29, profit: -10.29, reason: ExitStopLoss,
+			netProfit: -10.29, winRate: 0.0, maxDrawdown: 10.29,
+		},
+		{
+			name:     "stop loss on a gap",
+			tradeCfg: TradeConfig{TradeVolumeType: TVTFixed, Volume: 100.0, StopLoss: 0.1},
+			prices:   [][4]float64{{100, 100, 100, 100}, {100, 102, 95, 100}, {80, 85, 78, 82}, {82, 82, 82, 82}},
+			signals: map[int]types.Signal{
+				1: types.NewBacktestSignal("test", testSymbol, types.Buy, testOpenTime),
+			},
+			// Stopped at the open 80 (maker 0.08)
+			entry: 100.0, exit: 80.0, exitIndex: 2, exitAtOpen: true, fees: 0.28, profit: -20.28, reason: ExitStopLoss,
+			netProfit: -20.28, winRate: 0.0, maxDrawdown: 20.28,
+		},
+		{
+			name:     "take profit",
+			tradeCfg: TradeConfig{TradeVolumeType: TVTFixed, Volume: 100.0, TakeProfit: 0.1},
+			prices:   [][4]float64{{100, 100, 100, 100}, {100, 104, 99, 99}, {105, 112, 104, 111}, {111, 111, 111, 111}},
+			signals: map[int]types.Signal{
+				1: types.NewBacktestSignal("test", testSymbol, types.Buy, testOpenTime),
+			},
+			// Target 110 reached within the candle (maker 0.11) after closing the first candle at a loss of 1.2
+			entry: 100.0, exit: 110.0, exitIndex: 2, exitAtOpen: false, fees: 0.31, profit: 9.69, reason: ExitTakeProfit,
+			netProfit: 9.69, winRate: 1.0, maxDrawdown: 1.2,
+		},
+		{
+			name:     "leveraged short liquidated",
+			tradeCfg: TradeConfig{TradeVolumeType: TVTFixed, Volume: 100.0, Shorts: true, Leverage: 2},
+			prices:   [][4]float64{{100, 100, 100, 100}, {100, 101, 99, 100}, {120, 160, 118, 155}, {155, 155, 155, 155}},
+			signals: map[int]types.Signal{
+				1: types.NewBacktestPositionSignal("test", testSymbol, types.Sell, types.Short, testOpenTime),
+			},
+			// Sold 2 at 100 on a margin of 100 (taker 0.4), liquidated at 150 (taker 0.6)
+			entry: 100.0, exit: 150.0, exitIndex: 2, exitAtOpen: false, fees: 1.0, profit: -101.0, reason: ExitLiquidation,
+			netProfit: -101.0, winRate: 0.0, maxDrawdown: 101.0,
+		},
+	}
+	var sim *backtestSimulation
+	var series types.Series
+	var result BacktestResult
+	var trade BacktestTrade
+	var exitTime time.Time
+	var accountInfo = types.NewAccountInfo(testMakerCommission, testTakerCommission, 0.0, 0.0, []types.AccountBalance{types.NewAccountBalance("USDT", 1000.0, 0.0)})
+	var signals []types.Signal
+	var signal types.Signal
+	var ok bool
+	var index, candle int
+
+	for index = range tests {
+		series = backtestSeries(tests[index].prices)
+		sim = newBacktestSimulation(tests[index].tradeCfg, accountInfo, series)
+		for candle = 1; candle < series.Length(); candle++ {
+			signals = nil
+			if signal, ok = tests[index].signals[candle]; ok {
+				signals = []types.Signal{signal}
+			}
+			sim.executeSignals(signals, candle)
+			sim.markCandle(candle)
+		}
+		result = sim.finish()
+
+		if len(result.Trades) != 1 {
+			t.Errorf("%s: got %d trades, want 1", tests[index].name, len(result.Trades))
+			continue
+		}
+		trade = result.Trades[0]
+		exitTime = series.Candles[tests[index].exitIndex].CloseTime
+		if tests[index].exitAtOpen {
+			exitTime = series.Candles[tests[index].exitIndex].OpenTime
+		}
+		if !almostEqual(trade.EntryPrice, tests[index].entry) || !almostEqual(trade.ExitPrice, tests[index].exit) || trade.ExitReason != tests[index].reason {
+			t.Errorf("%s: got %f -> %f (%s), want %f -> %f (%s)", tests[index].name, trade.EntryPrice, trade.ExitPrice, trade.ExitReason.String(), tests[index].entry, tests[index].exit, tests[index].reason.String())
+		}
+		if !trade.EntryTime.Equal(series.Candles[1].OpenTime) || !trade.ExitTime.Equal(exitTime) {
+			t.Errorf("%s: got trade from %v to %v, want from %v to %v", tests[index].name, trade.EntryTime, trade.ExitTime, series.Candles[1].OpenTime, exitTime)
+		}
+		if !almostEqual(trade.Fees, tests[index].fees) || !almostEqual(trade.Profit, tests[index].profit) {
+			t.Errorf("%s: got fees %f profit %f, want fees %f profit %f", tests[index].name, trade.Fees, trade.Profit, tests[index].fees, tests[index].profit)
+		}
+
+		if !almostEqual(result.Statistics.NetProfit, tests[index].netProfit) || !almostEqual(result.Statistics.TotalFees, tests[index].fees) {
+			t.Errorf("%s: got net profit %f fees %f, want %f %f", tests[index].name, result.Statistics.NetProfit, result.Statistics.TotalFees, tests[index].netProfit, tests[index].fees)
+		}
+		if result.Statistics.WinRate != tests[index].winRate || !almostEqual(result.Statistics.MaxDrawdown, tests[index].maxDrawdown) {
+			t.Errorf("%s: got win rate %f max drawdown %f, want %f %f", tests[index].name, result.Statistics.WinRate, result.Statistics.MaxDrawdown, tests[index].winRate, tests[index].maxDrawdown)
+		}
+		if !almostEqual(result.Statistics.FinalEquity, 1000.0+tests[index].netProfit) || len(result.EquityCurve) != series.Length()-1 {
+			t.Errorf("%s: got final equity %f over %d candles", tests[index].name, result.Statistics.FinalEquity, len(result.EquityCurve))
+		}
+	}
+}
+
+func TestCalculateStatistics(t *testing.T) {
+	var trades = []BacktestTrade{{Profit: 10.0, Fees: 0.5}, {Profit: -11.0, Fees: 0.5}, {Profit: 9.9, Fees: 0.4}}
+	var equityCurve = []EquityPoint{{Equity: 110.0}, {Equity: 99.0}, {Equity: 108.9}}
+	var stats BacktestStatistics
+
+	stats = calculateStatistics(100.0, trades, equityCurve, types.NewTimeframe(1, types.TuDay))
+
+	if !almostEqual(stats.NetProfit, 8.9) || !almostEqual(stats.NetProfitPct, 0.089) {
+		t.Errorf("net profit: got %f (%f)", stats.NetProfit, stats.NetProfitPct)
+	}
+	if stats.NumTrades != 3 || stats.WinningTrades != 2 || stats.LosingTrades != 1 || !almostEqual(stats.WinRate, 2.0/3.0) {
+		t.Errorf("trades: got %d (won %d, lost %d, rate %f)", stats.NumTrades, stats.WinningTrades, stats.LosingTrades, stats.WinRate)
+	}
+	if !almostEqual(stats.GrossProfit, 19.9) || !almostEqual(stats.GrossLoss, 11.0) || !almostEqual(stats.ProfitFactor, 19.9/11.0) || !almostEqual(stats.TotalFees, 1.4) {
+		t.Errorf("profit: got gross %f/%f factor %f fees %f", stats.GrossProfit, stats.GrossLoss, stats.ProfitFactor, stats.TotalFees)
+	}
+	if !almostEqual(stats.MaxDrawdown, 11.0) || !almostEqual(stats.MaxDrawdownPct, 0.1) {
+		t.Errorf("drawdown: got %f (%f), want 11 (0.1)", stats.MaxDrawdown, stats.MaxDrawdownPct)
+	}
+
+	// Daily returns of +10%, -10%, +10% have a mean of 1/30 and a standard deviation of 2/sqrt(300)
+	if !almostEqual(stats.SharpeRatio, math.Sqrt(365.0/12.0)) {
+		t.Errorf("sharpe ratio: got %f, want %f", stats.SharpeRatio, math.Sqrt(365.0/12.0))
+	}
+
+	stats = calculateStatistics(100.0, nil, []EquityPoint{{Equity: 100.0}, {Equity: 100.0}}, types.NewTimeframe(1, types.TuDay))
+	if stats.WinRate != 0.0 || stats.ProfitFactor != 0.0 || stats.SharpeRatio != 0.0 || stats.MaxDrawdown != 0.0 {
+		t.Errorf("without trades: got %+v", stats)
+	}
+}
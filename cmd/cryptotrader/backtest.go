@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mhereman/cryptotrader"
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/history"
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+func runBacktest(args []string) (err error) {
+	var assetCfg cryptotrader.AssetConfig
+	var algoCfg cryptotrader.AlgorithmConfig
+	var tradeCfg cryptotrader.TradeConfig
+	var backtestCfg cryptotrader.BacktestConfig
+	var series types.Series
+	var result cryptotrader.BacktestResult
+
+	if assetCfg, algoCfg, tradeCfg, backtestCfg, err = cryptotrader.ReadBacktestFlags(args); err != nil {
+		return
+	}
+
+	if series, err = loadBacktestSeries(context.Background(), assetCfg, backtestCfg); err != nil {
+		return
+	}
+
+	if result, err = cryptotrader.NewBacktester(algoCfg, tradeCfg, backtestCfg.AccountInfo(assetCfg.Symbol)).Run(context.Background(), series); err != nil {
+		return
+	}
+
+	err = result.WriteReport(os.Stdout)
+	return
+}
+
+// loadBacktestSeries loads the candles of the backtest range from the data file or the candle store
+func loadBacktestSeries(ctx context.Context, assetCfg cryptotrader.AssetConfig, backtestCfg cryptotrader.BacktestConfig) (series types.Series, err error) {
+	var store *history.CandleStore
+	var driver interfaces.IExchangeDriver
+	var historical interfaces.IHistoricalExchangeDriver
+	var ok bool
+
+	if backtestCfg.DataFile != "" {
+		if series, err = history.LoadCSV(backtestCfg.DataFile, assetCfg.Symbol, assetCfg.Timeframe); err != nil {
+			return
+		}
+		series = history.SeriesBetween(series, backtestCfg.Start, backtestCfg.End)
+		logger.Infof("Loaded %d candles from %s\n", series.Length(), backtestCfg.DataFile)
+		return
+	}
+
+	store = history.NewCandleStore(backtestCfg.Exchange.CandleStore)
+	if !backtestCfg.Download {
+		if series, err = store.Load(backtestCfg.Exchange.Name, assetCfg.Symbol, assetCfg.Timeframe, backtestCfg.Start, backtestCfg.End); err != nil {
+			return
+		}
+		logger.Infof("Loaded %d candles from candle store %s\n", series.Length(), backtestCfg.Exchange.CandleStore)
+		return
+	}
+
+	if driver, err = exchange.GetExchange(ctx, backtestCfg.Exchange.Name, backtestCfg.Exchange.ArgMap); err != nil {
+		return
+	}
+	if backtestCfg.Exchange.Limiter.Enabled() {
+		driver = exchange.NewLimiter(driver, backtestCfg.Exchange.Limiter)
+	}
+	if historical, ok = exchange.HistoricalDriver(driver); !ok {
+		err = fmt.Errorf("Exchange %s does not support downloading candles", backtestCfg.Exchange.Name)
+		return
+	}
+
+	if series, err = store.Update(ctx, driver.Name(), historical, assetCfg.Symbol, assetCfg.Timeframe, backtestCfg.Start, backtestCfg.End); err != nil {
+		return
+	}
+	logger.Infof("Loaded %d candles from candle store %s\n", series.Length(), backtestCfg.Exchange.CandleStore)
+	return
+}
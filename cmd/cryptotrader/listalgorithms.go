@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/mhereman/cryptotrader/algorithms"
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// listAlgorithms writes the registered algorithms with their parameters
+func listAlgorithms(w io.Writer) (err error) {
+	var tw *tabwriter.Writer
+	var name string
+	var algo interfaces.IAlgorithm
+	var param types.AlgorithmParam
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, name = range algorithms.GetAlgorithmNames() {
+		if algo, err = algorithms.GetAlgorithm(name); err != nil {
+			return
+		}
+
+		fmt.Fprintf(tw, "%s\n", name)
+		fmt.Fprintf(tw, "  Parameter\tType\tDefault\tRange\tDescription\n")
+		for _, param = range algo.Schema() {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", param.Name, param.Type.String(), param.Default, param.Range(), param.Description)
+		}
+		fmt.Fprintln(tw)
+	}
+	err = tw.Flush()
+	return
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/mhereman/cryptotrader"
 
@@ -9,23 +10,54 @@ import (
 )
 
 func main() {
-	var assetCfg cryptotrader.AssetConfig
+	var marketCfgs []cryptotrader.MarketConfig
 	var exchangeCfg cryptotrader.ExchangeConfig
-	var algoCfg cryptotrader.AlgorithmConfig
 	var tradeCfg cryptotrader.TradeConfig
+	var notifierCfgs []cryptotrader.NotifierConfig
 	var notifierCfg cryptotrader.NotifierConfig
+	var stateStoreCfg cryptotrader.StateStoreConfig
 	var trader *cryptotrader.CryptoTrader
 	var err error
 
-	if assetCfg, exchangeCfg, algoCfg, tradeCfg, notifierCfg, err = cryptotrader.ReadFlags(); err != nil {
+	if len(os.Args) > 1 && os.Args[1] == "backtest" {
+		if err = runBacktest(os.Args[2:]); err != nil {
+			log.Fatalf("Error %v\n", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "optimize" {
+		if err = runOptimize(os.Args[2:]); err != nil {
+			log.Fatalf("Error %v\n", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "walkforward" {
+		if err = runWalkForward(os.Args[2:]); err != nil {
+			log.Fatalf("Error %v\n", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "list-algorithms" {
+		if err = listAlgorithms(os.Stdout); err != nil {
+			log.Fatalf("Error %v\n", err)
+		}
+		return
+	}
+
+	if marketCfgs, exchangeCfg, tradeCfg, notifierCfgs, stateStoreCfg, err = cryptotrader.ReadFlags(); err != nil {
 		log.Fatalf("Error %v\n", err)
 	}
 
-	logger.Debugf("API_KEY: %s", exchangeCfg.ArgMap["apiKey"])
-	logger.Debugf("API_SECRET: %s", exchangeCfg.ArgMap["apiSecret"])
+	logger.Debugf("Exchange: %s\n", exchangeCfg.String())
+	for _, notifierCfg = range notifierCfgs {
+		logger.Debugf("Notifier: %s\n", notifierCfg.String())
+	}
 
 	logger.Infoln("Starting cryptotrader")
-	trader = cryptotrader.New(assetCfg, exchangeCfg, algoCfg, tradeCfg, notifierCfg)
+	trader = cryptotrader.New(marketCfgs, exchangeCfg, tradeCfg, notifierCfgs, stateStoreCfg)
 	if err = trader.Run(); err != nil {
 		logger.Fatalf("Error %v\n", err)
 	}
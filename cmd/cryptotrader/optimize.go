@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/mhereman/cryptotrader"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+func runOptimize(args []string) (err error) {
+	var assetCfg cryptotrader.AssetConfig
+	var algoCfg cryptotrader.AlgorithmConfig
+	var tradeCfg cryptotrader.TradeConfig
+	var backtestCfg cryptotrader.BacktestConfig
+	var optimizerCfg cryptotrader.OptimizerConfig
+	var series types.Series
+	var result cryptotrader.OptimizationResult
+	var file *os.File
+
+	if assetCfg, algoCfg, tradeCfg, backtestCfg, optimizerCfg, err = cryptotrader.ReadOptimizeFlags(args); err != nil {
+		return
+	}
+
+	if series, err = loadBacktestSeries(context.Background(), assetCfg, backtestCfg); err != nil {
+		return
+	}
+
+	if result, err = cryptotrader.NewOptimizer(algoCfg, tradeCfg, backtestCfg.AccountInfo(assetCfg.Symbol), optimizerCfg).Run(context.Background(), series); err != nil {
+		return
+	}
+
+	if file, err = os.Create(optimizerCfg.ResultFile); err != nil {
+		return
+	}
+	defer file.Close()
+	if err = result.WriteCSV(file); err != nil {
+		return
+	}
+	logger.Infof("Optimization results written to %s\n", optimizerCfg.ResultFile)
+
+	err = result.WriteReport(os.Stdout, optimizerCfg.Top)
+	return
+}
@@ -4,11 +4,23 @@ package main
 import (
 	// Exchanges
 	_ "github.com/mhereman/cryptotrader/exchange/binance"
+	_ "github.com/mhereman/cryptotrader/exchange/binancefutures"
+	_ "github.com/mhereman/cryptotrader/exchange/coinbase"
+	_ "github.com/mhereman/cryptotrader/exchange/kraken"
+	_ "github.com/mhereman/cryptotrader/exchange/simulated"
 
 	// Algorithms
 	_ "github.com/mhereman/cryptotrader/algorithms/emasmav1"
 
 	// Notifiers
+	_ "github.com/mhereman/cryptotrader/notifiers/console"
 	_ "github.com/mhereman/cryptotrader/notifiers/noop"
 	_ "github.com/mhereman/cryptotrader/notifiers/proximussms"
+	_ "github.com/mhereman/cryptotrader/notifiers/smtp"
+	_ "github.com/mhereman/cryptotrader/notifiers/telegram"
+	_ "github.com/mhereman/cryptotrader/notifiers/webhook"
+
+	// State stores
+	_ "github.com/mhereman/cryptotrader/statestores/jsonfile"
+	_ "github.com/mhereman/cryptotrader/statestores/memory"
 )
@@ -1,5 +1,8 @@
 #!/usr/bin/env bash
 
+# The configuration below can also be read from a YAML or JSON file with -config, see config.example.yaml
+# Arguments given on the cmdline override the values of the file.
+
 # Logging Configuration
 #######################
 
@@ -11,14 +14,48 @@ LOGLEVEL='info'
 ########################
 
 # The exchange to use.
-# Currently only Binance is supported.
+# Can be: binance, binance-futures, coinbase, kraken
 EXCHANGE='binance'
 
-# Your Binance API Key.
-API_KEY=''
-
-# Your Binance API Secret.
-API_SECRET=''
+# Your exchange API Key.
+export API_KEY=''
+
+# Your exchange API Secret.
+export API_SECRET=''
+
+# The exchange and notifier arguments can reference secrets instead of containing them,
+# so they do not show up in the process list or the shell history:
+#   ${ENV_VAR}  is replaced by the value of the environment variable
+#   file:/path  is replaced by the contents of the file, e.g. 'apiSecret=file:/run/secrets/binance'
+# The secrets are redacted from the log.
+
+# Candles are streamed from the Binance kline websocket.
+# Add 'streamURL=...' to the exchange arguments to use another websocket endpoint.
+# Add 'testnet=true' to trade on the Binance Spot testnet with play money (testnet API keys required),
+# or 'baseURL=...' to use another REST endpoint (e.g. a local test server).
+# The Binance USDⓈ-M futures exchange ('binance-futures') trades the perpetual contracts of the symbols, its candles are polled.
+# The account must use the one-way position mode, add 'testnet=true' to trade on the futures testnet.
+# Kraken and Coinbase candles are polled, add 'baseURL=...' to the exchange arguments to use another REST endpoint
+# (e.g. a local test server).
+# For Coinbase the API key is the CDP key name (organizations/.../apiKeys/...) and the API secret its EC private key,
+# preferably referenced as 'apiSecret=file:/path/to/key.pem'.
+
+# Directory to store the downloaded candles in.
+# Only the candles closed since the last run are downloaded, the same store can be used for backtests.
+# Use an empty string to disable
+CANDLE_STORE='candles'
+
+# Throttle the requests to the rate limits of the exchange.
+# Requests rejected by the exchange for exceeding the limits are retried after the delay it asks for.
+RATE_LIMIT='true'
+
+# Number of retries of failed requests (network errors, server errors and rate limits).
+# Orders are only retried when rate limited, so they are never placed twice.
+RETRIES='3'
+
+# Number of consecutive failed requests after which the requests are paused for a minute.
+# Use 0 to disable
+CIRCUIT_BREAKER='5'
 
 
 # Asset Configuration
@@ -47,6 +84,13 @@ ALGO='Ema/Sma'
 # Ema/Sma.ema_len = 7
 # Ema/Sma.rsi_len = 14
 # Ema/Sma.rsi_buy_max = 90.0
+# Ema/Sma.shorts = false, open short positions on the sell signals (requires SHORTS)
+# Run 'cryptotrader list-algorithms' for the parameters, defaults and valid ranges of every algorithm.
+# Unknown parameters and out of range values are rejected at startup.
+# The parameters can be tuned by backtesting ranges of values, e.g.:
+#   cryptotrader optimize -data candles.csv -range 'Ema/Sma.sma_len=10..30:5' -range 'Ema/Sma.rsi_buy_min=40,45,50' -objective sharpe
+# To check the optimized parameters generalize, optimize on rolling windows and evaluate on the following candles:
+#   cryptotrader walkforward -data candles.csv -range 'Ema/Sma.sma_len=10..30:5' -insample 500 -outofsample 100
 ALGO_ARGS='Ema/Sma.sma_len=14;Ema/Sma.ema_len=7;Ema/Sma.rsi_len=14;Ema/Sma.rsi_buy_min=45.0;Ema/Sma.rsi_buy_max=70.0;Ema/Sma.rsi_sell=90.0;Ema/Sma.backtest=false'
 
 
@@ -78,6 +122,47 @@ MAX_SLIPPAGE='0.001'
 # Stop loss pct
 STOP_LOSS='0.05'
 
+# Take profit target
+# Either a pct above the entry price (e.g. 0.1) or a multiple of the stop loss distance (e.g. 2R).
+# The take profit is placed together with the stop loss as a one-cancels-other pair.
+# Use an empty string to disable
+TAKE_PROFIT=''
+
+# Trailing stop pct
+# If set, the stop loss follows the highest price seen at this distance below it.
+# Use 0 to disable
+TRAILING_STOP='0'
+
+# Max number of positions open at the same time over all markets
+# Use 0 for unlimited
+MAX_POSITIONS='0'
+
+# Open short positions on the short signals of the algorithm
+# Requires a futures exchange, the stop loss and take profit are placed above and below the entry price.
+SHORTS='false'
+
+# Leverage of the positions
+# The trade volume is the margin, the position size is the volume times the leverage.
+# Requires a futures exchange, use 1 to disable
+LEVERAGE='1'
+
+# Margin type of the positions on a futures exchange
+# Can be: isolated, cross
+MARGIN_TYPE='isolated'
+
+
+
+# Market Configuration
+######################
+
+# Additional markets to trade from the same instance, one -market argument per market.
+# Format: base/quote@timeframe[,algo[,volume[,algoargs]]]
+# Omitted values are taken from the asset, algorithm and trade configuration above.
+# If no markets are configured the asset configuration above is traded.
+# Every symbol can only be traded on a single timeframe.
+MARKETS=()
+# MARKETS=(-market='btc/usdt@4h' -market='eth/usdt@1h,Ema/Sma,50.0')
+
 
 
 # Notifier Configuration
@@ -85,20 +170,52 @@ STOP_LOSS='0.05'
 
 # The notifier to enable
 # If empty string, no notifier is configured
-# At the moment only the Proximus SMS api is available as a notifier ('proximus-sms')
+# Available notifiers: the log ('console'), the Proximus SMS api ('proximus-sms'), email ('smtp'), Telegram ('telegram') and webhooks ('webhook')
 # The configuration of the Proximus SMS api needs a 'apiToken' and 'destination' entry
+# The Telegram bot notifier ('telegram') needs a 'token' and 'chatId' entry,
+# with 'commands=true' the bot accepts /status, /positions, /pause, /resume and /close BASE/QUOTE from the chat
+# The webhook notifier ('webhook') posts to the 'url' entry, the body is rendered from the 'template' or 'templateFile' entry,
+# e.g. 'url=https://hooks.slack.com/services/...;header.Authorization=Bearer abc;hmacSecret=def;retries=3'
+# The email notifier ('smtp') needs a 'host', 'from' and 'to' entry, 'security' is starttls (default), tls or none,
+# with 'digestInterval=24h' the events below the 'digestSeverity' (default warning) are mailed once a day in a digest,
+# e.g. 'host=smtp.example.com;username=bot;password=secret;from=bot@example.com;to=a@example.com,b@example.com;digestInterval=24h;minSeverity=info'
+# The events sent to any notifier are selected with the 'minSeverity' entry (info, notice, warning, error; default notice)
+# and the 'events' entry, a comma separated list of startup, signal, order_placed, order_filled, stop_loss, take_profit,
+# position_closed and error, e.g. 'events=position_closed,error'
+# A delivery taking longer than the 'deliveryTimeout' entry (default 1m) is abandoned, the webhook retries are fitted in it
 NOTIFIER=''
 
 # The configuration arguments for the notifier
 NOTIFIER_CONFIG=''
 
+# Multiple notifiers are enabled by repeating the -notifier argument, each with its own arguments after a ':'
+# The events are delivered to all notifiers concurrently
+# NOTIFIERS=(-notifier='console' -notifier='webhook:url=https://example.com/hook;minSeverity=info' -notifier='proximus-sms:apiToken=abc;destination=+32470000000;events=order_filled,error')
+
+
+
+# State Store Configuration
+###########################
+
+# The store to persist open trades in, so a restart resumes the open positions
+# If empty string, the open trades are only kept in memory
+# The 'json' state store needs a 'path' entry
+STATE_STORE='json'
+
+# The configuration arguments for the state store
+STATE_STORE_CONFIG='path=cryptotrader-state.json'
+
 
 
 # Run cryptotrader
 cryptotrader \
     -loglevel=${LOGLEVEL} \
     -exchange=${EXCHANGE} \
-    -exchangeargs="apiKey=${API_KEY};apiSecret=${API_SECRET}" \
+    -exchangeargs='apiKey=${API_KEY};apiSecret=${API_SECRET}' \
+    -candlestore=${CANDLE_STORE} \
+    -ratelimit=${RATE_LIMIT} \
+    -retries=${RETRIES} \
+    -circuitbreaker=${CIRCUIT_BREAKER} \
     -base=${BASE_ASSET} \
     -quote=${QUOTE_ASSET} \
     -timeframe=${TIME_FRAME} \
@@ -110,5 +227,15 @@ cryptotrader \
     -papertrading=${PAPER_TRADING} \
     -maxslippage=${MAX_SLIPPAGE} \
     -stoploss=${STOP_LOSS} \
+    -takeprofit=${TAKE_PROFIT} \
+    -trailingstop=${TRAILING_STOP} \
+    -maxpositions=${MAX_POSITIONS} \
+    -shorts=${SHORTS} \
+    -leverage=${LEVERAGE} \
+    -margintype=${MARGIN_TYPE} \
+    "${MARKETS[@]}" \
     -notifier=${NOTIFIER} \
-    -notifierargs=${NOTIFIER_CONFIG}
+    -notifierargs=${NOTIFIER_CONFIG} \
+    "${NOTIFIERS[@]}" \
+    -statestore=${STATE_STORE} \
+    -statestoreargs=${STATE_STORE_CONFIG}
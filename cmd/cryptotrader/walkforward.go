@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/mhereman/cryptotrader"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+func runWalkForward(args []string) (err error) {
+	var assetCfg cryptotrader.AssetConfig
+	var algoCfg cryptotrader.AlgorithmConfig
+	var tradeCfg cryptotrader.TradeConfig
+	var backtestCfg cryptotrader.BacktestConfig
+	var walkForwardCfg cryptotrader.WalkForwardConfig
+	var series types.Series
+	var result cryptotrader.WalkForwardResult
+	var file *os.File
+
+	if assetCfg, algoCfg, tradeCfg, backtestCfg, walkForwardCfg, err = cryptotrader.ReadWalkForwardFlags(args); err != nil {
+		return
+	}
+
+	if series, err = loadBacktestSeries(context.Background(), assetCfg, backtestCfg); err != nil {
+		return
+	}
+
+	if result, err = cryptotrader.NewWalkForward(algoCfg, tradeCfg, backtestCfg.AccountInfo(assetCfg.Symbol), walkForwardCfg).Run(context.Background(), series); err != nil {
+		return
+	}
+
+	if file, err = os.Create(walkForwardCfg.Optimizer.ResultFile); err != nil {
+		return
+	}
+	defer file.Close()
+	if err = result.WriteCSV(file); err != nil {
+		return
+	}
+	logger.Infof("Walk-forward results written to %s\n", walkForwardCfg.Optimizer.ResultFile)
+
+	err = result.WriteReport(os.Stdout)
+	return
+}
@@ -0,0 +1,199 @@
+package cryptotrader
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const commandHelp = `Commands:
+/status - Trading mode and number of open positions
+/positions - Open positions
+/pause - Ignore the signals of the algorithms
+/resume - Execute the signals of the algorithms again
+/close BASE/QUOTE - Close the position of the symbol`
+
+// runCommandListenerAsync passes the commands received by the notifier to the trader,
+// if the notifier supports commands
+func (ct *CryptoTrader) runCommandListenerAsync() {
+	var commandNotifier interfaces.ICommandNotifier
+	var ok bool
+
+	if commandNotifier, ok = ct.notifier.(interfaces.ICommandNotifier); !ok {
+		return
+	}
+
+	ct.wg.Add(1)
+	go commandListenerRoutine(ct, commandNotifier)
+}
+
+func commandListenerRoutine(ct *CryptoTrader, commandNotifier interfaces.ICommandNotifier) {
+	defer ct.wg.Done()
+
+	if err := commandNotifier.ListenCommands(ct.ctx, ct); err != nil {
+		logger.Errorf("commandListener: %v\n", err)
+	}
+}
+
+// HandleCommand executes a command received by the notifier and returns the reply
+// The stop losses and take profits of the open positions stay active while trading is paused.
+func (ct *CryptoTrader) HandleCommand(ctx context.Context, command string, args []string) (reply string, err error) {
+	logger.Infof("Received command: %s %s\n", command, strings.Join(args, " "))
+
+	switch strings.ToLower(command) {
+	case "status":
+		reply = ct.statusCommand()
+	case "positions":
+		reply = ct.positionsCommand()
+	case "pause":
+		reply = ct.pauseCommand(true)
+	case "resume":
+		reply = ct.pauseCommand(false)
+	case "close":
+		if len(args) != 1 {
+			err = fmt.Errorf("Usage: /close BASE/QUOTE")
+			return
+		}
+		reply, err = ct.closeCommand(args[0])
+	case "help", "start":
+		reply = commandHelp
+	default:
+		err = fmt.Errorf("Unknown command: %s\n%s", command, commandHelp)
+	}
+	return
+}
+
+func (ct *CryptoTrader) statusCommand() string {
+	var mode, state, maxPositions, circuit string
+	var marketCfg MarketConfig
+	var markets []string
+
+	ct.positionMux.Lock()
+	defer ct.positionMux.Unlock()
+
+	mode = "live"
+	if ct.tradeCfg.Paper {
+		mode = "paper trading"
+	}
+	state = "trading"
+	if ct.paused {
+		state = "paused"
+	}
+	maxPositions = "unlimited"
+	if ct.tradeCfg.MaxOpenPositions > 0 {
+		maxPositions = fmt.Sprintf("%d", ct.tradeCfg.MaxOpenPositions)
+	}
+	for _, marketCfg = range ct.marketCfgs {
+		markets = append(markets, marketCfg.String())
+	}
+	circuit = "disabled"
+	if ct.limiter != nil {
+		circuit = ct.limiter.CircuitState().String()
+	}
+
+	return fmt.Sprintf("Cryptotrader running in %s mode, %s\nMarkets: %s\nOpen positions: %d (max: %s)\nExchange circuit breaker: %s\nNotifications: %s", mode, state, strings.Join(markets, ", "), len(ct.openTrades), maxPositions, circuit, ct.notificationStats())
+}
+
+func (ct *CryptoTrader) positionsCommand() string {
+	var symbolString, stopLossID string
+	var symbols, lines []string
+	var symbol types.Symbol
+	var price, takeProfit float64
+	var line, stopLoss string
+	var stopLossUUID uuid.UUID
+	var orderInfo types.OrderInfo
+	var ok bool
+	var err error
+
+	ct.positionMux.Lock()
+	defer ct.positionMux.Unlock()
+
+	if len(ct.openTrades) == 0 {
+		return "No open positions"
+	}
+
+	for symbolString = range ct.openTrades {
+		symbols = append(symbols, symbolString)
+	}
+	sort.Strings(symbols)
+
+	for _, symbolString = range symbols {
+		line = symbolString
+		if ct.positionSides[symbolString] == types.Short {
+			line += " Short"
+		}
+		if symbol, err = types.NewSymbolFromString(symbolString); err == nil {
+			if price, err = ct.exchangeDriver.Ticker(ct.ctx, symbol); err == nil {
+				line += fmt.Sprintf(" Price: %f", price)
+			}
+		}
+
+		if stopLossID, ok = ct.stopLossOrders[symbolString]; ok {
+			stopLoss = "open"
+			if stopLossUUID, err = uuid.Parse(stopLossID); err == nil {
+				if orderInfo, err = ct.exchangeDriver.GetOrder(ct.ctx, types.Order{
+					Symbol:        symbol,
+					UserReference: stopLossUUID,
+				}); err == nil {
+					stopLoss = fmt.Sprintf("%f", orderInfo.StopPrice)
+				}
+			}
+			line += fmt.Sprintf(" Stop loss: %s", stopLoss)
+		}
+		if takeProfit, ok = ct.takeProfitPrices[symbolString]; ok {
+			line += fmt.Sprintf(" Take profit: %f", takeProfit)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (ct *CryptoTrader) pauseCommand(pause bool) string {
+	ct.positionMux.Lock()
+	defer ct.positionMux.Unlock()
+
+	ct.paused = pause
+	if pause {
+		logger.Infoln("Trading paused")
+		return "Trading paused, the signals of the algorithms are ignored"
+	}
+	logger.Infoln("Trading resumed")
+	return "Trading resumed"
+}
+
+func (ct *CryptoTrader) closeCommand(symbolString string) (reply string, err error) {
+	var symbol types.Symbol
+	var accountInfo types.AccountInfo
+	var ok bool
+
+	if symbol, err = types.NewSymbolFromString(symbolString); err != nil || symbol.Base() == "" || symbol.Quote() == "" {
+		err = fmt.Errorf("Invalid symbol: %s", symbolString)
+		return
+	}
+
+	ct.positionMux.Lock()
+	defer ct.positionMux.Unlock()
+
+	if _, ok = ct.openTrades[symbol.String()]; !ok {
+		err = fmt.Errorf("No open position for symbol %s", symbol.String())
+		return
+	}
+
+	if accountInfo, err = ct.exchangeDriver.GetAccountInfo(ct.ctx); err != nil {
+		err = fmt.Errorf("Failed to retrieve account info %v", err)
+		return
+	}
+
+	if err = ct.closeFn(accountInfo, symbol); err != nil {
+		return
+	}
+	reply = fmt.Sprintf("Position for symbol %s closed", symbol.String())
+	return
+}
@@ -0,0 +1,104 @@
+package cryptotrader
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// paperDriver is an exchange stand-in quoting a single price for paper trading
+type paperDriver struct {
+	reconcileDriver
+	price float64
+}
+
+func newPaperDriver(price float64) *paperDriver {
+	return &paperDriver{reconcileDriver: reconcileDriver{orders: make(map[uuid.UUID]types.OrderInfo)}, price: price}
+}
+
+func (d *paperDriver) GetAccountInfo(ctx context.Context) (accountInfo types.AccountInfo, err error) {
+	return
+}
+
+func (d *paperDriver) Ticker(ctx context.Context, symbol types.Symbol) (price float64, err error) {
+	price = d.price
+	return
+}
+
+func (d *paperDriver) GetOrderBook(ctx context.Context, symbol types.Symbol) (orderBook types.OrderBook, err error) {
+	orderBook = types.OrderBook{
+		Symbol: symbol,
+		Bids:   []types.OrderBookEntry{{Price: d.price, Quantity: 1000.0}},
+		Asks:   []types.OrderBookEntry{{Price: d.price, Quantity: 1000.0}},
+	}
+	return
+}
+
+func TestHandleCommand(t *testing.T) {
+	var tests = []struct {
+		name    string
+		command string
+		args    []string
+		signal  *types.Signal
+		reply   string
+		err     string
+		open    bool
+	}{
+		{"status", "status", nil, nil, "paper trading mode, trading", "", false},
+		{"no positions", "positions", nil, nil, "No open positions", "", false},
+		{"pause", "pause", nil, nil, "Trading paused", "", false},
+		{"status paused", "status", nil, nil, "paper trading mode, paused", "", false},
+		{"signal while paused", "", nil, &types.Signal{Symbol: testSymbol, Side: types.Buy, Position: types.Long}, "", "", false},
+		{"resume", "resume", nil, nil, "Trading resumed", "", false},
+		{"signal after resume", "", nil, &types.Signal{Symbol: testSymbol, Side: types.Buy, Position: types.Long}, "", "", true},
+		{"positions", "positions", nil, nil, "BTC/USDT Price: 100.000000 Stop loss: open", "", true},
+		{"close without symbol", "close", nil, nil, "", "Usage: /close BASE/QUOTE", true},
+		{"close malformed symbol", "close", []string{"BTCUSDT"}, nil, "", "Invalid symbol: BTCUSDT", true},
+		{"close empty quote", "close", []string{"BTC/"}, nil, "", "Invalid symbol: BTC/", true},
+		{"close other symbol", "close", []string{"ETH/USDT"}, nil, "", "No open position for symbol ETH/USDT", true},
+		{"close", "close", []string{"btc/usdt"}, nil, "Position for symbol BTC/USDT closed", "", false},
+		{"unknown command", "buy", nil, nil, "", "Unknown command: buy", false},
+	}
+	var ct *CryptoTrader
+	var trades []types.OpenTrade
+	var reply string
+	var index int
+	var ok bool
+	var err error
+
+	ct = newTestTraderConfig(t, newPaperDriver(100.0), TradeConfig{Paper: true, Volume: 1000.0})
+	defer ct.cancelFn()
+
+	for index = range tests {
+		if tests[index].signal != nil {
+			if err = ct.executeSignal(*tests[index].signal); err != nil {
+				t.Errorf("%s: executeSignal: %v", tests[index].name, err)
+			}
+		} else {
+			reply, err = ct.HandleCommand(context.Background(), tests[index].command, tests[index].args)
+			if tests[index].err != "" && (err == nil || !strings.HasPrefix(err.Error(), tests[index].err)) {
+				t.Errorf("%s: got error %v, want %s", tests[index].name, err, tests[index].err)
+			}
+			if tests[index].err == "" && err != nil {
+				t.Errorf("%s: %v", tests[index].name, err)
+			}
+			if !strings.Contains(reply, tests[index].reply) {
+				t.Errorf("%s: got reply %q, want %q", tests[index].name, reply, tests[index].reply)
+			}
+		}
+
+		if _, ok = ct.openTrades[testSymbol.String()]; ok != tests[index].open {
+			t.Errorf("%s: got open position %v, want %v", tests[index].name, ok, tests[index].open)
+		}
+		if trades, err = ct.stateStore.Load(ct.ctx); err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if len(trades) == 1 != tests[index].open {
+			t.Errorf("%s: got %d persisted trades, want open %v", tests[index].name, len(trades), tests[index].open)
+		}
+	}
+}
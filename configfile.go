@@ -0,0 +1,360 @@
+package cryptotrader
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// configFile represents the contents of a -config file
+// JSON files are read as YAML, every value is optional.
+type configFile struct {
+	LogLevel   string               `yaml:"logLevel"`
+	Exchange   exchangeFileConfig   `yaml:"exchange"`
+	Asset      assetFileConfig      `yaml:"asset"`
+	Algorithm  algorithmFileConfig  `yaml:"algorithm"`
+	Trade      tradeFileConfig      `yaml:"trade"`
+	Markets    []marketFileConfig   `yaml:"markets"`
+	Notifiers  []notifierFileConfig `yaml:"notifiers"`
+	StateStore stateStoreFileConfig `yaml:"stateStore"`
+}
+
+type exchangeFileConfig struct {
+	Name           string            `yaml:"name"`
+	Args           map[string]string `yaml:"args"`
+	CandleStore    string            `yaml:"candleStore"`
+	RateLimit      *bool             `yaml:"rateLimit"`
+	Retries        *int              `yaml:"retries"`
+	CircuitBreaker *int              `yaml:"circuitBreaker"`
+}
+
+type assetFileConfig struct {
+	Base      string `yaml:"base"`
+	Quote     string `yaml:"quote"`
+	Timeframe string `yaml:"timeframe"`
+}
+
+type algorithmFileConfig struct {
+	Name string            `yaml:"name"`
+	Args map[string]string `yaml:"args"`
+}
+
+type tradeFileConfig struct {
+	Type         string   `yaml:"type"`
+	Volume       *float64 `yaml:"volume"`
+	Reduce       *bool    `yaml:"reduce"`
+	Paper        *bool    `yaml:"paper"`
+	MaxSlippage  *float64 `yaml:"maxSlippage"`
+	StopLoss     *float64 `yaml:"stopLoss"`
+	TakeProfit   string   `yaml:"takeProfit"`
+	TrailingStop *float64 `yaml:"trailingStop"`
+	MaxPositions *int     `yaml:"maxPositions"`
+	Shorts       *bool    `yaml:"shorts"`
+	Leverage     *int     `yaml:"leverage"`
+	MarginType   string   `yaml:"marginType"`
+}
+
+type marketFileConfig struct {
+	Market        string            `yaml:"market"`
+	Algorithm     string            `yaml:"algorithm"`
+	Volume        *float64          `yaml:"volume"`
+	AlgorithmArgs map[string]string `yaml:"algorithmArgs"`
+}
+
+type notifierFileConfig struct {
+	Name            string            `yaml:"name"`
+	Args            map[string]string `yaml:"args"`
+	Events          []string          `yaml:"events"`
+	MinSeverity     string            `yaml:"minSeverity"`
+	DeliveryTimeout string            `yaml:"deliveryTimeout"`
+}
+
+type stateStoreFileConfig struct {
+	Name string            `yaml:"name"`
+	Args map[string]string `yaml:"args"`
+}
+
+// configValue maps a value of the config file onto a cmdline argument
+type configValue struct {
+	field    string
+	flag     string
+	value    string
+	validate func(string) error
+}
+
+func loadConfigFile(path string) (cf configFile, err error) {
+	var data []byte
+
+	if data, err = ioutil.ReadFile(path); err != nil {
+		err = fmt.Errorf("Failed to read config file %s: %v", path, err)
+		return
+	}
+	if err = yaml.UnmarshalStrict(data, &cf); err != nil {
+		err = fmt.Errorf("Invalid config file %s: %v", path, err)
+		return
+	}
+	return
+}
+
+// applyConfigFile reads the -config file into the flag values
+// The values of the cmdline arguments which are set explicitly are kept, the argument maps
+// of the file are merged with the arguments of the cmdline.
+func (fv *flagValues) applyConfigFile(fs *flag.FlagSet) (err error) {
+	var cf configFile
+	var cv configValue
+	var index int
+	var market marketFileConfig
+	var notifier notifierFileConfig
+	var notifierConfig NotifierConfig
+
+	fv.setFlags = make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		fv.setFlags[f.Name] = true
+	})
+
+	if *fv.configFile == "" {
+		return
+	}
+	if cf, err = loadConfigFile(*fv.configFile); err != nil {
+		return
+	}
+
+	for _, cv = range []configValue{
+		{"logLevel", "loglevel", cf.LogLevel, validateLogLevel},
+		{"exchange.name", "exchange", cf.Exchange.Name, nil},
+		{"exchange.candleStore", "candlestore", cf.Exchange.CandleStore, nil},
+		{"exchange.rateLimit", "ratelimit", formatBool(cf.Exchange.RateLimit), nil},
+		{"exchange.retries", "retries", formatInt(cf.Exchange.Retries), validateNotNegative},
+		{"exchange.circuitBreaker", "circuitbreaker", formatInt(cf.Exchange.CircuitBreaker), validateNotNegative},
+		{"asset.base", "base", cf.Asset.Base, nil},
+		{"asset.quote", "quote", cf.Asset.Quote, nil},
+		{"asset.timeframe", "timeframe", cf.Asset.Timeframe, validateTimeframe},
+		{"algorithm.name", "algo", cf.Algorithm.Name, nil},
+		{"trade.type", "tradetype", cf.Trade.Type, validateTradeVolumeType},
+		{"trade.volume", "volume", formatFloat(cf.Trade.Volume), validatePositive},
+		{"trade.reduce", "reduce", formatBool(cf.Trade.Reduce), nil},
+		{"trade.paper", "papertrading", formatBool(cf.Trade.Paper), nil},
+		{"trade.maxSlippage", "maxslippage", formatFloat(cf.Trade.MaxSlippage), validatePercentage},
+		{"trade.stopLoss", "stoploss", formatFloat(cf.Trade.StopLoss), validatePercentage},
+		{"trade.takeProfit", "takeprofit", cf.Trade.TakeProfit, validateTakeProfit},
+		{"trade.trailingStop", "trailingstop", formatFloat(cf.Trade.TrailingStop), validatePercentage},
+		{"trade.maxPositions", "maxpositions", formatInt(cf.Trade.MaxPositions), validateNotNegative},
+		{"trade.shorts", "shorts", formatBool(cf.Trade.Shorts), nil},
+		{"trade.leverage", "leverage", formatInt(cf.Trade.Leverage), validatePositive},
+		{"trade.marginType", "margintype", cf.Trade.MarginType, validateMarginType},
+		{"stateStore.name", "statestore", cf.StateStore.Name, nil},
+	} {
+		if err = fv.applyConfigValue(fs, cv); err != nil {
+			err = fmt.Errorf("Invalid config file %s: %s: %v", *fv.configFile, cv.field, err)
+			return
+		}
+	}
+
+	fv.fileExchangeArgs = cf.Exchange.Args
+	fv.fileAlgoArgs = cf.Algorithm.Args
+	fv.fileStateStoreArgs = cf.StateStore.Args
+
+	for index, market = range cf.Markets {
+		if market.Market == "" {
+			err = fmt.Errorf("Invalid config file %s: markets[%d].market: missing", *fv.configFile, index)
+			return
+		}
+		if market.Volume != nil {
+			if err = validatePositive(formatFloat(market.Volume)); err != nil {
+				err = fmt.Errorf("Invalid config file %s: markets[%d].volume: %v", *fv.configFile, index, err)
+				return
+			}
+		}
+	}
+	fv.fileMarkets = cf.Markets
+
+	for index, notifier = range cf.Notifiers {
+		if notifierConfig, err = notifier.notifierConfig(); err != nil {
+			err = fmt.Errorf("Invalid config file %s: notifiers[%d]: %v", *fv.configFile, index, err)
+			return
+		}
+		fv.fileNotifiers = append(fv.fileNotifiers, notifierConfig)
+	}
+	return
+}
+
+func (fv *flagValues) applyConfigValue(fs *flag.FlagSet, cv configValue) (err error) {
+	if cv.value == "" || fv.setFlags[cv.flag] || fs.Lookup(cv.flag) == nil {
+		return
+	}
+	if cv.validate != nil {
+		if err = cv.validate(cv.value); err != nil {
+			return
+		}
+	}
+	err = fs.Set(cv.flag, cv.value)
+	return
+}
+
+// argMap returns the arguments of the file merged with the arguments of the cmdline argument
+// Without file arguments the cmdline argument is used as is.
+func (fv *flagValues) argMap(fileArgs map[string]string, flagName string, flagValue string) (out map[string]string) {
+	var key, value string
+
+	if fileArgs == nil {
+		return buildArgMap(flagValue)
+	}
+
+	out = make(map[string]string)
+	for key, value = range fileArgs {
+		out[key] = value
+	}
+	if fv.setFlags[flagName] {
+		for key, value = range buildArgMap(flagValue) {
+			out[key] = value
+		}
+	}
+	return
+}
+
+// marketConfig creates the MarketConfig of the market of the config file
+func (mfc marketFileConfig) marketConfig(defaults MarketConfig) (mc MarketConfig, err error) {
+	if mc, err = NewMarketConfigFromFlag(mfc.Market, defaults); err != nil {
+		return
+	}
+	if mfc.Algorithm != "" {
+		mc.Algorithm.Name = mfc.Algorithm
+	}
+	if mfc.AlgorithmArgs != nil {
+		mc.Algorithm.Config = types.AlgorithmConfig(mfc.AlgorithmArgs)
+	}
+	if mfc.Volume != nil {
+		mc.Volume = *mfc.Volume
+	}
+	return
+}
+
+// notifierConfig creates the NotifierConfig of the notifier of the config file
+func (nfc notifierFileConfig) notifierConfig() (nc NotifierConfig, err error) {
+	var args map[string]string
+	var key, value, event string
+	var index int
+
+	if nfc.Name == "" {
+		err = fmt.Errorf("name: missing")
+		return
+	}
+	for index, event = range nfc.Events {
+		if _, err = types.NewEventTypeFromString(event); err != nil {
+			err = fmt.Errorf("events[%d]: %v", index, err)
+			return
+		}
+	}
+	if nfc.MinSeverity != "" {
+		if _, err = types.NewSeverityFromString(nfc.MinSeverity); err != nil {
+			err = fmt.Errorf("minSeverity: %v", err)
+			return
+		}
+	}
+	if nfc.DeliveryTimeout != "" {
+		if _, err = time.ParseDuration(nfc.DeliveryTimeout); err != nil {
+			err = fmt.Errorf("deliveryTimeout: %v", err)
+			return
+		}
+	}
+
+	args = make(map[string]string)
+	for key, value = range nfc.Args {
+		args[key] = value
+	}
+	if len(nfc.Events) > 0 {
+		args[notifierEventsArg] = strings.Join(nfc.Events, ",")
+	}
+	if nfc.MinSeverity != "" {
+		args[notifierMinSeverityArg] = nfc.MinSeverity
+	}
+	if nfc.DeliveryTimeout != "" {
+		args[notifierTimeoutArg] = nfc.DeliveryTimeout
+	}
+	nc, err = NewNotifierConfigFromFlags(nfc.Name, args)
+	return
+}
+
+func formatFloat(in *float64) string {
+	if in == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*in, 'f', -1, 64)
+}
+
+func formatBool(in *bool) string {
+	if in == nil {
+		return ""
+	}
+	return strconv.FormatBool(*in)
+}
+
+func formatInt(in *int) string {
+	if in == nil {
+		return ""
+	}
+	return strconv.Itoa(*in)
+}
+
+func validateLogLevel(in string) (err error) {
+	switch strings.ToLower(in) {
+	case "debug", "error", "warning", "info", "none":
+	default:
+		err = fmt.Errorf("invalid log level: %s, valid: debug, error, warning, info, none", in)
+	}
+	return
+}
+
+func validateTimeframe(in string) (err error) {
+	_, err = types.NewTimeframeFromString(in)
+	return
+}
+
+func validateTradeVolumeType(in string) (err error) {
+	_, err = TradeVolumeTypeFromString(in)
+	return
+}
+
+func validateMarginType(in string) (err error) {
+	_, err = types.NewMarginTypeFromString(in)
+	return
+}
+
+func validateTakeProfit(in string) (err error) {
+	_, _, err = TakeProfitFromString(in)
+	return
+}
+
+func validatePositive(in string) (err error) {
+	var value float64
+
+	if value, err = strconv.ParseFloat(in, 64); err == nil && value <= 0.0 {
+		err = fmt.Errorf("must be greater than 0: %s", in)
+	}
+	return
+}
+
+func validatePercentage(in string) (err error) {
+	var value float64
+
+	if value, err = strconv.ParseFloat(in, 64); err == nil && (value < 0.0 || value >= 1.0) {
+		err = fmt.Errorf("must be between 0 and 1: %s", in)
+	}
+	return
+}
+
+func validateNotNegative(in string) (err error) {
+	var value int
+
+	if value, err = strconv.Atoi(in); err == nil && value < 0 {
+		err = fmt.Errorf("must not be negative: %s", in)
+	}
+	return
+}
@@ -11,59 +11,80 @@ import (
 	"github.com/mhereman/cryptotrader/algorithms"
 	"github.com/mhereman/cryptotrader/logger"
 	"github.com/mhereman/cryptotrader/notifiers"
+	"github.com/mhereman/cryptotrader/statestores"
 
 	"github.com/mhereman/cryptotrader/exchange"
 
+	"github.com/mhereman/cryptotrader/history"
 	"github.com/mhereman/cryptotrader/interfaces"
 	"github.com/mhereman/cryptotrader/types"
 )
 
 type CryptoTrader struct {
-	ctx            context.Context
-	cancelFn       context.CancelFunc
-	wg             *sync.WaitGroup
-	signalChannel  types.SignalChannel
-	assetCfg       AssetConfig
-	exchangeCfg    ExchangeConfig
-	algoCfg        AlgorithmConfig
-	tradeCfg       TradeConfig
-	notifierCfg    NotifierConfig
-	openTrades     map[string]string
-	stopLossOrders map[string]string
-	exchangeDriver interfaces.IExchangeDriver
-	dataFetcher    interfaces.IDataFetcher
-	algorithm      interfaces.IAlgorithm
-	notifier       interfaces.INotifier
-	buyFn          func(types.AccountInfo, types.Symbol) error
-	closeFn        func(types.AccountInfo, types.Symbol) error
-	my_var         int
-}
-
-func New(assetConfig AssetConfig, exchangeConfig ExchangeConfig, algorithmConfig AlgorithmConfig, tradeConfig TradeConfig, notifierConfig NotifierConfig) (ct *CryptoTrader) {
+	ctx              context.Context
+	cancelFn         context.CancelFunc
+	wg               *sync.WaitGroup
+	signalChannel    types.SignalChannel
+	marketCfgs       []MarketConfig
+	exchangeCfg      ExchangeConfig
+	tradeCfg         TradeConfig
+	notifierCfgs     []NotifierConfig
+	stateStoreCfg    StateStoreConfig
+	positionMux      sync.Mutex
+	paused           bool
+	openTrades       map[string]string
+	stopLossOrders   map[string]string
+	highPrices       map[string]float64
+	entryPrices      map[string]float64
+	quantities       map[string]float64
+	takeProfitOrders map[string]string
+	takeProfitPrices map[string]float64
+	positionSides    map[string]types.PositionSide
+	exchangeDriver   interfaces.IExchangeDriver
+	limiter          *exchange.Limiter
+	futuresDriver    interfaces.IFuturesExchangeDriver
+	dataFetcher      interfaces.IDataFetcher
+	algorithms       []interfaces.IAlgorithm
+	notifier         interfaces.INotifier
+	notifications    *notificationQueue
+	stateStore       interfaces.IStateStore
+	openFn           func(types.AccountInfo, types.Symbol, types.PositionSide) error
+	closeFn          func(types.AccountInfo, types.Symbol) error
+	my_var           int
+}
+
+func New(marketConfigs []MarketConfig, exchangeConfig ExchangeConfig, tradeConfig TradeConfig, notifierConfigs []NotifierConfig, stateStoreConfig StateStoreConfig) (ct *CryptoTrader) {
 	ct = new(CryptoTrader)
 	ct.ctx, ct.cancelFn = context.WithCancel(context.Background())
 	ct.wg = &sync.WaitGroup{}
 	ct.signalChannel = make(types.SignalChannel)
-	ct.assetCfg = assetConfig
+	ct.marketCfgs = marketConfigs
 	ct.exchangeCfg = exchangeConfig
-	ct.algoCfg = algorithmConfig
 	ct.tradeCfg = tradeConfig
-	ct.notifierCfg = notifierConfig
+	ct.notifierCfgs = notifierConfigs
+	ct.notifications = newNotificationQueue(notificationQueueSize)
+	ct.stateStoreCfg = stateStoreConfig
 	ct.openTrades = make(map[string]string)
 	ct.stopLossOrders = make(map[string]string)
+	ct.highPrices = make(map[string]float64)
+	ct.entryPrices = make(map[string]float64)
+	ct.quantities = make(map[string]float64)
+	ct.takeProfitOrders = make(map[string]string)
+	ct.takeProfitPrices = make(map[string]float64)
+	ct.positionSides = make(map[string]types.PositionSide)
 
 	if ct.tradeCfg.Paper {
 		if ct.tradeCfg.TradeVolumeType == TVTFixed {
-			ct.buyFn = ct.paperBuyFixed
+			ct.openFn = ct.paperOpenFixed
 		} else {
-			ct.buyFn = ct.paperBuyPercent
+			ct.openFn = ct.paperOpenPercent
 		}
 		ct.closeFn = ct.paperClosePosition
 	} else {
 		if ct.tradeCfg.TradeVolumeType == TVTFixed {
-			ct.buyFn = ct.liveBuyFixed
+			ct.openFn = ct.liveOpenFixed
 		} else {
-			ct.buyFn = ct.liveBuyPercent
+			ct.openFn = ct.liveOpenPercent
 		}
 		ct.closeFn = ct.liveClosePosition
 	}
@@ -74,7 +95,9 @@ func (ct *CryptoTrader) Run() (err error) {
 	var signal types.Signal
 	var accountInfo types.AccountInfo
 	var balance types.AccountBalance
-	var seriesChannel types.SeriesChannel
+	var seriesChannels []types.SeriesChannel
+	var marketCfg MarketConfig
+	var index int
 	var mainLoop bool
 
 	defer func() {
@@ -91,21 +114,40 @@ func (ct *CryptoTrader) Run() (err error) {
 
 	setupCloseHandler(ct.ctx, ct.cancelFn)
 
+	if err = validateMarketConfigs(ct.marketCfgs); err != nil {
+		logger.Errorf("Error configuring markets: %v\n", err)
+		return
+	}
+
+	// The algorithm configurations are validated before connecting to the exchange
+	if err = ct.initAlgorithms(); err != nil {
+		return
+	}
+
 	if err = ct.initExchangeDriver(); err != nil {
 		return
 	}
 
-	if seriesChannel, err = ct.initDataFetcher(); err != nil {
+	if err = ct.initFutures(); err != nil {
 		return
 	}
 
-	if err = ct.initAlgorithm(); err != nil {
+	if seriesChannels, err = ct.initDataFetcher(); err != nil {
 		return
 	}
 
 	if err = ct.initNotifier(); err != nil {
 		return
 	}
+	ct.runNotificationQueueAsync()
+
+	if err = ct.initStateStore(); err != nil {
+		return
+	}
+
+	if err = ct.restoreOpenTrades(); err != nil {
+		return
+	}
 
 	if accountInfo, err = ct.exchangeDriver.GetAccountInfo(ct.ctx); err != nil {
 		logger.Errorf("Failed to retrieve account info: %v\n", err)
@@ -125,16 +167,48 @@ func (ct *CryptoTrader) Run() (err error) {
 	}
 
 	ct.dataFetcher.RunAsync(ct.ctx, ct.wg)
-	ct.algorithm.RunAsync(ct.ctx, ct.algoCfg.Config, seriesChannel, ct.signalChannel, ct.wg)
+	for index, marketCfg = range ct.marketCfgs {
+		if err = ct.algorithms[index].RunAsync(ct.ctx, marketCfg.Algorithm.Config, seriesChannels[index], ct.signalChannel, ct.wg); err != nil {
+			logger.Errorf("Error starting algorithm '%s' for market %s: %v\n", marketCfg.Algorithm.Name, marketCfg.String(), err)
+			return
+		}
+	}
+
+	if ct.tradeCfg.TrailingStop > 0.0 || ct.tradeCfg.TakeProfit > 0.0 {
+		if ct.tradeCfg.Paper {
+			logger.Warningln("Trailing stop and take profit are not supported in paper trading mode")
+		} else {
+			ct.runPositionMonitorAsync()
+		}
+	}
+
+	ct.runCommandListenerAsync()
 
 	if ct.tradeCfg.Paper {
 		logger.Infoln("Cryptotrader running in paper trading mode")
-		ct.notifier.Notify(ct.ctx, []byte("Cryptotrader running in paper trading mode"))
+		ct.notify(types.NewStartupEvent(true, "Cryptotrader running in paper trading mode"))
 	} else {
 		logger.Infoln("Cryptotrader running in live mode")
-		ct.notifier.Notify(ct.ctx, []byte("Cryptotrader running in live mode"))
+		ct.notify(types.NewStartupEvent(false, "Cryptotrader running in live mode"))
+	}
+	for _, marketCfg = range ct.marketCfgs {
+		logger.Infof(" . Symbol: %s[%s], Algorithm: %s, Ordersize: %s: %f", marketCfg.Asset.Symbol.String(), marketCfg.Asset.Timeframe.String(), marketCfg.Algorithm.Name, ct.tradeCfg.TradeVolumeType.String(), marketCfg.Volume)
+	}
+	if ct.tradeCfg.TrailingStop > 0.0 {
+		logger.Infof(" . Trailing stop: %f", ct.tradeCfg.TrailingStop)
+	}
+	if ct.tradeCfg.TakeProfit > 0.0 {
+		logger.Infof(" . Take profit: %s: %f", ct.tradeCfg.TakeProfitType.String(), ct.tradeCfg.TakeProfit)
+	}
+	if ct.tradeCfg.MaxOpenPositions > 0 {
+		logger.Infof(" . Max open positions: %d", ct.tradeCfg.MaxOpenPositions)
+	}
+	if ct.tradeCfg.Shorts {
+		logger.Infoln(" . Short positions enabled")
+	}
+	if ct.futuresDriver != nil {
+		logger.Infof(" . Leverage: %d, Margin type: %s", ct.tradeCfg.Leverage, ct.tradeCfg.MarginType.String())
 	}
-	logger.Infof(" . Symbol: %s[%s], Algorithm: %s, Ordersize: %s: %f", ct.assetCfg.Symbol.String(), ct.assetCfg.Timeframe.String(), ct.algoCfg.Name, ct.tradeCfg.TradeVolumeType.String(), ct.tradeCfg.Volume)
 	showDonations()
 
 	mainLoop = true
@@ -155,6 +229,7 @@ func (ct *CryptoTrader) Run() (err error) {
 		}
 	}
 
+	ct.waitNotifications()
 	ct.wg.Done()
 	return
 }
@@ -164,45 +239,377 @@ func (ct *CryptoTrader) initExchangeDriver() (err error) {
 		logger.Errorf("Error configuring exchange: %v\n", err)
 		return
 	}
+	if ct.exchangeCfg.Limiter.Enabled() {
+		ct.limiter = exchange.NewLimiter(ct.exchangeDriver, ct.exchangeCfg.Limiter)
+		ct.limiter.OnCircuitStateChange(ct.circuitStateChanged)
+		ct.exchangeDriver = ct.limiter
+	}
 	logger.Infof("Exchange '%s' initialized\n", ct.exchangeCfg.Name)
 	return
 }
 
-func (ct *CryptoTrader) initDataFetcher() (seriesChannel types.SeriesChannel, err error) {
-	ct.dataFetcher = NewDataFetcher(ct.exchangeDriver)
-	if seriesChannel, err = ct.dataFetcher.Register(ct.ctx, ct.assetCfg.Symbol, ct.assetCfg.Timeframe); err != nil {
-		logger.Errorf("Error registering asset: %s[%s] %v\n", ct.assetCfg.Symbol.String(), ct.assetCfg.Timeframe.String(), err)
+// circuitStateChanged notifies the requests to the exchange are paused
+func (ct *CryptoTrader) circuitStateChanged(state types.CircuitState) {
+	if state == types.CircuitOpen {
+		ct.notify(types.NewErrorEvent(types.Symbol{}, fmt.Sprintf("Exchange requests paused, too many failed requests to %s", ct.exchangeCfg.Name)))
+	}
+}
+
+// initFutures sets the leverage and the margin type of the traded markets on a futures exchange
+// Short positions and leverage are only supported on futures exchanges.
+func (ct *CryptoTrader) initFutures() (err error) {
+	var marketCfg MarketConfig
+	var symbol types.Symbol
+	var ok bool
+
+	if ct.futuresDriver, ok = exchange.FuturesDriver(ct.exchangeDriver); !ok {
+		if ct.tradeCfg.Futures() {
+			err = fmt.Errorf("Exchange '%s' does not support short positions and leverage", ct.exchangeCfg.Name)
+			logger.Errorf("Error configuring exchange: %v\n", err)
+		}
 		return
 	}
-	logger.Infof("DataCacher initialized for asset %s[%s]\n", ct.assetCfg.Symbol.String(), ct.assetCfg.Timeframe.String())
+
+	// Paper trading does not change the settings of the account
+	if ct.tradeCfg.Paper {
+		return
+	}
+
+	for _, marketCfg = range ct.marketCfgs {
+		symbol = marketCfg.Asset.Symbol
+		if err = ct.futuresDriver.SetMarginType(ct.ctx, symbol, ct.tradeCfg.MarginType); err != nil {
+			logger.Errorf("Error setting the margin type of symbol %s: %v\n", symbol.String(), err)
+			return
+		}
+		if err = ct.futuresDriver.SetLeverage(ct.ctx, symbol, ct.tradeCfg.Leverage); err != nil {
+			logger.Errorf("Error setting the leverage of symbol %s: %v\n", symbol.String(), err)
+			return
+		}
+	}
 	return
 }
 
-func (ct *CryptoTrader) initAlgorithm() (err error) {
-	if ct.algorithm, err = algorithms.GetAlgorithm(ct.algoCfg.Name); err != nil {
-		logger.Errorf("Error configuring algorithm: %v\n", err)
-		return
+func (ct *CryptoTrader) initDataFetcher() (seriesChannels []types.SeriesChannel, err error) {
+	var marketCfg MarketConfig
+	var seriesChannel types.SeriesChannel
+	var store *history.CandleStore
+
+	if ct.exchangeCfg.CandleStore != "" {
+		store = history.NewCandleStore(ct.exchangeCfg.CandleStore)
+		logger.Infof("Candle store '%s' initialized\n", ct.exchangeCfg.CandleStore)
+	}
+
+	ct.dataFetcher = NewDataFetcher(ct.exchangeDriver, store)
+	for _, marketCfg = range ct.marketCfgs {
+		if seriesChannel, err = ct.dataFetcher.Register(ct.ctx, marketCfg.Asset.Symbol, marketCfg.Asset.Timeframe); err != nil {
+			logger.Errorf("Error registering asset: %s[%s] %v\n", marketCfg.Asset.Symbol.String(), marketCfg.Asset.Timeframe.String(), err)
+			return
+		}
+		seriesChannels = append(seriesChannels, seriesChannel)
+		logger.Infof("DataCacher initialized for asset %s[%s]\n", marketCfg.Asset.Symbol.String(), marketCfg.Asset.Timeframe.String())
+	}
+	return
+}
+
+func (ct *CryptoTrader) initAlgorithms() (err error) {
+	var marketCfg MarketConfig
+	var algorithm interfaces.IAlgorithm
+	var index int
+
+	ct.algorithms = make([]interfaces.IAlgorithm, 0, len(ct.marketCfgs))
+	for index, marketCfg = range ct.marketCfgs {
+		if algorithm, err = algorithms.GetAlgorithm(marketCfg.Algorithm.Name); err != nil {
+			logger.Errorf("Error configuring algorithm for market %s: %v\n", marketCfg.String(), err)
+			return
+		}
+		if ct.marketCfgs[index].Algorithm.Config, err = algorithms.ValidateConfig(algorithm, marketCfg.Algorithm.Config); err != nil {
+			logger.Errorf("Error configuring algorithm for market %s: %v\n", marketCfg.String(), err)
+			return
+		}
+		ct.algorithms = append(ct.algorithms, algorithm)
+		logger.Infof("Algorithm '%s' initialized for market %s\n", marketCfg.Algorithm.Name, marketCfg.String())
+	}
+	return
+}
+
+// marketConfig returns the config of the market trading the symbol
+func (ct *CryptoTrader) marketConfig(symbol types.Symbol) (marketCfg MarketConfig, ok bool) {
+	for _, marketCfg = range ct.marketCfgs {
+		if marketCfg.Asset.Symbol == symbol {
+			ok = true
+			return
+		}
 	}
-	logger.Infof("Algorithm '%s' initialized\n", ct.algoCfg.Name)
 	return
 }
 
 func (ct *CryptoTrader) initNotifier() (err error) {
-	if ct.notifierCfg.Name == "" {
-		ct.notifierCfg.Name = "noop"
-		ct.notifierCfg.ArgMap = make(map[string]string)
+	var multi *notifiers.Multi
+	var notifierCfg NotifierConfig
+	var notifier interfaces.INotifier
+
+	multi = notifiers.NewMulti()
+	for _, notifierCfg = range ct.notifierCfgs {
+		if notifier, err = notifiers.GetNotifier(ct.ctx, notifierCfg.Name, notifierCfg.ArgMap); err != nil {
+			logger.Errorf("Error configuring notifier %s: %v\n", notifierCfg.Name, err)
+			return
+		}
+		multi.Add(notifier, notifierCfg.Filter, notifierCfg.Timeout)
+		logger.Infof("Notifier '%s' initialized\n", notifierCfg.Name)
 	}
+	ct.notifier = multi
+	return
+}
 
-	if ct.notifier, err = notifiers.GetNotifier(ct.ctx, ct.notifierCfg.Name, ct.notifierCfg.ArgMap); err != nil {
-		logger.Errorf("Error configuring notifier: %v\n", err)
+func (ct *CryptoTrader) initStateStore() (err error) {
+	if ct.stateStoreCfg.Name == "" {
+		ct.stateStoreCfg.Name = "memory"
+		ct.stateStoreCfg.ArgMap = make(map[string]string)
+	}
+
+	if ct.stateStore, err = statestores.GetStateStore(ct.ctx, ct.stateStoreCfg.Name, ct.stateStoreCfg.ArgMap); err != nil {
+		logger.Errorf("Error configuring state store: %v\n", err)
 		return
 	}
-	logger.Infof("Notifier '%s' initialized\n", ct.notifierCfg.Name)
+	logger.Infof("State store '%s' initialized\n", ct.stateStoreCfg.Name)
 	return
 }
 
+// restoreOpenTrades reloads the persisted open trades and reconciles them with the exchange
+func (ct *CryptoTrader) restoreOpenTrades() (err error) {
+	var trades []types.OpenTrade
+	var trade types.OpenTrade
+	var symbolString string
+	var keep, ok bool
+
+	if trades, err = ct.stateStore.Load(ct.ctx); err != nil {
+		logger.Errorf("Error loading open trades: %v\n", err)
+		return
+	}
+
+	for _, trade = range trades {
+		symbolString = trade.Symbol.String()
+		if _, ok = ct.marketConfig(trade.Symbol); !ok {
+			logger.Warningf("restoreOpenTrades: Ignoring open trade for symbol %s which is not traded\n", symbolString)
+			continue
+		}
+		if trade.Paper != ct.tradeCfg.Paper {
+			logger.Warningf("restoreOpenTrades: Ignoring open trade for symbol %s, paper trading mismatch\n", symbolString)
+			continue
+		}
+
+		keep = true
+		if !trade.Paper {
+			// The trade stays persisted, reconciling is retried on the next start
+			if keep, err = ct.reconcileOpenTrade(&trade); err != nil {
+				logger.Errorf("Error reconciling open trade for symbol %s: %v\n", symbolString, err)
+				return
+			}
+		}
+
+		if !keep {
+			if err = ct.stateStore.Delete(ct.ctx, trade.Symbol); err != nil {
+				logger.Errorf("Error deleting open trade: %v\n", err)
+				return
+			}
+			continue
+		}
+
+		ct.openTrades[symbolString] = trade.TradeReference.String()
+		ct.positionSides[symbolString] = trade.Position
+		ct.highPrices[symbolString] = trade.HighPrice
+		if trade.EntryPrice > 0.0 {
+			ct.entryPrices[symbolString] = trade.EntryPrice
+		}
+		if trade.Quantity > 0.0 {
+			ct.quantities[symbolString] = trade.Quantity
+		}
+		if trade.StopLossReference != uuid.Nil {
+			ct.stopLossOrders[symbolString] = trade.StopLossReference.String()
+		}
+		if trade.TakeProfitReference != uuid.Nil {
+			ct.takeProfitOrders[symbolString] = trade.TakeProfitReference.String()
+		}
+		if trade.TakeProfitPrice > 0.0 {
+			ct.takeProfitPrices[symbolString] = trade.TakeProfitPrice
+		}
+		if err = ct.stateStore.Save(ct.ctx, trade); err != nil {
+			logger.Errorf("Error saving open trade: %v\n", err)
+			return
+		}
+		logger.Infof("Restored open %s trade for symbol %s [UUID: %s; Stop loss UUID: %s]\n", trade.Position.String(), symbolString, trade.TradeReference.String(), trade.StopLossReference.String())
+	}
+	return
+}
+
+// reconcileOpenTrade verifies the state of a restored trade on the exchange
+// The stop loss reference is cleared if the stop loss is no longer active,
+// false is returned if the position is no longer open. Only the exchange reporting an order
+// as unknown or unfilled closes the trade, any other failure is returned as error.
+func (ct *CryptoTrader) reconcileOpenTrade(trade *types.OpenTrade) (keep bool, err error) {
+	var symbolString string
+	var orderInfo types.OrderInfo
+	var openOrders []types.OrderInfo
+
+	symbolString = trade.Symbol.String()
+	if orderInfo, err = ct.exchangeDriver.GetOrder(ct.ctx, types.Order{
+		Symbol:        trade.Symbol,
+		UserReference: trade.TradeReference,
+	}); err != nil {
+		if !exchange.IsOrderNotFound(err) {
+			err = fmt.Errorf("Failed to retrieve entry order %s for symbol %s: %v", trade.TradeReference.String(), symbolString, err)
+			return
+		}
+		logger.Warningf("reconcileOpenTrade: Entry order %s for symbol %s not found on the exchange: %v\n", trade.TradeReference.String(), symbolString, err)
+		err = nil
+		return
+	}
+	if orderInfo.ExecutedQuantity <= 0.0 {
+		logger.Warningf("reconcileOpenTrade: Entry order %s for symbol %s was never filled\n", trade.TradeReference.String(), symbolString)
+		return
+	}
+
+	keep = true
+	if trade.TakeProfitReference != uuid.Nil {
+		if orderInfo, err = ct.exchangeDriver.GetOrder(ct.ctx, types.Order{
+			Symbol:        trade.Symbol,
+			UserReference: trade.TakeProfitReference,
+		}); err != nil {
+			if !exchange.IsOrderNotFound(err) {
+				err = fmt.Errorf("Failed to retrieve take profit %s for symbol %s: %v", trade.TakeProfitReference.String(), symbolString, err)
+				return
+			}
+			logger.Warningf("reconcileOpenTrade: Take profit %s for symbol %s not found on the exchange: %v\n", trade.TakeProfitReference.String(), symbolString, err)
+			err = nil
+			trade.TakeProfitReference = uuid.Nil
+		} else {
+			switch orderInfo.Status {
+			case types.StatusNew, types.StatusPartiallyFilled:
+			case types.StatusFilled:
+				logger.Infof("reconcileOpenTrade: Position for symbol %s was closed by its take profit\n", symbolString)
+				keep = false
+				return
+			default:
+				trade.TakeProfitReference = uuid.Nil
+			}
+		}
+	}
+
+	if trade.StopLossReference == uuid.Nil {
+		return
+	}
+
+	if openOrders, err = ct.exchangeDriver.OpenOrders(ct.ctx, trade.Symbol); err != nil {
+		logger.Warningf("reconcileOpenTrade: Failed to retrieve open orders for symbol %s: %v\n", symbolString, err)
+	}
+	for _, orderInfo = range openOrders {
+		if orderInfo.UserReference == trade.StopLossReference {
+			return
+		}
+	}
+
+	if orderInfo, err = ct.exchangeDriver.GetOrder(ct.ctx, types.Order{
+		Symbol:        trade.Symbol,
+		UserReference: trade.StopLossReference,
+	}); err != nil {
+		if !exchange.IsOrderNotFound(err) {
+			err = fmt.Errorf("Failed to retrieve stop loss %s for symbol %s: %v", trade.StopLossReference.String(), symbolString, err)
+			return
+		}
+		logger.Warningf("reconcileOpenTrade: Stop loss %s for symbol %s not found on the exchange: %v\n", trade.StopLossReference.String(), symbolString, err)
+		err = nil
+		trade.StopLossReference = uuid.Nil
+		return
+	}
+
+	switch orderInfo.Status {
+	case types.StatusNew, types.StatusPartiallyFilled:
+	case types.StatusFilled:
+		logger.Infof("reconcileOpenTrade: Position for symbol %s was closed by its stop loss\n", symbolString)
+		keep = false
+	default:
+		logger.Warningf("reconcileOpenTrade: Stop loss %s for symbol %s is no longer active, the position is unprotected\n", trade.StopLossReference.String(), symbolString)
+		trade.StopLossReference = uuid.Nil
+	}
+	return
+}
+
+// saveOpenTrade persists the open trade of the symbol
+func (ct *CryptoTrader) saveOpenTrade(symbol types.Symbol) {
+	var err error
+	var trade types.OpenTrade
+	var stopLossID, takeProfitID string
+	var ok bool
+
+	trade = types.NewOpenTrade(symbol, uuid.Nil, uuid.Nil, ct.tradeCfg.Paper, time.Now(), ct.highPrices[symbol.String()])
+	if trade.TradeReference, err = uuid.Parse(ct.openTrades[symbol.String()]); err != nil {
+		logger.Errorf("saveOpenTrade: Invalid trade uuid: %s %v\n", ct.openTrades[symbol.String()], err)
+		return
+	}
+	if stopLossID, ok = ct.stopLossOrders[symbol.String()]; ok {
+		if trade.StopLossReference, err = uuid.Parse(stopLossID); err != nil {
+			logger.Errorf("saveOpenTrade: Invalid stop loss uuid: %s %v\n", stopLossID, err)
+			return
+		}
+	}
+	if takeProfitID, ok = ct.takeProfitOrders[symbol.String()]; ok {
+		if trade.TakeProfitReference, err = uuid.Parse(takeProfitID); err != nil {
+			logger.Errorf("saveOpenTrade: Invalid take profit uuid: %s %v\n", takeProfitID, err)
+			return
+		}
+	}
+	trade.TakeProfitPrice = ct.takeProfitPrices[symbol.String()]
+	trade.EntryPrice = ct.entryPrices[symbol.String()]
+	trade.Quantity = ct.quantities[symbol.String()]
+	trade.Position = ct.positionSides[symbol.String()]
+
+	if err = ct.stateStore.Save(ct.ctx, trade); err != nil {
+		logger.Errorf("saveOpenTrade: Failed to save open trade for symbol %s: %v\n", symbol.String(), err)
+	}
+}
+
+// forgetPosition removes all state kept for the position of the symbol
+func (ct *CryptoTrader) forgetPosition(symbol types.Symbol) {
+	var symbolString string
+
+	symbolString = symbol.String()
+	delete(ct.openTrades, symbolString)
+	delete(ct.stopLossOrders, symbolString)
+	delete(ct.takeProfitOrders, symbolString)
+	delete(ct.takeProfitPrices, symbolString)
+	delete(ct.highPrices, symbolString)
+	delete(ct.entryPrices, symbolString)
+	delete(ct.quantities, symbolString)
+	delete(ct.positionSides, symbolString)
+	ct.deleteOpenTrade(symbol)
+}
+
+// deleteOpenTrade removes the persisted open trade of the symbol
+func (ct *CryptoTrader) deleteOpenTrade(symbol types.Symbol) {
+	var err error
+
+	if err = ct.stateStore.Delete(ct.ctx, symbol); err != nil {
+		logger.Errorf("deleteOpenTrade: Failed to delete open trade for symbol %s: %v\n", symbol.String(), err)
+	}
+}
+
 func (ct *CryptoTrader) executeSignal(signal types.Signal) (err error) {
 	var accountInfo types.AccountInfo
+	var position types.PositionSide
+	var ok bool
+
+	if _, ok = ct.marketConfig(signal.Symbol); !ok {
+		logger.Warningf("Execute Signal: Ignoring signal for symbol %s which is not traded\n", signal.Symbol.String())
+		return
+	}
+	if signal.Position == types.Short && !ct.tradeCfg.Shorts {
+		logger.Debugf("Execute Signal: Short positions disabled, ignoring signal %s\n", signal.String())
+		return
+	}
+
+	// Positions and the shared quote balance are only modified while holding the position lock
+	ct.positionMux.Lock()
+	defer ct.positionMux.Unlock()
 
 	if accountInfo, err = ct.exchangeDriver.GetAccountInfo(ct.ctx); err != nil {
 		logger.Errorf("Execute Signal Error: %v\n", err)
@@ -213,219 +620,355 @@ func (ct *CryptoTrader) executeSignal(signal types.Signal) (err error) {
 		logger.Infoln(signal.String())
 		return
 	}
+	// Algorithms repeat their signal while it holds, only the signals changing a position are sent
+	if ct.signalChangesPosition(signal) {
+		ct.notify(types.NewSignalEvent(signal, ct.tradeCfg.Paper))
+	}
 
-	if signal.Side == types.Buy {
-		err = ct.buyFn(accountInfo, signal.Symbol)
+	if ct.paused {
+		logger.Infof("Execute Signal: Trading paused, ignoring signal %s\n", signal.String())
+		return
+	}
+
+	if signal.Opens() {
+		if ct.maxOpenPositionsReached(signal.Symbol) {
+			logger.Warningf("Execute Signal: Max open positions (%d) reached, ignoring %s signal for symbol %s\n", ct.tradeCfg.MaxOpenPositions, signal.Side.String(), signal.Symbol.String())
+			return
+		}
+		err = ct.openFn(accountInfo, signal.Symbol, signal.Position)
 	} else {
+		if position, ok = ct.positionSides[signal.Symbol.String()]; ok && position != signal.Position {
+			logger.Debugf("Execute Signal: No %s position to close for symbol %s\n", signal.Position.String(), signal.Symbol.String())
+			return
+		}
 		err = ct.closeFn(accountInfo, signal.Symbol)
 	}
 
 	if err != nil {
 		logger.Errorf("Execute Signal Error: %v\n", err)
+		ct.notify(types.NewErrorEvent(signal.Symbol, fmt.Sprintf("Failed to execute %s signal: %v", signal.Side.String(), err)))
 	}
 	return
 }
 
-func (ct *CryptoTrader) buyMarket(symbol types.Symbol, orderQuantity float64) (orderInfo types.OrderInfo, stopOrderInfo types.OrderInfo, averagePrice float64, err error) {
+// signalChangesPosition returns true if the signal opens a position while none is open for its symbol,
+// or if it closes the open position of its symbol
+func (ct *CryptoTrader) signalChangesPosition(signal types.Signal) bool {
+	var ok bool
+
+	if _, ok = ct.openTrades[signal.Symbol.String()]; !ok {
+		return signal.Opens()
+	}
+	return !signal.Opens() && ct.positionSides[signal.Symbol.String()] == signal.Position
+}
+
+// maxOpenPositionsReached returns true if no new position can be opened for the symbol
+func (ct *CryptoTrader) maxOpenPositionsReached(symbol types.Symbol) bool {
+	var ok bool
+
+	if ct.tradeCfg.MaxOpenPositions <= 0 {
+		return false
+	}
+	if _, ok = ct.openTrades[symbol.String()]; ok {
+		return false
+	}
+	return len(ct.openTrades) >= ct.tradeCfg.MaxOpenPositions
+}
+
+// tradeVolume returns the volume to trade for the symbol
+func (ct *CryptoTrader) tradeVolume(symbol types.Symbol) float64 {
+	var marketCfg MarketConfig
+	var ok bool
+
+	if marketCfg, ok = ct.marketConfig(symbol); !ok {
+		return ct.tradeCfg.Volume
+	}
+	return marketCfg.Volume
+}
+
+func (ct *CryptoTrader) enterMarket(symbol types.Symbol, position types.PositionSide, orderQuantity float64) (orderInfo types.OrderInfo, averagePrice float64, err error) {
 	var orderBook types.OrderBook
-	var baseQuantity, stopLoss, stopLossLimit float64
+	var baseQuantity float64
 	var symbolInfo types.SymbolInfo
+	var side types.Side
 
 	if symbolInfo, err = ct.exchangeDriver.GetSymbolInfo(ct.ctx, symbol); err != nil {
-		err = fmt.Errorf("buyLimit Failed to retrieve symbol info for symbol %s %v", symbol.String(), err)
+		err = fmt.Errorf("enterMarket Failed to retrieve symbol info for symbol %s %v", symbol.String(), err)
 		return
 	}
 
 	if orderBook, err = ct.exchangeDriver.GetOrderBook(ct.ctx, symbol); err != nil {
-		err = fmt.Errorf("buyMarket Failed to retrieve order book for symbol: %s %v", symbol.String(), err)
+		err = fmt.Errorf("enterMarket Failed to retrieve order book for symbol: %s %v", symbol.String(), err)
 		return
 	}
-	baseQuantity, averagePrice = orderBook.GetBuyVolumeAndAveragePrice(orderQuantity)
-	baseQuantity = normalizeQuantity(baseQuantity)
-
-	if orderInfo, err = ct.exchangeDriver.PlaceOrder(ct.ctx, types.NewMarketOrder(symbol, types.Buy, baseQuantity), &symbolInfo); err != nil {
-		err = fmt.Errorf("buyMarket Failed to place order for symbol: %s %v", symbol.String(), err)
-		return
+	if position == types.Short {
+		baseQuantity, averagePrice = orderBook.GetSellVolumeAndAveragePrice(orderQuantity)
+	} else {
+		baseQuantity, averagePrice = orderBook.GetBuyVolumeAndAveragePrice(orderQuantity)
 	}
+	baseQuantity = normalizeQuantity(baseQuantity)
 
-	stopLoss = averagePrice * (1.0 - ct.tradeCfg.StopLoss)
-	stopLossLimit = stopLoss * (1.0 - ct.tradeCfg.MaxSlippage)
-	if stopOrderInfo, err = ct.exchangeDriver.PlaceOrder(ct.ctx, types.NewStopLossLimitOrder(symbol, types.Sell, orderInfo.ExecutedQuantity, stopLoss, stopLossLimit), &symbolInfo); err != nil {
-		err = fmt.Errorf("buyLimit Failed to place stop loss for symbol: %s %v", symbol.String(), err)
+	side = position.OpenSide()
+	if orderInfo, err = ct.exchangeDriver.PlaceOrder(ct.ctx, types.NewMarketOrder(symbol, side, baseQuantity), &symbolInfo); err != nil {
+		err = fmt.Errorf("enterMarket Failed to place order for symbol: %s %v", symbol.String(), err)
 		return
 	}
+	ct.notify(types.NewOrderPlacedEvent(symbol, side, baseQuantity, averagePrice, false, "market"))
 
 	return
 }
 
-func (ct *CryptoTrader) buyLimit(symbol types.Symbol, orderQuantity float64) (orderInfo types.OrderInfo, stopOrderInfo types.OrderInfo, limitPrice float64, err error) {
+func (ct *CryptoTrader) enterLimit(symbol types.Symbol, position types.PositionSide, orderQuantity float64) (orderInfo types.OrderInfo, limitPrice float64, err error) {
 	var marketPrice, baseQuantity float64
-	var stopLossLimit, stopLoss float64
 	var symbolInfo types.SymbolInfo
+	var side types.Side
 
 	if symbolInfo, err = ct.exchangeDriver.GetSymbolInfo(ct.ctx, symbol); err != nil {
-		err = fmt.Errorf("buyLimit Failed to retrieve symbol info for symbol %s %v", symbol.String(), err)
+		err = fmt.Errorf("enterLimit Failed to retrieve symbol info for symbol %s %v", symbol.String(), err)
 		return
 	}
 
 	if marketPrice, err = ct.exchangeDriver.Ticker(ct.ctx, symbol); err != nil {
-		err = fmt.Errorf("buyLimit Failed to retrieve market price for symbol %s %v", symbol.String(), err)
+		err = fmt.Errorf("enterLimit Failed to retrieve market price for symbol %s %v", symbol.String(), err)
 		return
 	}
 
 	limitPrice = marketPrice * (1.0 + ct.tradeCfg.MaxSlippage)
+	if position == types.Short {
+		limitPrice = marketPrice * (1.0 - ct.tradeCfg.MaxSlippage)
+	}
 	baseQuantity = orderQuantity / limitPrice
 	baseQuantity = normalizeQuantity(baseQuantity)
 
-	if orderInfo, err = ct.exchangeDriver.PlaceOrder(ct.ctx, types.NewLimitOrder(symbol, types.Buy, types.ImmediateOrCancel, baseQuantity, limitPrice), &symbolInfo); err != nil {
-		err = fmt.Errorf("buyLimit Failed to place order for symbol: %s %v", symbol.String(), err)
+	side = position.OpenSide()
+	if orderInfo, err = ct.exchangeDriver.PlaceOrder(ct.ctx, types.NewLimitOrder(symbol, side, types.ImmediateOrCancel, baseQuantity, limitPrice), &symbolInfo); err != nil {
+		err = fmt.Errorf("enterLimit Failed to place order for symbol: %s %v", symbol.String(), err)
 		return
 	}
+	ct.notify(types.NewOrderPlacedEvent(symbol, side, baseQuantity, limitPrice, false, "limit"))
 
-	stopLoss = limitPrice * (1.0 - ct.tradeCfg.StopLoss)
-	stopLossLimit = stopLoss * (1.0 - ct.tradeCfg.MaxSlippage)
-	if stopOrderInfo, err = ct.exchangeDriver.PlaceOrder(ct.ctx, types.NewStopLossLimitOrder(symbol, types.Sell, orderInfo.ExecutedQuantity, stopLoss, stopLossLimit), &symbolInfo); err != nil {
-		err = fmt.Errorf("buyLimit Failed to place stop loss for symbol: %s %v", symbol.String(), err)
-		return
-	}
+	return
+}
+
+// placeExitOrders places the stop loss and the optional take profit of a position entered at the entry price
+func (ct *CryptoTrader) placeExitOrders(symbol types.Symbol, position types.PositionSide, quantity float64, entryPrice float64) (err error) {
+	var stopLoss, stopLossLimit float64
 
+	stopLoss, stopLossLimit = ct.tradeCfg.StopLossPrices(entryPrice, ct.tradeCfg.StopLoss, position)
+	err = ct.placeProtectiveOrders(symbol, position, quantity, stopLoss, stopLossLimit, ct.tradeCfg.TakeProfitPrice(entryPrice, position))
 	return
 }
 
-func (ct *CryptoTrader) liveBuyFixed(accountInfo types.AccountInfo, symbol types.Symbol) (err error) {
+// placeProtectiveOrders places the stop loss and, if the take profit is set, the take profit of the position
+// Both are placed as one-cancels-other pair if the exchange supports it, otherwise the
+// take profit is emulated by the position monitor.
+func (ct *CryptoTrader) placeProtectiveOrders(symbol types.Symbol, position types.PositionSide, quantity float64, stopLoss float64, stopLossLimit float64, takeProfit float64) (err error) {
 	var symbolString string
-	var orderQuantity, freeQuantity, baseQuantity, price float64
-	var orderInfo, stopLossInfo types.OrderInfo
+	var symbolInfo types.SymbolInfo
+	var ocoDriver interfaces.IOCOExchangeDriver
+	var limitInfo, stopInfo types.OrderInfo
+	var order types.Order
+	var side types.Side
 	var ok bool
 
 	symbolString = symbol.String()
-	if _, ok = ct.openTrades[symbolString]; ok {
-		logger.Warningf("liveBuyFixed: Trade still open for symbol: %s\n", symbolString)
+	delete(ct.takeProfitOrders, symbolString)
+	delete(ct.takeProfitPrices, symbolString)
+	if takeProfit > 0.0 {
+		ct.takeProfitPrices[symbolString] = takeProfit
+	}
+
+	if symbolInfo, err = ct.exchangeDriver.GetSymbolInfo(ct.ctx, symbol); err != nil {
+		err = fmt.Errorf("placeProtectiveOrders Failed to retrieve symbol info for symbol %s %v", symbolString, err)
 		return
 	}
 
-	orderQuantity = ct.tradeCfg.Volume
-	freeQuantity, _ = accountInfo.GetAssetQuantity(symbol.Quote())
-	if (freeQuantity * maxPctVolume) < orderQuantity {
-		if ct.tradeCfg.Reduce == false {
-			logger.Warningf("liveBuyFixed: Insufficient funds to initiate buy position for symbol %s (required: %f, available: %f)", symbolString, orderQuantity, (freeQuantity * 0.995))
+	side = position.CloseSide()
+	if takeProfit > 0.0 {
+		if ocoDriver, ok = exchange.OCODriver(ct.exchangeDriver); ok {
+			if limitInfo, stopInfo, err = ocoDriver.PlaceOCOOrder(ct.ctx, types.NewOCOOrder(symbol, side, quantity, takeProfit, stopLoss, stopLossLimit), &symbolInfo); err != nil {
+				err = fmt.Errorf("placeProtectiveOrders Failed to place OCO order for symbol: %s %v", symbolString, err)
+				return
+			}
+			ct.stopLossOrders[symbolString] = stopInfo.UserReference.String()
+			ct.takeProfitOrders[symbolString] = limitInfo.UserReference.String()
+			ct.notify(types.NewOrderPlacedEvent(symbol, side, quantity, stopLoss, false, "stop loss"))
+			ct.notify(types.NewOrderPlacedEvent(symbol, side, quantity, takeProfit, false, "take profit"))
 			return
 		}
-		orderQuantity = freeQuantity * maxPctVolume
 	}
 
-	if ct.tradeCfg.MaxSlippage > 0.0 {
-		orderInfo, stopLossInfo, price, err = ct.buyLimit(symbol, orderQuantity)
-	} else {
-		orderInfo, stopLossInfo, price, err = ct.buyMarket(symbol, orderQuantity)
-	}
-	if err != nil {
-		logger.Errorf("liveBuyFixed: %v\n", err)
+	order = types.NewStopLossLimitOrder(symbol, side, quantity, stopLoss, stopLossLimit)
+	order.ReduceOnly = true
+	if stopInfo, err = ct.exchangeDriver.PlaceOrder(ct.ctx, order, &symbolInfo); err != nil {
+		err = fmt.Errorf("placeProtectiveOrders Failed to place stop loss for symbol: %s %v", symbolString, err)
 		return
 	}
-
-	ct.openTrades[symbolString] = orderInfo.UserReference.String()
-	ct.stopLossOrders[symbolString] = stopLossInfo.UserReference.String()
-	logger.Infof("liveBuyFixed: Buy %s [Amount: %f; Average Price: %f; UUID: %s]", symbolString, baseQuantity, price, ct.openTrades[symbolString])
-	ct.notifier.Notify(ct.ctx, []byte(fmt.Sprintf("Signal %s Buy", symbolString)))
-
+	ct.stopLossOrders[symbolString] = stopInfo.UserReference.String()
+	ct.notify(types.NewOrderPlacedEvent(symbol, side, quantity, stopLoss, false, "stop loss"))
 	return
 }
 
-func (ct *CryptoTrader) paperBuyFixed(accountInfo types.AccountInfo, symbol types.Symbol) (err error) {
+func (ct *CryptoTrader) liveOpenFixed(accountInfo types.AccountInfo, symbol types.Symbol, position types.PositionSide) (err error) {
 	var symbolString string
-	var orderQuantity, baseQuantity, averagePrice float64
-	var orderBook types.OrderBook
+	var orderQuantity, freeQuantity float64
 	var ok bool
 
 	symbolString = symbol.String()
 	if _, ok = ct.openTrades[symbolString]; ok {
-		logger.Warningf("paperFixed: Trade still open for symbol: %s\n", symbolString)
+		logger.Warningf("liveOpenFixed: Trade still open for symbol: %s\n", symbolString)
 		return
 	}
 
-	orderQuantity = ct.tradeCfg.Volume
-	if orderBook, err = ct.exchangeDriver.GetOrderBook(ct.ctx, symbol); err != nil {
-		logger.Errorf("paperBuyFixed Failed to retrieve order book for symbol: %s %v\n", symbolString, err)
-		return
+	orderQuantity = ct.tradeVolume(symbol)
+	freeQuantity, _ = accountInfo.GetAssetQuantity(symbol.Quote())
+	if (freeQuantity * maxPctVolume) < orderQuantity {
+		if ct.tradeCfg.Reduce == false {
+			logger.Warningf("liveOpenFixed: Insufficient funds to initiate %s position for symbol %s (required: %f, available: %f)", position.String(), symbolString, orderQuantity, (freeQuantity * 0.995))
+			return
+		}
+		orderQuantity = freeQuantity * maxPctVolume
 	}
-	baseQuantity, averagePrice = orderBook.GetBuyVolumeAndAveragePrice(orderQuantity)
-	baseQuantity = normalizeQuantity(baseQuantity)
-
-	ct.openTrades[symbolString] = uuid.New().String()
-	ct.stopLossOrders[symbolString] = uuid.New().String()
-	logger.Infof("paperBuyFixed: Buy %s [Amount: %f; Average Price: %f; UUID: %s]", symbolString, baseQuantity, averagePrice, ct.openTrades[symbolString])
-	ct.notifier.Notify(ct.ctx, []byte(fmt.Sprintf("Signal %s Buy", symbolString)))
 
+	err = ct.liveOpen(symbol, position, orderQuantity)
 	return
 }
 
-func (ct *CryptoTrader) liveBuyPercent(accountInfo types.AccountInfo, symbol types.Symbol) (err error) {
+func (ct *CryptoTrader) liveOpenPercent(accountInfo types.AccountInfo, symbol types.Symbol, position types.PositionSide) (err error) {
 	var symbolString string
-	var freeQuantity, orderQuantity, baseQuantity, price float64
-	var orderInfo, stopLossInfo types.OrderInfo
+	var freeQuantity, orderQuantity float64
 	var ok bool
 
 	symbolString = symbol.String()
 	if _, ok = ct.openTrades[symbolString]; ok {
-		logger.Warningf("liveBuyPercent: Trade still open for symbol: %s\n", symbolString)
+		logger.Warningf("liveOpenPercent: Trade still open for symbol: %s\n", symbolString)
 		return
 	}
 
 	freeQuantity, _ = accountInfo.GetAssetQuantity(symbol.Quote())
-	orderQuantity = freeQuantity * ct.tradeCfg.Volume
+	orderQuantity = freeQuantity * ct.tradeVolume(symbol)
 
-	if ct.tradeCfg.MaxSlippage > 0 {
-		orderInfo, stopLossInfo, price, err = ct.buyLimit(symbol, orderQuantity)
+	err = ct.liveOpen(symbol, position, orderQuantity)
+	return
+}
+
+// liveOpen opens the position with the quote asset quantity and places its exit orders
+// On a futures exchange the quote asset quantity is the margin of the position.
+func (ct *CryptoTrader) liveOpen(symbol types.Symbol, position types.PositionSide, orderQuantity float64) (err error) {
+	var symbolString string
+	var baseQuantity, price float64
+	var orderInfo types.OrderInfo
+
+	symbolString = symbol.String()
+	orderQuantity = ct.tradeCfg.PositionSize(orderQuantity)
+
+	if ct.tradeCfg.MaxSlippage > 0.0 {
+		orderInfo, price, err = ct.enterLimit(symbol, position, orderQuantity)
 	} else {
-		orderInfo, stopLossInfo, price, err = ct.buyMarket(symbol, orderQuantity)
+		orderInfo, price, err = ct.enterMarket(symbol, position, orderQuantity)
 	}
 	if err != nil {
-		logger.Errorf("liveBuyPercent: %v\n", err)
+		logger.Errorf("liveOpen: %v\n", err)
 		return
 	}
 
+	baseQuantity = netQuantity(orderInfo)
+	if orderInfo.AveragePrice() > 0.0 {
+		price = orderInfo.AveragePrice()
+	}
 	ct.openTrades[symbolString] = orderInfo.UserReference.String()
-	ct.stopLossOrders[symbolString] = stopLossInfo.UserReference.String()
-	logger.Infof("liveBuyPercent: Buy %s [Amount: %f; Average Price: %f; UUID: %s]", symbolString, baseQuantity, price, ct.openTrades[symbolString])
-	ct.notifier.Notify(ct.ctx, []byte(fmt.Sprintf("Signal %s Buy", symbolString)))
+	ct.positionSides[symbolString] = position
+	ct.highPrices[symbolString] = price
+	ct.entryPrices[symbolString] = price
+	ct.quantities[symbolString] = baseQuantity
+	ct.notify(types.NewOrderFilledEvent(symbol, position.OpenSide(), baseQuantity, price, false))
+	if err = ct.placeExitOrders(symbol, position, baseQuantity, price); err != nil {
+		logger.Errorf("liveOpen: %v\n", err)
+		ct.notify(types.NewErrorEvent(symbol, fmt.Sprintf("Failed to place the exit orders, the position is unprotected: %v", err)))
+		err = nil
+	}
+	ct.saveOpenTrade(symbol)
+	logger.Infof("liveOpen: %s %s %s [Amount: %f; Average Price: %f; UUID: %s]", position.OpenSide().String(), position.String(), symbolString, baseQuantity, price, ct.openTrades[symbolString])
+
 	return
 }
 
-func (ct *CryptoTrader) paperBuyPercent(accountInfo types.AccountInfo, symbol types.Symbol) (err error) {
+func (ct *CryptoTrader) paperOpenFixed(accountInfo types.AccountInfo, symbol types.Symbol, position types.PositionSide) (err error) {
 	var symbolString string
-	var freeQuantity, orderQuantity, baseQuantity, averagePrice float64
-	var orderBook types.OrderBook
 	var ok bool
 
 	symbolString = symbol.String()
 	if _, ok = ct.openTrades[symbolString]; ok {
-		logger.Warningf("paperFixed: Trade still open for symbol: %s\n", symbolString)
+		logger.Warningf("paperOpenFixed: Trade still open for symbol: %s\n", symbolString)
+		return
+	}
+
+	err = ct.paperOpen(symbol, position, ct.tradeVolume(symbol))
+	return
+}
+
+func (ct *CryptoTrader) paperOpenPercent(accountInfo types.AccountInfo, symbol types.Symbol, position types.PositionSide) (err error) {
+	var symbolString string
+	var freeQuantity float64
+	var ok bool
+
+	symbolString = symbol.String()
+	if _, ok = ct.openTrades[symbolString]; ok {
+		logger.Warningf("paperOpenPercent: Trade still open for symbol: %s\n", symbolString)
 		return
 	}
 
 	freeQuantity, _ = accountInfo.GetAssetQuantity(symbol.Quote())
-	orderQuantity = freeQuantity * ct.tradeCfg.Volume
+	err = ct.paperOpen(symbol, position, freeQuantity*ct.tradeVolume(symbol))
+	return
+}
+
+// paperOpen simulates opening the position with the quote asset quantity at the prices of the order book
+func (ct *CryptoTrader) paperOpen(symbol types.Symbol, position types.PositionSide, orderQuantity float64) (err error) {
+	var symbolString string
+	var baseQuantity, averagePrice float64
+	var orderBook types.OrderBook
+
+	symbolString = symbol.String()
+	orderQuantity = ct.tradeCfg.PositionSize(orderQuantity)
 
 	if orderBook, err = ct.exchangeDriver.GetOrderBook(ct.ctx, symbol); err != nil {
-		logger.Errorf("BuyPercent Failed to retrieve order book for symbol: %s %v\n", symbol.String(), err)
+		logger.Errorf("paperOpen Failed to retrieve order book for symbol: %s %v\n", symbolString, err)
 		return
 	}
-	baseQuantity, averagePrice = orderBook.GetBuyVolumeAndAveragePrice(orderQuantity)
+	if position == types.Short {
+		baseQuantity, averagePrice = orderBook.GetSellVolumeAndAveragePrice(orderQuantity)
+	} else {
+		baseQuantity, averagePrice = orderBook.GetBuyVolumeAndAveragePrice(orderQuantity)
+	}
 	baseQuantity = normalizeQuantity(baseQuantity)
 
 	ct.openTrades[symbolString] = uuid.New().String()
+	ct.positionSides[symbolString] = position
 	ct.stopLossOrders[symbolString] = uuid.New().String()
-	logger.Infof("paperBuyPercent: Buy %s [Amount: %f; Average Price: %f; UUID: %s]", symbolString, baseQuantity, averagePrice, ct.openTrades[symbolString])
-	ct.notifier.Notify(ct.ctx, []byte(fmt.Sprintf("Signal %s Buy", symbolString)))
+	ct.entryPrices[symbolString] = averagePrice
+	ct.quantities[symbolString] = baseQuantity
+	ct.saveOpenTrade(symbol)
+	logger.Infof("paperOpen: %s %s %s [Amount: %f; Average Price: %f; UUID: %s]", position.OpenSide().String(), position.String(), symbolString, baseQuantity, averagePrice, ct.openTrades[symbolString])
+	ct.notify(types.NewOrderFilledEvent(symbol, position.OpenSide(), baseQuantity, averagePrice, true))
+
 	return
 }
 
-func (ct *CryptoTrader) tryCloseStopLoss(symbol types.Symbol) (closed bool) {
+// stopLossCancelAttempts max number of times the status of a cancelled stop loss is polled
+const stopLossCancelAttempts int = 50
+
+// stopLossCancelPollInterval interval between the polls of the status of a cancelled stop loss
+const stopLossCancelPollInterval time.Duration = (time.Millisecond * 100)
+
+// tryCloseStopLoss cancels the stop loss of the symbol and waits until the exchange confirms it is no longer active
+// stopInfo is the last known state of the stop loss, if its status is filled the position was closed by it
+// and closed is false.
+func (ct *CryptoTrader) tryCloseStopLoss(symbol types.Symbol) (closed bool, stopInfo types.OrderInfo) {
 	var err error
 	var stopLossID string
 	var stopLossUUID uuid.UUID
-	var orderInfo types.OrderInfo
+	var attempt int
 	var ok bool
 
 	if stopLossID, ok = ct.stopLossOrders[symbol.String()]; ok {
@@ -438,7 +981,7 @@ func (ct *CryptoTrader) tryCloseStopLoss(symbol types.Symbol) (closed bool) {
 			return
 		}
 
-		if orderInfo, err = ct.exchangeDriver.GetOrder(ct.ctx, types.Order{
+		if stopInfo, err = ct.exchangeDriver.GetOrder(ct.ctx, types.Order{
 			Symbol:        symbol,
 			UserReference: stopLossUUID,
 		}); err != nil {
@@ -446,11 +989,11 @@ func (ct *CryptoTrader) tryCloseStopLoss(symbol types.Symbol) (closed bool) {
 			return
 		}
 
-		if orderInfo.Status != types.StatusNew && orderInfo.Status != types.StatusPartiallyFilled {
+		if stopInfo.Status != types.StatusNew && stopInfo.Status != types.StatusPartiallyFilled {
 			return
 		}
 
-		if orderInfo, err = ct.exchangeDriver.CancelOrder(ct.ctx, types.Order{
+		if stopInfo, err = ct.exchangeDriver.CancelOrder(ct.ctx, types.Order{
 			Symbol:        symbol,
 			UserReference: stopLossUUID,
 		}, uuid.New()); err != nil {
@@ -458,10 +1001,21 @@ func (ct *CryptoTrader) tryCloseStopLoss(symbol types.Symbol) (closed bool) {
 			return
 		}
 
-		for orderInfo.Status != types.StatusCanceled {
-			time.Sleep(time.Millisecond * 100)
+		// The stop loss can still fill or expire between the cancel and the poll
+		for attempt = 0; !isFinalStatus(stopInfo.Status); attempt++ {
+			if attempt >= stopLossCancelAttempts {
+				logger.Warningf("tryCloseStopLoss: Cancellation of stop loss %s not confirmed, status: %d\n", stopLossID, stopInfo.Status)
+				return
+			}
+
+			select {
+			case <-ct.ctx.Done():
+				logger.Warningf("tryCloseStopLoss: Stopped waiting for the cancellation of stop loss %s\n", stopLossID)
+				return
+			case <-time.After(stopLossCancelPollInterval):
+			}
 
-			if orderInfo, err = ct.exchangeDriver.GetOrder(ct.ctx, types.Order{
+			if stopInfo, err = ct.exchangeDriver.GetOrder(ct.ctx, types.Order{
 				Symbol:        symbol,
 				UserReference: stopLossUUID,
 			}); err != nil {
@@ -470,7 +1024,7 @@ func (ct *CryptoTrader) tryCloseStopLoss(symbol types.Symbol) (closed bool) {
 			}
 		}
 
-		closed = true
+		closed = stopInfo.Status != types.StatusFilled
 		return
 	}
 
@@ -478,42 +1032,119 @@ func (ct *CryptoTrader) tryCloseStopLoss(symbol types.Symbol) (closed bool) {
 }
 
 func (ct *CryptoTrader) liveClosePosition(accountInfo types.AccountInfo, symbol types.Symbol) (err error) {
-	var symbolString, origTradeID string
+	var symbolString, origTradeID, stopLossID string
 	var origTradeUUID uuid.UUID
-	var orderInfo types.OrderInfo
-	var trades []types.Trade
-	var trade types.Trade
-	var baseQuantity float64
-	var ok bool
+	var position types.PositionSide
+	var order types.Order
+	var orderInfo, stopInfo types.OrderInfo
+	var baseQuantity, price float64
+	var closed, ok bool
 
 	symbolString = symbol.String()
 	if origTradeID, ok = ct.openTrades[symbol.String()]; !ok {
 		logger.Warningf("liveClosePosition: No trade to close for symbol: %s\n", symbol.String())
 		return
 	}
-	defer func() {
-		delete(ct.openTrades, symbol.String())
-	}()
+	position = ct.positionSides[symbolString]
 
 	if origTradeUUID, err = uuid.Parse(origTradeID); err != nil {
 		logger.Errorf("liveClosePosition: Invalid original trade uuid: %s %v\n", origTradeID, err)
 		return
 	}
 
-	if ct.tryCloseStopLoss(symbol) {
+	stopLossID = ct.stopLossOrders[symbolString]
+	if closed, stopInfo = ct.tryCloseStopLoss(symbol); closed {
 		logger.Infof("liveClosePosition: Closed stop loss order")
+	} else if stopInfo.Status == types.StatusFilled {
+		logger.Infof("liveClosePosition: Position for symbol %s closed by its stop loss\n", symbolString)
+		ct.notifyExit(types.NewStopLossEvent(symbol, position, stopInfo.ExecutedQuantity, stopInfo.AveragePrice()))
+		ct.forgetPosition(symbol)
+		return
 	}
 
+	// The position is only forgotten once it is closed on the exchange,
+	// on failure its stop loss is placed again
+	defer func() {
+		if err != nil {
+			ct.restoreStopLoss(symbol, stopLossID, closed, stopInfo)
+		}
+	}()
+
+	if ct.futuresDriver != nil {
+		baseQuantity, err = ct.futuresPositionQuantity(symbol, position)
+	} else {
+		baseQuantity, err = ct.spotPositionQuantity(symbol, origTradeUUID)
+	}
+	if err != nil {
+		logger.Errorf("liveClosePosition Error: %v\n", err)
+		return
+	}
+	if baseQuantity <= 0.0 {
+		logger.Warningf("liveClosePosition: No %s position open on the exchange for symbol: %s\n", position.String(), symbolString)
+		ct.notify(types.NewErrorEvent(symbol, fmt.Sprintf("No %s position open on the exchange, it was closed outside of cryptotrader", position.String())))
+		ct.forgetPosition(symbol)
+		return
+	}
+
+	order = types.NewMarketOrder(symbol, position.CloseSide(), baseQuantity)
+	order.ReduceOnly = true
+	if orderInfo, err = ct.exchangeDriver.PlaceOrder(ct.ctx, order, nil); err != nil {
+		logger.Warningf("liveClosePosition Unable to close position for symbol: %s %v\n", symbolString, err)
+		ct.notify(types.NewErrorEvent(symbol, fmt.Sprintf("Unable to close position: %v", err)))
+		return
+	}
+
+	logger.Infof("liveClosePosition: Symbol %s %s quantity: %f\n", symbolString, position.CloseSide().String(), baseQuantity)
+	if price = orderInfo.AveragePrice(); price <= 0.0 {
+		price, _ = ct.exchangeDriver.Ticker(ct.ctx, symbol)
+	}
+	ct.notify(types.NewOrderFilledEvent(symbol, position.CloseSide(), baseQuantity, price, false))
+	ct.notify(types.NewPositionClosedEvent(symbol, position, baseQuantity, ct.entryPrices[symbolString], price, false))
+	ct.forgetPosition(symbol)
+
+	return
+}
+
+// restoreStopLoss protects the position again after closing it failed
+// A stop loss which was not cancelled is kept, a cancelled one is placed again at the same prices.
+func (ct *CryptoTrader) restoreStopLoss(symbol types.Symbol, stopLossID string, closed bool, stopInfo types.OrderInfo) {
+	var err error
+	var symbolString string
+	var quantity float64
+
+	symbolString = symbol.String()
+	defer ct.saveOpenTrade(symbol)
+
+	if !closed {
+		if stopLossID != "" {
+			ct.stopLossOrders[symbolString] = stopLossID
+		}
+		return
+	}
+
+	quantity = normalizeQuantity(stopInfo.OriginalQuantity - stopInfo.ExecutedQuantity)
+	if err = ct.placeProtectiveOrders(symbol, ct.positionSides[symbolString], quantity, stopInfo.StopPrice, stopInfo.Price, ct.takeProfitPrices[symbolString]); err != nil {
+		logger.Errorf("restoreStopLoss: Failed to restore stop loss for symbol %s, the position is unprotected %v\n", symbolString, err)
+		ct.notify(types.NewErrorEvent(symbol, "Stop loss lost, the position is unprotected"))
+		return
+	}
+	logger.Infof("restoreStopLoss: Placed stop loss for symbol %s again at %f [UUID: %s]\n", symbolString, stopInfo.StopPrice, ct.stopLossOrders[symbolString])
+}
+
+// spotPositionQuantity returns the base asset quantity bought by the order with the user reference, minus the commission payed in base asset
+func (ct *CryptoTrader) spotPositionQuantity(symbol types.Symbol, userReference uuid.UUID) (baseQuantity float64, err error) {
+	var orderInfo types.OrderInfo
+	var trades []types.Trade
+	var trade types.Trade
+
 	if orderInfo, err = ct.exchangeDriver.GetOrder(ct.ctx, types.Order{
 		Symbol:        symbol,
-		UserReference: origTradeUUID,
+		UserReference: userReference,
 	}); err != nil {
-		logger.Errorf("liveClosePosition Error: %v\n", err)
 		return
 	}
 
 	if trades, err = ct.exchangeDriver.GetOrderTrades(ct.ctx, orderInfo); err != nil {
-		logger.Errorf("liveClosePosition Error: %v\n", err)
 		return
 	}
 
@@ -524,20 +1155,27 @@ func (ct *CryptoTrader) liveClosePosition(accountInfo types.AccountInfo, symbol
 		}
 	}
 	baseQuantity = normalizeQuantity(baseQuantity)
+	return
+}
 
-	if _, err = ct.exchangeDriver.PlaceOrder(ct.ctx, types.NewMarketOrder(symbol, types.Sell, baseQuantity), nil); err != nil {
-		logger.Warningf("liveClosePosition Unable to close position for symbol: %s %v\n", symbolString, err)
-	}
-
-	logger.Infof("liveClosePosition: Symbol %s sell quantity: %f\n", symbolString, baseQuantity)
-	ct.notifier.Notify(ct.ctx, []byte(fmt.Sprintf("Signal %s Sell", symbol.String())))
+// futuresPositionQuantity returns the quantity of the position on the futures exchange, 0 if no position of the side is open
+func (ct *CryptoTrader) futuresPositionQuantity(symbol types.Symbol, position types.PositionSide) (baseQuantity float64, err error) {
+	var futuresPosition types.Position
 
+	if futuresPosition, err = ct.futuresDriver.GetPosition(ct.ctx, symbol); err != nil {
+		return
+	}
+	if futuresPosition.Side != position {
+		return
+	}
+	baseQuantity = futuresPosition.Quantity
 	return
 }
 
 func (ct *CryptoTrader) paperClosePosition(accountInfo types.AccountInfo, symbol types.Symbol) (err error) {
 	var symbolString string
 	var price float64
+	var position types.PositionSide
 	var ok bool
 
 	symbolString = symbol.String()
@@ -551,10 +1189,10 @@ func (ct *CryptoTrader) paperClosePosition(accountInfo types.AccountInfo, symbol
 		return
 	}
 
-	logger.Printf("PaperTrade: Sell %s [Market Price: %f; UUID: %s]", symbolString, price, ct.openTrades[symbolString])
-	delete(ct.openTrades, symbolString)
-	delete(ct.stopLossOrders, symbolString)
-	ct.notifier.Notify(ct.ctx, []byte(fmt.Sprintf("Signal %s Sell", symbolString)))
+	position = ct.positionSides[symbolString]
+	logger.Printf("PaperTrade: %s %s %s [Market Price: %f; UUID: %s]", position.CloseSide().String(), position.String(), symbolString, price, ct.openTrades[symbolString])
+	ct.notify(types.NewPositionClosedEvent(symbol, position, ct.quantities[symbolString], ct.entryPrices[symbolString], price, true))
+	ct.forgetPosition(symbol)
 
 	return
 }
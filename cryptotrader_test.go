@@ -0,0 +1,363 @@
+package cryptotrader
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/interfaces"
+	_ "github.com/mhereman/cryptotrader/statestores/memory"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+var testSymbol = types.NewSymbol("BTC", "USDT")
+
+// reconcileDriver is an exchange stand-in knowing a fixed set of orders
+// Every order request fails with err if it is set.
+type reconcileDriver struct {
+	interfaces.IExchangeDriver
+	orders map[uuid.UUID]types.OrderInfo
+	err    error
+}
+
+func (d *reconcileDriver) GetOrder(ctx context.Context, order types.Order) (info types.OrderInfo, err error) {
+	var ok bool
+
+	if d.err != nil {
+		err = d.err
+		return
+	}
+	if info, ok = d.orders[order.UserReference]; !ok {
+		err = exchange.NewOrderNotFoundError(order.UserReference.String())
+	}
+	return
+}
+
+func (d *reconcileDriver) OpenOrders(ctx context.Context, symbol types.Symbol) (orders []types.OrderInfo, err error) {
+	var info types.OrderInfo
+
+	if d.err != nil {
+		err = d.err
+		return
+	}
+	for _, info = range d.orders {
+		if info.Status == types.StatusNew || info.Status == types.StatusPartiallyFilled {
+			orders = append(orders, info)
+		}
+	}
+	return
+}
+
+// newTestTrader creates a live trader of the test symbol with an in memory state store
+func newTestTrader(t *testing.T, driver interfaces.IExchangeDriver) (ct *CryptoTrader) {
+	return newTestTraderConfig(t, driver, TradeConfig{})
+}
+
+// newTestTraderConfig creates a trader of the test symbol trading with the trade config and an in memory state store
+func newTestTraderConfig(t *testing.T, driver interfaces.IExchangeDriver, tradeCfg TradeConfig) (ct *CryptoTrader) {
+	var err error
+
+	ct = New([]MarketConfig{
+		NewMarketConfig(AssetConfig{Symbol: testSymbol, Timeframe: types.NewTimeframe(1, types.TuMin)}, AlgorithmConfig{}, tradeCfg.Volume),
+	}, ExchangeConfig{}, tradeCfg, nil, StateStoreConfig{})
+	ct.exchangeDriver = driver
+	if err = ct.initStateStore(); err != nil {
+		t.Fatalf("initStateStore: %v", err)
+	}
+	return
+}
+
+func TestRestoreOpenTradesKeepsTradeOnError(t *testing.T) {
+	var tests = []struct {
+		name string
+		err  error
+	}{
+		{"server error", &exchange.HTTPError{StatusCode: http.StatusServiceUnavailable, Err: fmt.Errorf("service unavailable")}},
+		{"rate limited", &exchange.HTTPError{StatusCode: http.StatusTooManyRequests, Err: fmt.Errorf("too many requests")}},
+		{"circuit breaker open", fmt.Errorf("GetOrder: Circuit breaker open")},
+	}
+	var driver *reconcileDriver
+	var ct *CryptoTrader
+	var trade types.OpenTrade
+	var trades []types.OpenTrade
+	var index int
+	var err error
+
+	for index = range tests {
+		driver = &reconcileDriver{err: tests[index].err}
+		ct = newTestTrader(t, driver)
+		trade = types.NewOpenTrade(testSymbol, uuid.New(), uuid.New(), false, testOpenTime, 100.0)
+		if err = ct.stateStore.Save(ct.ctx, trade); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		if err = ct.restoreOpenTrades(); err == nil {
+			t.Errorf("%s: expected restoreOpenTrades to fail", tests[index].name)
+		}
+		if trades, err = ct.stateStore.Load(ct.ctx); err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if len(trades) != 1 || trades[0] != trade {
+			t.Errorf("%s: persisted trades %+v, want the trade kept unchanged", tests[index].name, trades)
+		}
+
+		// Once the exchange answers again the trade is restored
+		driver.err = nil
+		driver.orders = map[uuid.UUID]types.OrderInfo{
+			trade.TradeReference:    {UserReference: trade.TradeReference, ExecutedQuantity: 1.0, Status: types.StatusFilled},
+			trade.StopLossReference: {UserReference: trade.StopLossReference, Status: types.StatusNew},
+		}
+		if err = ct.restoreOpenTrades(); err != nil {
+			t.Fatalf("%s: restoreOpenTrades: %v", tests[index].name, err)
+		}
+		if ct.openTrades[testSymbol.String()] != trade.TradeReference.String() || ct.stopLossOrders[testSymbol.String()] != trade.StopLossReference.String() {
+			t.Errorf("%s: trade not restored with its stop loss", tests[index].name)
+		}
+		ct.cancelFn()
+	}
+}
+
+func TestRestoreOpenTradesReconciles(t *testing.T) {
+	var tests = []struct {
+		name         string
+		entry        *types.OrderInfo
+		stopLoss     *types.OrderInfo
+		keep         bool
+		keepStopLoss bool
+	}{
+		{"unknown entry order", nil, nil, false, false},
+		{"unfilled entry order", &types.OrderInfo{Status: types.StatusCanceled}, nil, false, false},
+		{"stop loss active", &types.OrderInfo{ExecutedQuantity: 1.0, Status: types.StatusFilled}, &types.OrderInfo{Status: types.StatusNew}, true, true},
+		{"stop loss filled", &types.OrderInfo{ExecutedQuantity: 1.0, Status: types.StatusFilled}, &types.OrderInfo{Status: types.StatusFilled}, false, false},
+		{"stop loss canceled", &types.OrderInfo{ExecutedQuantity: 1.0, Status: types.StatusFilled}, &types.OrderInfo{Status: types.StatusCanceled}, true, false},
+		{"unknown stop loss", &types.OrderInfo{ExecutedQuantity: 1.0, Status: types.StatusFilled}, nil, true, false},
+	}
+	var driver *reconcileDriver
+	var ct *CryptoTrader
+	var trade types.OpenTrade
+	var trades []types.OpenTrade
+	var index int
+	var ok bool
+	var err error
+
+	for index = range tests {
+		trade = types.NewOpenTrade(testSymbol, uuid.New(), uuid.New(), false, testOpenTime, 100.0)
+		driver = &reconcileDriver{orders: make(map[uuid.UUID]types.OrderInfo)}
+		if tests[index].entry != nil {
+			driver.orders[trade.TradeReference] = *tests[index].entry
+		}
+		if tests[index].stopLoss != nil {
+			tests[index].stopLoss.UserReference = trade.StopLossReference
+			driver.orders[trade.StopLossReference] = *tests[index].stopLoss
+		}
+		ct = newTestTrader(t, driver)
+		if err = ct.stateStore.Save(ct.ctx, trade); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		if err = ct.restoreOpenTrades(); err != nil {
+			t.Errorf("%s: restoreOpenTrades: %v", tests[index].name, err)
+			continue
+		}
+		if trades, err = ct.stateStore.Load(ct.ctx); err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if _, ok = ct.openTrades[testSymbol.String()]; ok != tests[index].keep || len(trades) == 1 != tests[index].keep {
+			t.Errorf("%s: got restored %v persisted %d, want kept %v", tests[index].name, ok, len(trades), tests[index].keep)
+		}
+		if _, ok = ct.stopLossOrders[testSymbol.String()]; ok != tests[index].keepStopLoss {
+			t.Errorf("%s: got stop loss %v, want %v", tests[index].name, ok, tests[index].keepStopLoss)
+		}
+		ct.cancelFn()
+	}
+}
+
+// futuresDriver is a futures exchange stand-in holding a single position of the test symbol
+// Market orders are filled at the price of the paper driver, the other orders stay open.
+type futuresDriver struct {
+	paperDriver
+	amount float64
+	placed []types.Order
+}
+
+func newFuturesDriver(price float64) *futuresDriver {
+	return &futuresDriver{paperDriver: *newPaperDriver(price)}
+}
+
+func (d *futuresDriver) GetAccountInfo(ctx context.Context) (accountInfo types.AccountInfo, err error) {
+	accountInfo = types.NewAccountInfo(0.0002, 0.0004, 0.0, 0.0, []types.AccountBalance{types.NewAccountBalance("USDT", 10000.0, 0.0)})
+	return
+}
+
+func (d *futuresDriver) GetSymbolInfo(ctx context.Context, symbol types.Symbol) (symbolInfo types.SymbolInfo, err error) {
+	symbolInfo = types.NewSymbolInfo(symbol, "0.01", "0.001")
+	return
+}
+
+func (d *futuresDriver) PlaceOrder(ctx context.Context, order types.Order, symbolInfo *types.SymbolInfo) (info types.OrderInfo, err error) {
+	d.placed = append(d.placed, order)
+	info = types.OrderInfo{
+		Symbol:           order.Symbol,
+		UserReference:    order.UserReference,
+		Side:             order.Side,
+		OrderType:        order.Type,
+		OriginalQuantity: order.Quantity,
+		Price:            order.Price,
+		StopPrice:        order.StopPrice,
+		Status:           types.StatusNew,
+	}
+	if order.Type == types.Market {
+		info.Price = d.price
+		info.ExecutedQuantity = order.Quantity
+		info.Status = types.StatusFilled
+		if order.Side == types.Buy {
+			d.amount += order.Quantity
+		} else {
+			d.amount -= order.Quantity
+		}
+	}
+	d.orders[order.UserReference] = info
+	return
+}
+
+func (d *futuresDriver) CancelOrder(ctx context.Context, order types.Order, userReference uuid.UUID) (info types.OrderInfo, err error) {
+	var ok bool
+
+	if info, ok = d.orders[order.UserReference]; !ok {
+		err = exchange.NewOrderNotFoundError(order.UserReference.String())
+		return
+	}
+	info.Status = types.StatusCanceled
+	d.orders[order.UserReference] = info
+	return
+}
+
+func (d *futuresDriver) SetLeverage(ctx context.Context, symbol types.Symbol, leverage int) error {
+	return nil
+}
+
+func (d *futuresDriver) SetMarginType(ctx context.Context, symbol types.Symbol, marginType types.MarginType) error {
+	return nil
+}
+
+func (d *futuresDriver) GetPosition(ctx context.Context, symbol types.Symbol) (position types.Position, err error) {
+	position.Symbol = symbol
+	position.Quantity = math.Abs(d.amount)
+	if d.amount < 0.0 {
+		position.Side = types.Short
+	}
+	return
+}
+
+// closedEvent returns the last position closed event of the notification queue
+func closedEvent(ct *CryptoTrader) (event types.Event, ok bool) {
+	var next types.Event
+
+	for {
+		select {
+		case next = <-ct.notifications.events:
+			if next.Type == types.EventPositionClosed {
+				event, ok = next, true
+			}
+		default:
+			return
+		}
+	}
+}
+
+func TestShortRoundTrip(t *testing.T) {
+	var tests = []struct {
+		name          string
+		paper         bool
+		closedOutside bool
+		// expected orders, without the orders of the paper trades
+		orders int
+		pnl    float64
+	}{
+		{"paper short", true, false, 0, 20.0},
+		{"live short", false, false, 3, 20.0},
+		{"live short closed outside of cryptotrader", false, true, 2, 0.0},
+	}
+	var driver *futuresDriver
+	var ct *CryptoTrader
+	var stopLoss, closing types.Order
+	var event types.Event
+	var position types.Position
+	var symbolString = testSymbol.String()
+	var index int
+	var ok bool
+	var err error
+
+	for index = range tests {
+		driver = newFuturesDriver(100.0)
+		ct = newTestTraderConfig(t, driver, TradeConfig{Paper: tests[index].paper, TradeVolumeType: TVTFixed, Volume: 100.0, Shorts: true, Leverage: 2, StopLoss: 0.1})
+		ct.futuresDriver, _ = exchange.FuturesDriver(driver)
+
+		// A margin of 100 at a leverage of 2 sells 2 BTC at 100
+		if err = ct.executeSignal(types.NewPositionSignal("test", testSymbol, types.Sell, types.Short)); err != nil {
+			t.Errorf("%s: open short: %v", tests[index].name, err)
+		}
+		if ct.positionSides[symbolString] != types.Short || ct.quantities[symbolString] != 2.0 || ct.entryPrices[symbolString] != 100.0 {
+			t.Errorf("%s: got %s %f at %f, want a short of 2 at 100", tests[index].name, ct.positionSides[symbolString].String(), ct.quantities[symbolString], ct.entryPrices[symbolString])
+		}
+		if !tests[index].paper {
+			if position, _ = driver.GetPosition(ct.ctx, testSymbol); position.Side != types.Short || position.Quantity != 2.0 {
+				t.Errorf("%s: got exchange position %s %f, want a short of 2", tests[index].name, position.Side.String(), position.Quantity)
+			}
+			if len(driver.placed) != 2 {
+				t.Fatalf("%s: got %d orders, want the entry and its stop loss", tests[index].name, len(driver.placed))
+			}
+			stopLoss = driver.placed[1]
+			if stopLoss.Side != types.Buy || stopLoss.Type != types.StopLossLimit || !stopLoss.ReduceOnly || !almostEqual(stopLoss.StopPrice, 110.0) || stopLoss.Quantity != 2.0 {
+				t.Errorf("%s: got stop loss %+v, want a reduce only buy of 2 at 110", tests[index].name, stopLoss)
+			}
+		}
+
+		// A long exit signal does not close the short
+		if err = ct.executeSignal(types.NewPositionSignal("test", testSymbol, types.Sell, types.Long)); err != nil {
+			t.Errorf("%s: close long: %v", tests[index].name, err)
+		}
+		if _, ok = ct.openTrades[symbolString]; !ok {
+			t.Errorf("%s: short closed by a long exit signal", tests[index].name)
+		}
+
+		if tests[index].closedOutside {
+			driver.amount = 0.0
+		}
+		driver.price = 90.0
+		if err = ct.executeSignal(types.NewPositionSignal("test", testSymbol, types.Buy, types.Short)); err != nil {
+			t.Errorf("%s: close short: %v", tests[index].name, err)
+		}
+		if _, ok = ct.openTrades[symbolString]; ok {
+			t.Errorf("%s: short still open", tests[index].name)
+		}
+		if len(driver.placed) != tests[index].orders {
+			t.Errorf("%s: got %d orders, want %d", tests[index].name, len(driver.placed), tests[index].orders)
+		}
+		if !tests[index].paper {
+			if driver.orders[stopLoss.UserReference].Status != types.StatusCanceled {
+				t.Errorf("%s: stop loss not cancelled", tests[index].name)
+			}
+			if driver.amount != 0.0 {
+				t.Errorf("%s: got exchange position %f, want it closed", tests[index].name, driver.amount)
+			}
+		}
+		if !tests[index].closedOutside {
+			if !tests[index].paper {
+				closing = driver.placed[2]
+				if closing.Side != types.Buy || closing.Type != types.Market || !closing.ReduceOnly || closing.Quantity != 2.0 {
+					t.Errorf("%s: got closing order %+v, want a reduce only market buy of 2", tests[index].name, closing)
+				}
+			}
+			if event, ok = closedEvent(ct); !ok || event.Position != types.Short || !almostEqual(event.PnL, tests[index].pnl) {
+				t.Errorf("%s: got closed event %v with PnL %f, want %f", tests[index].name, ok, event.PnL, tests[index].pnl)
+			}
+		}
+		ct.cancelFn()
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/history"
 	"github.com/mhereman/cryptotrader/interfaces"
 	"github.com/mhereman/cryptotrader/logger"
 	"github.com/mhereman/cryptotrader/types"
@@ -14,19 +16,27 @@ import (
 const (
 	checkInterval time.Duration = (time.Millisecond * 1000)
 	sleepInterval time.Duration = (time.Millisecond * 500)
+
+	// seriesLength number of candles pushed when the series is read from the candle store
+	seriesLength = 500
 )
 
 type DataFetcher struct {
 	driver        interfaces.IExchangeDriver
+	store         *history.CandleStore
 	channels      map[string]map[string]types.SeriesChannel
 	refreshTime   map[string]map[string]time.Time
 	lastCheckTime time.Time
 	mux           sync.RWMutex
 }
 
-func NewDataFetcher(driver interfaces.IExchangeDriver) (dc *DataFetcher) {
+// NewDataFetcher creates a new DataFetcher instance
+// If a candle store is provided and the exchange driver supports downloading time ranges,
+// the series are read from the store and only the new candles are fetched.
+func NewDataFetcher(driver interfaces.IExchangeDriver, store *history.CandleStore) (dc *DataFetcher) {
 	dc = &DataFetcher{
 		driver:        driver,
+		store:         store,
 		channels:      make(map[string]map[string]types.SeriesChannel),
 		refreshTime:   make(map[string]map[string]time.Time),
 		lastCheckTime: time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC),
@@ -35,7 +45,17 @@ func NewDataFetcher(driver interfaces.IExchangeDriver) (dc *DataFetcher) {
 	return
 }
 
+// RunAsync runs the DataFetcher in a goroutine
+// If the exchange driver supports streaming, the registered series are streamed,
+// otherwise or when the stream fails they are polled.
 func (dc *DataFetcher) RunAsync(ctx context.Context, waitGroup *sync.WaitGroup) {
+	var streamer interfaces.IStreamingExchangeDriver
+	var ok bool
+
+	if streamer, ok = exchange.StreamingDriver(dc.driver); ok {
+		dc.startStreams(ctx, waitGroup, streamer)
+	}
+
 	waitGroup.Add(1)
 	go fetchRoutine(ctx, waitGroup, dc)
 }
@@ -139,8 +159,20 @@ func (dc *DataFetcher) fetchExpiredData(ctx context.Context) {
 
 func (dc *DataFetcher) fetchData(ctx context.Context, symbol types.Symbol, timeFrame types.Timeframe) (series types.Series, nextRefreshTime time.Time, err error) {
 	var lastCandle types.OHLC
+	var historical interfaces.IHistoricalExchangeDriver
+	var ok bool
 
-	if series, err = dc.driver.GetSeries(ctx, symbol, timeFrame); err != nil {
+	if historical, ok = exchange.HistoricalDriver(dc.driver); ok && dc.store != nil {
+		series, err = dc.fetchStoredData(ctx, historical, symbol, timeFrame)
+	} else {
+		series, err = dc.driver.GetSeries(ctx, symbol, timeFrame)
+	}
+	if err != nil {
+		logger.Errorf("DataCacher::fetchData Error %v\n", err)
+		return
+	}
+	if series.Length() == 0 {
+		err = fmt.Errorf("No candles available for series %s[%s]", symbol.String(), timeFrame.String())
 		logger.Errorf("DataCacher::fetchData Error %v\n", err)
 		return
 	}
@@ -150,6 +182,24 @@ func (dc *DataFetcher) fetchData(ctx context.Context, symbol types.Symbol, timeF
 	return
 }
 
+// fetchStoredData tops up the candle store and returns the last candles of the series
+func (dc *DataFetcher) fetchStoredData(ctx context.Context, historical interfaces.IHistoricalExchangeDriver, symbol types.Symbol, timeFrame types.Timeframe) (series types.Series, err error) {
+	var serverTime, start time.Time
+
+	if serverTime, err = dc.driver.GetServerTime(ctx); err != nil {
+		return
+	}
+	start = serverTime.Add(-timeFrame.Duration() * seriesLength)
+
+	if series, err = dc.store.Update(ctx, dc.driver.Name(), historical, symbol, timeFrame, start, time.Time{}); err != nil {
+		return
+	}
+	if series.Length() > seriesLength {
+		series = series.SubSeries(series.Length()-seriesLength, seriesLength)
+	}
+	return
+}
+
 func fetchRoutine(ctx context.Context, wg *sync.WaitGroup, dc *DataFetcher) {
 	defer wg.Done()
 
@@ -166,3 +216,142 @@ func fetchRoutine(ctx context.Context, wg *sync.WaitGroup, dc *DataFetcher) {
 		}
 	}
 }
+
+// startStreams starts streaming the registered series, the streamed series are no longer polled
+func (dc *DataFetcher) startStreams(ctx context.Context, waitGroup *sync.WaitGroup, streamer interfaces.IStreamingExchangeDriver) {
+	dc.mux.Lock()
+	defer dc.mux.Unlock()
+
+	var symbolString, timeframeString string
+	var subMap map[string]types.SeriesChannel
+	var symbol types.Symbol
+	var timeFrame types.Timeframe
+	var seriesChannel types.SeriesChannel
+	var updates types.CandleUpdateChannel
+	var err error
+
+	for symbolString, subMap = range dc.channels {
+		if symbol, err = types.NewSymbolFromString(symbolString); err != nil {
+			logger.Errorf("DataCacher::startStreams Error %v\n", err)
+			continue
+		}
+
+		for timeframeString, seriesChannel = range subMap {
+			if timeFrame, err = types.NewTimeframeFromString(timeframeString); err != nil {
+				logger.Errorf("DataCacher::startStreams Error %v\n", err)
+				continue
+			}
+
+			if updates, err = streamer.StreamCandles(ctx, symbol, timeFrame); err != nil {
+				logger.Warningf("DataCacher::startStreams Streaming %s[%s] failed, falling back to polling: %v\n", symbolString, timeframeString, err)
+				continue
+			}
+
+			delete(dc.refreshTime[symbolString], timeframeString)
+			logger.Infof("DataCacher streaming asset %s[%s]\n", symbolString, timeframeString)
+
+			waitGroup.Add(1)
+			go streamRoutine(ctx, waitGroup, dc, symbol, timeFrame, seriesChannel, updates)
+		}
+	}
+}
+
+// pollSeries (re)starts polling the series, used when its stream ends
+func (dc *DataFetcher) pollSeries(symbol types.Symbol, timeFrame types.Timeframe) {
+	dc.mux.Lock()
+	defer dc.mux.Unlock()
+
+	dc.refreshTime[symbol.String()][timeFrame.String()] = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// applyCandleUpdate applies a streamed candle update to the series
+// The series must be pushed if a candle closed, the series must be fetched again if candles were missed.
+func applyCandleUpdate(series types.Series, update types.CandleUpdate) (out types.Series, push bool, resync bool) {
+	var last, next types.OHLC
+	var lastIndex int
+
+	out = series
+	lastIndex = len(out.Candles) - 1
+	last = out.Candles[lastIndex]
+
+	switch {
+	case update.Candle.OpenTime.Equal(last.OpenTime):
+		out.Candles[lastIndex] = update.Candle
+	case update.Candle.OpenTime.After(last.OpenTime):
+		// The close of the last candle was missed
+		if !update.Candle.OpenTime.Equal(out.Timeframe.NextOpen(last.OpenTime, last.CloseTime)) {
+			resync = true
+			return
+		}
+		out.Candles = append(out.Candles[1:], update.Candle)
+		push = true
+	default:
+		return
+	}
+
+	if update.Final {
+		// The algorithms consider the last candle of the series as the active candle,
+		// the next candle is started at the close price of the closed candle
+		last = update.Candle
+		next = types.NewOHLC(last.Close, last.Close, last.Close, last.Close, 0.0, out.Timeframe.NextOpen(last.OpenTime, last.CloseTime), time.Time{})
+		next.CloseTime = out.Timeframe.NextOpen(next.OpenTime, last.CloseTime).Add(-time.Millisecond)
+		out.Candles = append(out.Candles[1:], next)
+		push = true
+	}
+	return
+}
+
+func streamRoutine(ctx context.Context, wg *sync.WaitGroup, dc *DataFetcher, symbol types.Symbol, timeFrame types.Timeframe, seriesChannel types.SeriesChannel, updates types.CandleUpdateChannel) {
+	defer wg.Done()
+
+	var series types.Series
+	var candles []types.OHLC
+	var update types.CandleUpdate
+	var push, resync, ok bool
+	var err error
+
+	if series, _, err = dc.fetchData(ctx, symbol, timeFrame); err != nil {
+		logger.Errorf("DataCacher::streamRoutine Error %v\n", err)
+		dc.pollSeries(symbol, timeFrame)
+		return
+	}
+	push = true
+
+	for {
+		if push {
+			logger.Debugf("Pushing new data")
+			// The candles are updated in place, the algorithm receives a copy
+			candles = make([]types.OHLC, len(series.Candles))
+			copy(candles, series.Candles)
+			select {
+			case <-ctx.Done():
+				return
+			case seriesChannel <- types.NewSeries(series.Symbol, series.Timeframe, candles):
+			}
+			push = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok = <-updates:
+			if !ok {
+				if ctx.Err() == nil {
+					logger.Warningf("DataCacher::streamRoutine Stream %s[%s] ended, falling back to polling\n", symbol.String(), timeFrame.String())
+					dc.pollSeries(symbol, timeFrame)
+				}
+				return
+			}
+
+			if series, push, resync = applyCandleUpdate(series, update); resync {
+				logger.Debugf("Resynchronizing series %s[%s]\n", symbol.String(), timeFrame.String())
+				if series, _, err = dc.fetchData(ctx, symbol, timeFrame); err != nil {
+					logger.Errorf("DataCacher::streamRoutine Error %v\n", err)
+					dc.pollSeries(symbol, timeFrame)
+					return
+				}
+				push = true
+			}
+		}
+	}
+}
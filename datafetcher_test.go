@@ -0,0 +1,111 @@
+package cryptotrader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+var testOpenTime = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// testCandle returns the candle of the 1m timeframe opening the index minutes after the test open time
+func testCandle(index int, closePrice float64) types.OHLC {
+	var openTime = testOpenTime.Add(time.Minute * time.Duration(index))
+
+	return types.NewOHLC(closePrice, closePrice, closePrice, closePrice, 1.0, openTime, openTime.Add(time.Minute-time.Millisecond))
+}
+
+func testSeries() types.Series {
+	return types.NewSeries(types.NewSymbol("BTC", "USDT"), types.NewTimeframe(1, types.TuMin), []types.OHLC{
+		testCandle(0, 10.0),
+		testCandle(1, 11.0),
+		testCandle(2, 12.0),
+	})
+}
+
+func TestApplyCandleUpdate(t *testing.T) {
+	var tests = []struct {
+		name       string
+		update     types.CandleUpdate
+		push       bool
+		resync     bool
+		wantOpens  []int
+		wantCloses []float64
+	}{
+		{
+			name:       "update of the active candle",
+			update:     types.NewCandleUpdate(testCandle(2, 12.5), false),
+			wantOpens:  []int{0, 1, 2},
+			wantCloses: []float64{10.0, 11.0, 12.5},
+		},
+		{
+			name:       "close of the active candle starts the next candle",
+			update:     types.NewCandleUpdate(testCandle(2, 12.5), true),
+			push:       true,
+			wantOpens:  []int{1, 2, 3},
+			wantCloses: []float64{11.0, 12.5, 12.5},
+		},
+		{
+			name:       "first update of the next candle",
+			update:     types.NewCandleUpdate(testCandle(3, 13.0), false),
+			push:       true,
+			wantOpens:  []int{1, 2, 3},
+			wantCloses: []float64{11.0, 12.0, 13.0},
+		},
+		{
+			name:   "missed candles",
+			update: types.NewCandleUpdate(testCandle(5, 15.0), false),
+			resync: true,
+		},
+		{
+			name:       "stale update",
+			update:     types.NewCandleUpdate(testCandle(1, 99.0), true),
+			wantOpens:  []int{0, 1, 2},
+			wantCloses: []float64{10.0, 11.0, 12.0},
+		},
+	}
+	var index, candleIndex int
+	var out types.Series
+	var push, resync bool
+
+	for index = range tests {
+		out, push, resync = applyCandleUpdate(testSeries(), tests[index].update)
+
+		if push != tests[index].push || resync != tests[index].resync {
+			t.Errorf("%s: got push %v resync %v, want push %v resync %v", tests[index].name, push, resync, tests[index].push, tests[index].resync)
+			continue
+		}
+		if resync {
+			continue
+		}
+
+		if len(out.Candles) != len(tests[index].wantOpens) {
+			t.Errorf("%s: got %d candles, want %d", tests[index].name, len(out.Candles), len(tests[index].wantOpens))
+			continue
+		}
+		for candleIndex = range out.Candles {
+			if !out.Candles[candleIndex].OpenTime.Equal(testCandle(tests[index].wantOpens[candleIndex], 0.0).OpenTime) {
+				t.Errorf("%s: candle %d opens at %v, want minute %d", tests[index].name, candleIndex, out.Candles[candleIndex].OpenTime, tests[index].wantOpens[candleIndex])
+			}
+			if out.Candles[candleIndex].Close != tests[index].wantCloses[candleIndex] {
+				t.Errorf("%s: candle %d closes at %f, want %f", tests[index].name, candleIndex, out.Candles[candleIndex].Close, tests[index].wantCloses[candleIndex])
+			}
+		}
+	}
+}
+
+func TestApplyCandleUpdateNextCandle(t *testing.T) {
+	var out types.Series
+	var next types.OHLC
+
+	out, _, _ = applyCandleUpdate(testSeries(), types.NewCandleUpdate(testCandle(2, 12.5), true))
+	next = out.Candles[len(out.Candles)-1]
+
+	if next.Open != 12.5 || next.High != 12.5 || next.Low != 12.5 || next.Volume != 0.0 {
+		t.Errorf("next candle: got %s volume %f, want all prices at the close 12.5 and no volume", next.String(), next.Volume)
+	}
+	if !next.CloseTime.Equal(testCandle(3, 0.0).CloseTime) {
+		t.Errorf("next candle close time: got %v, want %v", next.CloseTime, testCandle(3, 0.0).CloseTime)
+	}
+}
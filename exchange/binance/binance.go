@@ -3,6 +3,9 @@ package binance
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/mhereman/cryptotrader/exchange"
 	"github.com/mhereman/cryptotrader/interfaces"
@@ -11,7 +14,15 @@ import (
 	bin "github.com/adshao/go-binance"
 )
 
-const exchangeName = "binance"
+const (
+	exchangeName     = "binance"
+	defaultStreamURL = "wss://stream.binance.com:9443/ws"
+	testnetBaseURL   = "https://testnet.binance.vision"
+	testnetStreamURL = "wss://testnet.binance.vision/ws"
+
+	// codeUnknownOrder is the error code of the requests for an order the exchange does not know
+	codeUnknownOrder = -2013
+)
 
 func init() {
 	exchange.RegisterExchange(exchangeName, createBinance)
@@ -21,12 +32,15 @@ func init() {
 type Binance struct {
 	client     *bin.Client
 	allSymbols map[string][]string
+	streamURL  string
 }
 
 // New creates a new Binance Exchange plugin
+// The 'testnet' argument targets the Spot testnet, the 'baseURL' and 'streamURL' arguments replace
+// the REST and websocket endpoints, e.g. to run against a local test server.
 func New(ctx context.Context, config map[string]string) (driver *Binance, err error) {
-	var apiKey, apiSecret string
-	var ok bool
+	var apiKey, apiSecret, baseURL, streamURL, value string
+	var testnet, ok bool
 
 	if apiKey, ok = config["apiKey"]; !ok {
 		err = fmt.Errorf("Binance config error: 'apiKey' entry not found")
@@ -38,9 +52,29 @@ func New(ctx context.Context, config map[string]string) (driver *Binance, err er
 		return
 	}
 
+	if value, ok = config["testnet"]; ok && value != "" {
+		if testnet, err = strconv.ParseBool(value); err != nil {
+			err = fmt.Errorf("Binance config error: invalid 'testnet' value %s", value)
+			return
+		}
+	}
+
 	driver = new(Binance)
 	driver.client = bin.NewClient(apiKey, apiSecret)
+	driver.client.HTTPClient = &http.Client{Transport: exchange.NewHTTPErrorTransport(http.DefaultTransport)}
 	driver.allSymbols = make(map[string][]string)
+	driver.streamURL = defaultStreamURL
+	if testnet {
+		driver.client.BaseURL = testnetBaseURL
+		driver.streamURL = testnetStreamURL
+		logger.Infof("Binance: Using the Spot testnet\n")
+	}
+	if baseURL, ok = config["baseURL"]; ok && baseURL != "" {
+		driver.client.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+	if streamURL, ok = config["streamURL"]; ok && streamURL != "" {
+		driver.streamURL = strings.TrimSuffix(streamURL, "/")
+	}
 
 	if err = driver.getAllSymbols(ctx); err != nil {
 		logger.Errorf("Binance::New Error: %v\n", err)
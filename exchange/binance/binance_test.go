@@ -0,0 +1,130 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const testExchangeInfo = `{"timezone":"UTC","serverTime":1600000000000,"symbols":[{"symbol":"BTCUSDT","status":"TRADING","baseAsset":"BTC","quoteAsset":"USDT"},{"symbol":"ETHBTC","status":"TRADING","baseAsset":"ETH","quoteAsset":"BTC"}]}`
+
+// fakeBinance is a local stand-in for the Binance REST API serving the exchange info
+type fakeBinance struct {
+	server     *httptest.Server
+	mux        sync.Mutex
+	requests   int
+	status     int
+	retryAfter string
+}
+
+func newFakeBinance() (fb *fakeBinance) {
+	fb = new(fakeBinance)
+	fb.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fb.mux.Lock()
+		defer fb.mux.Unlock()
+
+		fb.requests++
+		if !strings.HasSuffix(r.URL.Path, "/exchangeInfo") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if fb.status != 0 {
+			w.Header().Set("Retry-After", fb.retryAfter)
+			w.WriteHeader(fb.status)
+			fmt.Fprint(w, `{"code":-1003,"msg":"Too many requests"}`)
+			return
+		}
+		fmt.Fprint(w, testExchangeInfo)
+	}))
+	return
+}
+
+func TestNewBaseURL(t *testing.T) {
+	var fb *fakeBinance
+	var b *Binance
+	var err error
+
+	fb = newFakeBinance()
+	defer fb.server.Close()
+
+	if b, err = New(context.Background(), map[string]string{"apiKey": "key", "apiSecret": "secret", "baseURL": fb.server.URL + "/"}); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if fb.requests != 1 {
+		t.Errorf("requests to the base URL: got %d, want 1", fb.requests)
+	}
+	if b.client.BaseURL != fb.server.URL {
+		t.Errorf("base URL: got %s, want %s without trailing slash", b.client.BaseURL, fb.server.URL)
+	}
+	if b.streamURL != defaultStreamURL {
+		t.Errorf("stream URL: got %s, want %s", b.streamURL, defaultStreamURL)
+	}
+	if _, err = b.symbolToBinance(types.NewSymbol("BTC", "USDT")); err != nil {
+		t.Errorf("BTC/USDT not loaded from the exchange info: %v", err)
+	}
+}
+
+func TestNewTestnet(t *testing.T) {
+	var fb *fakeBinance
+	var b *Binance
+	var err error
+
+	fb = newFakeBinance()
+	defer fb.server.Close()
+
+	// The base URL takes precedence over the testnet endpoint, the testnet stream is kept
+	if b, err = New(context.Background(), map[string]string{"apiKey": "key", "apiSecret": "secret", "testnet": "true", "baseURL": fb.server.URL}); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if b.client.BaseURL != fb.server.URL || b.streamURL != testnetStreamURL {
+		t.Errorf("testnet with base URL: got %s and %s", b.client.BaseURL, b.streamURL)
+	}
+
+	if b, err = New(context.Background(), map[string]string{"apiKey": "key", "apiSecret": "secret", "testnet": "true", "baseURL": fb.server.URL, "streamURL": "ws://127.0.0.1:1/ws/"}); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if b.streamURL != "ws://127.0.0.1:1/ws" {
+		t.Errorf("stream URL: got %s, want ws://127.0.0.1:1/ws", b.streamURL)
+	}
+
+	if _, err = New(context.Background(), map[string]string{"apiKey": "key", "apiSecret": "secret", "testnet": "maybe", "baseURL": fb.server.URL}); err == nil {
+		t.Errorf("expected an error for an invalid testnet value")
+	}
+	if fb.requests != 2 {
+		t.Errorf("requests to the base URL: got %d, want 2", fb.requests)
+	}
+}
+
+func TestNewRateLimited(t *testing.T) {
+	var fb *fakeBinance
+	var urlError *url.Error
+	var httpError *exchange.HTTPError
+	var ok bool
+	var err error
+
+	fb = newFakeBinance()
+	defer fb.server.Close()
+	fb.status = 429
+	fb.retryAfter = "7"
+
+	if _, err = New(context.Background(), map[string]string{"apiKey": "key", "apiSecret": "secret", "baseURL": fb.server.URL}); err == nil {
+		t.Fatalf("expected an error for a rate limited request")
+	}
+	if urlError, ok = err.(*url.Error); ok {
+		err = urlError.Err
+	}
+	if httpError, ok = err.(*exchange.HTTPError); !ok {
+		t.Fatalf("got %T %v, want the *exchange.HTTPError of the transport", err, err)
+	}
+	if !httpError.RateLimited() || httpError.RetryAfter.Seconds() != 7 {
+		t.Errorf("got status %d retry after %v", httpError.StatusCode, httpError.RetryAfter)
+	}
+}
@@ -175,6 +175,10 @@ func (b Binance) toTime(in int64) (t time.Time) {
 	return
 }
 
+func (b Binance) fromTime(in time.Time) int64 {
+	return in.UnixNano() / int64(time.Millisecond)
+}
+
 func (b Binance) toStatus(in bin.OrderStatusType) types.OrderStatus {
 	switch in {
 	case bin.OrderStatusTypeNew:
@@ -4,7 +4,9 @@ import (
 	"context"
 
 	bin "github.com/adshao/go-binance"
+	"github.com/adshao/go-binance/common"
 	"github.com/google/uuid"
+	"github.com/mhereman/cryptotrader/exchange"
 	"github.com/mhereman/cryptotrader/logger"
 	"github.com/mhereman/cryptotrader/types"
 )
@@ -14,6 +16,8 @@ func (b Binance) GetOrder(ctx context.Context, order types.Order) (info types.Or
 	var gos *bin.GetOrderService
 	var response *bin.Order
 	var binanceSymbol string
+	var apiErr *common.APIError
+	var ok bool
 
 	if binanceSymbol, err = b.symbolToBinance(order.Symbol); err != nil {
 		logger.Errorf("Binance::GetOrder Error %v\n", err)
@@ -24,6 +28,9 @@ func (b Binance) GetOrder(ctx context.Context, order types.Order) (info types.Or
 	gos.Symbol(binanceSymbol)
 	gos.OrigClientOrderID(order.UserReference.String())
 	if response, err = gos.Do(ctx); err != nil {
+		if apiErr, ok = err.(*common.APIError); ok && apiErr.Code == codeUnknownOrder {
+			err = exchange.NewOrderNotFoundError(order.UserReference.String())
+		}
 		logger.Errorf("Binance::GetOrder Error %v\n", err)
 		return
 	}
@@ -2,14 +2,37 @@ package binance
 
 import (
 	"context"
+	"time"
 
 	bin "github.com/adshao/go-binance"
 	"github.com/mhereman/cryptotrader/logger"
 	"github.com/mhereman/cryptotrader/types"
 )
 
+// maxKlines is the max number of klines returned by a single klines request
+const maxKlines = 1000
+
 // GetSeries executes the get series request
 func (b Binance) GetSeries(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe) (series types.Series, err error) {
+	if series, err = b.getKlines(ctx, symbol, timeframe, time.Time{}, time.Time{}); err != nil {
+		logger.Errorf("Binance::GetSeries Error: %v\n", err)
+		return
+	}
+	return
+}
+
+// GetSeriesRange executes the get series request for the candles opened between start and end
+// At most 1000 candles are returned per request.
+func (b Binance) GetSeriesRange(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe, start time.Time, end time.Time) (series types.Series, err error) {
+	if series, err = b.getKlines(ctx, symbol, timeframe, start, end); err != nil {
+		logger.Errorf("Binance::GetSeriesRange Error: %v\n", err)
+		return
+	}
+	return
+}
+
+// getKlines executes the klines request, zero start and end times are not sent
+func (b Binance) getKlines(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe, start time.Time, end time.Time) (series types.Series, err error) {
 	var ks *bin.KlinesService
 	var binanceSymbol, binanceInterval string
 	var response []*bin.Kline
@@ -18,20 +41,26 @@ func (b Binance) GetSeries(ctx context.Context, symbol types.Symbol, timeframe t
 	var ohlc []types.OHLC
 
 	if binanceSymbol, err = b.symbolToBinance(symbol); err != nil {
-		logger.Errorf("Binance::GetSeries Error: %v\n", err)
 		return
 	}
 
 	if binanceInterval, err = b.timeframeToBinance(timeframe); err != nil {
-		logger.Errorf("Binance:GetSeries Error: %v\n", err)
 		return
 	}
 
 	ks = b.client.NewKlinesService()
 	ks.Symbol(binanceSymbol)
 	ks.Interval(binanceInterval)
+	if !start.IsZero() || !end.IsZero() {
+		ks.Limit(maxKlines)
+	}
+	if !start.IsZero() {
+		ks.StartTime(b.fromTime(start))
+	}
+	if !end.IsZero() {
+		ks.EndTime(b.fromTime(end))
+	}
 	if response, err = ks.Do(ctx); err != nil {
-		logger.Errorf("Binance::GetSeries Error: %v\n", err)
 		return
 	}
 
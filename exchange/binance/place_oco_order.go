@@ -0,0 +1,88 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+
+	bin "github.com/adshao/go-binance"
+	"github.com/google/uuid"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// PlaceOCOOrder executes the place one-cancels-other order request
+func (b Binance) PlaceOCOOrder(ctx context.Context, order types.OCOOrder, symbolInfo *types.SymbolInfo) (limitInfo types.OrderInfo, stopInfo types.OrderInfo, err error) {
+	var cos *bin.CreateOCOService
+	var binanceSymbol string
+	var response *bin.CreateOCOResponse
+	var report *bin.OCOOrderReport
+	var info types.OrderInfo
+	var strPrice string
+
+	if binanceSymbol, err = b.symbolToBinance(order.Symbol); err != nil {
+		logger.Errorf("Binance::PlaceOCOOrder Error %v\n", err)
+		return
+	}
+
+	cos = b.client.NewCreateOCOService()
+	cos.ListClientOrderID(order.ListReference.String())
+	cos.LimitClientOrderID(order.LimitReference.String())
+	cos.StopClientOrderID(order.StopReference.String())
+	cos.Symbol(binanceSymbol)
+	cos.Side(b.sideToBinance(order.Side))
+	cos.Quantity(fmt.Sprintf("%f", order.Quantity))
+	if strPrice, err = symbolInfo.ClampPrice(order.Price); err != nil {
+		return
+	}
+	cos.Price(strPrice)
+	if strPrice, err = symbolInfo.ClampPrice(order.StopPrice); err != nil {
+		return
+	}
+	cos.StopPrice(strPrice)
+	if strPrice, err = symbolInfo.ClampPrice(order.StopLimitPrice); err != nil {
+		return
+	}
+	cos.StopLimitPrice(strPrice)
+	cos.StopLimitTimeInForce(b.timeInForceToBinance(types.GoodTillCancel))
+
+	if response, err = cos.Do(ctx); err != nil {
+		logger.Errorf("Binance::PlaceOCOOrder Error %v\n", err)
+		return
+	}
+
+	for _, report = range response.OrderReports {
+		info = types.OrderInfo{}
+		if info.UserReference, err = uuid.Parse(report.ClientOrderID); err != nil {
+			logger.Errorf("Binance::PlaceOCOOrder Error %v\n", err)
+			return
+		}
+		info.ExchangeOrderID = report.OrderID
+		if info.Symbol, err = b.toSymbol(report.Symbol); err != nil {
+			logger.Errorf("Binance::PlaceOCOOrder Error %v\n", err)
+			return
+		}
+		info.TransactionTime = b.toTime(report.TransactionTime)
+		info.OriginalQuantity = b.toFloat(report.OrigQuantity)
+		info.ExecutedQuantity = b.toFloat(report.ExecutedQuantity)
+		info.Price = b.toFloat(report.Price)
+		info.StopPrice = b.toFloat(report.StopPrice)
+		info.Status = b.toStatus(report.Status)
+		info.TimeInForce = b.toTimeInForce(report.TimeInForce)
+		info.OrderType = b.toOrderType(report.Type)
+		info.Side = b.toSide(report.Side)
+
+		switch info.UserReference {
+		case order.LimitReference:
+			limitInfo = info
+		case order.StopReference:
+			stopInfo = info
+		}
+	}
+
+	if limitInfo.UserReference != order.LimitReference || stopInfo.UserReference != order.StopReference {
+		err = fmt.Errorf("Incomplete order list response for %s", order.ListReference.String())
+		logger.Errorf("Binance::PlaceOCOOrder Error %v\n", err)
+		return
+	}
+	return
+}
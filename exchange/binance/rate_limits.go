@@ -0,0 +1,41 @@
+package binance
+
+import (
+	"time"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	// weightBucket is the request weight limit of the IP address
+	weightBucket = "REQUEST_WEIGHT"
+
+	// ordersBucket is the order rate limit of the account
+	ordersBucket = "ORDERS"
+)
+
+// RateLimits returns the request limits of the Binance Spot API
+// The weights are the weights of the endpoints called by the driver methods, the websocket streams are not limited.
+func (b Binance) RateLimits() types.RateLimits {
+	return types.RateLimits{
+		Buckets: map[string]types.RateLimit{
+			weightBucket: types.NewRateLimit(6000, time.Minute),
+			ordersBucket: types.NewRateLimit(100, time.Second*10),
+		},
+		Weights: map[string]map[string]int{
+			"GetAccountInfo": {weightBucket: 20},
+			"GetOrderBook":   {weightBucket: 5},
+			"GetSeries":      {weightBucket: 2},
+			"GetSeriesRange": {weightBucket: 2},
+			"Ticker":         {weightBucket: 2},
+			"GetSymbolInfo":  {weightBucket: 20},
+			"PlaceOrder":     {weightBucket: 1, ordersBucket: 1},
+			"PlaceOCOOrder":  {weightBucket: 1, ordersBucket: 2},
+			"GetOrder":       {weightBucket: 4},
+			"OpenOrders":     {weightBucket: 6},
+			"GetOrderTrades": {weightBucket: 20},
+			"StreamCandles":  {},
+		},
+		DefaultBucket: weightBucket,
+	}
+}
@@ -0,0 +1,171 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	streamReadTimeout  time.Duration = (time.Minute * 5)
+	streamWriteTimeout time.Duration = (time.Second * 10)
+	streamMinBackoff   time.Duration = (time.Second * 1)
+	streamMaxBackoff   time.Duration = (time.Minute * 1)
+)
+
+type wsKlineEvent struct {
+	Event     string  `json:"e"`
+	EventTime int64   `json:"E"`
+	Symbol    string  `json:"s"`
+	Kline     wsKline `json:"k"`
+}
+
+type wsKline struct {
+	OpenTime       int64  `json:"t"`
+	CloseTime      int64  `json:"T"`
+	Interval       string `json:"i"`
+	Open           string `json:"o"`
+	Close          string `json:"c"`
+	High           string `json:"h"`
+	Low            string `json:"l"`
+	LastTradeID    int64  `json:"L"`
+	Volume         string `json:"v"`
+	TakerBuyVolume string `json:"V"`
+	Final          bool   `json:"x"`
+}
+
+// StreamCandles streams the kline updates of the symbol and timeframe from the kline websocket
+// The stream reconnects until the context is done.
+func (b Binance) StreamCandles(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe) (updates types.CandleUpdateChannel, err error) {
+	var binanceSymbol, binanceInterval, url string
+	var conn *websocket.Conn
+
+	if binanceSymbol, err = b.symbolToBinance(symbol); err != nil {
+		logger.Errorf("Binance::StreamCandles Error %v\n", err)
+		return
+	}
+
+	if binanceInterval, err = b.timeframeToBinance(timeframe); err != nil {
+		logger.Errorf("Binance::StreamCandles Error %v\n", err)
+		return
+	}
+
+	url = fmt.Sprintf("%s/%s@kline_%s", b.streamURL, strings.ToLower(binanceSymbol), binanceInterval)
+	if conn, _, err = websocket.DefaultDialer.DialContext(ctx, url, nil); err != nil {
+		logger.Errorf("Binance::StreamCandles Error %v\n", err)
+		return
+	}
+
+	updates = make(types.CandleUpdateChannel)
+	go b.streamRoutine(ctx, conn, url, updates)
+	return
+}
+
+func (b Binance) streamRoutine(ctx context.Context, conn *websocket.Conn, url string, updates types.CandleUpdateChannel) {
+	defer close(updates)
+
+	var backoff time.Duration
+	var err error
+
+	backoff = streamMinBackoff
+	for {
+		if conn == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			if conn, _, err = websocket.DefaultDialer.DialContext(ctx, url, nil); err != nil {
+				logger.Warningf("Binance::StreamCandles Failed to reconnect to %s: %v\n", url, err)
+				conn = nil
+				backoff *= 2
+				if backoff > streamMaxBackoff {
+					backoff = streamMaxBackoff
+				}
+				continue
+			}
+			backoff = streamMinBackoff
+		}
+
+		err = b.readStream(ctx, conn, updates)
+		conn.Close()
+		conn = nil
+
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warningf("Binance::StreamCandles Stream %s disconnected: %v\n", url, err)
+	}
+}
+
+func (b Binance) readStream(ctx context.Context, conn *websocket.Conn, updates types.CandleUpdateChannel) (err error) {
+	var done chan struct{}
+	var message []byte
+	var update types.CandleUpdate
+
+	done = make(chan struct{})
+	defer close(done)
+
+	// Unblock the pending read when the context is done
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(streamWriteTimeout))
+	})
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+		if _, message, err = conn.ReadMessage(); err != nil {
+			return
+		}
+
+		if update, err = b.toCandleUpdate(message); err != nil {
+			logger.Warningf("Binance::StreamCandles Error %v\n", err)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case updates <- update:
+		}
+	}
+}
+
+func (b Binance) toCandleUpdate(message []byte) (update types.CandleUpdate, err error) {
+	var event wsKlineEvent
+
+	if err = json.Unmarshal(message, &event); err != nil {
+		return
+	}
+	if event.Event != "kline" {
+		err = fmt.Errorf("Unexpected stream event: %s", event.Event)
+		return
+	}
+
+	update = types.NewCandleUpdate(types.NewOHLC(
+		b.toFloat(event.Kline.Open),
+		b.toFloat(event.Kline.High),
+		b.toFloat(event.Kline.Low),
+		b.toFloat(event.Kline.Close),
+		b.toFloat(event.Kline.Volume),
+		b.toTime(event.Kline.OpenTime),
+		b.toTime(event.Kline.CloseTime),
+	), event.Kline.Final)
+	return
+}
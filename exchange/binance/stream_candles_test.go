@@ -0,0 +1,177 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const testKlineMessage = `{"e":"kline","E":1600000000100,"s":"BTCUSDT","k":{"t":1600000000000,"T":1600000059999,"i":"1m","o":"10000.5","c":"10010.25","h":"10020","l":"9990","L":42,"v":"12.5","V":"6.25","x":%s}}`
+
+// klineServer is a local stand-in for the kline websocket, every connection receives the messages and is closed
+type klineServer struct {
+	server   *httptest.Server
+	mux      sync.Mutex
+	paths    []string
+	messages []string
+}
+
+func newKlineServer(t *testing.T, messages ...string) (ks *klineServer) {
+	var upgrader websocket.Upgrader
+
+	ks = &klineServer{messages: messages}
+	ks.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var conn *websocket.Conn
+		var message string
+		var err error
+
+		ks.mux.Lock()
+		ks.paths = append(ks.paths, r.URL.Path)
+		ks.mux.Unlock()
+
+		if conn, err = upgrader.Upgrade(w, r, nil); err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		for _, message = range ks.messages {
+			if err = conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+				return
+			}
+		}
+	}))
+	return
+}
+
+func (ks *klineServer) url() string {
+	return "ws" + strings.TrimPrefix(ks.server.URL, "http") + "/ws"
+}
+
+func (ks *klineServer) requestedPaths() []string {
+	ks.mux.Lock()
+	defer ks.mux.Unlock()
+
+	return append([]string(nil), ks.paths...)
+}
+
+func testDriver(streamURL string) Binance {
+	return Binance{
+		allSymbols: map[string][]string{"BTCUSDT": {"BTC", "USDT"}},
+		streamURL:  streamURL,
+	}
+}
+
+func receiveUpdate(t *testing.T, updates types.CandleUpdateChannel) (update types.CandleUpdate) {
+	var ok bool
+
+	select {
+	case update, ok = <-updates:
+		if !ok {
+			t.Fatalf("update channel closed")
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("no candle update received")
+	}
+	return
+}
+
+func TestToCandleUpdate(t *testing.T) {
+	var b Binance
+	var update types.CandleUpdate
+	var err error
+
+	if update, err = b.toCandleUpdate([]byte(strings.Replace(testKlineMessage, "%s", "true", 1))); err != nil {
+		t.Fatalf("toCandleUpdate: %v", err)
+	}
+	if !update.Final {
+		t.Errorf("final: got false, want true")
+	}
+	if update.Candle.Open != 10000.5 || update.Candle.High != 10020 || update.Candle.Low != 9990 || update.Candle.Close != 10010.25 || update.Candle.Volume != 12.5 {
+		t.Errorf("candle: got %s volume %f", update.Candle.String(), update.Candle.Volume)
+	}
+	if !update.Candle.OpenTime.Equal(time.Unix(1600000000, 0)) {
+		t.Errorf("open time: got %v", update.Candle.OpenTime)
+	}
+	if !update.Candle.CloseTime.Equal(time.Unix(1600000059, 999000000)) {
+		t.Errorf("close time: got %v", update.Candle.CloseTime)
+	}
+
+	if _, err = b.toCandleUpdate([]byte(`{"e":"trade","s":"BTCUSDT"}`)); err == nil {
+		t.Errorf("trade event: expected an error")
+	}
+	if _, err = b.toCandleUpdate([]byte(`not json`)); err == nil {
+		t.Errorf("invalid message: expected an error")
+	}
+}
+
+func TestStreamCandles(t *testing.T) {
+	var ks *klineServer
+	var ctx context.Context
+	var cancel context.CancelFunc
+	var updates types.CandleUpdateChannel
+	var update types.CandleUpdate
+	var paths []string
+	var err error
+
+	ks = newKlineServer(t,
+		`{"e":"trade","s":"BTCUSDT"}`,
+		strings.Replace(testKlineMessage, "%s", "false", 1),
+		strings.Replace(testKlineMessage, "%s", "true", 1),
+	)
+	defer ks.server.Close()
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	if updates, err = testDriver(ks.url()).StreamCandles(ctx, types.NewSymbol("BTC", "USDT"), types.NewTimeframe(1, types.TuMin)); err != nil {
+		t.Fatalf("StreamCandles: %v", err)
+	}
+
+	// The unexpected trade event is skipped
+	if update = receiveUpdate(t, updates); update.Final || update.Candle.Close != 10010.25 {
+		t.Errorf("first update: got final %v close %f", update.Final, update.Candle.Close)
+	}
+	if update = receiveUpdate(t, updates); !update.Final {
+		t.Errorf("second update: got final false, want true")
+	}
+
+	// The server closes the connection after its messages, the stream reconnects
+	if update = receiveUpdate(t, updates); update.Final {
+		t.Errorf("update after reconnect: got final true, want false")
+	}
+
+	paths = ks.requestedPaths()
+	if len(paths) < 2 {
+		t.Fatalf("connections: got %d, want at least 2", len(paths))
+	}
+	if paths[0] != "/ws/btcusdt@kline_1m" {
+		t.Errorf("path: got %s, want /ws/btcusdt@kline_1m", paths[0])
+	}
+
+	cancel()
+	for range updates {
+	}
+}
+
+func TestStreamCandlesDialError(t *testing.T) {
+	var ks *klineServer
+	var err error
+
+	ks = newKlineServer(t)
+	ks.server.Close()
+
+	if _, err = testDriver(ks.url()).StreamCandles(context.Background(), types.NewSymbol("BTC", "USDT"), types.NewTimeframe(1, types.TuMin)); err == nil {
+		t.Errorf("expected an error when the stream endpoint is down")
+	}
+	if _, err = testDriver(ks.url()).StreamCandles(context.Background(), types.NewSymbol("ETH", "USDT"), types.NewTimeframe(1, types.TuMin)); err == nil {
+		t.Errorf("expected an error for an unknown symbol")
+	}
+}
@@ -0,0 +1,118 @@
+package binancefutures
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	exchangeName   = "binance-futures"
+	defaultBaseURL = "https://fapi.binance.com"
+	testnetBaseURL = "https://testnet.binancefuture.com"
+	defaultTimeout = time.Second * 30
+)
+
+func init() {
+	exchange.RegisterExchange(exchangeName, createBinanceFutures)
+}
+
+// BinanceFutures represents the Binance USDⓈ-M futures exchange plugin
+// Only perpetual contracts are traded, the account must use the one-way position mode.
+type BinanceFutures struct {
+	apiKey    string
+	apiSecret string
+	baseURL   string
+	client    *http.Client
+
+	// contracts maps the symbol strings (e.g. BTC/USDT) onto the perpetual contracts
+	contracts map[string]contract
+
+	// symbols maps the contract names (e.g. BTCUSDT) onto the symbols
+	symbols map[string]types.Symbol
+}
+
+// New creates a new Binance futures exchange plugin
+// The 'testnet' argument targets the futures testnet, the 'baseURL' argument replaces the REST endpoint,
+// e.g. to run against a local test server.
+func New(ctx context.Context, config map[string]string) (driver *BinanceFutures, err error) {
+	var apiKey, apiSecret, baseURL, value string
+	var testnet, ok bool
+
+	if apiKey, ok = config["apiKey"]; !ok {
+		err = fmt.Errorf("Binance futures config error: 'apiKey' entry not found")
+		return
+	}
+
+	if apiSecret, ok = config["apiSecret"]; !ok {
+		err = fmt.Errorf("Binance futures config error: 'apiSecret' entry not found")
+		return
+	}
+
+	if value, ok = config["testnet"]; ok && value != "" {
+		if testnet, err = strconv.ParseBool(value); err != nil {
+			err = fmt.Errorf("Binance futures config error: invalid 'testnet' value %s", value)
+			return
+		}
+	}
+
+	driver = new(BinanceFutures)
+	driver.apiKey = apiKey
+	driver.apiSecret = apiSecret
+	driver.baseURL = defaultBaseURL
+	if testnet {
+		driver.baseURL = testnetBaseURL
+		logger.Infof("Binance futures: Using the futures testnet\n")
+	}
+	if baseURL, ok = config["baseURL"]; ok && baseURL != "" {
+		driver.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+	driver.client = &http.Client{Timeout: defaultTimeout}
+
+	if err = driver.loadContracts(ctx); err != nil {
+		logger.Errorf("BinanceFutures::New Error: %v\n", err)
+		return
+	}
+	return
+}
+
+func createBinanceFutures(ctx context.Context, config map[string]string) (driver interfaces.IExchangeDriver, err error) {
+	driver, err = New(ctx, config)
+	return
+}
+
+// Name returns the name of the exchange plugin
+func (b *BinanceFutures) Name() string {
+	return exchangeName
+}
+
+// loadContracts loads the perpetual contracts open for trading
+func (b *BinanceFutures) loadContracts(ctx context.Context) (err error) {
+	var response exchangeInfo
+	var c contract
+	var symbol types.Symbol
+
+	if err = b.publicRequest(ctx, "/fapi/v1/exchangeInfo", nil, &response); err != nil {
+		return
+	}
+
+	b.contracts = make(map[string]contract)
+	b.symbols = make(map[string]types.Symbol)
+	for _, c = range response.Symbols {
+		if c.ContractType != "PERPETUAL" || c.Status != "TRADING" {
+			continue
+		}
+		symbol = types.NewSymbol(c.BaseAsset, c.QuoteAsset)
+		b.contracts[symbol.String()] = c
+		b.symbols[c.Symbol] = symbol
+	}
+	return
+}
@@ -0,0 +1,442 @@
+package binancefutures
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const testExchangeInfo = `{"symbols":[` +
+	`{"symbol":"BTCUSDT","contractType":"PERPETUAL","status":"TRADING","baseAsset":"BTC","quoteAsset":"USDT","marginAsset":"USDT","pricePrecision":2,"quantityPrecision":3,"filters":[{"filterType":"LOT_SIZE","stepSize":"0.001","minQty":"0.001"}]},` +
+	`{"symbol":"ETHUSDT_250926","contractType":"CURRENT_QUARTER","status":"TRADING","baseAsset":"ETH","quoteAsset":"USDT","marginAsset":"USDT","pricePrecision":2,"quantityPrecision":3},` +
+	`{"symbol":"XRPUSDT","contractType":"PERPETUAL","status":"SETTLING","baseAsset":"XRP","quoteAsset":"USDT","marginAsset":"USDT","pricePrecision":4,"quantityPrecision":1}]}`
+
+var testSymbol = types.NewSymbol("BTC", "USDT")
+
+// fakeFutures is a local stand-in for the Binance futures REST API
+// Market orders are filled at the ticker price on the first query after being placed,
+// the signed requests are rejected unless they are signed with the secret of the fake.
+type fakeFutures struct {
+	server      *httptest.Server
+	mux         sync.Mutex
+	requests    map[string]url.Values
+	orders      map[string]orderEntry
+	nextOrderID int64
+	price       string
+	positionAmt string
+	marginCode  int
+}
+
+func newFakeFutures() (ff *fakeFutures) {
+	ff = &fakeFutures{requests: make(map[string]url.Values), orders: make(map[string]orderEntry), nextOrderID: 1, price: "100.00", positionAmt: "0.000"}
+	ff.server = httptest.NewServer(http.HandlerFunc(ff.handle))
+	return
+}
+
+func (ff *fakeFutures) handle(w http.ResponseWriter, r *http.Request) {
+	var params url.Values
+	var entry orderEntry
+	var ok bool
+
+	ff.mux.Lock()
+	defer ff.mux.Unlock()
+
+	params = r.URL.Query()
+	ff.requests[r.Method+" "+r.URL.Path] = params
+	switch r.URL.Path {
+	case "/fapi/v1/exchangeInfo":
+		fmt.Fprint(w, testExchangeInfo)
+		return
+	case "/fapi/v1/ticker/price":
+		ff.reply(w, tickerResult{Symbol: params.Get("symbol"), Price: ff.price})
+		return
+	}
+
+	if !ff.signed(r) {
+		ff.fail(w, http.StatusUnauthorized, -1022, "Signature for this request is not valid.")
+		return
+	}
+
+	switch r.Method + " " + r.URL.Path {
+	case "GET /fapi/v2/account":
+		ff.reply(w, accountResult{Assets: []accountAsset{{Asset: "USDT", WalletBalance: "1000.0", AvailableBalance: "750.0"}}})
+	case "GET /fapi/v1/commissionRate":
+		ff.reply(w, commissionRateResult{Symbol: params.Get("symbol"), MakerCommissionRate: "0.0002", TakerCommissionRate: "0.0004"})
+	case "POST /fapi/v1/order":
+		entry = orderEntry{
+			OrderID:       ff.nextOrderID,
+			ClientOrderID: params.Get("newClientOrderId"),
+			Symbol:        params.Get("symbol"),
+			Status:        "NEW",
+			Price:         params.Get("price"),
+			OrigQty:       params.Get("quantity"),
+			ExecutedQty:   "0",
+			TimeInForce:   params.Get("timeInForce"),
+			Type:          params.Get("type"),
+			Side:          params.Get("side"),
+			StopPrice:     params.Get("stopPrice"),
+			ReduceOnly:    params.Get("reduceOnly") == "true",
+			Time:          1600000000000,
+		}
+		ff.nextOrderID++
+		ff.reply(w, entry)
+		if entry.Type == "MARKET" {
+			entry.Status = "FILLED"
+			entry.ExecutedQty = entry.OrigQty
+			entry.AvgPrice = ff.price
+			entry.UpdateTime = 1600000001000
+		}
+		ff.orders[entry.ClientOrderID] = entry
+	case "GET /fapi/v1/order":
+		if entry, ok = ff.orders[params.Get("origClientOrderId")]; !ok {
+			ff.fail(w, http.StatusBadRequest, codeUnknownOrder, "Order does not exist.")
+			return
+		}
+		ff.reply(w, entry)
+	case "GET /fapi/v1/userTrades":
+		ff.reply(w, ff.trades(params.Get("orderId")))
+	case "GET /fapi/v2/positionRisk":
+		ff.reply(w, []positionRisk{
+			{Symbol: "ETHUSDT", PositionAmt: "2.000", Leverage: "10", MarginType: "cross"},
+			{Symbol: params.Get("symbol"), PositionAmt: ff.positionAmt, EntryPrice: "100.0", MarkPrice: "99.5", UnRealizedProfit: "0.25", LiquidationPrice: "190.0", Leverage: "2", MarginType: "isolated", PositionSide: "BOTH"},
+		})
+	case "POST /fapi/v1/leverage":
+		ff.reply(w, map[string]interface{}{"symbol": params.Get("symbol"), "leverage": params.Get("leverage")})
+	case "POST /fapi/v1/marginType":
+		if ff.marginCode != 0 {
+			ff.fail(w, http.StatusBadRequest, ff.marginCode, "Margin type error.")
+			return
+		}
+		ff.reply(w, map[string]interface{}{"code": 200, "msg": "success"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// signed verifies the API key and the signature of the query
+func (ff *fakeFutures) signed(r *http.Request) bool {
+	var query, signature string
+	var index int
+	var mac = hmac.New(sha256.New, []byte("secret"))
+
+	if r.Header.Get("X-MBX-APIKEY") != "key" {
+		return false
+	}
+	query = r.URL.RawQuery
+	if index = strings.Index(query, "&signature="); index < 0 {
+		return false
+	}
+	query, signature = query[:index], query[index+len("&signature="):]
+	if r.URL.Query().Get("timestamp") == "" || r.URL.Query().Get("recvWindow") != recvWindow {
+		return false
+	}
+	mac.Write([]byte(query))
+	return hmac.Equal([]byte(signature), []byte(hex.EncodeToString(mac.Sum(nil))))
+}
+
+// trades returns the fill of a filled order
+func (ff *fakeFutures) trades(orderID string) (userTrades []userTrade) {
+	var entry orderEntry
+
+	userTrades = []userTrade{}
+	for _, entry = range ff.orders {
+		if strconv.FormatInt(entry.OrderID, 10) != orderID || entry.Status != "FILLED" {
+			continue
+		}
+		userTrades = append(userTrades, userTrade{
+			ID:              entry.OrderID * 10,
+			OrderID:         entry.OrderID,
+			Symbol:          entry.Symbol,
+			Side:            entry.Side,
+			Price:           entry.AvgPrice,
+			Qty:             entry.ExecutedQty,
+			QuoteQty:        "50.0",
+			Commission:      "0.02",
+			CommissionAsset: "USDT",
+			Buyer:           entry.Side == "BUY",
+			Time:            entry.UpdateTime,
+		})
+	}
+	return
+}
+
+func (ff *fakeFutures) reply(w http.ResponseWriter, body interface{}) {
+	json.NewEncoder(w).Encode(body)
+}
+
+func (ff *fakeFutures) fail(w http.ResponseWriter, status int, code int, message string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"code":%d,"msg":%q}`, code, message)
+}
+
+func (ff *fakeFutures) request(key string) (params url.Values, ok bool) {
+	ff.mux.Lock()
+	defer ff.mux.Unlock()
+
+	params, ok = ff.requests[key]
+	return
+}
+
+func newTestDriver(t *testing.T, ff *fakeFutures, secret string) (b *BinanceFutures) {
+	var err error
+
+	if b, err = New(context.Background(), map[string]string{"apiKey": "key", "apiSecret": secret, "baseURL": ff.server.URL + "/"}); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return
+}
+
+func TestNewContracts(t *testing.T) {
+	var tests = []struct {
+		symbol types.Symbol
+		loaded bool
+	}{
+		{testSymbol, true},
+		{types.NewSymbol("ETH", "USDT"), false},
+		{types.NewSymbol("XRP", "USDT"), false},
+	}
+	var ff *fakeFutures
+	var b *BinanceFutures
+	var err error
+	var index int
+
+	ff = newFakeFutures()
+	defer ff.server.Close()
+	b = newTestDriver(t, ff, "secret")
+
+	if b.baseURL != ff.server.URL {
+		t.Errorf("base URL: got %s, want %s without trailing slash", b.baseURL, ff.server.URL)
+	}
+	for index = range tests {
+		if _, err = b.symbolToBinance(tests[index].symbol); (err == nil) != tests[index].loaded {
+			t.Errorf("%s: got error %v, want loaded %v", tests[index].symbol.String(), err, tests[index].loaded)
+		}
+	}
+}
+
+func TestSignedRequests(t *testing.T) {
+	var ff *fakeFutures
+	var b *BinanceFutures
+	var info types.AccountInfo
+	var httpErr *exchange.HTTPError
+	var apiErr *apiError
+	var ok bool
+	var err error
+
+	ff = newFakeFutures()
+	defer ff.server.Close()
+
+	b = newTestDriver(t, ff, "secret")
+	if info, err = b.GetAccountInfo(context.Background()); err != nil {
+		t.Fatalf("GetAccountInfo: %v", err)
+	}
+	if len(info.Balances) != 1 || info.Balances[0].Asset != "USDT" || info.Balances[0].Free != 750.0 || info.Balances[0].Locked != 250.0 {
+		t.Errorf("balances: got %+v, want 750 USDT free and 250 locked", info.Balances)
+	}
+	if info.MakerCommission != 0.0002 || info.TakerCommission != 0.0004 {
+		t.Errorf("commissions: got %f %f, want 0.0002 0.0004", info.MakerCommission, info.TakerCommission)
+	}
+
+	// The fake rejects the requests signed with another secret
+	b = newTestDriver(t, ff, "other")
+	if _, err = b.GetAccountInfo(context.Background()); err == nil {
+		t.Fatalf("GetAccountInfo: expected an error for an invalid signature")
+	}
+	if httpErr, ok = err.(*exchange.HTTPError); !ok || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("got %T %v, want the *exchange.HTTPError of the rejected request", err, err)
+	}
+	if apiErr, ok = httpErr.Err.(*apiError); !ok || apiErr.Code != -1022 {
+		t.Errorf("got %v, want the API error -1022", httpErr.Err)
+	}
+
+	b = newTestDriver(t, ff, "")
+	if _, err = b.GetPosition(context.Background(), testSymbol); err == nil || !strings.Contains(err.Error(), "no API credentials") {
+		t.Errorf("GetPosition without credentials: got %v", err)
+	}
+}
+
+func TestPlaceOrder(t *testing.T) {
+	var tests = []struct {
+		name   string
+		order  types.Order
+		params map[string]string
+		status types.OrderStatus
+		fills  int
+	}{
+		{
+			name:   "market order opening a short",
+			order:  types.NewMarketOrder(testSymbol, types.Sell, 0.5004),
+			params: map[string]string{"symbol": "BTCUSDT", "side": "SELL", "type": "MARKET", "quantity": "0.500", "newOrderRespType": "RESULT", "reduceOnly": "", "price": ""},
+			status: types.StatusFilled,
+			fills:  1,
+		},
+		{
+			name:   "stop loss of the short",
+			order:  types.NewStopLossLimitOrder(testSymbol, types.Buy, 0.5, 110.0, 111.0),
+			params: map[string]string{"side": "BUY", "type": "STOP", "quantity": "0.500", "stopPrice": "110.00", "price": "111.00", "timeInForce": "GTC", "reduceOnly": "true"},
+			status: types.StatusNew,
+			fills:  0,
+		},
+		{
+			name:   "take profit of the short",
+			order:  types.NewTakeProfitOrder(testSymbol, types.Buy, 0.5, 90.0),
+			params: map[string]string{"type": "TAKE_PROFIT_MARKET", "stopPrice": "90.00", "price": "", "timeInForce": "", "reduceOnly": "true"},
+			status: types.StatusNew,
+			fills:  0,
+		},
+	}
+	var ff *fakeFutures
+	var b *BinanceFutures
+	var order types.Order
+	var info types.OrderInfo
+	var params url.Values
+	var key, value string
+	var index int
+	var err error
+
+	ff = newFakeFutures()
+	defer ff.server.Close()
+	b = newTestDriver(t, ff, "secret")
+
+	for index = range tests {
+		order = tests[index].order
+		if order.Type != types.Market {
+			order.ReduceOnly = true
+		}
+		if info, err = b.PlaceOrder(context.Background(), order, nil); err != nil {
+			t.Errorf("%s: PlaceOrder: %v", tests[index].name, err)
+			continue
+		}
+		params, _ = ff.request("POST /fapi/v1/order")
+		for key, value = range tests[index].params {
+			if params.Get(key) != value {
+				t.Errorf("%s: got %s=%q, want %q", tests[index].name, key, params.Get(key), value)
+			}
+		}
+		if params.Get("newClientOrderId") != order.UserReference.String() || info.UserReference != order.UserReference {
+			t.Errorf("%s: got client order id %s, want %s", tests[index].name, params.Get("newClientOrderId"), order.UserReference.String())
+		}
+		if info.Status != tests[index].status || len(info.Fills) != tests[index].fills || info.Symbol != testSymbol {
+			t.Errorf("%s: got %s order with status %d and %d fills", tests[index].name, info.Symbol.String(), info.Status, len(info.Fills))
+		}
+	}
+
+	// The market order was followed up until it was filled
+	order = tests[0].order
+	if info, err = b.GetOrder(context.Background(), order); err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if info.Status != types.StatusFilled || info.ExecutedQuantity != 0.5 || info.Price != 100.0 || info.Side != types.Sell || info.OrderType != types.Market {
+		t.Errorf("market order: got %+v", info)
+	}
+
+	order = types.NewMarketOrder(testSymbol, types.Buy, 0.5)
+	if _, err = b.GetOrder(context.Background(), order); !exchange.IsOrderNotFound(err) {
+		t.Errorf("GetOrder of an unknown order: got %v, want the order not found error", err)
+	}
+}
+
+func TestGetPosition(t *testing.T) {
+	var tests = []struct {
+		name     string
+		amount   string
+		side     types.PositionSide
+		quantity float64
+	}{
+		{"short position", "-0.500", types.Short, 0.5},
+		{"long position", "0.250", types.Long, 0.25},
+		{"no position", "0.000", types.Long, 0.0},
+	}
+	var ff *fakeFutures
+	var b *BinanceFutures
+	var position types.Position
+	var params url.Values
+	var index int
+	var err error
+
+	ff = newFakeFutures()
+	defer ff.server.Close()
+	b = newTestDriver(t, ff, "secret")
+
+	for index = range tests {
+		ff.mux.Lock()
+		ff.positionAmt = tests[index].amount
+		ff.mux.Unlock()
+
+		if position, err = b.GetPosition(context.Background(), testSymbol); err != nil {
+			t.Errorf("%s: GetPosition: %v", tests[index].name, err)
+			continue
+		}
+		if position.Side != tests[index].side || position.Quantity != tests[index].quantity {
+			t.Errorf("%s: got %s %f, want %s %f", tests[index].name, position.Side.String(), position.Quantity, tests[index].side.String(), tests[index].quantity)
+		}
+		if position.Symbol != testSymbol || position.Leverage != 2 || position.MarginType != types.Isolated || position.LiquidationPrice != 190.0 {
+			t.Errorf("%s: got %+v, want the position of BTCUSDT only", tests[index].name, position)
+		}
+	}
+	if params, _ = ff.request("GET /fapi/v2/positionRisk"); params.Get("symbol") != "BTCUSDT" {
+		t.Errorf("position of contract %s requested, want BTCUSDT", params.Get("symbol"))
+	}
+}
+
+func TestFuturesSettings(t *testing.T) {
+	var ff *fakeFutures
+	var b *BinanceFutures
+	var params url.Values
+	var price float64
+	var err error
+
+	ff = newFakeFutures()
+	defer ff.server.Close()
+	b = newTestDriver(t, ff, "secret")
+
+	if err = b.SetLeverage(context.Background(), testSymbol, 5); err != nil {
+		t.Errorf("SetLeverage: %v", err)
+	}
+	if params, _ = ff.request("POST /fapi/v1/leverage"); params.Get("symbol") != "BTCUSDT" || params.Get("leverage") != "5" {
+		t.Errorf("leverage request: got %v", params)
+	}
+
+	if err = b.SetMarginType(context.Background(), testSymbol, types.Cross); err != nil {
+		t.Errorf("SetMarginType: %v", err)
+	}
+	if params, _ = ff.request("POST /fapi/v1/marginType"); params.Get("marginType") != "CROSSED" {
+		t.Errorf("margin type request: got %v", params)
+	}
+
+	// Setting the margin type the contract already has is not an error
+	ff.mux.Lock()
+	ff.marginCode = codeMarginTypeUnchanged
+	ff.mux.Unlock()
+	if err = b.SetMarginType(context.Background(), testSymbol, types.Isolated); err != nil {
+		t.Errorf("SetMarginType unchanged: %v", err)
+	}
+
+	ff.mux.Lock()
+	ff.marginCode = -4047
+	ff.mux.Unlock()
+	if err = b.SetMarginType(context.Background(), testSymbol, types.Isolated); err == nil {
+		t.Errorf("SetMarginType with open orders: expected an error")
+	}
+
+	if err = b.SetLeverage(context.Background(), types.NewSymbol("ETH", "USDT"), 5); err == nil {
+		t.Errorf("SetLeverage of a quarterly contract: expected an error")
+	}
+
+	if price, err = b.Ticker(context.Background(), testSymbol); err != nil || price != 100.0 {
+		t.Errorf("Ticker: got %f %v, want 100", price, err)
+	}
+}
@@ -0,0 +1,34 @@
+package binancefutures
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// CancelOrder executes the cancel order request
+func (b *BinanceFutures) CancelOrder(ctx context.Context, order types.Order, newUUID uuid.UUID) (info types.OrderInfo, err error) {
+	var c contract
+	var entry orderEntry
+
+	if c, err = b.symbolToBinance(order.Symbol); err != nil {
+		logger.Errorf("BinanceFutures::CancelOrder Error %v\n", err)
+		return
+	}
+
+	if err = b.privateRequest(ctx, http.MethodDelete, "/fapi/v1/order", url.Values{"symbol": {c.Symbol}, "origClientOrderId": {order.UserReference.String()}}, &entry); err != nil {
+		logger.Errorf("BinanceFutures::CancelOrder Error %v\n", err)
+		return
+	}
+
+	if info, err = b.toOrderInfo(entry); err != nil {
+		logger.Errorf("BinanceFutures::CancelOrder Error %v\n", err)
+		return
+	}
+	info.CancelUserReference = newUUID
+	return
+}
@@ -0,0 +1,116 @@
+package binancefutures
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhereman/cryptotrader/exchange"
+)
+
+const (
+	// recvWindow is the number of milliseconds a signed request stays valid after its timestamp
+	recvWindow = "5000"
+
+	// codeUnknownOrder is the error code of the requests for an order the exchange does not know
+	codeUnknownOrder = -2013
+)
+
+// apiError represents the body of a request rejected by the exchange
+type apiError struct {
+	Method  string `json:"-"`
+	Path    string `json:"-"`
+	Status  string `json:"-"`
+	Code    int    `json:"code"`
+	Message string `json:"msg"`
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s %s: %s %d %s", e.Method, e.Path, e.Status, e.Code, e.Message)
+}
+
+// publicRequest executes a GET request of a public market data endpoint
+func (b *BinanceFutures) publicRequest(ctx context.Context, path string, params url.Values, out interface{}) (err error) {
+	err = b.request(ctx, http.MethodGet, path, params, false, out)
+	return
+}
+
+// privateRequest executes a signed request, the parameters are sent in the query string
+func (b *BinanceFutures) privateRequest(ctx context.Context, method string, path string, params url.Values, out interface{}) (err error) {
+	if b.apiKey == "" || b.apiSecret == "" {
+		err = fmt.Errorf("%s %s: no API credentials configured", method, path)
+		return
+	}
+	err = b.request(ctx, method, path, params, true, out)
+	return
+}
+
+func (b *BinanceFutures) request(ctx context.Context, method string, path string, params url.Values, sign bool, out interface{}) (err error) {
+	var request *http.Request
+	var response *http.Response
+	var query string
+	var body []byte
+	var errResult *apiError
+
+	if params == nil {
+		params = url.Values{}
+	}
+	if sign {
+		params.Set("timestamp", strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10))
+		params.Set("recvWindow", recvWindow)
+	}
+	query = params.Encode()
+	if sign {
+		query += "&signature=" + b.sign(query)
+	}
+
+	if request, err = http.NewRequest(method, b.baseURL+path+"?"+query, nil); err != nil {
+		return
+	}
+	if sign {
+		request.Header.Set("X-MBX-APIKEY", b.apiKey)
+	}
+
+	if response, err = b.client.Do(request.WithContext(ctx)); err != nil {
+		return
+	}
+	defer response.Body.Close()
+
+	if body, err = ioutil.ReadAll(response.Body); err != nil {
+		return
+	}
+	if response.StatusCode != http.StatusOK {
+		errResult = &apiError{Method: method, Path: path, Status: response.Status}
+		if json.Unmarshal(body, errResult) == nil && errResult.Code != 0 {
+			err = exchange.NewHTTPError(response, errResult)
+			return
+		}
+		err = exchange.NewHTTPError(response, fmt.Errorf("%s %s: %s %s", method, path, response.Status, strings.TrimSpace(string(body))))
+		return
+	}
+
+	if out != nil {
+		if err = json.Unmarshal(body, out); err != nil {
+			err = fmt.Errorf("%s %s: invalid response %v", method, path, err)
+			return
+		}
+	}
+	return
+}
+
+// sign returns the hex encoded HMAC-SHA256 of the query string, keyed with the secret
+func (b *BinanceFutures) sign(query string) string {
+	var mac = hmac.New(sha256.New, []byte(b.apiSecret))
+
+	mac.Write([]byte(query))
+	return hex.EncodeToString(mac.Sum(nil))
+}
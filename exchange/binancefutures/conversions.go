@@ -0,0 +1,287 @@
+package binancefutures
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// intervals are the kline intervals of Binance futures and their timeframes
+var intervals = []struct {
+	name      string
+	timeframe types.Timeframe
+}{
+	{"1m", types.NewTimeframe(1, types.TuMin)},
+	{"3m", types.NewTimeframe(3, types.TuMin)},
+	{"5m", types.NewTimeframe(5, types.TuMin)},
+	{"15m", types.NewTimeframe(15, types.TuMin)},
+	{"30m", types.NewTimeframe(30, types.TuMin)},
+	{"1h", types.NewTimeframe(1, types.TuHour)},
+	{"2h", types.NewTimeframe(2, types.TuHour)},
+	{"4h", types.NewTimeframe(4, types.TuHour)},
+	{"6h", types.NewTimeframe(6, types.TuHour)},
+	{"8h", types.NewTimeframe(8, types.TuHour)},
+	{"12h", types.NewTimeframe(12, types.TuHour)},
+	{"1d", types.NewTimeframe(1, types.TuDay)},
+	{"3d", types.NewTimeframe(3, types.TuDay)},
+	{"1w", types.NewTimeframe(1, types.TuWeek)},
+	{"1M", types.NewTimeframe(1, types.TuMonth)},
+}
+
+func (b *BinanceFutures) symbolToBinance(symbol types.Symbol) (c contract, err error) {
+	var ok bool
+
+	if c, ok = b.contracts[symbol.String()]; !ok {
+		err = fmt.Errorf("Symbol '%s' is not available on Binance futures", symbol.String())
+		return
+	}
+	return
+}
+
+func (b *BinanceFutures) toSymbol(in string) (symbol types.Symbol, err error) {
+	var ok bool
+
+	if symbol, ok = b.symbols[in]; !ok {
+		err = fmt.Errorf("Contract '%s' is not available on Binance futures", in)
+		return
+	}
+	return
+}
+
+func (b *BinanceFutures) timeframeToBinance(timeframe types.Timeframe) (interval string, err error) {
+	var index int
+
+	for index = range intervals {
+		if intervals[index].timeframe == timeframe {
+			interval = intervals[index].name
+			return
+		}
+	}
+	err = fmt.Errorf("Timeframe %s is not valid on Binance futures", timeframe.String())
+	return
+}
+
+func (b *BinanceFutures) sideToBinance(s types.Side) string {
+	if s == types.Buy {
+		return "BUY"
+	}
+	return "SELL"
+}
+
+func (b *BinanceFutures) toSide(in string) types.Side {
+	if in == "BUY" {
+		return types.Buy
+	}
+	return types.Sell
+}
+
+// orderTypeToBinance converts the order type, stop orders without limit price trigger market orders
+func (b *BinanceFutures) orderTypeToBinance(t types.OrderType) string {
+	switch t {
+	case types.Market:
+		return "MARKET"
+	case types.StopLoss:
+		return "STOP_MARKET"
+	case types.StopLossLimit:
+		return "STOP"
+	case types.TakeProfit:
+		return "TAKE_PROFIT_MARKET"
+	case types.TakeProfitLimit:
+		return "TAKE_PROFIT"
+	}
+	return "LIMIT"
+}
+
+// toOrderType converts the order type, post only limit orders are limit maker orders
+func (b *BinanceFutures) toOrderType(in string, timeInForce string) types.OrderType {
+	switch in {
+	case "MARKET":
+		return types.Market
+	case "STOP_MARKET":
+		return types.StopLoss
+	case "STOP":
+		return types.StopLossLimit
+	case "TAKE_PROFIT_MARKET":
+		return types.TakeProfit
+	case "TAKE_PROFIT":
+		return types.TakeProfitLimit
+	}
+	if timeInForce == "GTX" {
+		return types.LimitMaker
+	}
+	return types.Limit
+}
+
+// timeInForceToBinance converts the time in force, limit maker orders are post only (GTX)
+func (b *BinanceFutures) timeInForceToBinance(order types.Order) string {
+	if order.Type == types.LimitMaker {
+		return "GTX"
+	}
+	switch order.TimeInForce {
+	case types.ImmediateOrCancel:
+		return "IOC"
+	case types.FillOrCancel:
+		return "FOK"
+	}
+	return "GTC"
+}
+
+func (b *BinanceFutures) toTimeInForce(in string) types.TimeInForce {
+	switch in {
+	case "IOC":
+		return types.ImmediateOrCancel
+	case "FOK":
+		return types.FillOrCancel
+	}
+	return types.GoodTillCancel
+}
+
+func (b *BinanceFutures) toStatus(in string) types.OrderStatus {
+	switch in {
+	case "NEW":
+		return types.StatusNew
+	case "PARTIALLY_FILLED":
+		return types.StatusPartiallyFilled
+	case "FILLED":
+		return types.StatusFilled
+	case "CANCELED":
+		return types.StatusCanceled
+	case "EXPIRED", "EXPIRED_IN_MATCH":
+		return types.StatusExpired
+	}
+	return types.StatusRejected
+}
+
+func (b *BinanceFutures) marginTypeToBinance(mt types.MarginType) string {
+	if mt == types.Cross {
+		return "CROSSED"
+	}
+	return "ISOLATED"
+}
+
+func (b *BinanceFutures) toFloat(in string) (flt float64) {
+	var err error
+
+	if in == "" {
+		return
+	}
+	if flt, err = strconv.ParseFloat(in, 64); err != nil {
+		logger.Warningf("BinanceFutures::toFloat Error %v\n", err)
+		flt = math.NaN()
+	}
+	return
+}
+
+func (b *BinanceFutures) toTime(in int64) time.Time {
+	return time.Unix(in/1000, (in%1000)*int64(time.Millisecond))
+}
+
+func (b *BinanceFutures) fromTime(in time.Time) int64 {
+	return in.UnixNano() / int64(time.Millisecond)
+}
+
+// filter returns the filter of the contract with the given type
+func (b *BinanceFutures) filter(c contract, filterType string) (f filter) {
+	for _, f = range c.Filters {
+		if f.FilterType == filterType {
+			return
+		}
+	}
+	f = filter{}
+	return
+}
+
+// decimals returns the number of decimals of an increment (e.g. 0.001)
+func (b *BinanceFutures) decimals(increment string) int {
+	var index int
+
+	increment = strings.TrimRight(increment, "0")
+	if index = strings.Index(increment, "."); index < 0 {
+		return 0
+	}
+	return len(increment) - index - 1
+}
+
+// formatQuantity truncates the quantity to the step size of the contract
+func (b *BinanceFutures) formatQuantity(quantity float64, c contract) string {
+	var stepSize string
+	var decimals int
+	var factor float64
+
+	decimals = c.QuantityPrecision
+	if stepSize = b.filter(c, "LOT_SIZE").StepSize; stepSize != "" {
+		decimals = b.decimals(stepSize)
+	}
+	factor = math.Pow(10, float64(decimals))
+	return strconv.FormatFloat(math.Floor(quantity*factor+1e-9)/factor, 'f', decimals, 64)
+}
+
+// formatPrice clamps the price to the tick size of the symbol,
+// without symbol info the price is rounded to the price precision of the contract
+func (b *BinanceFutures) formatPrice(price float64, symbolInfo *types.SymbolInfo, c contract) (strPrice string, err error) {
+	if symbolInfo != nil {
+		strPrice, err = symbolInfo.ClampPrice(price)
+		return
+	}
+	strPrice = strconv.FormatFloat(price, 'f', c.PricePrecision, 64)
+	return
+}
+
+// toOrderInfo converts an order entry into an order info
+// The price of market orders is their average fill price, the transaction time their last update.
+func (b *BinanceFutures) toOrderInfo(entry orderEntry) (info types.OrderInfo, err error) {
+	if info.UserReference, err = uuid.Parse(entry.ClientOrderID); err != nil {
+		return
+	}
+	if info.Symbol, err = b.toSymbol(entry.Symbol); err != nil {
+		return
+	}
+
+	info.ExchangeOrderID = entry.OrderID
+	info.TransactionTime = b.toTime(entry.UpdateTime)
+	if entry.UpdateTime == 0 {
+		info.TransactionTime = b.toTime(entry.Time)
+	}
+	info.OriginalQuantity = b.toFloat(entry.OrigQty)
+	info.ExecutedQuantity = b.toFloat(entry.ExecutedQty)
+	info.Price = b.toFloat(entry.Price)
+	if info.Price == 0.0 {
+		info.Price = b.toFloat(entry.AvgPrice)
+	}
+	info.StopPrice = b.toFloat(entry.StopPrice)
+	info.Status = b.toStatus(entry.Status)
+	info.TimeInForce = b.toTimeInForce(entry.TimeInForce)
+	info.OrderType = b.toOrderType(entry.Type, entry.TimeInForce)
+	info.Side = b.toSide(entry.Side)
+	return
+}
+
+// toTrade converts an account trade into a trade
+func (b *BinanceFutures) toTrade(t userTrade) (trade types.Trade, err error) {
+	var symbol types.Symbol
+
+	if symbol, err = b.toSymbol(t.Symbol); err != nil {
+		return
+	}
+	trade = types.NewTrade(
+		symbol,
+		t.ID,
+		t.OrderID,
+		b.toFloat(t.Price),
+		b.toFloat(t.Qty),
+		b.toFloat(t.QuoteQty),
+		b.toFloat(t.Commission),
+		t.CommissionAsset,
+		b.toTime(t.Time),
+		t.Buyer,
+		t.Maker,
+		true,
+	)
+	return
+}
@@ -0,0 +1,40 @@
+package binancefutures
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// commissionContract is the contract the commission rates of the account are requested for
+const commissionContract = "BTCUSDT"
+
+// GetAccountInfo executes the get account info request
+// The free balance of a margin asset is the balance available for new positions, the locked balance
+// the remainder of the wallet balance. The commissions are the rates of the BTCUSDT contract.
+func (b *BinanceFutures) GetAccountInfo(ctx context.Context) (info types.AccountInfo, err error) {
+	var response accountResult
+	var asset accountAsset
+	var available float64
+	var commission commissionRateResult
+
+	if err = b.privateRequest(ctx, http.MethodGet, "/fapi/v2/account", nil, &response); err != nil {
+		logger.Errorf("BinanceFutures::GetAccountInfo Error: %v\n", err)
+		return
+	}
+	for _, asset = range response.Assets {
+		available = b.toFloat(asset.AvailableBalance)
+		info.Balances = append(info.Balances, types.NewAccountBalance(asset.Asset, available, b.toFloat(asset.WalletBalance)-available))
+	}
+
+	if err = b.privateRequest(ctx, http.MethodGet, "/fapi/v1/commissionRate", url.Values{"symbol": {commissionContract}}, &commission); err != nil {
+		logger.Errorf("BinanceFutures::GetAccountInfo Error: %v\n", err)
+		return
+	}
+	info.MakerCommission = b.toFloat(commission.MakerCommissionRate)
+	info.TakerCommission = b.toFloat(commission.TakerCommissionRate)
+	return
+}
@@ -0,0 +1,50 @@
+package binancefutures
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// GetOrder executes the get order request
+func (b *BinanceFutures) GetOrder(ctx context.Context, order types.Order) (info types.OrderInfo, err error) {
+	var c contract
+	var entry orderEntry
+
+	if c, err = b.symbolToBinance(order.Symbol); err != nil {
+		logger.Errorf("BinanceFutures::GetOrder Error %v\n", err)
+		return
+	}
+
+	if entry, err = b.getOrder(ctx, c, order.UserReference.String()); err != nil {
+		logger.Errorf("BinanceFutures::GetOrder Error %v\n", err)
+		return
+	}
+
+	if info, err = b.toOrderInfo(entry); err != nil {
+		logger.Errorf("BinanceFutures::GetOrder Error %v\n", err)
+		return
+	}
+	return
+}
+
+// getOrder executes the query order request for a client order id
+func (b *BinanceFutures) getOrder(ctx context.Context, c contract, clientOrderID string) (entry orderEntry, err error) {
+	var httpErr *exchange.HTTPError
+	var apiErr *apiError
+	var ok bool
+
+	if err = b.privateRequest(ctx, http.MethodGet, "/fapi/v1/order", url.Values{"symbol": {c.Symbol}, "origClientOrderId": {clientOrderID}}, &entry); err != nil {
+		if httpErr, ok = err.(*exchange.HTTPError); ok {
+			if apiErr, ok = httpErr.Err.(*apiError); ok && apiErr.Code == codeUnknownOrder {
+				err = exchange.NewOrderNotFoundError(clientOrderID)
+			}
+		}
+		return
+	}
+	return
+}
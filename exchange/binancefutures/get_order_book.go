@@ -0,0 +1,44 @@
+package binancefutures
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// depthLimit is the number of price levels requested per side of the order book
+const depthLimit = "100"
+
+// GetOrderBook executes the get orderbook request
+func (b *BinanceFutures) GetOrderBook(ctx context.Context, symbol types.Symbol) (book types.OrderBook, err error) {
+	var c contract
+	var response depthResult
+
+	if c, err = b.symbolToBinance(symbol); err != nil {
+		logger.Errorf("BinanceFutures::GetOrderBook Error: %v\n", err)
+		return
+	}
+
+	if err = b.publicRequest(ctx, "/fapi/v1/depth", url.Values{"symbol": {c.Symbol}, "limit": {depthLimit}}, &response); err != nil {
+		logger.Errorf("BinanceFutures::GetOrderBook Error: %v\n", err)
+		return
+	}
+
+	book = types.NewOrderBook(symbol, b.toBookEntries(response.Bids), b.toBookEntries(response.Asks))
+	return
+}
+
+func (b *BinanceFutures) toBookEntries(levels [][]string) (entries []types.OrderBookEntry) {
+	var level []string
+
+	entries = make([]types.OrderBookEntry, 0, len(levels))
+	for _, level = range levels {
+		if len(level) < 2 {
+			continue
+		}
+		entries = append(entries, types.NewOrderBookEntry(b.toFloat(level[0]), b.toFloat(level[1])))
+	}
+	return
+}
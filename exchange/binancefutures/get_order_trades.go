@@ -0,0 +1,62 @@
+package binancefutures
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// GetOrderTrades executes the get order trades request
+func (b *BinanceFutures) GetOrderTrades(ctx context.Context, orderInfo types.OrderInfo) (trades []types.Trade, err error) {
+	var c contract
+	var userTrades []userTrade
+	var t userTrade
+	var trade types.Trade
+
+	if c, err = b.symbolToBinance(orderInfo.Symbol); err != nil {
+		logger.Errorf("BinanceFutures::GetOrderTrades Error %v\n", err)
+		return
+	}
+
+	if userTrades, err = b.listTrades(ctx, c, orderInfo.ExchangeOrderID); err != nil {
+		logger.Errorf("BinanceFutures::GetOrderTrades Error %v\n", err)
+		return
+	}
+
+	trades = make([]types.Trade, 0, len(userTrades))
+	for _, t = range userTrades {
+		if trade, err = b.toTrade(t); err != nil {
+			logger.Errorf("BinanceFutures::GetOrderTrades Error %v\n", err)
+			return
+		}
+		trades = append(trades, trade)
+	}
+	return
+}
+
+// listTrades executes the account trade list request of the order
+func (b *BinanceFutures) listTrades(ctx context.Context, c contract, orderID int64) (userTrades []userTrade, err error) {
+	var params url.Values
+
+	params = url.Values{"symbol": {c.Symbol}, "orderId": {strconv.FormatInt(orderID, 10)}}
+	err = b.privateRequest(ctx, http.MethodGet, "/fapi/v1/userTrades", params, &userTrades)
+	return
+}
+
+// orderFills returns the fills of the order
+func (b *BinanceFutures) orderFills(ctx context.Context, c contract, orderID int64) (fills []types.OrderFill, err error) {
+	var userTrades []userTrade
+	var t userTrade
+
+	if userTrades, err = b.listTrades(ctx, c, orderID); err != nil {
+		return
+	}
+	for _, t = range userTrades {
+		fills = append(fills, types.NewOrderFill(b.toFloat(t.Price), b.toFloat(t.Qty), b.toFloat(t.Commission), t.CommissionAsset))
+	}
+	return
+}
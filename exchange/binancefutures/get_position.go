@@ -0,0 +1,59 @@
+package binancefutures
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// GetPosition executes the position information request
+// In the one-way position mode the sign of the position amount is the side of the position.
+func (b *BinanceFutures) GetPosition(ctx context.Context, symbol types.Symbol) (position types.Position, err error) {
+	var c contract
+	var response []positionRisk
+	var risk positionRisk
+	var amount float64
+
+	if c, err = b.symbolToBinance(symbol); err != nil {
+		logger.Errorf("BinanceFutures::GetPosition Error %v\n", err)
+		return
+	}
+
+	if err = b.privateRequest(ctx, http.MethodGet, "/fapi/v2/positionRisk", url.Values{"symbol": {c.Symbol}}, &response); err != nil {
+		logger.Errorf("BinanceFutures::GetPosition Error %v\n", err)
+		return
+	}
+
+	position.Symbol = symbol
+	for _, risk = range response {
+		if risk.Symbol != c.Symbol {
+			continue
+		}
+		amount = b.toFloat(risk.PositionAmt)
+		if amount < 0.0 {
+			position.Side = types.Short
+		}
+		position.Quantity = math.Abs(amount)
+		position.EntryPrice = b.toFloat(risk.EntryPrice)
+		position.MarkPrice = b.toFloat(risk.MarkPrice)
+		position.UnrealizedProfit = b.toFloat(risk.UnRealizedProfit)
+		position.LiquidationPrice = b.toFloat(risk.LiquidationPrice)
+		if position.Leverage, err = strconv.Atoi(risk.Leverage); err != nil {
+			logger.Errorf("BinanceFutures::GetPosition Error %v\n", err)
+			return
+		}
+		if position.MarginType, err = types.NewMarginTypeFromString(risk.MarginType); err != nil {
+			logger.Errorf("BinanceFutures::GetPosition Error %v\n", err)
+			return
+		}
+		if position.Quantity > 0.0 {
+			break
+		}
+	}
+	return
+}
@@ -0,0 +1,122 @@
+package binancefutures
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	// defaultKlines is the number of candles returned by the get series request
+	defaultKlines = 500
+
+	// maxKlines is the max number of candles returned by a single klines request
+	maxKlines = 1500
+)
+
+// GetSeries executes the get series request
+func (b *BinanceFutures) GetSeries(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe) (series types.Series, err error) {
+	var params url.Values
+
+	params = url.Values{"limit": {strconv.Itoa(defaultKlines)}}
+	if series, err = b.getKlines(ctx, symbol, timeframe, params); err != nil {
+		logger.Errorf("BinanceFutures::GetSeries Error: %v\n", err)
+		return
+	}
+	return
+}
+
+// GetSeriesRange executes the get series request for the candles opened between start and end
+// At most 1500 candles are returned per request.
+func (b *BinanceFutures) GetSeriesRange(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe, start time.Time, end time.Time) (series types.Series, err error) {
+	var params url.Values
+
+	params = url.Values{}
+	params.Set("limit", strconv.Itoa(maxKlines))
+	params.Set("startTime", strconv.FormatInt(b.fromTime(start), 10))
+	params.Set("endTime", strconv.FormatInt(b.fromTime(end), 10))
+	if series, err = b.getKlines(ctx, symbol, timeframe, params); err != nil {
+		logger.Errorf("BinanceFutures::GetSeriesRange Error: %v\n", err)
+		return
+	}
+	return
+}
+
+// getKlines executes the klines request, the candles are returned oldest first
+// Every kline is an array starting with the open time, open, high, low, close, volume and close time.
+func (b *BinanceFutures) getKlines(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe, params url.Values) (series types.Series, err error) {
+	var c contract
+	var interval string
+	var response [][]interface{}
+	var kline []interface{}
+	var ohlc []types.OHLC
+	var values [5]float64
+	var openTime, closeTime float64
+	var index int
+	var ok bool
+
+	if c, err = b.symbolToBinance(symbol); err != nil {
+		return
+	}
+
+	if interval, err = b.timeframeToBinance(timeframe); err != nil {
+		return
+	}
+
+	params.Set("symbol", c.Symbol)
+	params.Set("interval", interval)
+	if err = b.publicRequest(ctx, "/fapi/v1/klines", params, &response); err != nil {
+		return
+	}
+
+	ohlc = make([]types.OHLC, 0, len(response))
+	for _, kline = range response {
+		if len(kline) < 7 {
+			err = fmt.Errorf("Invalid kline: %v", kline)
+			return
+		}
+		if openTime, ok = kline[0].(float64); !ok {
+			err = fmt.Errorf("Invalid kline open time: %v", kline[0])
+			return
+		}
+		if closeTime, ok = kline[6].(float64); !ok {
+			err = fmt.Errorf("Invalid kline close time: %v", kline[6])
+			return
+		}
+		for index = range values {
+			if values[index], err = b.klineValue(kline[index+1]); err != nil {
+				return
+			}
+		}
+		ohlc = append(ohlc, types.NewOHLC(
+			values[0],
+			values[1],
+			values[2],
+			values[3],
+			values[4],
+			b.toTime(int64(openTime)),
+			b.toTime(int64(closeTime)),
+		))
+	}
+
+	series = types.NewSeries(symbol, timeframe, ohlc)
+	return
+}
+
+// klineValue converts a price or volume of a kline, these are sent as strings
+func (b *BinanceFutures) klineValue(in interface{}) (value float64, err error) {
+	var str string
+	var ok bool
+
+	if str, ok = in.(string); !ok {
+		err = fmt.Errorf("Invalid kline value: %v", in)
+		return
+	}
+	value, err = strconv.ParseFloat(str, 64)
+	return
+}
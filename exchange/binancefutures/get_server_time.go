@@ -0,0 +1,20 @@
+package binancefutures
+
+import (
+	"context"
+	"time"
+
+	"github.com/mhereman/cryptotrader/logger"
+)
+
+// GetServerTime executes the get server time request
+func (b *BinanceFutures) GetServerTime(ctx context.Context) (serverTime time.Time, err error) {
+	var response timeResult
+
+	if err = b.publicRequest(ctx, "/fapi/v1/time", nil, &response); err != nil {
+		logger.Errorf("BinanceFutures::GetServerTime Error: %v\n", err)
+		return
+	}
+	serverTime = b.toTime(response.ServerTime)
+	return
+}
@@ -0,0 +1,35 @@
+package binancefutures
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// GetSymbolInfo retrieves the symbol information for trading
+// The price increment is the tick size of the contract, or the smallest price of its precision.
+func (b *BinanceFutures) GetSymbolInfo(ctx context.Context, symbol types.Symbol) (info types.SymbolInfo, err error) {
+	var c contract
+	var minPrice string
+
+	if c, err = b.symbolToBinance(symbol); err != nil {
+		logger.Errorf("BinanceFutures::GetSymbolInfo Error %v\n", err)
+		return
+	}
+
+	minPrice = b.filter(c, "PRICE_FILTER").TickSize
+	if b.toFloat(minPrice) <= 0.0 {
+		minPrice = strconv.FormatFloat(math.Pow(10, -float64(c.PricePrecision)), 'f', c.PricePrecision, 64)
+	}
+
+	// The symbol info expects the price increment to contain a decimal point
+	if !strings.Contains(minPrice, ".") {
+		minPrice += ".0"
+	}
+	info = types.NewSymbolInfo(symbol, minPrice, b.filter(c, "LOT_SIZE").MinQty)
+	return
+}
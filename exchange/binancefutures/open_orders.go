@@ -0,0 +1,40 @@
+package binancefutures
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// OpenOrders executes the open orders request
+// Orders placed without a client order id in UUID format (e.g. on the website) are skipped.
+func (b *BinanceFutures) OpenOrders(ctx context.Context, symbol types.Symbol) (orders []types.OrderInfo, err error) {
+	var c contract
+	var response []orderEntry
+	var entry orderEntry
+	var info types.OrderInfo
+
+	if c, err = b.symbolToBinance(symbol); err != nil {
+		logger.Errorf("BinanceFutures::OpenOrders Error %v\n", err)
+		return
+	}
+
+	if err = b.privateRequest(ctx, http.MethodGet, "/fapi/v1/openOrders", url.Values{"symbol": {c.Symbol}}, &response); err != nil {
+		logger.Errorf("BinanceFutures::OpenOrders Error %v\n", err)
+		return
+	}
+
+	orders = make([]types.OrderInfo, 0, len(response))
+	for _, entry = range response {
+		if info, err = b.toOrderInfo(entry); err != nil {
+			logger.Debugf("BinanceFutures::OpenOrders Skipping order %d: %v\n", entry.OrderID, err)
+			err = nil
+			continue
+		}
+		orders = append(orders, info)
+	}
+	return
+}
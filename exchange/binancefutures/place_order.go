@@ -0,0 +1,111 @@
+package binancefutures
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	// fillPollInterval interval between the requests of the state of an immediate order
+	fillPollInterval = time.Millisecond * 500
+
+	// fillPollAttempts max number of requests of the state of an immediate order
+	fillPollAttempts = 10
+)
+
+// PlaceOrder executes the place order request
+// Reduce only orders can only decrease the position. Market, immediate or cancel and fill or kill orders
+// are followed up until they are done to report their fills.
+func (b *BinanceFutures) PlaceOrder(ctx context.Context, order types.Order, symbolInfo *types.SymbolInfo) (info types.OrderInfo, err error) {
+	var c contract
+	var params url.Values
+	var entry orderEntry
+	var price, stopPrice string
+	var attempt int
+
+	if c, err = b.symbolToBinance(order.Symbol); err != nil {
+		logger.Errorf("BinanceFutures::PlaceOrder Error %v\n", err)
+		return
+	}
+
+	params = url.Values{}
+	params.Set("symbol", c.Symbol)
+	params.Set("side", b.sideToBinance(order.Side))
+	params.Set("type", b.orderTypeToBinance(order.Type))
+	params.Set("quantity", b.formatQuantity(order.Quantity, c))
+	params.Set("newClientOrderId", order.UserReference.String())
+	params.Set("newOrderRespType", "RESULT")
+	if order.ReduceOnly {
+		params.Set("reduceOnly", "true")
+	}
+
+	switch order.Type {
+	case types.Limit, types.LimitMaker, types.StopLossLimit, types.TakeProfitLimit:
+		if price, err = b.formatPrice(order.Price, symbolInfo, c); err != nil {
+			logger.Errorf("BinanceFutures::PlaceOrder Error %v\n", err)
+			return
+		}
+		params.Set("price", price)
+		params.Set("timeInForce", b.timeInForceToBinance(order))
+	}
+	switch order.Type {
+	case types.StopLoss, types.StopLossLimit, types.TakeProfit, types.TakeProfitLimit:
+		if stopPrice, err = b.formatPrice(order.StopPrice, symbolInfo, c); err != nil {
+			logger.Errorf("BinanceFutures::PlaceOrder Error %v\n", err)
+			return
+		}
+		params.Set("stopPrice", stopPrice)
+	}
+
+	if err = b.privateRequest(ctx, http.MethodPost, "/fapi/v1/order", params, &entry); err != nil {
+		logger.Errorf("BinanceFutures::PlaceOrder Error %v\n", err)
+		return
+	}
+
+	// Market orders are matched asynchronously, the response may not contain the fills yet
+	for attempt = 0; b.isImmediate(order) && !b.isFinal(entry.Status) && attempt < fillPollAttempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-time.After(fillPollInterval):
+		}
+
+		if entry, err = b.getOrder(ctx, c, order.UserReference.String()); err != nil {
+			logger.Errorf("BinanceFutures::PlaceOrder Error %v\n", err)
+			return
+		}
+	}
+
+	if info, err = b.toOrderInfo(entry); err != nil {
+		logger.Errorf("BinanceFutures::PlaceOrder Error %v\n", err)
+		return
+	}
+
+	if info.ExecutedQuantity > 0.0 {
+		if info.Fills, err = b.orderFills(ctx, c, entry.OrderID); err != nil {
+			logger.Errorf("BinanceFutures::PlaceOrder Error %v\n", err)
+			return
+		}
+	}
+	return
+}
+
+// isImmediate returns true if the order is expected to be done right after being placed
+func (b *BinanceFutures) isImmediate(order types.Order) bool {
+	return order.Type == types.Market || (order.Type == types.Limit && order.TimeInForce != types.GoodTillCancel)
+}
+
+// isFinal returns true if the order status does not change anymore
+func (b *BinanceFutures) isFinal(status string) bool {
+	switch status {
+	case "FILLED", "CANCELED", "EXPIRED", "EXPIRED_IN_MATCH", "REJECTED":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package binancefutures
+
+import (
+	"time"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	// weightBucket is the request weight limit of the IP address
+	weightBucket = "REQUEST_WEIGHT"
+
+	// ordersBucket is the order rate limit of the account
+	ordersBucket = "ORDERS"
+)
+
+// RateLimits returns the request limits of the Binance USDⓈ-M futures API
+// The weights are the weights of the endpoints called by the driver methods, e.g. placing an order
+// also queries the order and its trades.
+func (b *BinanceFutures) RateLimits() types.RateLimits {
+	return types.RateLimits{
+		Buckets: map[string]types.RateLimit{
+			weightBucket: types.NewRateLimit(2400, time.Minute),
+			ordersBucket: types.NewRateLimit(300, time.Second*10),
+		},
+		Weights: map[string]map[string]int{
+			"GetAccountInfo": {weightBucket: 25},
+			"GetOrderBook":   {weightBucket: 5},
+			"GetSeries":      {weightBucket: 2},
+			"GetSeriesRange": {weightBucket: 10},
+			"GetSymbolInfo":  {},
+			"PlaceOrder":     {weightBucket: 6, ordersBucket: 1},
+			"GetOrderTrades": {weightBucket: 5},
+			"GetPosition":    {weightBucket: 5},
+		},
+		DefaultBucket: weightBucket,
+	}
+}
@@ -0,0 +1,111 @@
+package binancefutures
+
+// filter represents a trading rule of a contract, only the fields of the used filters are decoded
+type filter struct {
+	FilterType string `json:"filterType"`
+	TickSize   string `json:"tickSize"`
+	StepSize   string `json:"stepSize"`
+	MinQty     string `json:"minQty"`
+}
+
+// contract represents a contract of the exchange info response
+type contract struct {
+	Symbol            string   `json:"symbol"`
+	ContractType      string   `json:"contractType"`
+	Status            string   `json:"status"`
+	BaseAsset         string   `json:"baseAsset"`
+	QuoteAsset        string   `json:"quoteAsset"`
+	MarginAsset       string   `json:"marginAsset"`
+	PricePrecision    int      `json:"pricePrecision"`
+	QuantityPrecision int      `json:"quantityPrecision"`
+	Filters           []filter `json:"filters"`
+}
+
+// exchangeInfo represents the exchange info response
+type exchangeInfo struct {
+	Symbols []contract `json:"symbols"`
+}
+
+// timeResult represents the server time response
+type timeResult struct {
+	ServerTime int64 `json:"serverTime"`
+}
+
+// tickerResult represents the symbol price ticker response
+type tickerResult struct {
+	Symbol string `json:"symbol"`
+	Price  string `json:"price"`
+}
+
+// depthResult represents the order book response, the entries are [price, quantity] pairs
+type depthResult struct {
+	Bids [][]string `json:"bids"`
+	Asks [][]string `json:"asks"`
+}
+
+// accountAsset represents the balance of a margin asset
+type accountAsset struct {
+	Asset            string `json:"asset"`
+	WalletBalance    string `json:"walletBalance"`
+	AvailableBalance string `json:"availableBalance"`
+}
+
+// accountResult represents the account information response
+type accountResult struct {
+	Assets []accountAsset `json:"assets"`
+}
+
+// commissionRateResult represents the user commission rate response
+type commissionRateResult struct {
+	Symbol              string `json:"symbol"`
+	MakerCommissionRate string `json:"makerCommissionRate"`
+	TakerCommissionRate string `json:"takerCommissionRate"`
+}
+
+// orderEntry represents an order of the order responses
+type orderEntry struct {
+	OrderID       int64  `json:"orderId"`
+	ClientOrderID string `json:"clientOrderId"`
+	Symbol        string `json:"symbol"`
+	Status        string `json:"status"`
+	Price         string `json:"price"`
+	AvgPrice      string `json:"avgPrice"`
+	OrigQty       string `json:"origQty"`
+	ExecutedQty   string `json:"executedQty"`
+	TimeInForce   string `json:"timeInForce"`
+	Type          string `json:"type"`
+	Side          string `json:"side"`
+	StopPrice     string `json:"stopPrice"`
+	ReduceOnly    bool   `json:"reduceOnly"`
+	Time          int64  `json:"time"`
+	UpdateTime    int64  `json:"updateTime"`
+}
+
+// userTrade represents a trade of the account trade list response
+type userTrade struct {
+	ID              int64  `json:"id"`
+	OrderID         int64  `json:"orderId"`
+	Symbol          string `json:"symbol"`
+	Side            string `json:"side"`
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	QuoteQty        string `json:"quoteQty"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+	Buyer           bool   `json:"buyer"`
+	Maker           bool   `json:"maker"`
+	Time            int64  `json:"time"`
+}
+
+// positionRisk represents a position of the position information response
+type positionRisk struct {
+	Symbol           string `json:"symbol"`
+	PositionAmt      string `json:"positionAmt"`
+	EntryPrice       string `json:"entryPrice"`
+	MarkPrice        string `json:"markPrice"`
+	UnRealizedProfit string `json:"unRealizedProfit"`
+	LiquidationPrice string `json:"liquidationPrice"`
+	Leverage         string `json:"leverage"`
+	MarginType       string `json:"marginType"`
+	PositionSide     string `json:"positionSide"`
+}
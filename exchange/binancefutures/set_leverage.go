@@ -0,0 +1,27 @@
+package binancefutures
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// SetLeverage executes the change initial leverage request
+func (b *BinanceFutures) SetLeverage(ctx context.Context, symbol types.Symbol, leverage int) (err error) {
+	var c contract
+
+	if c, err = b.symbolToBinance(symbol); err != nil {
+		logger.Errorf("BinanceFutures::SetLeverage Error %v\n", err)
+		return
+	}
+
+	if err = b.privateRequest(ctx, http.MethodPost, "/fapi/v1/leverage", url.Values{"symbol": {c.Symbol}, "leverage": {strconv.Itoa(leverage)}}, nil); err != nil {
+		logger.Errorf("BinanceFutures::SetLeverage Error %v\n", err)
+		return
+	}
+	return
+}
@@ -0,0 +1,40 @@
+package binancefutures
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// codeMarginTypeUnchanged is the error code returned if the margin type is already set
+const codeMarginTypeUnchanged = -4046
+
+// SetMarginType executes the change margin type request
+// Setting the margin type the contract already has is not an error.
+func (b *BinanceFutures) SetMarginType(ctx context.Context, symbol types.Symbol, marginType types.MarginType) (err error) {
+	var c contract
+	var httpErr *exchange.HTTPError
+	var apiErr *apiError
+	var ok bool
+
+	if c, err = b.symbolToBinance(symbol); err != nil {
+		logger.Errorf("BinanceFutures::SetMarginType Error %v\n", err)
+		return
+	}
+
+	if err = b.privateRequest(ctx, http.MethodPost, "/fapi/v1/marginType", url.Values{"symbol": {c.Symbol}, "marginType": {b.marginTypeToBinance(marginType)}}, nil); err != nil {
+		if httpErr, ok = err.(*exchange.HTTPError); ok {
+			if apiErr, ok = httpErr.Err.(*apiError); ok && apiErr.Code == codeMarginTypeUnchanged {
+				err = nil
+				return
+			}
+		}
+		logger.Errorf("BinanceFutures::SetMarginType Error %v\n", err)
+		return
+	}
+	return
+}
@@ -0,0 +1,17 @@
+package binancefutures
+
+import (
+	"context"
+
+	"github.com/mhereman/cryptotrader/logger"
+)
+
+// TestConnectivity tests exchange connectivity
+func (b *BinanceFutures) TestConnectivity(ctx context.Context) (ok bool, err error) {
+	if err = b.publicRequest(ctx, "/fapi/v1/ping", nil, nil); err != nil {
+		logger.Errorf("BinanceFutures::TestConnectivity Error: %v\n", err)
+		return
+	}
+	ok = true
+	return
+}
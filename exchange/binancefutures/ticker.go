@@ -0,0 +1,28 @@
+package binancefutures
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// Ticker executes the ticker request
+func (b *BinanceFutures) Ticker(ctx context.Context, symbol types.Symbol) (price float64, err error) {
+	var c contract
+	var response tickerResult
+
+	if c, err = b.symbolToBinance(symbol); err != nil {
+		logger.Errorf("BinanceFutures::Ticker Error: %v\n", err)
+		return
+	}
+
+	if err = b.publicRequest(ctx, "/fapi/v1/ticker/price", url.Values{"symbol": {c.Symbol}}, &response); err != nil {
+		logger.Errorf("BinanceFutures::Ticker Error: %v\n", err)
+		return
+	}
+
+	price = b.toFloat(response.Price)
+	return
+}
@@ -0,0 +1,53 @@
+package exchange
+
+import "github.com/mhereman/cryptotrader/interfaces"
+
+// The Limiter implements every optional exchange interface, the functions below look through it
+// to tell whether the wrapped plugin supports the feature.
+
+// unwrap returns the plugin wrapped by a Limiter
+func unwrap(driver interfaces.IExchangeDriver) interfaces.IExchangeDriver {
+	var limiter *Limiter
+	var ok bool
+
+	if limiter, ok = driver.(*Limiter); ok {
+		return limiter.driver
+	}
+	return driver
+}
+
+// OCODriver returns the driver as one-cancels-other driver, if the plugin supports one-cancels-other orders
+func OCODriver(driver interfaces.IExchangeDriver) (oco interfaces.IOCOExchangeDriver, ok bool) {
+	if _, ok = unwrap(driver).(interfaces.IOCOExchangeDriver); !ok {
+		return
+	}
+	oco, ok = driver.(interfaces.IOCOExchangeDriver)
+	return
+}
+
+// HistoricalDriver returns the driver as historical driver, if the plugin supports downloading candles
+func HistoricalDriver(driver interfaces.IExchangeDriver) (historical interfaces.IHistoricalExchangeDriver, ok bool) {
+	if _, ok = unwrap(driver).(interfaces.IHistoricalExchangeDriver); !ok {
+		return
+	}
+	historical, ok = driver.(interfaces.IHistoricalExchangeDriver)
+	return
+}
+
+// StreamingDriver returns the driver as streaming driver, if the plugin supports streaming candles
+func StreamingDriver(driver interfaces.IExchangeDriver) (streaming interfaces.IStreamingExchangeDriver, ok bool) {
+	if _, ok = unwrap(driver).(interfaces.IStreamingExchangeDriver); !ok {
+		return
+	}
+	streaming, ok = driver.(interfaces.IStreamingExchangeDriver)
+	return
+}
+
+// FuturesDriver returns the driver as futures driver, if the plugin trades futures
+func FuturesDriver(driver interfaces.IExchangeDriver) (futures interfaces.IFuturesExchangeDriver, ok bool) {
+	if _, ok = unwrap(driver).(interfaces.IFuturesExchangeDriver); !ok {
+		return
+	}
+	futures, ok = driver.(interfaces.IFuturesExchangeDriver)
+	return
+}
@@ -0,0 +1,145 @@
+package exchange
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// circuitBreaker stops sending requests after consecutive failures, until the exchange is expected to recover
+// When the cooldown expires a single request tests the exchange, its success closes the circuit again.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	state     types.CircuitState
+	failures  int
+	openUntil time.Time
+	probing   bool
+	listeners []func(types.CircuitState)
+	mux       sync.Mutex
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow returns an error if the request may not be sent
+func (cb *circuitBreaker) allow(now time.Time) (err error) {
+	var changed bool
+
+	cb.mux.Lock()
+	switch cb.state {
+	case types.CircuitOpen:
+		if now.Before(cb.openUntil) {
+			err = fmt.Errorf("Circuit breaker open until %s", cb.openUntil.Format(time.RFC3339))
+			break
+		}
+		changed = cb.setState(types.CircuitHalfOpen)
+		cb.probing = true
+	case types.CircuitHalfOpen:
+		if cb.probing {
+			err = fmt.Errorf("Circuit breaker half-open, waiting for a test request")
+			break
+		}
+		cb.probing = true
+	}
+	cb.mux.Unlock()
+
+	if changed {
+		cb.notify(types.CircuitHalfOpen)
+	}
+	return
+}
+
+// cancel releases the test request of a half-open circuit, the request was not sent
+func (cb *circuitBreaker) cancel() {
+	cb.mux.Lock()
+	defer cb.mux.Unlock()
+
+	cb.probing = false
+}
+
+// success registers a request answered by the exchange, it closes the circuit
+func (cb *circuitBreaker) success() {
+	var changed bool
+
+	cb.mux.Lock()
+	cb.failures = 0
+	cb.probing = false
+	changed = cb.setState(types.CircuitClosed)
+	cb.mux.Unlock()
+
+	if changed {
+		cb.notify(types.CircuitClosed)
+	}
+}
+
+// failure registers a failed request
+// The circuit opens after threshold consecutive failures, if the test request fails or if the exchange
+// refuses requests until a given time (e.g. an IP ban).
+func (cb *circuitBreaker) failure(now time.Time, until time.Time) {
+	var changed bool
+
+	if cb.threshold <= 0 {
+		return
+	}
+
+	cb.mux.Lock()
+	cb.failures++
+	cb.probing = false
+	if cb.state == types.CircuitHalfOpen || cb.failures >= cb.threshold || !until.IsZero() {
+		if until.Before(now.Add(cb.cooldown)) {
+			until = now.Add(cb.cooldown)
+		}
+		if until.After(cb.openUntil) {
+			cb.openUntil = until
+		}
+		changed = cb.setState(types.CircuitOpen)
+	}
+	cb.mux.Unlock()
+
+	if changed {
+		cb.notify(types.CircuitOpen)
+	}
+}
+
+// currentState returns the state of the circuit
+func (cb *circuitBreaker) currentState() types.CircuitState {
+	cb.mux.Lock()
+	defer cb.mux.Unlock()
+
+	return cb.state
+}
+
+// addListener registers a function called on every state change
+func (cb *circuitBreaker) addListener(fn func(types.CircuitState)) {
+	cb.mux.Lock()
+	defer cb.mux.Unlock()
+
+	cb.listeners = append(cb.listeners, fn)
+}
+
+func (cb *circuitBreaker) setState(state types.CircuitState) (changed bool) {
+	changed = cb.state != state
+	cb.state = state
+	return
+}
+
+func (cb *circuitBreaker) notify(state types.CircuitState) {
+	var listeners []func(types.CircuitState)
+	var fn func(types.CircuitState)
+
+	cb.mux.Lock()
+	listeners = cb.listeners
+	cb.mux.Unlock()
+
+	for _, fn = range listeners {
+		fn(state)
+	}
+}
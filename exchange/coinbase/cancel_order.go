@@ -0,0 +1,43 @@
+package coinbase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// CancelOrder executes the cancel order request
+func (c *Coinbase) CancelOrder(ctx context.Context, order types.Order, newUUID uuid.UUID) (info types.OrderInfo, err error) {
+	var entry orderEntry
+	var response cancelOrdersResult
+
+	if entry, err = c.findOrder(ctx, order.Symbol, order.UserReference); err != nil {
+		logger.Errorf("Coinbase::CancelOrder Error %v\n", err)
+		return
+	}
+
+	if err = c.privateRequest(ctx, http.MethodPost, "/orders/batch_cancel", nil, cancelOrdersRequest{OrderIDs: []string{entry.OrderID}}, &response); err != nil {
+		logger.Errorf("Coinbase::CancelOrder Error %v\n", err)
+		return
+	}
+	if len(response.Results) != 1 || !response.Results[0].Success {
+		err = fmt.Errorf("Order not canceled: %s %v", order.UserReference.String(), response.Results)
+		logger.Errorf("Coinbase::CancelOrder Error %v\n", err)
+		return
+	}
+
+	if entry, err = c.getOrder(ctx, entry.OrderID); err != nil {
+		logger.Errorf("Coinbase::CancelOrder Error %v\n", err)
+		return
+	}
+	if info, err = c.toOrderInfo(entry); err != nil {
+		logger.Errorf("Coinbase::CancelOrder Error %v\n", err)
+		return
+	}
+	info.CancelUserReference = newUUID
+	return
+}
@@ -0,0 +1,190 @@
+package coinbase
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mhereman/cryptotrader/exchange"
+)
+
+const (
+	// apiPath prefix of the Advanced Trade API endpoints
+	apiPath = "/api/v3/brokerage"
+
+	// jwtLifetime validity of the request tokens
+	jwtLifetime = time.Minute * 2
+)
+
+// errorResult represents the body of a failed request
+type errorResult struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// parsePrivateKey parses the PEM encoded EC private key
+// Escaped newlines are accepted, so the key can be passed on a single line.
+func parsePrivateKey(in string) (key *ecdsa.PrivateKey, err error) {
+	var block *pem.Block
+	var parsed interface{}
+	var ok bool
+
+	if block, _ = pem.Decode([]byte(strings.Replace(in, `\n`, "\n", -1))); block == nil {
+		err = fmt.Errorf("no PEM encoded key found")
+		return
+	}
+	if key, err = x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return
+	}
+	if parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		return
+	}
+	if key, ok = parsed.(*ecdsa.PrivateKey); !ok {
+		err = fmt.Errorf("not an EC private key")
+		return
+	}
+	return
+}
+
+func hostOf(baseURL string) (host string, err error) {
+	var u *url.URL
+
+	if u, err = url.Parse(baseURL); err != nil {
+		return
+	}
+	if u.Host == "" {
+		err = fmt.Errorf("no host in %s", baseURL)
+		return
+	}
+	host = u.Host
+	return
+}
+
+// publicRequest executes a GET request of a public market data endpoint
+func (c *Coinbase) publicRequest(ctx context.Context, path string, params url.Values, out interface{}) (err error) {
+	err = c.request(ctx, http.MethodGet, path, params, nil, false, out)
+	return
+}
+
+// privateRequest executes an authenticated request, the body is sent as JSON if not nil
+func (c *Coinbase) privateRequest(ctx context.Context, method string, path string, params url.Values, body interface{}, out interface{}) (err error) {
+	err = c.request(ctx, method, path, params, body, true, out)
+	return
+}
+
+func (c *Coinbase) request(ctx context.Context, method string, path string, params url.Values, body interface{}, authenticate bool, out interface{}) (err error) {
+	var request *http.Request
+	var response *http.Response
+	var requestURL, token string
+	var reader io.Reader
+	var payload, responseBody []byte
+	var errResult errorResult
+
+	requestURL = c.baseURL + apiPath + path
+	if len(params) > 0 {
+		requestURL += "?" + params.Encode()
+	}
+	if body != nil {
+		if payload, err = json.Marshal(body); err != nil {
+			return
+		}
+		reader = bytes.NewReader(payload)
+	}
+	if request, err = http.NewRequest(method, requestURL, reader); err != nil {
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if authenticate {
+		if token, err = c.token(method, apiPath+path); err != nil {
+			return
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	if response, err = c.client.Do(request.WithContext(ctx)); err != nil {
+		return
+	}
+	defer response.Body.Close()
+
+	if responseBody, err = ioutil.ReadAll(response.Body); err != nil {
+		return
+	}
+	if response.StatusCode != http.StatusOK {
+		if json.Unmarshal(responseBody, &errResult) == nil && errResult.Message != "" {
+			err = exchange.NewHTTPError(response, fmt.Errorf("%s %s: %s %s", method, path, response.Status, errResult.Message))
+			return
+		}
+		err = exchange.NewHTTPError(response, fmt.Errorf("%s %s: %s %s", method, path, response.Status, strings.TrimSpace(string(responseBody))))
+		return
+	}
+
+	if out != nil {
+		if err = json.Unmarshal(responseBody, out); err != nil {
+			err = fmt.Errorf("%s %s: invalid response %v", method, path, err)
+			return
+		}
+	}
+	return
+}
+
+// token creates the ES256 signed JWT of the request
+func (c *Coinbase) token(method string, path string) (token string, err error) {
+	var header, claims []byte
+	var nonce [16]byte
+	var now time.Time
+	var signingInput string
+	var digest [32]byte
+	var r, s *big.Int
+	var signature []byte
+
+	if _, err = rand.Read(nonce[:]); err != nil {
+		return
+	}
+	if header, err = json.Marshal(map[string]string{
+		"alg":   "ES256",
+		"typ":   "JWT",
+		"kid":   c.keyName,
+		"nonce": hex.EncodeToString(nonce[:]),
+	}); err != nil {
+		return
+	}
+
+	now = time.Now()
+	if claims, err = json.Marshal(map[string]interface{}{
+		"sub": c.keyName,
+		"iss": "cdp",
+		"nbf": now.Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"uri": fmt.Sprintf("%s %s%s", method, c.host, path),
+	}); err != nil {
+		return
+	}
+
+	signingInput = base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest = sha256.Sum256([]byte(signingInput))
+	if r, s, err = ecdsa.Sign(rand.Reader, c.privateKey, digest[:]); err != nil {
+		return
+	}
+
+	// The signature is the concatenation of the 32 byte big endian r and s values
+	signature = make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	token = signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+	return
+}
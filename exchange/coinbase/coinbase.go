@@ -0,0 +1,109 @@
+package coinbase
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+)
+
+const (
+	exchangeName   = "coinbase"
+	defaultBaseURL = "https://api.coinbase.com"
+	defaultTimeout = time.Second * 30
+)
+
+func init() {
+	exchange.RegisterExchange(exchangeName, createCoinbase)
+}
+
+// Coinbase represents the Coinbase Advanced Trade exchange plugin
+type Coinbase struct {
+	keyName    string
+	privateKey *ecdsa.PrivateKey
+	baseURL    string
+	host       string
+	client     *http.Client
+
+	// products maps the symbol strings (e.g. BTC/USD) onto the products
+	products map[string]product
+
+	// orderIDs maps the client order ids onto the exchange order ids of the orders placed by this instance
+	orderIDs    map[uuid.UUID]string
+	orderIDsMux sync.Mutex
+}
+
+// New creates a new Coinbase exchange plugin
+// The 'apiKey' is the name of the CDP API key, the 'apiSecret' its EC private key in PEM format.
+// The 'baseURL' argument replaces the Coinbase API endpoint, e.g. to run against a local test server.
+func New(ctx context.Context, config map[string]string) (driver *Coinbase, err error) {
+	var apiKey, apiSecret, baseURL string
+	var ok bool
+
+	if apiKey, ok = config["apiKey"]; !ok {
+		err = fmt.Errorf("Coinbase config error: 'apiKey' entry not found")
+		return
+	}
+
+	if apiSecret, ok = config["apiSecret"]; !ok {
+		err = fmt.Errorf("Coinbase config error: 'apiSecret' entry not found")
+		return
+	}
+
+	driver = new(Coinbase)
+	driver.keyName = apiKey
+	if driver.privateKey, err = parsePrivateKey(apiSecret); err != nil {
+		err = fmt.Errorf("Coinbase config error: invalid 'apiSecret' %v", err)
+		return
+	}
+	driver.baseURL = defaultBaseURL
+	if baseURL, ok = config["baseURL"]; ok && baseURL != "" {
+		driver.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+	if driver.host, err = hostOf(driver.baseURL); err != nil {
+		err = fmt.Errorf("Coinbase config error: invalid 'baseURL' %v", err)
+		return
+	}
+	driver.client = &http.Client{Timeout: defaultTimeout}
+	driver.orderIDs = make(map[uuid.UUID]string)
+
+	if err = driver.loadProducts(ctx); err != nil {
+		logger.Errorf("Coinbase::New Error: %v\n", err)
+		return
+	}
+	return
+}
+
+func createCoinbase(ctx context.Context, config map[string]string) (driver interfaces.IExchangeDriver, err error) {
+	driver, err = New(ctx, config)
+	return
+}
+
+// Name returns the name of the exchange plugin
+func (c *Coinbase) Name() string {
+	return exchangeName
+}
+
+// loadProducts loads the tradable products
+func (c *Coinbase) loadProducts(ctx context.Context) (err error) {
+	var response productsResult
+	var p product
+
+	if err = c.publicRequest(ctx, "/market/products", nil, &response); err != nil {
+		return
+	}
+
+	c.products = make(map[string]product)
+	for _, p = range response.Products {
+		c.products[c.toSymbolString(p.BaseCurrency, p.QuoteCurrency)] = p
+	}
+	return
+}
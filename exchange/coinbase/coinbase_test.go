@@ -0,0 +1,277 @@
+package coinbase
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const testKeyName = "organizations/test/apiKeys/test"
+
+// fakeCoinbase is a local stand-in for the Advanced Trade API
+// The private endpoints are rejected unless the request carries a valid JWT of the test key.
+type fakeCoinbase struct {
+	server    *httptest.Server
+	publicKey *ecdsa.PublicKey
+	mux       sync.Mutex
+	responses map[string]string
+	status    map[string]int
+	headers   map[string]http.Header
+	tokens    int
+}
+
+func newFakeCoinbase(t *testing.T, publicKey *ecdsa.PublicKey) (fc *fakeCoinbase) {
+	fc = &fakeCoinbase{
+		publicKey: publicKey,
+		responses: map[string]string{
+			"/market/products":         `{"products":[{"product_id":"BTC-USD","price":"30000.5","base_currency_id":"BTC","quote_currency_id":"USD","base_increment":"0.00000001","quote_increment":"0.01","price_increment":"0.01","base_min_size":"0.00001","status":"online"}]}`,
+			"/market/products/BTC-USD": `{"product_id":"BTC-USD","price":"30100.25"}`,
+			"/accounts":                `{"accounts":[{"currency":"BTC","available_balance":{"value":"1.5","currency":"BTC"},"hold":{"value":"0.5","currency":"BTC"}}],"has_next":false}`,
+			"/transaction_summary":     `{"fee_tier":{"taker_fee_rate":"0.006","maker_fee_rate":"0.004"}}`,
+		},
+		status:  map[string]int{},
+		headers: map[string]http.Header{},
+	}
+	fc.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var path, key string
+		var values []string
+		var err error
+
+		fc.mux.Lock()
+		defer fc.mux.Unlock()
+
+		path = strings.TrimPrefix(r.URL.Path, apiPath)
+		if strings.HasPrefix(path, "/market/") {
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("%s: public request carries an Authorization header", path)
+			}
+		} else {
+			if err = verifyToken(fc.publicKey, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), r.Method+" "+r.Host+r.URL.Path); err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprintf(w, `{"error":"UNAUTHENTICATED","message":"%v"}`, err)
+				return
+			}
+			fc.tokens++
+		}
+
+		for key, values = range fc.headers[path] {
+			w.Header()[key] = values
+		}
+		if fc.status[path] != 0 {
+			w.WriteHeader(fc.status[path])
+		}
+		fmt.Fprint(w, fc.responses[path])
+	}))
+	return
+}
+
+func (fc *fakeCoinbase) respond(path string, status int, header http.Header, response string) {
+	fc.mux.Lock()
+	defer fc.mux.Unlock()
+
+	fc.status[path] = status
+	fc.headers[path] = header
+	fc.responses[path] = response
+}
+
+// verifyToken verifies the ES256 signature and the claims of the JWT, independent of the driver
+func verifyToken(publicKey *ecdsa.PublicKey, token string, uri string) (err error) {
+	var parts []string
+	var header, claims map[string]interface{}
+	var data, signature []byte
+	var digest [32]byte
+	var nbf, exp float64
+
+	if parts = strings.Split(token, "."); len(parts) != 3 {
+		err = fmt.Errorf("malformed token")
+		return
+	}
+	if data, err = base64.RawURLEncoding.DecodeString(parts[0]); err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &header); err != nil {
+		return
+	}
+	if data, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &claims); err != nil {
+		return
+	}
+	if signature, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return
+	}
+
+	digest = sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if len(signature) != 64 || !ecdsa.Verify(publicKey, digest[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])) {
+		err = fmt.Errorf("invalid signature")
+		return
+	}
+
+	if header["alg"] != "ES256" || header["kid"] != testKeyName || header["nonce"] == "" {
+		err = fmt.Errorf("invalid header %v", header)
+		return
+	}
+	if claims["sub"] != testKeyName || claims["iss"] != "cdp" || claims["uri"] != uri {
+		err = fmt.Errorf("invalid claims %v, want uri %s", claims, uri)
+		return
+	}
+	nbf, _ = claims["nbf"].(float64)
+	exp, _ = claims["exp"].(float64)
+	if time.Unix(int64(nbf), 0).After(time.Now()) || time.Unix(int64(exp), 0).Before(time.Now()) {
+		err = fmt.Errorf("token not valid now: nbf %f exp %f", nbf, exp)
+		return
+	}
+	return
+}
+
+// testKey generates an EC key and returns it with its PEM encoding as it is passed on a single line
+func testKey(t *testing.T) (key *ecdsa.PrivateKey, secret string) {
+	var der []byte
+	var err error
+
+	if key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	if der, err = x509.MarshalECPrivateKey(key); err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	secret = strings.Replace(string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})), "\n", `\n`, -1)
+	return
+}
+
+func newTestCoinbase(t *testing.T) (c *Coinbase, fc *fakeCoinbase) {
+	var key *ecdsa.PrivateKey
+	var secret string
+	var err error
+
+	key, secret = testKey(t)
+	fc = newFakeCoinbase(t, &key.PublicKey)
+	if c, err = New(context.Background(), map[string]string{
+		"apiKey":    testKeyName,
+		"apiSecret": secret,
+		"baseURL":   fc.server.URL + "/",
+	}); err != nil {
+		fc.server.Close()
+		t.Fatalf("New: %v", err)
+	}
+	return
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	var key *ecdsa.PrivateKey
+	var der []byte
+	var err error
+
+	key, _ = testKey(t)
+	if der, err = x509.MarshalPKCS8PrivateKey(key); err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	if _, err = parsePrivateKey(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))); err != nil {
+		t.Errorf("PKCS8 key: %v", err)
+	}
+	if _, err = parsePrivateKey("not a key"); err == nil {
+		t.Errorf("expected an error for a value without PEM block")
+	}
+}
+
+func TestRequests(t *testing.T) {
+	var c *Coinbase
+	var fc *fakeCoinbase
+	var price float64
+	var info types.AccountInfo
+	var err error
+
+	c, fc = newTestCoinbase(t)
+	defer fc.server.Close()
+
+	if _, err = c.symbolToCoinbase(types.NewSymbol("BTC", "USD")); err != nil {
+		t.Fatalf("BTC/USD not loaded from the products: %v", err)
+	}
+	if price, err = c.Ticker(context.Background(), types.NewSymbol("BTC", "USD")); err != nil {
+		t.Fatalf("Ticker: %v", err)
+	}
+	if price != 30100.25 {
+		t.Errorf("Ticker: got %f, want 30100.25", price)
+	}
+
+	if info, err = c.GetAccountInfo(context.Background()); err != nil {
+		t.Fatalf("GetAccountInfo: %v", err)
+	}
+	if fc.tokens != 2 {
+		t.Errorf("authenticated requests: got %d, want 2", fc.tokens)
+	}
+	if info.MakerCommission != 0.004 || info.TakerCommission != 0.006 {
+		t.Errorf("commissions: got maker %f taker %f", info.MakerCommission, info.TakerCommission)
+	}
+	if len(info.Balances) != 1 || info.Balances[0].Free != 1.5 || info.Balances[0].Locked != 0.5 {
+		t.Errorf("balances: got %+v", info.Balances)
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	var tests = []struct {
+		status      int
+		header      http.Header
+		response    string
+		message     string
+		rateLimited bool
+		transient   bool
+		retryAfter  time.Duration
+	}{
+		{http.StatusBadRequest, nil, `{"error":"INVALID_ARGUMENT","message":"invalid product_id"}`, "invalid product_id", false, false, 0},
+		{http.StatusTooManyRequests, http.Header{"Retry-After": {"2"}}, `{"error":"RESOURCE_EXHAUSTED","message":"too many requests"}`, "too many requests", true, true, time.Second * 2},
+		{http.StatusInternalServerError, nil, `upstream failure`, "upstream failure", false, true, 0},
+	}
+	var c *Coinbase
+	var fc *fakeCoinbase
+	var httpError *exchange.HTTPError
+	var index int
+	var ok bool
+	var err error
+
+	c, fc = newTestCoinbase(t)
+	defer fc.server.Close()
+
+	for index = range tests {
+		fc.respond("/market/products/BTC-USD", tests[index].status, tests[index].header, tests[index].response)
+
+		if _, err = c.Ticker(context.Background(), types.NewSymbol("BTC", "USD")); err == nil {
+			t.Errorf("status %d: expected an error", tests[index].status)
+			continue
+		}
+		if !strings.Contains(err.Error(), tests[index].message) {
+			t.Errorf("status %d: got error %v, want message %s", tests[index].status, err, tests[index].message)
+		}
+		if httpError, ok = err.(*exchange.HTTPError); !ok {
+			t.Errorf("status %d: got %T, want *exchange.HTTPError", tests[index].status, err)
+			continue
+		}
+		if httpError.StatusCode != tests[index].status || httpError.RateLimited() != tests[index].rateLimited || httpError.Transient() != tests[index].transient || httpError.RetryAfter != tests[index].retryAfter {
+			t.Errorf("status %d: got %+v", tests[index].status, httpError)
+		}
+	}
+
+	// A token of another key is rejected
+	c.privateKey, _ = testKey(t)
+	if _, err = c.GetAccountInfo(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Errorf("foreign key: got %v, want the invalid signature error", err)
+	}
+}
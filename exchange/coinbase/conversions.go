@@ -0,0 +1,351 @@
+package coinbase
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// granularities are the candle granularities of Coinbase and their timeframes
+var granularities = []struct {
+	name      string
+	timeframe types.Timeframe
+}{
+	{"ONE_MINUTE", types.NewTimeframe(1, types.TuMin)},
+	{"FIVE_MINUTE", types.NewTimeframe(5, types.TuMin)},
+	{"FIFTEEN_MINUTE", types.NewTimeframe(15, types.TuMin)},
+	{"THIRTY_MINUTE", types.NewTimeframe(30, types.TuMin)},
+	{"ONE_HOUR", types.NewTimeframe(1, types.TuHour)},
+	{"TWO_HOUR", types.NewTimeframe(2, types.TuHour)},
+	{"FOUR_HOUR", types.NewTimeframe(4, types.TuHour)},
+	{"SIX_HOUR", types.NewTimeframe(6, types.TuHour)},
+	{"ONE_DAY", types.NewTimeframe(1, types.TuDay)},
+}
+
+func (c *Coinbase) toSymbolString(base string, quote string) string {
+	return types.NewSymbol(base, quote).String()
+}
+
+func (c *Coinbase) symbolToCoinbase(symbol types.Symbol) (p product, err error) {
+	var ok bool
+
+	if p, ok = c.products[symbol.String()]; !ok {
+		err = fmt.Errorf("Symbol '%s' is not available on Coinbase", symbol.String())
+		return
+	}
+	return
+}
+
+func (c *Coinbase) toSymbol(in string) (symbol types.Symbol, err error) {
+	var parts []string
+	var ok bool
+
+	parts = strings.Split(in, "-")
+	if len(parts) != 2 {
+		err = fmt.Errorf("Product '%s' is not available on Coinbase", in)
+		return
+	}
+	symbol = types.NewSymbol(parts[0], parts[1])
+	if _, ok = c.products[symbol.String()]; !ok {
+		err = fmt.Errorf("Product '%s' is not available on Coinbase", in)
+		return
+	}
+	return
+}
+
+func (c *Coinbase) timeframeToCoinbase(timeframe types.Timeframe) (granularity string, err error) {
+	var index int
+
+	for index = range granularities {
+		if granularities[index].timeframe == timeframe {
+			granularity = granularities[index].name
+			return
+		}
+	}
+	err = fmt.Errorf("Timeframe %s is not valid on Coinbase", timeframe.String())
+	return
+}
+
+func (c *Coinbase) sideToCoinbase(s types.Side) string {
+	if s == types.Buy {
+		return "BUY"
+	}
+	return "SELL"
+}
+
+func (c *Coinbase) toSide(in string) types.Side {
+	if in == "BUY" {
+		return types.Buy
+	}
+	return types.Sell
+}
+
+// stopDirection returns the direction the price crosses the stop price in
+// Stop losses trigger against the position, take profits with it.
+func (c *Coinbase) stopDirection(order types.Order) string {
+	if (order.Type == types.StopLossLimit) == (order.Side == types.Sell) {
+		return "STOP_DIRECTION_STOP_DOWN"
+	}
+	return "STOP_DIRECTION_STOP_UP"
+}
+
+// toOrderConfiguration converts the order into the Coinbase order configuration
+func (c *Coinbase) toOrderConfiguration(order types.Order, symbolInfo *types.SymbolInfo, p product) (config orderConfiguration, err error) {
+	var baseSize, limitPrice, stopPrice string
+	var limit *limitConfiguration
+
+	baseSize = c.formatSize(order.Quantity, p)
+
+	switch order.Type {
+	case types.Market:
+		config.MarketIOC = &marketConfiguration{BaseSize: baseSize}
+		return
+	case types.StopLoss, types.TakeProfit:
+		err = fmt.Errorf("Order type %d is not supported on Coinbase, use a stop limit order", order.Type)
+		return
+	}
+
+	if limitPrice, err = c.formatPrice(order.Price, symbolInfo, p); err != nil {
+		return
+	}
+
+	switch order.Type {
+	case types.Limit, types.LimitMaker:
+		limit = &limitConfiguration{BaseSize: baseSize, LimitPrice: limitPrice, PostOnly: order.Type == types.LimitMaker}
+		switch order.TimeInForce {
+		case types.GoodTillCancel:
+			config.LimitGTC = limit
+		case types.ImmediateOrCancel:
+			config.LimitIOC = limit
+		case types.FillOrCancel:
+			config.LimitFOK = limit
+		}
+	case types.StopLossLimit, types.TakeProfitLimit:
+		if order.TimeInForce != types.GoodTillCancel {
+			err = fmt.Errorf("Time in force %d is not supported for stop limit orders on Coinbase", order.TimeInForce)
+			return
+		}
+		if stopPrice, err = c.formatPrice(order.StopPrice, symbolInfo, p); err != nil {
+			return
+		}
+		config.StopLimitGTC = &stopLimitConfiguration{
+			BaseSize:      baseSize,
+			LimitPrice:    limitPrice,
+			StopPrice:     stopPrice,
+			StopDirection: c.stopDirection(order),
+		}
+	}
+	return
+}
+
+// toOrderType converts the order type, the configuration tells post only and stop loss or take profit orders apart
+func (c *Coinbase) toOrderType(entry orderEntry) types.OrderType {
+	var config = entry.OrderConfiguration
+
+	switch entry.OrderType {
+	case "MARKET":
+		return types.Market
+	case "LIMIT":
+		if config.LimitGTC != nil && config.LimitGTC.PostOnly {
+			return types.LimitMaker
+		}
+		return types.Limit
+	case "STOP_LIMIT":
+		if config.StopLimitGTC != nil && (config.StopLimitGTC.StopDirection == "STOP_DIRECTION_STOP_DOWN") != (entry.Side == "SELL") {
+			return types.TakeProfitLimit
+		}
+		return types.StopLossLimit
+	}
+	return types.Market
+}
+
+func (c *Coinbase) toTimeInForce(in string) types.TimeInForce {
+	switch in {
+	case "IMMEDIATE_OR_CANCEL":
+		return types.ImmediateOrCancel
+	case "FILL_OR_KILL":
+		return types.FillOrCancel
+	}
+	return types.GoodTillCancel
+}
+
+// toStatus converts the order status, open orders with a filled size are partially filled
+func (c *Coinbase) toStatus(in string, filledSize float64) types.OrderStatus {
+	switch in {
+	case "PENDING", "QUEUED", "OPEN":
+		if filledSize > 0.0 {
+			return types.StatusPartiallyFilled
+		}
+		return types.StatusNew
+	case "FILLED":
+		return types.StatusFilled
+	case "CANCELLED":
+		return types.StatusCanceled
+	case "CANCEL_QUEUED":
+		return types.StatusPendingCancel
+	case "EXPIRED":
+		return types.StatusExpired
+	}
+	return types.StatusRejected
+}
+
+// isFinal returns true if the order status does not change anymore
+func (c *Coinbase) isFinal(status string) bool {
+	switch status {
+	case "FILLED", "CANCELLED", "EXPIRED", "FAILED":
+		return true
+	}
+	return false
+}
+
+func (c *Coinbase) toFloat(in string) (flt float64) {
+	var err error
+
+	if in == "" {
+		return
+	}
+	if flt, err = strconv.ParseFloat(in, 64); err != nil {
+		logger.Warningf("Coinbase::toFloat Error %v\n", err)
+		flt = math.NaN()
+	}
+	return
+}
+
+func (c *Coinbase) toTime(in string) (t time.Time) {
+	var err error
+
+	if in == "" {
+		return
+	}
+	if t, err = time.Parse(time.RFC3339Nano, in); err != nil {
+		logger.Warningf("Coinbase::toTime Error %v\n", err)
+	}
+	return
+}
+
+// toID converts a Coinbase order or trade id into a numeric id
+func (c *Coinbase) toID(id string) int64 {
+	var h = fnv.New64a()
+
+	h.Write([]byte(id))
+	return int64(h.Sum64() & math.MaxInt64)
+}
+
+// decimals returns the number of decimals of an increment (e.g. 0.01)
+func (c *Coinbase) decimals(increment string) int {
+	var index int
+
+	increment = strings.TrimRight(increment, "0")
+	if index = strings.Index(increment, "."); index < 0 {
+		return 0
+	}
+	return len(increment) - index - 1
+}
+
+// formatSize truncates the quantity to the base increment of the product
+func (c *Coinbase) formatSize(quantity float64, p product) string {
+	var decimals int
+	var factor float64
+
+	decimals = c.decimals(p.BaseIncrement)
+	factor = math.Pow(10, float64(decimals))
+	return strconv.FormatFloat(math.Floor(quantity*factor+1e-9)/factor, 'f', decimals, 64)
+}
+
+// formatPrice clamps the price to the price increment of the symbol,
+// without symbol info the price is rounded to the decimals of the price increment of the product
+func (c *Coinbase) formatPrice(price float64, symbolInfo *types.SymbolInfo, p product) (strPrice string, err error) {
+	if symbolInfo != nil {
+		strPrice, err = symbolInfo.ClampPrice(price)
+		return
+	}
+	strPrice = strconv.FormatFloat(price, 'f', c.decimals(p.PriceIncrement), 64)
+	return
+}
+
+// toOrderInfo converts an order entry into an order info
+func (c *Coinbase) toOrderInfo(entry orderEntry) (info types.OrderInfo, err error) {
+	var config = entry.OrderConfiguration
+
+	if info.UserReference, err = uuid.Parse(entry.ClientOrderID); err != nil {
+		return
+	}
+	if info.Symbol, err = c.toSymbol(entry.ProductID); err != nil {
+		return
+	}
+
+	info.ExchangeOrderID = c.toID(entry.OrderID)
+	info.TransactionTime = c.toTime(entry.CreatedTime)
+	info.ExecutedQuantity = c.toFloat(entry.FilledSize)
+	info.Status = c.toStatus(entry.Status, info.ExecutedQuantity)
+	info.TimeInForce = c.toTimeInForce(entry.TimeInForce)
+	info.OrderType = c.toOrderType(entry)
+	info.Side = c.toSide(entry.Side)
+
+	switch {
+	case config.MarketIOC != nil:
+		info.OriginalQuantity = c.toFloat(config.MarketIOC.BaseSize)
+		info.Price = c.toFloat(entry.AverageFilledPrice)
+	case config.LimitGTC != nil:
+		info.OriginalQuantity = c.toFloat(config.LimitGTC.BaseSize)
+		info.Price = c.toFloat(config.LimitGTC.LimitPrice)
+	case config.LimitIOC != nil:
+		info.OriginalQuantity = c.toFloat(config.LimitIOC.BaseSize)
+		info.Price = c.toFloat(config.LimitIOC.LimitPrice)
+	case config.LimitFOK != nil:
+		info.OriginalQuantity = c.toFloat(config.LimitFOK.BaseSize)
+		info.Price = c.toFloat(config.LimitFOK.LimitPrice)
+	case config.StopLimitGTC != nil:
+		info.OriginalQuantity = c.toFloat(config.StopLimitGTC.BaseSize)
+		info.Price = c.toFloat(config.StopLimitGTC.LimitPrice)
+		info.StopPrice = c.toFloat(config.StopLimitGTC.StopPrice)
+	}
+	return
+}
+
+// fillQuantity returns the base quantity of the fill, the size of some fills is in quote asset
+func (c *Coinbase) fillQuantity(f fill) float64 {
+	var price float64
+
+	if !f.SizeInQuote {
+		return c.toFloat(f.Size)
+	}
+	if price = c.toFloat(f.Price); price <= 0.0 {
+		return 0.0
+	}
+	return c.toFloat(f.Size) / price
+}
+
+// toTrade converts a fill into a trade, the commission is payed in quote asset
+func (c *Coinbase) toTrade(f fill) (trade types.Trade, err error) {
+	var symbol types.Symbol
+	var price, quantity float64
+
+	if symbol, err = c.toSymbol(f.ProductID); err != nil {
+		return
+	}
+	price = c.toFloat(f.Price)
+	quantity = c.fillQuantity(f)
+	trade = types.NewTrade(
+		symbol,
+		c.toID(f.TradeID),
+		c.toID(f.OrderID),
+		price,
+		quantity,
+		price*quantity,
+		c.toFloat(f.Commission),
+		symbol.Quote(),
+		c.toTime(f.TradeTime),
+		f.Side == "BUY",
+		f.LiquidityIndicator == "MAKER",
+		true,
+	)
+	return
+}
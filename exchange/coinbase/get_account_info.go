@@ -0,0 +1,46 @@
+package coinbase
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// maxAccounts is the max number of accounts returned by a single list accounts request
+const maxAccounts = "250"
+
+// GetAccountInfo executes the get account info request
+// The commissions are the fees of the current fee tier of the account.
+func (c *Coinbase) GetAccountInfo(ctx context.Context) (info types.AccountInfo, err error) {
+	var params url.Values
+	var response accountsResult
+	var a account
+	var summary transactionSummary
+
+	params = url.Values{"limit": {maxAccounts}}
+	for {
+		response = accountsResult{}
+		if err = c.privateRequest(ctx, http.MethodGet, "/accounts", params, nil, &response); err != nil {
+			logger.Errorf("Coinbase::GetAccountInfo Error: %v\n", err)
+			return
+		}
+		for _, a = range response.Accounts {
+			info.Balances = append(info.Balances, types.NewAccountBalance(a.Currency, c.toFloat(a.AvailableBalance.Value), c.toFloat(a.Hold.Value)))
+		}
+		if !response.HasNext || response.Cursor == "" {
+			break
+		}
+		params.Set("cursor", response.Cursor)
+	}
+
+	if err = c.privateRequest(ctx, http.MethodGet, "/transaction_summary", nil, nil, &summary); err != nil {
+		logger.Errorf("Coinbase::GetAccountInfo Error: %v\n", err)
+		return
+	}
+	info.MakerCommission = c.toFloat(summary.FeeTier.MakerFeeRate)
+	info.TakerCommission = c.toFloat(summary.FeeTier.TakerFeeRate)
+	return
+}
@@ -0,0 +1,105 @@
+package coinbase
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	// maxOrders is the max number of orders returned by a single list orders request
+	maxOrders = "250"
+
+	// maxOrderPages is the max number of pages of orders searched for a client order id
+	maxOrderPages = 10
+)
+
+// GetOrder executes the get order request
+func (c *Coinbase) GetOrder(ctx context.Context, order types.Order) (info types.OrderInfo, err error) {
+	var entry orderEntry
+
+	if entry, err = c.findOrder(ctx, order.Symbol, order.UserReference); err != nil {
+		logger.Errorf("Coinbase::GetOrder Error %v\n", err)
+		return
+	}
+
+	if info, err = c.toOrderInfo(entry); err != nil {
+		logger.Errorf("Coinbase::GetOrder Error %v\n", err)
+		return
+	}
+	return
+}
+
+// findOrder looks up the order by its client order id
+// The orders placed by another instance are searched in the most recent orders of the symbol.
+func (c *Coinbase) findOrder(ctx context.Context, symbol types.Symbol, userReference uuid.UUID) (entry orderEntry, err error) {
+	var p product
+	var orderID string
+	var ok bool
+	var params url.Values
+	var response ordersResult
+	var page int
+
+	c.orderIDsMux.Lock()
+	orderID, ok = c.orderIDs[userReference]
+	c.orderIDsMux.Unlock()
+	if ok {
+		entry, err = c.getOrder(ctx, orderID)
+		return
+	}
+
+	if p, err = c.symbolToCoinbase(symbol); err != nil {
+		return
+	}
+
+	params = url.Values{"product_ids": {p.ProductID}, "limit": {maxOrders}}
+	for page = 0; page < maxOrderPages; page++ {
+		response = ordersResult{}
+		if err = c.privateRequest(ctx, http.MethodGet, "/orders/historical/batch", params, nil, &response); err != nil {
+			return
+		}
+		for _, entry = range response.Orders {
+			if entry.ClientOrderID == userReference.String() {
+				c.rememberOrder(userReference, entry.OrderID)
+				return
+			}
+		}
+		if !response.HasNext || response.Cursor == "" {
+			break
+		}
+		params.Set("cursor", response.Cursor)
+	}
+
+	entry = orderEntry{}
+	err = exchange.NewOrderNotFoundError(userReference.String())
+	return
+}
+
+// getOrder executes the get order request for an exchange order id
+func (c *Coinbase) getOrder(ctx context.Context, orderID string) (entry orderEntry, err error) {
+	var response orderResult
+	var httpErr *exchange.HTTPError
+	var ok bool
+
+	if err = c.privateRequest(ctx, http.MethodGet, "/orders/historical/"+url.PathEscape(orderID), nil, nil, &response); err != nil {
+		if httpErr, ok = err.(*exchange.HTTPError); ok && httpErr.StatusCode == http.StatusNotFound {
+			err = exchange.NewOrderNotFoundError(orderID)
+		}
+		return
+	}
+	entry = response.Order
+	return
+}
+
+// rememberOrder stores the exchange order id of the client order id
+func (c *Coinbase) rememberOrder(userReference uuid.UUID, orderID string) {
+	c.orderIDsMux.Lock()
+	defer c.orderIDsMux.Unlock()
+
+	c.orderIDs[userReference] = orderID
+}
@@ -0,0 +1,42 @@
+package coinbase
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// GetOrderBook executes the get orderbook request
+func (c *Coinbase) GetOrderBook(ctx context.Context, symbol types.Symbol) (book types.OrderBook, err error) {
+	var p product
+	var response bookResult
+	var numBids, numAsks, index int
+	var entry bookEntry
+	var bids, asks []types.OrderBookEntry
+
+	if p, err = c.symbolToCoinbase(symbol); err != nil {
+		logger.Errorf("Coinbase::GetOrderBook Error: %v\n", err)
+		return
+	}
+
+	if err = c.publicRequest(ctx, "/market/product_book", url.Values{"product_id": {p.ProductID}}, &response); err != nil {
+		logger.Errorf("Coinbase::GetOrderBook Error: %v\n", err)
+		return
+	}
+
+	numBids = len(response.PriceBook.Bids)
+	numAsks = len(response.PriceBook.Asks)
+	bids = make([]types.OrderBookEntry, numBids, numBids)
+	asks = make([]types.OrderBookEntry, numAsks, numAsks)
+
+	for index, entry = range response.PriceBook.Bids {
+		bids[index] = types.NewOrderBookEntry(c.toFloat(entry.Price), c.toFloat(entry.Size))
+	}
+	for index, entry = range response.PriceBook.Asks {
+		asks[index] = types.NewOrderBookEntry(c.toFloat(entry.Price), c.toFloat(entry.Size))
+	}
+	book = types.NewOrderBook(symbol, bids, asks)
+	return
+}
@@ -0,0 +1,72 @@
+package coinbase
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// GetOrderTrades executes the get order trades request
+func (c *Coinbase) GetOrderTrades(ctx context.Context, orderInfo types.OrderInfo) (trades []types.Trade, err error) {
+	var entry orderEntry
+	var fills []fill
+	var f fill
+	var trade types.Trade
+
+	if entry, err = c.findOrder(ctx, orderInfo.Symbol, orderInfo.UserReference); err != nil {
+		logger.Errorf("Coinbase::GetOrderTrades Error %v\n", err)
+		return
+	}
+
+	if fills, err = c.listFills(ctx, entry.OrderID); err != nil {
+		logger.Errorf("Coinbase::GetOrderTrades Error %v\n", err)
+		return
+	}
+
+	trades = make([]types.Trade, 0, len(fills))
+	for _, f = range fills {
+		if trade, err = c.toTrade(f); err != nil {
+			logger.Errorf("Coinbase::GetOrderTrades Error %v\n", err)
+			return
+		}
+		trades = append(trades, trade)
+	}
+	return
+}
+
+// listFills executes the list fills requests of the order
+func (c *Coinbase) listFills(ctx context.Context, orderID string) (fills []fill, err error) {
+	var params url.Values
+	var response fillsResult
+
+	params = url.Values{"order_ids": {orderID}}
+	for {
+		response = fillsResult{}
+		if err = c.privateRequest(ctx, http.MethodGet, "/orders/historical/fills", params, nil, &response); err != nil {
+			return
+		}
+		fills = append(fills, response.Fills...)
+		if response.Cursor == "" || len(response.Fills) == 0 {
+			break
+		}
+		params.Set("cursor", response.Cursor)
+	}
+	return
+}
+
+// orderFills returns the fills of the order, the commission is payed in quote asset
+func (c *Coinbase) orderFills(ctx context.Context, entry orderEntry, symbol types.Symbol) (orderFills []types.OrderFill, err error) {
+	var fills []fill
+	var f fill
+
+	if fills, err = c.listFills(ctx, entry.OrderID); err != nil {
+		return
+	}
+	for _, f = range fills {
+		orderFills = append(orderFills, types.NewOrderFill(c.toFloat(f.Price), c.fillQuantity(f), c.toFloat(f.Commission), symbol.Quote()))
+	}
+	return
+}
@@ -0,0 +1,91 @@
+package coinbase
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// maxCandles is the max number of candles returned by a single candles request
+const maxCandles = 350
+
+// GetSeries executes the get series request
+func (c *Coinbase) GetSeries(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe) (series types.Series, err error) {
+	var end time.Time
+
+	end = time.Now()
+	if series, err = c.getCandles(ctx, symbol, timeframe, end.Add(-timeframe.Duration()*(maxCandles-1)), end); err != nil {
+		logger.Errorf("Coinbase::GetSeries Error: %v\n", err)
+		return
+	}
+	return
+}
+
+// GetSeriesRange executes the get series request for the candles opened between start and end
+// At most 350 candles are returned per request.
+func (c *Coinbase) GetSeriesRange(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe, start time.Time, end time.Time) (series types.Series, err error) {
+	if end.Sub(start) >= timeframe.Duration()*maxCandles {
+		end = start.Add(timeframe.Duration() * (maxCandles - 1))
+	}
+	if series, err = c.getCandles(ctx, symbol, timeframe, start, end); err != nil {
+		logger.Errorf("Coinbase::GetSeriesRange Error: %v\n", err)
+		return
+	}
+	return
+}
+
+// getCandles executes the candles request, the candles are returned oldest first
+func (c *Coinbase) getCandles(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe, start time.Time, end time.Time) (series types.Series, err error) {
+	var p product
+	var granularity string
+	var params url.Values
+	var response candlesResult
+	var numCandles, index int
+	var cdl candle
+	var openTime int64
+	var ohlc []types.OHLC
+
+	if p, err = c.symbolToCoinbase(symbol); err != nil {
+		return
+	}
+
+	if granularity, err = c.timeframeToCoinbase(timeframe); err != nil {
+		return
+	}
+
+	params = url.Values{}
+	params.Set("granularity", granularity)
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	if err = c.publicRequest(ctx, "/market/products/"+p.ProductID+"/candles", params, &response); err != nil {
+		return
+	}
+
+	numCandles = len(response.Candles)
+	ohlc = make([]types.OHLC, numCandles, numCandles)
+	for index, cdl = range response.Candles {
+		if openTime, err = strconv.ParseInt(cdl.Start, 10, 64); err != nil {
+			return
+		}
+		ohlc[index] = types.NewOHLC(
+			c.toFloat(cdl.Open),
+			c.toFloat(cdl.High),
+			c.toFloat(cdl.Low),
+			c.toFloat(cdl.Close),
+			c.toFloat(cdl.Volume),
+			time.Unix(openTime, 0),
+			time.Unix(openTime, 0).Add(timeframe.Duration()-time.Millisecond),
+		)
+	}
+	sort.Slice(ohlc, func(i, j int) bool {
+		return ohlc[i].OpenTime.Before(ohlc[j].OpenTime)
+	})
+
+	series = types.NewSeries(symbol, timeframe, ohlc)
+	return
+}
@@ -0,0 +1,27 @@
+package coinbase
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/mhereman/cryptotrader/logger"
+)
+
+// GetServerTime executes the get server time request
+func (c *Coinbase) GetServerTime(ctx context.Context) (serverTime time.Time, err error) {
+	var response timeResult
+	var millis int64
+
+	if err = c.publicRequest(ctx, "/time", nil, &response); err != nil {
+		logger.Errorf("Coinbase::GetServerTime Error: %v\n", err)
+		return
+	}
+
+	if millis, err = strconv.ParseInt(response.EpochMillis, 10, 64); err != nil {
+		logger.Errorf("Coinbase::GetServerTime Error: %v\n", err)
+		return
+	}
+	serverTime = time.Unix(millis/1000, (millis%1000)*int64(time.Millisecond))
+	return
+}
@@ -0,0 +1,28 @@
+package coinbase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// GetSymbolInfo retrieves the symbol information for trading
+func (c *Coinbase) GetSymbolInfo(ctx context.Context, symbol types.Symbol) (info types.SymbolInfo, err error) {
+	var p product
+	var minPrice string
+
+	if p, err = c.symbolToCoinbase(symbol); err != nil {
+		logger.Errorf("Coinbase::GetSymbolInfo Error %v\n", err)
+		return
+	}
+
+	// The symbol info expects the price increment to contain a decimal point
+	minPrice = p.PriceIncrement
+	if !strings.Contains(minPrice, ".") {
+		minPrice += ".0"
+	}
+	info = types.NewSymbolInfo(symbol, minPrice, p.BaseMinSize)
+	return
+}
@@ -0,0 +1,48 @@
+package coinbase
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// OpenOrders executes the open orders request
+// Orders placed without a client order id in UUID format (e.g. on the website) are skipped.
+func (c *Coinbase) OpenOrders(ctx context.Context, symbol types.Symbol) (orders []types.OrderInfo, err error) {
+	var p product
+	var params url.Values
+	var response ordersResult
+	var entry orderEntry
+	var info types.OrderInfo
+
+	if p, err = c.symbolToCoinbase(symbol); err != nil {
+		logger.Errorf("Coinbase::OpenOrders Error %v\n", err)
+		return
+	}
+
+	orders = make([]types.OrderInfo, 0)
+	params = url.Values{"product_ids": {p.ProductID}, "order_status": {"OPEN"}, "limit": {maxOrders}}
+	for {
+		response = ordersResult{}
+		if err = c.privateRequest(ctx, http.MethodGet, "/orders/historical/batch", params, nil, &response); err != nil {
+			logger.Errorf("Coinbase::OpenOrders Error %v\n", err)
+			return
+		}
+		for _, entry = range response.Orders {
+			if info, err = c.toOrderInfo(entry); err != nil {
+				logger.Debugf("Coinbase::OpenOrders Skipping order %s: %v\n", entry.OrderID, err)
+				err = nil
+				continue
+			}
+			orders = append(orders, info)
+		}
+		if !response.HasNext || response.Cursor == "" {
+			break
+		}
+		params.Set("cursor", response.Cursor)
+	}
+	return
+}
@@ -0,0 +1,88 @@
+package coinbase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	// fillPollInterval interval between the requests of the state of an immediate order
+	fillPollInterval = time.Millisecond * 500
+
+	// fillPollAttempts max number of requests of the state of an immediate order
+	fillPollAttempts = 10
+)
+
+// PlaceOrder executes the place order request
+// Market, immediate or cancel and fill or kill orders are followed up until they are done to report their fills.
+func (c *Coinbase) PlaceOrder(ctx context.Context, order types.Order, symbolInfo *types.SymbolInfo) (info types.OrderInfo, err error) {
+	var p product
+	var request createOrderRequest
+	var response createOrderResult
+	var entry orderEntry
+	var attempt int
+
+	if p, err = c.symbolToCoinbase(order.Symbol); err != nil {
+		logger.Errorf("Coinbase::PlaceOrder Error %v\n", err)
+		return
+	}
+
+	request.ClientOrderID = order.UserReference.String()
+	request.ProductID = p.ProductID
+	request.Side = c.sideToCoinbase(order.Side)
+	if request.OrderConfiguration, err = c.toOrderConfiguration(order, symbolInfo, p); err != nil {
+		logger.Errorf("Coinbase::PlaceOrder Error %v\n", err)
+		return
+	}
+
+	if err = c.privateRequest(ctx, http.MethodPost, "/orders", nil, request, &response); err != nil {
+		logger.Errorf("Coinbase::PlaceOrder Error %v\n", err)
+		return
+	}
+	if !response.Success {
+		err = fmt.Errorf("Order rejected: %s %s %s", response.ErrorResponse.Error, response.ErrorResponse.Message, response.ErrorResponse.NewOrderFailureReason+response.ErrorResponse.PreviewFailureReason)
+		logger.Errorf("Coinbase::PlaceOrder Error %v\n", err)
+		return
+	}
+	c.rememberOrder(order.UserReference, response.SuccessResponse.OrderID)
+
+	for attempt = 0; attempt < fillPollAttempts; attempt++ {
+		if entry, err = c.getOrder(ctx, response.SuccessResponse.OrderID); err != nil {
+			logger.Errorf("Coinbase::PlaceOrder Error %v\n", err)
+			return
+		}
+		if !c.isImmediate(order) || c.isFinal(entry.Status) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-time.After(fillPollInterval):
+		}
+	}
+
+	if info, err = c.toOrderInfo(entry); err != nil {
+		logger.Errorf("Coinbase::PlaceOrder Error %v\n", err)
+		return
+	}
+
+	if info.ExecutedQuantity > 0.0 {
+		if info.Fills, err = c.orderFills(ctx, entry, info.Symbol); err != nil {
+			logger.Errorf("Coinbase::PlaceOrder Error %v\n", err)
+			return
+		}
+	}
+	return
+}
+
+// isImmediate returns true if the order is expected to be done right after being placed
+func (c *Coinbase) isImmediate(order types.Order) bool {
+	return order.Type == types.Market || (order.Type == types.Limit && order.TimeInForce != types.GoodTillCancel)
+}
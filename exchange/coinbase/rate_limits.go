@@ -0,0 +1,35 @@
+package coinbase
+
+import (
+	"time"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	// publicBucket is the limit of the public market data endpoints
+	publicBucket = "public"
+
+	// privateBucket is the limit of the authenticated endpoints
+	privateBucket = "private"
+)
+
+// RateLimits returns the request limits of the Coinbase Advanced Trade API
+func (c *Coinbase) RateLimits() types.RateLimits {
+	return types.RateLimits{
+		Buckets: map[string]types.RateLimit{
+			publicBucket:  types.NewRateLimit(10, time.Second),
+			privateBucket: types.NewRateLimit(30, time.Second),
+		},
+		Weights: map[string]map[string]int{
+			"GetAccountInfo": {privateBucket: 2},
+			"GetSymbolInfo":  {},
+			"PlaceOrder":     {privateBucket: 2},
+			"GetOrder":       {privateBucket: 1},
+			"CancelOrder":    {privateBucket: 2},
+			"OpenOrders":     {privateBucket: 1},
+			"GetOrderTrades": {privateBucket: 2},
+		},
+		DefaultBucket: publicBucket,
+	}
+}
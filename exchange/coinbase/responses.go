@@ -0,0 +1,191 @@
+package coinbase
+
+// product represents a tradable product
+type product struct {
+	ProductID      string `json:"product_id"`
+	Price          string `json:"price"`
+	BaseCurrency   string `json:"base_currency_id"`
+	QuoteCurrency  string `json:"quote_currency_id"`
+	BaseIncrement  string `json:"base_increment"`
+	QuoteIncrement string `json:"quote_increment"`
+	PriceIncrement string `json:"price_increment"`
+	BaseMinSize    string `json:"base_min_size"`
+	Status         string `json:"status"`
+}
+
+// productsResult represents the list products response
+type productsResult struct {
+	Products []product `json:"products"`
+}
+
+// timeResult represents the server time response
+type timeResult struct {
+	EpochMillis string `json:"epochMillis"`
+}
+
+// candle represents a candle of the product candles response
+type candle struct {
+	Start  string `json:"start"`
+	Low    string `json:"low"`
+	High   string `json:"high"`
+	Open   string `json:"open"`
+	Close  string `json:"close"`
+	Volume string `json:"volume"`
+}
+
+// candlesResult represents the product candles response
+type candlesResult struct {
+	Candles []candle `json:"candles"`
+}
+
+// bookEntry represents a price level of the product book
+type bookEntry struct {
+	Price string `json:"price"`
+	Size  string `json:"size"`
+}
+
+// bookResult represents the product book response
+type bookResult struct {
+	PriceBook struct {
+		Bids []bookEntry `json:"bids"`
+		Asks []bookEntry `json:"asks"`
+	} `json:"pricebook"`
+}
+
+// amount represents a value in a currency
+type amount struct {
+	Value    string `json:"value"`
+	Currency string `json:"currency"`
+}
+
+// account represents an account (wallet) of a currency
+type account struct {
+	Currency         string `json:"currency"`
+	AvailableBalance amount `json:"available_balance"`
+	Hold             amount `json:"hold"`
+}
+
+// accountsResult represents a page of the list accounts response
+type accountsResult struct {
+	Accounts []account `json:"accounts"`
+	HasNext  bool      `json:"has_next"`
+	Cursor   string    `json:"cursor"`
+}
+
+// transactionSummary represents the transaction summary response
+type transactionSummary struct {
+	FeeTier struct {
+		TakerFeeRate string `json:"taker_fee_rate"`
+		MakerFeeRate string `json:"maker_fee_rate"`
+	} `json:"fee_tier"`
+}
+
+// orderConfiguration represents the configuration of an order, only one of the entries is set
+type orderConfiguration struct {
+	MarketIOC    *marketConfiguration    `json:"market_market_ioc,omitempty"`
+	LimitGTC     *limitConfiguration     `json:"limit_limit_gtc,omitempty"`
+	LimitIOC     *limitConfiguration     `json:"sor_limit_ioc,omitempty"`
+	LimitFOK     *limitConfiguration     `json:"limit_limit_fok,omitempty"`
+	StopLimitGTC *stopLimitConfiguration `json:"stop_limit_stop_limit_gtc,omitempty"`
+}
+
+type marketConfiguration struct {
+	BaseSize string `json:"base_size"`
+}
+
+type limitConfiguration struct {
+	BaseSize   string `json:"base_size"`
+	LimitPrice string `json:"limit_price"`
+	PostOnly   bool   `json:"post_only,omitempty"`
+}
+
+type stopLimitConfiguration struct {
+	BaseSize      string `json:"base_size"`
+	LimitPrice    string `json:"limit_price"`
+	StopPrice     string `json:"stop_price"`
+	StopDirection string `json:"stop_direction"`
+}
+
+// createOrderRequest represents the body of the create order request
+type createOrderRequest struct {
+	ClientOrderID      string             `json:"client_order_id"`
+	ProductID          string             `json:"product_id"`
+	Side               string             `json:"side"`
+	OrderConfiguration orderConfiguration `json:"order_configuration"`
+}
+
+// createOrderResult represents the create order response
+type createOrderResult struct {
+	Success         bool `json:"success"`
+	SuccessResponse struct {
+		OrderID string `json:"order_id"`
+	} `json:"success_response"`
+	ErrorResponse struct {
+		Error                 string `json:"error"`
+		Message               string `json:"message"`
+		ErrorDetails          string `json:"error_details"`
+		PreviewFailureReason  string `json:"preview_failure_reason"`
+		NewOrderFailureReason string `json:"new_order_failure_reason"`
+	} `json:"error_response"`
+}
+
+// orderEntry represents an order of the get order and list orders responses
+type orderEntry struct {
+	OrderID            string             `json:"order_id"`
+	ProductID          string             `json:"product_id"`
+	ClientOrderID      string             `json:"client_order_id"`
+	Side               string             `json:"side"`
+	Status             string             `json:"status"`
+	TimeInForce        string             `json:"time_in_force"`
+	CreatedTime        string             `json:"created_time"`
+	FilledSize         string             `json:"filled_size"`
+	AverageFilledPrice string             `json:"average_filled_price"`
+	OrderType          string             `json:"order_type"`
+	OrderConfiguration orderConfiguration `json:"order_configuration"`
+}
+
+// orderResult represents the get order response
+type orderResult struct {
+	Order orderEntry `json:"order"`
+}
+
+// ordersResult represents a page of the list orders response
+type ordersResult struct {
+	Orders  []orderEntry `json:"orders"`
+	HasNext bool         `json:"has_next"`
+	Cursor  string       `json:"cursor"`
+}
+
+// cancelOrdersRequest represents the body of the cancel orders request
+type cancelOrdersRequest struct {
+	OrderIDs []string `json:"order_ids"`
+}
+
+// cancelOrdersResult represents the cancel orders response
+type cancelOrdersResult struct {
+	Results []struct {
+		Success       bool   `json:"success"`
+		FailureReason string `json:"failure_reason"`
+		OrderID       string `json:"order_id"`
+	} `json:"results"`
+}
+
+// fill represents a fill of the list fills response
+type fill struct {
+	TradeID            string `json:"trade_id"`
+	OrderID            string `json:"order_id"`
+	TradeTime          string `json:"trade_time"`
+	Price              string `json:"price"`
+	Size               string `json:"size"`
+	Commission         string `json:"commission"`
+	ProductID          string `json:"product_id"`
+	LiquidityIndicator string `json:"liquidity_indicator"`
+	SizeInQuote        bool   `json:"size_in_quote"`
+	Side               string `json:"side"`
+}
+
+// fillsResult represents a page of the list fills response
+type fillsResult struct {
+	Fills  []fill `json:"fills"`
+	Cursor string `json:"cursor"`
+}
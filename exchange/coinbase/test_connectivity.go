@@ -0,0 +1,17 @@
+package coinbase
+
+import (
+	"context"
+
+	"github.com/mhereman/cryptotrader/logger"
+)
+
+// TestConnectivity tests exchange connectivity
+func (c *Coinbase) TestConnectivity(ctx context.Context) (ok bool, err error) {
+	if err = c.publicRequest(ctx, "/time", nil, nil); err != nil {
+		logger.Errorf("Coinbase::TestConnectivity Error: %v\n", err)
+		return
+	}
+	ok = true
+	return
+}
@@ -0,0 +1,26 @@
+package coinbase
+
+import (
+	"context"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// Ticker executes the ticker request
+func (c *Coinbase) Ticker(ctx context.Context, symbol types.Symbol) (price float64, err error) {
+	var p, response product
+
+	if p, err = c.symbolToCoinbase(symbol); err != nil {
+		logger.Errorf("Coinbase::Ticker Error: %v\n", err)
+		return
+	}
+
+	if err = c.publicRequest(ctx, "/market/products/"+p.ProductID, nil, &response); err != nil {
+		logger.Errorf("Coinbase::Ticker Error: %v\n", err)
+		return
+	}
+
+	price = c.toFloat(response.Price)
+	return
+}
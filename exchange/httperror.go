@@ -0,0 +1,109 @@
+package exchange
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxErrorBody is the max number of bytes of an error response included in the error message
+const maxErrorBody = 1024
+
+// HTTPError is returned by the exchange plugins for the requests answered with an HTTP error status
+// The Limiter retries the rate limited (429 and 418) and the server error (5xx) responses.
+type HTTPError struct {
+	// StatusCode of the response
+	StatusCode int
+
+	// RetryAfter time to wait before sending the next request, 0 if the response has no Retry-After header
+	RetryAfter time.Duration
+
+	// Err the error reported by the exchange
+	Err error
+}
+
+// NewHTTPError creates a new HTTPError instance from the response, the Retry-After header is parsed
+func NewHTTPError(response *http.Response, err error) *HTTPError {
+	return &HTTPError{
+		StatusCode: response.StatusCode,
+		RetryAfter: parseRetryAfter(response.Header.Get("Retry-After"), time.Now()),
+		Err:        err,
+	}
+}
+
+// Error returns the error reported by the exchange
+func (e *HTTPError) Error() string {
+	return e.Err.Error()
+}
+
+// RateLimited returns true if the exchange rejected the request for exceeding the rate limits
+// Binance answers 418 once the IP address is banned for ignoring the 429 responses.
+func (e *HTTPError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusTeapot
+}
+
+// Transient returns true if the request may succeed when it is sent again
+func (e *HTTPError) Transient() bool {
+	return e.RateLimited() || e.StatusCode >= http.StatusInternalServerError
+}
+
+// parseRetryAfter parses the Retry-After header, either a number of seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) (retryAfter time.Duration) {
+	var seconds int
+	var date time.Time
+	var err error
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	if seconds, err = strconv.Atoi(value); err == nil {
+		if seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return
+	}
+	if date, err = http.ParseTime(value); err == nil && date.After(now) {
+		retryAfter = date.Sub(now)
+	}
+	return
+}
+
+// httpErrorTransport converts the transient error responses into HTTPErrors
+type httpErrorTransport struct {
+	base http.RoundTripper
+}
+
+// NewHTTPErrorTransport creates a round tripper returning an HTTPError for the rate limited and server error responses
+// It lets the Limiter handle these responses for exchange plugins using a third party REST client.
+func NewHTTPErrorTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &httpErrorTransport{base: base}
+}
+
+// RoundTrip executes the request
+func (t *httpErrorTransport) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	var httpErr *HTTPError
+	var body []byte
+
+	if response, err = t.base.RoundTrip(request); err != nil {
+		return
+	}
+
+	httpErr = &HTTPError{StatusCode: response.StatusCode}
+	if !httpErr.Transient() {
+		return
+	}
+	defer response.Body.Close()
+
+	body, _ = ioutil.ReadAll(io.LimitReader(response.Body, maxErrorBody))
+	err = NewHTTPError(response, fmt.Errorf("%s %s: %s %s", request.Method, request.URL.Path, response.Status, strings.TrimSpace(string(body))))
+	response = nil
+	return
+}
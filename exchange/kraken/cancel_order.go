@@ -0,0 +1,38 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// CancelOrder executes the cancel order request
+func (k *Kraken) CancelOrder(ctx context.Context, order types.Order, newUUID uuid.UUID) (info types.OrderInfo, err error) {
+	var response cancelResult
+	var entry orderEntry
+
+	if err = k.privateRequest(ctx, "CancelOrder", url.Values{"cl_ord_id": {order.UserReference.String()}}, &response); err != nil {
+		logger.Errorf("Kraken::CancelOrder Error %v\n", err)
+		return
+	}
+	if response.Count == 0 {
+		err = fmt.Errorf("No order canceled: %s", order.UserReference.String())
+		logger.Errorf("Kraken::CancelOrder Error %v\n", err)
+		return
+	}
+
+	if entry, err = k.findOrder(ctx, order.UserReference); err != nil {
+		logger.Errorf("Kraken::CancelOrder Error %v\n", err)
+		return
+	}
+	if info, err = k.toOrderInfo(entry); err != nil {
+		logger.Errorf("Kraken::CancelOrder Error %v\n", err)
+		return
+	}
+	info.CancelUserReference = newUUID
+	return
+}
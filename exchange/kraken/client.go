@@ -0,0 +1,156 @@
+package kraken
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhereman/cryptotrader/exchange"
+)
+
+// response is the envelope of every Kraken API response
+type response struct {
+	Error  []string        `json:"error"`
+	Result json.RawMessage `json:"result"`
+}
+
+func decodeSecret(in string) (secret []byte, err error) {
+	// An empty secret is accepted, only the public requests can be used without credentials
+	if in == "" {
+		return
+	}
+	secret, err = base64.StdEncoding.DecodeString(in)
+	return
+}
+
+// publicRequest executes a GET request of a public endpoint and decodes the result into out
+func (k *Kraken) publicRequest(ctx context.Context, method string, params url.Values, out interface{}) (err error) {
+	var request *http.Request
+	var requestURL string
+
+	requestURL = fmt.Sprintf("%s/0/public/%s", k.baseURL, method)
+	if len(params) > 0 {
+		requestURL += "?" + params.Encode()
+	}
+	if request, err = http.NewRequest(http.MethodGet, requestURL, nil); err != nil {
+		return
+	}
+	err = k.do(ctx, request, method, out)
+	return
+}
+
+// privateRequest executes a signed POST request of a private endpoint and decodes the result into out
+func (k *Kraken) privateRequest(ctx context.Context, method string, params url.Values, out interface{}) (err error) {
+	var request *http.Request
+	var path, body string
+
+	if k.apiKey == "" || len(k.apiSecret) == 0 {
+		err = fmt.Errorf("%s: no API credentials configured", method)
+		return
+	}
+
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("nonce", strconv.FormatInt(k.nextNonce(), 10))
+	body = params.Encode()
+
+	path = fmt.Sprintf("/0/private/%s", method)
+	if request, err = http.NewRequest(http.MethodPost, k.baseURL+path, strings.NewReader(body)); err != nil {
+		return
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("API-Key", k.apiKey)
+	request.Header.Set("API-Sign", k.sign(path, params.Get("nonce"), body))
+	err = k.do(ctx, request, method, out)
+	return
+}
+
+// sign returns the API-Sign header value
+// HMAC-SHA512 of the path and the SHA256 of the nonce and the body, keyed with the decoded secret
+func (k *Kraken) sign(path string, nonce string, body string) string {
+	var digest [32]byte
+	var mac = hmac.New(sha512.New, k.apiSecret)
+
+	digest = sha256.Sum256([]byte(nonce + body))
+	mac.Write([]byte(path))
+	mac.Write(digest[:])
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// nextNonce returns a nonce higher than the previous one
+func (k *Kraken) nextNonce() int64 {
+	var nonce int64
+
+	k.nonceMux.Lock()
+	defer k.nonceMux.Unlock()
+
+	nonce = time.Now().UnixNano() / int64(time.Microsecond)
+	if nonce <= k.nonce {
+		nonce = k.nonce + 1
+	}
+	k.nonce = nonce
+	return nonce
+}
+
+func (k *Kraken) do(ctx context.Context, request *http.Request, method string, out interface{}) (err error) {
+	var httpResponse *http.Response
+	var body []byte
+	var envelope response
+
+	if httpResponse, err = k.client.Do(request.WithContext(ctx)); err != nil {
+		return
+	}
+	defer httpResponse.Body.Close()
+
+	if body, err = ioutil.ReadAll(httpResponse.Body); err != nil {
+		return
+	}
+	if httpResponse.StatusCode != http.StatusOK {
+		err = exchange.NewHTTPError(httpResponse, fmt.Errorf("%s: %s %s", method, httpResponse.Status, strings.TrimSpace(string(body))))
+		return
+	}
+
+	if err = json.Unmarshal(body, &envelope); err != nil {
+		err = fmt.Errorf("%s: invalid response %v", method, err)
+		return
+	}
+	if len(envelope.Error) > 0 {
+		err = fmt.Errorf("%s: %s", method, strings.Join(envelope.Error, ", "))
+		err = k.toHTTPError(envelope.Error, err)
+		return
+	}
+	if out != nil {
+		if err = json.Unmarshal(envelope.Result, out); err != nil {
+			err = fmt.Errorf("%s: invalid result %v", method, err)
+			return
+		}
+	}
+	return
+}
+
+// toHTTPError reports the rate limit and service errors, which Kraken returns with a 200 status, as HTTP errors
+// so the requests are retried.
+func (k *Kraken) toHTTPError(errors []string, err error) error {
+	var message string
+
+	for _, message = range errors {
+		switch {
+		case strings.HasSuffix(message, ":Rate limit exceeded"), message == "EGeneral:Too many requests":
+			return &exchange.HTTPError{StatusCode: http.StatusTooManyRequests, Err: err}
+		case message == "EService:Unavailable", message == "EService:Busy":
+			return &exchange.HTTPError{StatusCode: http.StatusServiceUnavailable, Err: err}
+		}
+	}
+	return err
+}
@@ -0,0 +1,287 @@
+package kraken
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// toAsset converts a Kraken asset altname into the common asset name
+func (k *Kraken) toAsset(in string) string {
+	in = strings.ToUpper(in)
+	switch in {
+	case "XBT":
+		return "BTC"
+	case "XDG":
+		return "DOGE"
+	}
+	return in
+}
+
+// assetName converts a Kraken asset code (e.g. XXBT) into the common asset name
+func (k *Kraken) assetName(code string) string {
+	var name string
+	var ok bool
+
+	if name, ok = k.assets[code]; ok {
+		return name
+	}
+	return k.toAsset(code)
+}
+
+func (k *Kraken) symbolToKraken(symbol types.Symbol) (pair assetPair, err error) {
+	var ok bool
+
+	if pair, ok = k.pairs[symbol.String()]; !ok {
+		err = fmt.Errorf("Symbol '%s' is not available on Kraken", symbol.String())
+		return
+	}
+	return
+}
+
+func (k *Kraken) toSymbol(in string) (symbol types.Symbol, err error) {
+	var symbolString string
+	var ok bool
+
+	if symbolString, ok = k.pairNames[in]; !ok {
+		err = fmt.Errorf("Pair '%s' is not available on Kraken", in)
+		return
+	}
+	symbol, err = types.NewSymbolFromString(symbolString)
+	return
+}
+
+// timeframeToKraken converts the timeframe into the OHLC interval in minutes
+func (k *Kraken) timeframeToKraken(timeframe types.Timeframe) (interval int, err error) {
+	switch timeframe.Unit {
+	case types.TuMin:
+		switch timeframe.Value {
+		case 1, 5, 15, 30:
+			interval = timeframe.Value
+			return
+		}
+	case types.TuHour:
+		switch timeframe.Value {
+		case 1, 4:
+			interval = timeframe.Value * 60
+			return
+		}
+	case types.TuDay:
+		switch timeframe.Value {
+		case 1, 15:
+			interval = timeframe.Value * 1440
+			return
+		}
+	case types.TuWeek:
+		if timeframe.Value == 1 {
+			interval = 10080
+			return
+		}
+	}
+	err = fmt.Errorf("Timeframe %s is not valid on Kraken", timeframe.String())
+	return
+}
+
+func (k *Kraken) sideToKraken(s types.Side) string {
+	if s == types.Buy {
+		return "buy"
+	}
+	return "sell"
+}
+
+func (k *Kraken) toSide(in string) types.Side {
+	if in == "buy" {
+		return types.Buy
+	}
+	return types.Sell
+}
+
+func (k *Kraken) orderTypeToKraken(t types.OrderType) string {
+	switch t {
+	case types.Limit, types.LimitMaker:
+		return "limit"
+	case types.Market:
+		return "market"
+	case types.StopLoss:
+		return "stop-loss"
+	case types.StopLossLimit:
+		return "stop-loss-limit"
+	case types.TakeProfit:
+		return "take-profit"
+	case types.TakeProfitLimit:
+		return "take-profit-limit"
+	}
+	return "market"
+}
+
+// toOrderType converts the order type, post only limit orders are limit maker orders
+func (k *Kraken) toOrderType(in string, oflags string) types.OrderType {
+	switch in {
+	case "limit":
+		if k.hasFlag(oflags, "post") {
+			return types.LimitMaker
+		}
+		return types.Limit
+	case "market":
+		return types.Market
+	case "stop-loss":
+		return types.StopLoss
+	case "stop-loss-limit":
+		return types.StopLossLimit
+	case "take-profit":
+		return types.TakeProfit
+	case "take-profit-limit":
+		return types.TakeProfitLimit
+	}
+	return types.Market
+}
+
+func (k *Kraken) timeInForceToKraken(t types.TimeInForce) (timeInForce string, err error) {
+	switch t {
+	case types.GoodTillCancel:
+		timeInForce = "GTC"
+	case types.ImmediateOrCancel:
+		timeInForce = "IOC"
+	default:
+		err = fmt.Errorf("Time in force %d is not supported on Kraken", t)
+	}
+	return
+}
+
+// toStatus converts the order status, open orders with executed volume are partially filled
+func (k *Kraken) toStatus(in string, executedQuantity float64) types.OrderStatus {
+	switch in {
+	case "pending", "open":
+		if executedQuantity > 0.0 {
+			return types.StatusPartiallyFilled
+		}
+		return types.StatusNew
+	case "closed":
+		return types.StatusFilled
+	case "canceled":
+		return types.StatusCanceled
+	case "expired":
+		return types.StatusExpired
+	}
+	return types.StatusRejected
+}
+
+func (k *Kraken) hasFlag(flags string, flag string) bool {
+	var f string
+
+	for _, f = range strings.Split(flags, ",") {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *Kraken) toFloat(in string) (flt float64) {
+	var err error
+
+	if in == "" {
+		return
+	}
+	if flt, err = strconv.ParseFloat(in, 64); err != nil {
+		logger.Warningf("Kraken::toFloat Error %v\n", err)
+		flt = math.NaN()
+	}
+	return
+}
+
+// toTime converts the Kraken timestamps in (fractional) seconds
+func (k *Kraken) toTime(in float64) time.Time {
+	var secs float64
+	var frac float64
+
+	secs, frac = math.Modf(in)
+	return time.Unix(int64(secs), int64(math.Round(frac*1000))*int64(time.Millisecond))
+}
+
+// toID converts a Kraken transaction id (e.g. OQCLML-BW3P3-BUCMWZ) into a numeric id
+func (k *Kraken) toID(txID string) int64 {
+	var h = fnv.New64a()
+
+	h.Write([]byte(txID))
+	return int64(h.Sum64() & math.MaxInt64)
+}
+
+// formatVolume truncates the quantity to the lot decimals of the pair
+func (k *Kraken) formatVolume(quantity float64, pair assetPair) string {
+	var factor float64
+
+	factor = math.Pow(10, float64(pair.LotDecimals))
+	return strconv.FormatFloat(math.Floor(quantity*factor+1e-9)/factor, 'f', pair.LotDecimals, 64)
+}
+
+// toOrderInfo converts an order entry into an order info
+func (k *Kraken) toOrderInfo(entry orderEntry) (info types.OrderInfo, err error) {
+	var orderType types.OrderType
+	var price, price2 float64
+
+	if info.UserReference, err = uuid.Parse(entry.ClOrdID); err != nil {
+		return
+	}
+	if info.Symbol, err = k.toSymbol(entry.Description.Pair); err != nil {
+		return
+	}
+
+	orderType = k.toOrderType(entry.Description.OrderType, entry.OFlags)
+	price = k.toFloat(entry.Description.Price)
+	price2 = k.toFloat(entry.Description.Price2)
+
+	info.ExchangeOrderID = k.toID(entry.TxID)
+	info.TransactionTime = k.toTime(entry.OpenTime)
+	info.OriginalQuantity = k.toFloat(entry.Volume)
+	info.ExecutedQuantity = k.toFloat(entry.VolumeExec)
+	info.Status = k.toStatus(entry.Status, info.ExecutedQuantity)
+	info.TimeInForce = types.GoodTillCancel
+	info.OrderType = orderType
+	info.Side = k.toSide(entry.Description.Type)
+
+	// The trigger price is the first price of the stop orders, the limit price the second one
+	switch orderType {
+	case types.Limit, types.LimitMaker:
+		info.Price = price
+	case types.Market:
+		info.Price = k.toFloat(entry.Price)
+	case types.StopLoss, types.TakeProfit:
+		info.StopPrice = price
+	case types.StopLossLimit, types.TakeProfitLimit:
+		info.StopPrice = price
+		info.Price = price2
+	}
+	return
+}
+
+// toTrade converts a trade entry into a trade, the fees are payed in quote asset
+func (k *Kraken) toTrade(txID string, entry tradeEntry) (trade types.Trade, err error) {
+	var symbol types.Symbol
+
+	if symbol, err = k.toSymbol(entry.Pair); err != nil {
+		return
+	}
+	trade = types.NewTrade(
+		symbol,
+		k.toID(txID),
+		k.toID(entry.OrderTxID),
+		k.toFloat(entry.Price),
+		k.toFloat(entry.Volume),
+		k.toFloat(entry.Cost),
+		k.toFloat(entry.Fee),
+		symbol.Quote(),
+		k.toTime(entry.Time),
+		entry.Type == "buy",
+		entry.Maker,
+		true,
+	)
+	return
+}
@@ -0,0 +1,77 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// referenceSymbol is the symbol the commissions of the account are requested for
+var referenceSymbol = types.NewSymbol("BTC", "USD")
+
+// GetAccountInfo executes the get account info request
+// The commissions are the fees of the reference pair for the 30 day volume of the account.
+func (k *Kraken) GetAccountInfo(ctx context.Context) (info types.AccountInfo, err error) {
+	var balances map[string]balance
+	var code string
+	var b balance
+	var total, hold float64
+	var pair assetPair
+	var volume tradeVolume
+	var fee feeInfo
+
+	if err = k.privateRequest(ctx, "BalanceEx", nil, &balances); err != nil {
+		logger.Errorf("Kraken::GetAccountInfo Error: %v\n", err)
+		return
+	}
+
+	for code, b = range balances {
+		total = k.toFloat(b.Balance)
+		hold = k.toFloat(b.HoldTrade)
+		info.Balances = append(info.Balances, types.NewAccountBalance(k.assetName(code), total-hold, hold))
+	}
+	sort.Slice(info.Balances, func(i, j int) bool {
+		return info.Balances[i].Asset < info.Balances[j].Asset
+	})
+
+	if pair, err = k.referencePair(); err != nil {
+		logger.Errorf("Kraken::GetAccountInfo Error: %v\n", err)
+		return
+	}
+	if err = k.privateRequest(ctx, "TradeVolume", url.Values{"pair": {pair.AltName}}, &volume); err != nil {
+		logger.Errorf("Kraken::GetAccountInfo Error: %v\n", err)
+		return
+	}
+	for _, fee = range volume.Fees {
+		info.TakerCommission = k.toFloat(fee.Fee) / 100.0
+	}
+	for _, fee = range volume.FeesMaker {
+		info.MakerCommission = k.toFloat(fee.Fee) / 100.0
+	}
+	return
+}
+
+// referencePair returns the pair of the reference symbol, or the first pair if it is not available
+func (k *Kraken) referencePair() (pair assetPair, err error) {
+	var symbols []string
+	var symbol string
+	var ok bool
+
+	if pair, ok = k.pairs[referenceSymbol.String()]; ok {
+		return
+	}
+	for symbol = range k.pairs {
+		symbols = append(symbols, symbol)
+	}
+	if len(symbols) == 0 {
+		err = fmt.Errorf("No asset pairs available on Kraken")
+		return
+	}
+	sort.Strings(symbols)
+	pair = k.pairs[symbols[0]]
+	return
+}
@@ -0,0 +1,72 @@
+package kraken
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// GetOrder executes the get order request
+func (k *Kraken) GetOrder(ctx context.Context, order types.Order) (info types.OrderInfo, err error) {
+	var entry orderEntry
+
+	if entry, err = k.findOrder(ctx, order.UserReference); err != nil {
+		logger.Errorf("Kraken::GetOrder Error %v\n", err)
+		return
+	}
+
+	if info, err = k.toOrderInfo(entry); err != nil {
+		logger.Errorf("Kraken::GetOrder Error %v\n", err)
+		return
+	}
+	return
+}
+
+// findOrder looks up the order by its client order id, in the open orders first and the closed orders next
+func (k *Kraken) findOrder(ctx context.Context, userReference uuid.UUID) (entry orderEntry, err error) {
+	var params url.Values
+	var open openOrders
+	var closed closedOrders
+	var txID string
+
+	params = url.Values{"cl_ord_id": {userReference.String()}, "trades": {"true"}}
+	if err = k.privateRequest(ctx, "OpenOrders", params, &open); err != nil {
+		return
+	}
+	for txID, entry = range open.Open {
+		entry.TxID = txID
+		return
+	}
+
+	params = url.Values{"cl_ord_id": {userReference.String()}, "trades": {"true"}}
+	if err = k.privateRequest(ctx, "ClosedOrders", params, &closed); err != nil {
+		return
+	}
+	for txID, entry = range closed.Closed {
+		entry.TxID = txID
+		return
+	}
+
+	err = exchange.NewOrderNotFoundError(userReference.String())
+	return
+}
+
+// queryOrder executes the query orders request for a single transaction id
+func (k *Kraken) queryOrder(ctx context.Context, txID string) (entry orderEntry, err error) {
+	var response map[string]orderEntry
+	var ok bool
+
+	if err = k.privateRequest(ctx, "QueryOrders", url.Values{"txid": {txID}, "trades": {"true"}}, &response); err != nil {
+		return
+	}
+	if entry, ok = response[txID]; !ok {
+		err = exchange.NewOrderNotFoundError(txID)
+		return
+	}
+	entry.TxID = txID
+	return
+}
@@ -0,0 +1,73 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// GetOrderBook executes the get orderbook request
+func (k *Kraken) GetOrderBook(ctx context.Context, symbol types.Symbol) (book types.OrderBook, err error) {
+	var pair assetPair
+	var response map[string]depth
+	var d depth
+	var bids, asks []types.OrderBookEntry
+
+	if pair, err = k.symbolToKraken(symbol); err != nil {
+		logger.Errorf("Kraken::GetOrderBook Error: %v\n", err)
+		return
+	}
+
+	if err = k.publicRequest(ctx, "Depth", url.Values{"pair": {pair.AltName}}, &response); err != nil {
+		logger.Errorf("Kraken::GetOrderBook Error: %v\n", err)
+		return
+	}
+
+	if len(response) != 1 {
+		err = fmt.Errorf("Invalid depth response, expected 1 book, received %d books", len(response))
+		logger.Errorf("Kraken::GetOrderBook Error: %v\n", err)
+		return
+	}
+	for _, d = range response {
+		if bids, err = k.toOrderBookEntries(d.Bids); err != nil {
+			logger.Errorf("Kraken::GetOrderBook Error: %v\n", err)
+			return
+		}
+		if asks, err = k.toOrderBookEntries(d.Asks); err != nil {
+			logger.Errorf("Kraken::GetOrderBook Error: %v\n", err)
+			return
+		}
+	}
+	book = types.NewOrderBook(symbol, bids, asks)
+	return
+}
+
+// toOrderBookEntries converts the price, volume and timestamp arrays of the depth response
+func (k *Kraken) toOrderBookEntries(in [][]interface{}) (entries []types.OrderBookEntry, err error) {
+	var numEntries, index int
+	var entry []interface{}
+	var price, volume string
+	var ok bool
+
+	numEntries = len(in)
+	entries = make([]types.OrderBookEntry, numEntries, numEntries)
+	for index, entry = range in {
+		if len(entry) < 2 {
+			err = fmt.Errorf("Invalid depth entry: %v", entry)
+			return
+		}
+		if price, ok = entry[0].(string); !ok {
+			err = fmt.Errorf("Invalid depth entry price: %v", entry[0])
+			return
+		}
+		if volume, ok = entry[1].(string); !ok {
+			err = fmt.Errorf("Invalid depth entry volume: %v", entry[1])
+			return
+		}
+		entries[index] = types.NewOrderBookEntry(k.toFloat(price), k.toFloat(volume))
+	}
+	return
+}
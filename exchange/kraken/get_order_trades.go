@@ -0,0 +1,90 @@
+package kraken
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// maxQueryTrades is the max number of trades of a single query trades request
+const maxQueryTrades = 20
+
+// GetOrderTrades executes the get order trades request
+func (k *Kraken) GetOrderTrades(ctx context.Context, orderInfo types.OrderInfo) (trades []types.Trade, err error) {
+	var entry orderEntry
+	var response map[string]tradeEntry
+	var txID string
+	var t tradeEntry
+	var trade types.Trade
+	var ok bool
+
+	if entry, err = k.findOrder(ctx, orderInfo.UserReference); err != nil {
+		logger.Errorf("Kraken::GetOrderTrades Error %v\n", err)
+		return
+	}
+
+	if response, err = k.queryTrades(ctx, entry.Trades); err != nil {
+		logger.Errorf("Kraken::GetOrderTrades Error %v\n", err)
+		return
+	}
+
+	trades = make([]types.Trade, 0, len(entry.Trades))
+	for _, txID = range entry.Trades {
+		if t, ok = response[txID]; !ok {
+			continue
+		}
+		if trade, err = k.toTrade(txID, t); err != nil {
+			logger.Errorf("Kraken::GetOrderTrades Error %v\n", err)
+			return
+		}
+		trades = append(trades, trade)
+	}
+	return
+}
+
+// queryTrades executes the query trades requests for the transaction ids
+func (k *Kraken) queryTrades(ctx context.Context, txIDs []string) (trades map[string]tradeEntry, err error) {
+	var response map[string]tradeEntry
+	var txID string
+	var t tradeEntry
+	var start, end int
+
+	trades = make(map[string]tradeEntry)
+	for start = 0; start < len(txIDs); start += maxQueryTrades {
+		end = start + maxQueryTrades
+		if end > len(txIDs) {
+			end = len(txIDs)
+		}
+
+		response = nil
+		if err = k.privateRequest(ctx, "QueryTrades", url.Values{"txid": {strings.Join(txIDs[start:end], ",")}}, &response); err != nil {
+			return
+		}
+		for txID, t = range response {
+			trades[txID] = t
+		}
+	}
+	return
+}
+
+// orderFills returns the fills of the trades of the order, the fees are payed in quote asset
+func (k *Kraken) orderFills(ctx context.Context, entry orderEntry, symbol types.Symbol) (fills []types.OrderFill, err error) {
+	var response map[string]tradeEntry
+	var txID string
+	var t tradeEntry
+	var ok bool
+
+	if response, err = k.queryTrades(ctx, entry.Trades); err != nil {
+		return
+	}
+	for _, txID = range entry.Trades {
+		if t, ok = response[txID]; !ok {
+			continue
+		}
+		fills = append(fills, types.NewOrderFill(k.toFloat(t.Price), k.toFloat(t.Volume), k.toFloat(t.Fee), symbol.Quote()))
+	}
+	return
+}
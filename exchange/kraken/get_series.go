@@ -0,0 +1,100 @@
+package kraken
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// GetSeries executes the get series request
+// Kraken returns the last 720 candles, the last one being the current candle.
+func (k *Kraken) GetSeries(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe) (series types.Series, err error) {
+	var pair assetPair
+	var interval, numCandles, index int
+	var response map[string]json.RawMessage
+	var key string
+	var raw json.RawMessage
+	var rows [][]interface{}
+	var row []interface{}
+	var ohlc []types.OHLC
+
+	if pair, err = k.symbolToKraken(symbol); err != nil {
+		logger.Errorf("Kraken::GetSeries Error: %v\n", err)
+		return
+	}
+
+	if interval, err = k.timeframeToKraken(timeframe); err != nil {
+		logger.Errorf("Kraken::GetSeries Error: %v\n", err)
+		return
+	}
+
+	if err = k.publicRequest(ctx, "OHLC", url.Values{"pair": {pair.AltName}, "interval": {strconv.Itoa(interval)}}, &response); err != nil {
+		logger.Errorf("Kraken::GetSeries Error: %v\n", err)
+		return
+	}
+
+	// The response contains the candles keyed by pair name and the 'last' id
+	for key, raw = range response {
+		if key == "last" {
+			continue
+		}
+		if err = json.Unmarshal(raw, &rows); err != nil {
+			logger.Errorf("Kraken::GetSeries Error: %v\n", err)
+			return
+		}
+	}
+
+	numCandles = len(rows)
+	ohlc = make([]types.OHLC, numCandles, numCandles)
+	for index, row = range rows {
+		if ohlc[index], err = k.toOHLC(row, timeframe); err != nil {
+			logger.Errorf("Kraken::GetSeries Error: %v\n", err)
+			return
+		}
+	}
+
+	series = types.NewSeries(symbol, timeframe, ohlc)
+	return
+}
+
+// toOHLC converts a row of time, open, high, low, close, vwap, volume and count
+func (k *Kraken) toOHLC(row []interface{}, timeframe types.Timeframe) (ohlc types.OHLC, err error) {
+	var openTime float64
+	var values [5]string
+	var columns = [5]int{1, 2, 3, 4, 6}
+	var index int
+	var ok bool
+
+	if len(row) < 7 {
+		err = fmt.Errorf("Invalid OHLC entry: %v", row)
+		return
+	}
+	if openTime, ok = row[0].(float64); !ok {
+		err = fmt.Errorf("Invalid OHLC entry time: %v", row[0])
+		return
+	}
+	// The open, high, low, close and volume columns, skipping vwap
+	for index = range columns {
+		if values[index], ok = row[columns[index]].(string); !ok {
+			err = fmt.Errorf("Invalid OHLC entry: %v", row)
+			return
+		}
+	}
+
+	ohlc = types.NewOHLC(
+		k.toFloat(values[0]),
+		k.toFloat(values[1]),
+		k.toFloat(values[2]),
+		k.toFloat(values[3]),
+		k.toFloat(values[4]),
+		k.toTime(openTime),
+		k.toTime(openTime).Add(timeframe.Duration()-time.Millisecond),
+	)
+	return
+}
@@ -0,0 +1,21 @@
+package kraken
+
+import (
+	"context"
+	"time"
+
+	"github.com/mhereman/cryptotrader/logger"
+)
+
+// GetServerTime executes the get server time request
+func (k *Kraken) GetServerTime(ctx context.Context) (serverTime time.Time, err error) {
+	var response timeResult
+
+	if err = k.publicRequest(ctx, "Time", nil, &response); err != nil {
+		logger.Errorf("Kraken::GetServerTime Error: %v\n", err)
+		return
+	}
+
+	serverTime = time.Unix(response.UnixTime, 0)
+	return
+}
@@ -0,0 +1,49 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// GetSymbolInfo retrieves the symbol information for trading
+// The price increment is the tick size of the pair, or the smallest price of its decimals.
+func (k *Kraken) GetSymbolInfo(ctx context.Context, symbol types.Symbol) (info types.SymbolInfo, err error) {
+	var pair assetPair
+	var minPrice string
+
+	if pair, err = k.symbolToKraken(symbol); err != nil {
+		logger.Errorf("Kraken::GetSymbolInfo Error %v\n", err)
+		return
+	}
+
+	minPrice = pair.TickSize
+	if k.toFloat(minPrice) <= 0.0 {
+		minPrice = strconv.FormatFloat(math.Pow(10, -float64(pair.PairDecimals)), 'f', pair.PairDecimals, 64)
+	}
+	if minPrice, err = k.decimalString(minPrice); err != nil {
+		logger.Errorf("Kraken::GetSymbolInfo Error %v\n", err)
+		return
+	}
+
+	info = types.NewSymbolInfo(symbol, minPrice, pair.OrderMin)
+	return
+}
+
+// decimalString makes sure the price contains a decimal point
+func (k *Kraken) decimalString(in string) (out string, err error) {
+	if _, err = strconv.ParseFloat(in, 64); err != nil {
+		err = fmt.Errorf("Invalid tick size: %s", in)
+		return
+	}
+	out = in
+	if !strings.Contains(out, ".") {
+		out += ".0"
+	}
+	return
+}
@@ -0,0 +1,127 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	exchangeName   = "kraken"
+	defaultBaseURL = "https://api.kraken.com"
+	defaultTimeout = time.Second * 30
+)
+
+func init() {
+	exchange.RegisterExchange(exchangeName, createKraken)
+}
+
+// Kraken represents the Kraken exchange plugin
+type Kraken struct {
+	apiKey    string
+	apiSecret []byte
+	baseURL   string
+	client    *http.Client
+
+	// nonce of the last private request, the nonces must increase for every request of the api key
+	nonce    int64
+	nonceMux sync.Mutex
+
+	// assets maps the Kraken asset codes (e.g. XXBT) onto the asset names (e.g. BTC)
+	assets map[string]string
+
+	// pairs maps the symbol strings (e.g. BTC/USD) onto the Kraken asset pairs
+	pairs map[string]assetPair
+
+	// pairNames maps the Kraken pair names (e.g. XXBTZUSD) and altnames (e.g. XBTUSD) onto the symbol strings
+	pairNames map[string]string
+}
+
+// New creates a new Kraken exchange plugin
+// The 'baseURL' argument replaces the Kraken API endpoint, e.g. to run against a local test server.
+func New(ctx context.Context, config map[string]string) (driver *Kraken, err error) {
+	var apiKey, apiSecret, baseURL string
+	var ok bool
+
+	if apiKey, ok = config["apiKey"]; !ok {
+		err = fmt.Errorf("Kraken config error: 'apiKey' entry not found")
+		return
+	}
+
+	if apiSecret, ok = config["apiSecret"]; !ok {
+		err = fmt.Errorf("Kraken config error: 'apiSecret' entry not found")
+		return
+	}
+
+	driver = new(Kraken)
+	driver.apiKey = apiKey
+	if driver.apiSecret, err = decodeSecret(apiSecret); err != nil {
+		err = fmt.Errorf("Kraken config error: invalid 'apiSecret' %v", err)
+		return
+	}
+	driver.baseURL = defaultBaseURL
+	if baseURL, ok = config["baseURL"]; ok && baseURL != "" {
+		driver.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+	driver.client = &http.Client{Timeout: defaultTimeout}
+
+	if err = driver.loadAssetPairs(ctx); err != nil {
+		logger.Errorf("Kraken::New Error: %v\n", err)
+		return
+	}
+	return
+}
+
+func createKraken(ctx context.Context, config map[string]string) (driver interfaces.IExchangeDriver, err error) {
+	driver, err = New(ctx, config)
+	return
+}
+
+// Name returns the name of the exchange plugin
+func (k *Kraken) Name() string {
+	return exchangeName
+}
+
+// loadAssetPairs loads the assets and the tradable asset pairs
+func (k *Kraken) loadAssetPairs(ctx context.Context) (err error) {
+	var assets map[string]asset
+	var pairs map[string]assetPair
+	var code, name, symbol string
+	var a asset
+	var pair assetPair
+
+	if err = k.publicRequest(ctx, "Assets", nil, &assets); err != nil {
+		return
+	}
+	if err = k.publicRequest(ctx, "AssetPairs", nil, &pairs); err != nil {
+		return
+	}
+
+	k.assets = make(map[string]string)
+	for code, a = range assets {
+		k.assets[code] = k.toAsset(a.AltName)
+	}
+
+	k.pairs = make(map[string]assetPair)
+	k.pairNames = make(map[string]string)
+	for name, pair = range pairs {
+		// Skip the dark pool pairs
+		if strings.HasSuffix(pair.AltName, ".d") {
+			continue
+		}
+		pair.Name = name
+		symbol = types.NewSymbol(k.assetName(pair.Base), k.assetName(pair.Quote)).String()
+		k.pairs[symbol] = pair
+		k.pairNames[name] = symbol
+		k.pairNames[pair.AltName] = symbol
+	}
+	return
+}
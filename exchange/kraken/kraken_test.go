@@ -0,0 +1,242 @@
+package kraken
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	testAPIKey    = "test-key"
+	testAPISecret = "c2VjcmV0LXNlY3JldC1zZWNyZXQ="
+
+	testAssets     = `{"error":[],"result":{"XXBT":{"altname":"XBT","decimals":10},"ZUSD":{"altname":"USD","decimals":4}}}`
+	testAssetPairs = `{"error":[],"result":{"XXBTZUSD":{"altname":"XBTUSD","base":"XXBT","quote":"ZUSD","pair_decimals":1,"lot_decimals":8,"ordermin":"0.0001","tick_size":"0.1","status":"online"},"XXBTZUSD.d":{"altname":"XBTUSD.d","base":"XXBT","quote":"ZUSD"}}}`
+)
+
+// fakeKraken is a local stand-in for the Kraken REST API
+// The private requests are rejected unless they are signed with the test secret and use increasing nonces.
+type fakeKraken struct {
+	server    *httptest.Server
+	mux       sync.Mutex
+	responses map[string]string
+	status    map[string]int
+	lastNonce int64
+	signed    int
+}
+
+func newFakeKraken(t *testing.T) (fk *fakeKraken) {
+	fk = &fakeKraken{
+		responses: map[string]string{
+			"/0/public/Assets":       testAssets,
+			"/0/public/AssetPairs":   testAssetPairs,
+			"/0/public/Ticker":       `{"error":[],"result":{"XXBTZUSD":{"c":["30000.5","0.1"]}}}`,
+			"/0/private/BalanceEx":   `{"error":[],"result":{"XXBT":{"balance":"1.5","hold_trade":"0.5"},"ZUSD":{"balance":"1000.0","hold_trade":"0"}}}`,
+			"/0/private/TradeVolume": `{"error":[],"result":{"fees":{"XXBTZUSD":{"fee":"0.26"}},"fees_maker":{"XXBTZUSD":{"fee":"0.16"}}}}`,
+		},
+		status: map[string]int{},
+	}
+	fk.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body []byte
+		var params url.Values
+		var nonce int64
+		var err error
+
+		fk.mux.Lock()
+		defer fk.mux.Unlock()
+
+		if r.Method == http.MethodPost {
+			body, _ = ioutil.ReadAll(r.Body)
+			if params, err = url.ParseQuery(string(body)); err != nil {
+				t.Errorf("%s: invalid body %v", r.URL.Path, err)
+			}
+			if nonce, err = strconv.ParseInt(params.Get("nonce"), 10, 64); err != nil || nonce <= fk.lastNonce {
+				t.Errorf("%s: nonce %s not increasing", r.URL.Path, params.Get("nonce"))
+			}
+			fk.lastNonce = nonce
+			if r.Header.Get("API-Key") != testAPIKey {
+				t.Errorf("%s: API-Key %s", r.URL.Path, r.Header.Get("API-Key"))
+			}
+			if r.Header.Get("API-Sign") != expectedSignature(r.URL.Path, params.Get("nonce"), string(body)) {
+				fmt.Fprint(w, `{"error":["EAPI:Invalid signature"]}`)
+				return
+			}
+			fk.signed++
+		}
+
+		if fk.status[r.URL.Path] != 0 {
+			w.WriteHeader(fk.status[r.URL.Path])
+		}
+		fmt.Fprint(w, fk.responses[r.URL.Path])
+	}))
+	return
+}
+
+func (fk *fakeKraken) respond(path string, status int, response string) {
+	fk.mux.Lock()
+	defer fk.mux.Unlock()
+
+	fk.status[path] = status
+	fk.responses[path] = response
+}
+
+// expectedSignature computes the signature as documented by Kraken, independent of the driver
+func expectedSignature(path string, nonce string, body string) string {
+	var secret, _ = base64.StdEncoding.DecodeString(testAPISecret)
+	var digest = sha256.Sum256([]byte(nonce + body))
+	var mac = hmac.New(sha512.New, secret)
+
+	mac.Write(append([]byte(path), digest[:]...))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newTestKraken(t *testing.T, fk *fakeKraken) (k *Kraken) {
+	var err error
+
+	if k, err = New(context.Background(), map[string]string{
+		"apiKey":    testAPIKey,
+		"apiSecret": testAPISecret,
+		"baseURL":   fk.server.URL + "/",
+	}); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return
+}
+
+func TestSign(t *testing.T) {
+	var k Kraken
+	var signature string
+	var err error
+
+	// Example of the Kraken API documentation
+	if k.apiSecret, err = decodeSecret("kQH5HW/8p1uGOVjbgWA7FunAmGO8lsSUXNsu3eow76sz84Q18fWxnyRzBHCd3pd5nE9qa99HAZtuZuj6F1huXg=="); err != nil {
+		t.Fatalf("decodeSecret: %v", err)
+	}
+	if signature = k.sign("/0/private/AddOrder", "1616492376594", "nonce=1616492376594&ordertype=limit&pair=XBTUSD&price=37500&type=buy&volume=1.25"); signature != "4/dpxb3iT4tp/ZCVEwSnEsLxx0bqyhLpdfOpc6fn7OR8+UClSV5n9E6aSS8MPtnRfp32bAb0nmbRn6H8ndwLUQ==" {
+		t.Errorf("sign: got %s", signature)
+	}
+}
+
+func TestPublicRequests(t *testing.T) {
+	var fk *fakeKraken
+	var k *Kraken
+	var price float64
+	var err error
+
+	fk = newFakeKraken(t)
+	defer fk.server.Close()
+	k = newTestKraken(t, fk)
+
+	if _, err = k.symbolToKraken(types.NewSymbol("BTC", "USD")); err != nil {
+		t.Fatalf("BTC/USD not loaded from the asset pairs: %v", err)
+	}
+	if len(k.pairs) != 1 {
+		t.Errorf("pairs: got %d, want the dark pool pair skipped", len(k.pairs))
+	}
+
+	if price, err = k.Ticker(context.Background(), types.NewSymbol("BTC", "USD")); err != nil {
+		t.Fatalf("Ticker: %v", err)
+	}
+	if price != 30000.5 {
+		t.Errorf("Ticker: got %f, want 30000.5", price)
+	}
+}
+
+func TestPrivateRequests(t *testing.T) {
+	var fk *fakeKraken
+	var k *Kraken
+	var info types.AccountInfo
+	var balance types.AccountBalance
+	var err error
+
+	fk = newFakeKraken(t)
+	defer fk.server.Close()
+	k = newTestKraken(t, fk)
+
+	if info, err = k.GetAccountInfo(context.Background()); err != nil {
+		t.Fatalf("GetAccountInfo: %v", err)
+	}
+	if fk.signed != 2 {
+		t.Errorf("signed requests: got %d, want 2", fk.signed)
+	}
+	if info.TakerCommission != 0.0026 || info.MakerCommission != 0.0016 {
+		t.Errorf("commissions: got taker %f maker %f", info.TakerCommission, info.MakerCommission)
+	}
+	if len(info.Balances) != 2 {
+		t.Fatalf("balances: got %d, want 2", len(info.Balances))
+	}
+	balance = info.Balances[0]
+	if balance.Asset != "BTC" || balance.Free != 1.0 || balance.Locked != 0.5 {
+		t.Errorf("BTC balance: got %+v", balance)
+	}
+
+	k.apiSecret = []byte("wrong")
+	if _, err = k.GetAccountInfo(context.Background()); err == nil {
+		t.Errorf("expected an error for an invalid signature")
+	}
+
+	k.apiKey = ""
+	if _, err = k.GetAccountInfo(context.Background()); err == nil {
+		t.Errorf("expected an error without credentials")
+	}
+}
+
+func TestToHTTPError(t *testing.T) {
+	var tests = []struct {
+		status      int
+		response    string
+		httpError   bool
+		rateLimited bool
+		transient   bool
+	}{
+		{http.StatusOK, `{"error":["EAPI:Rate limit exceeded"]}`, true, true, true},
+		{http.StatusOK, `{"error":["EOrder:Rate limit exceeded"]}`, true, true, true},
+		{http.StatusOK, `{"error":["EGeneral:Too many requests"]}`, true, true, true},
+		{http.StatusOK, `{"error":["EService:Unavailable"]}`, true, false, true},
+		{http.StatusOK, `{"error":["EService:Busy"]}`, true, false, true},
+		{http.StatusOK, `{"error":["EQuery:Unknown asset pair"]}`, false, false, false},
+		{http.StatusBadGateway, `bad gateway`, true, false, true},
+	}
+	var fk *fakeKraken
+	var k *Kraken
+	var httpError *exchange.HTTPError
+	var index int
+	var ok bool
+	var err error
+
+	fk = newFakeKraken(t)
+	defer fk.server.Close()
+	k = newTestKraken(t, fk)
+
+	for index = range tests {
+		fk.respond("/0/public/Ticker", tests[index].status, tests[index].response)
+
+		if _, err = k.Ticker(context.Background(), types.NewSymbol("BTC", "USD")); err == nil {
+			t.Errorf("%s: expected an error", tests[index].response)
+			continue
+		}
+		if httpError, ok = err.(*exchange.HTTPError); ok != tests[index].httpError {
+			t.Errorf("%s: got HTTP error %v, want %v (%v)", tests[index].response, ok, tests[index].httpError, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if httpError.RateLimited() != tests[index].rateLimited || httpError.Transient() != tests[index].transient {
+			t.Errorf("%s: got rate limited %v transient %v", tests[index].response, httpError.RateLimited(), httpError.Transient())
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package kraken
+
+import (
+	"context"
+	"net/url"
+	"sort"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// OpenOrders executes the open orders request
+// Orders placed without client order id (e.g. on the website) are skipped.
+func (k *Kraken) OpenOrders(ctx context.Context, symbol types.Symbol) (orders []types.OrderInfo, err error) {
+	var pair assetPair
+	var response openOrders
+	var txID string
+	var entry orderEntry
+	var info types.OrderInfo
+
+	if pair, err = k.symbolToKraken(symbol); err != nil {
+		logger.Errorf("Kraken::OpenOrders Error %v\n", err)
+		return
+	}
+
+	if err = k.privateRequest(ctx, "OpenOrders", url.Values{}, &response); err != nil {
+		logger.Errorf("Kraken::OpenOrders Error %v\n", err)
+		return
+	}
+
+	orders = make([]types.OrderInfo, 0, len(response.Open))
+	for txID, entry = range response.Open {
+		if entry.Description.Pair != pair.AltName || entry.ClOrdID == "" {
+			continue
+		}
+		entry.TxID = txID
+		if info, err = k.toOrderInfo(entry); err != nil {
+			logger.Errorf("Kraken::OpenOrders Error %v\n", err)
+			return
+		}
+		orders = append(orders, info)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].TransactionTime.Before(orders[j].TransactionTime)
+	})
+	return
+}
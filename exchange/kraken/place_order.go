@@ -0,0 +1,134 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	// fillPollInterval interval between the requests of the state of an immediate order
+	fillPollInterval = time.Millisecond * 500
+
+	// fillPollAttempts max number of requests of the state of an immediate order
+	fillPollAttempts = 10
+)
+
+// PlaceOrder executes the place order request
+// Market and immediate or cancel orders are followed up until they are closed to report their fills.
+func (k *Kraken) PlaceOrder(ctx context.Context, order types.Order, symbolInfo *types.SymbolInfo) (info types.OrderInfo, err error) {
+	var pair assetPair
+	var params url.Values
+	var timeInForce, strPrice string
+	var response addOrderResult
+	var entry orderEntry
+	var attempt int
+
+	if pair, err = k.symbolToKraken(order.Symbol); err != nil {
+		logger.Errorf("Kraken::PlaceOrder Error %v\n", err)
+		return
+	}
+
+	if timeInForce, err = k.timeInForceToKraken(order.TimeInForce); err != nil {
+		logger.Errorf("Kraken::PlaceOrder Error %v\n", err)
+		return
+	}
+
+	params = url.Values{}
+	params.Set("cl_ord_id", order.UserReference.String())
+	params.Set("pair", pair.AltName)
+	params.Set("type", k.sideToKraken(order.Side))
+	params.Set("ordertype", k.orderTypeToKraken(order.Type))
+	params.Set("volume", k.formatVolume(order.Quantity, pair))
+
+	switch order.Type {
+	case types.Limit:
+		params.Set("timeinforce", timeInForce)
+		if strPrice, err = k.formatPrice(order.Price, symbolInfo, pair); err != nil {
+			return
+		}
+		params.Set("price", strPrice)
+	case types.Market:
+	case types.StopLoss, types.TakeProfit:
+		if strPrice, err = k.formatPrice(order.StopPrice, symbolInfo, pair); err != nil {
+			return
+		}
+		params.Set("price", strPrice)
+	case types.StopLossLimit, types.TakeProfitLimit:
+		params.Set("timeinforce", timeInForce)
+		if strPrice, err = k.formatPrice(order.StopPrice, symbolInfo, pair); err != nil {
+			return
+		}
+		params.Set("price", strPrice)
+		if strPrice, err = k.formatPrice(order.Price, symbolInfo, pair); err != nil {
+			return
+		}
+		params.Set("price2", strPrice)
+	case types.LimitMaker:
+		params.Set("oflags", "post")
+		if strPrice, err = k.formatPrice(order.Price, symbolInfo, pair); err != nil {
+			return
+		}
+		params.Set("price", strPrice)
+	}
+
+	if err = k.privateRequest(ctx, "AddOrder", params, &response); err != nil {
+		logger.Errorf("Kraken::PlaceOrder Error %v\n", err)
+		return
+	}
+	if len(response.TxID) == 0 {
+		err = fmt.Errorf("Invalid add order response, no transaction id")
+		logger.Errorf("Kraken::PlaceOrder Error %v\n", err)
+		return
+	}
+
+	for attempt = 0; attempt < fillPollAttempts; attempt++ {
+		if entry, err = k.queryOrder(ctx, response.TxID[0]); err != nil {
+			logger.Errorf("Kraken::PlaceOrder Error %v\n", err)
+			return
+		}
+		if !k.isImmediate(order) || (entry.Status != "pending" && entry.Status != "open") {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-time.After(fillPollInterval):
+		}
+	}
+
+	if info, err = k.toOrderInfo(entry); err != nil {
+		logger.Errorf("Kraken::PlaceOrder Error %v\n", err)
+		return
+	}
+	info.TimeInForce = order.TimeInForce
+
+	if info.Fills, err = k.orderFills(ctx, entry, info.Symbol); err != nil {
+		logger.Errorf("Kraken::PlaceOrder Error %v\n", err)
+		return
+	}
+	return
+}
+
+// isImmediate returns true if the order is expected to be closed right after being placed
+func (k *Kraken) isImmediate(order types.Order) bool {
+	return order.Type == types.Market || (order.Type == types.Limit && order.TimeInForce == types.ImmediateOrCancel)
+}
+
+// formatPrice clamps the price to the price increment of the symbol,
+// without symbol info the price is rounded to the decimals of the pair
+func (k *Kraken) formatPrice(price float64, symbolInfo *types.SymbolInfo, pair assetPair) (strPrice string, err error) {
+	if symbolInfo != nil {
+		strPrice, err = symbolInfo.ClampPrice(price)
+		return
+	}
+	strPrice = strconv.FormatFloat(price, 'f', pair.PairDecimals, 64)
+	return
+}
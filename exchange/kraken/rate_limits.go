@@ -0,0 +1,36 @@
+package kraken
+
+import (
+	"time"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	// publicBucket is the limit of the public market data endpoints
+	publicBucket = "public"
+
+	// privateBucket is the API counter of the account, it decays by 0.33 per second up to a max of 15
+	privateBucket = "private"
+)
+
+// RateLimits returns the request limits of the Kraken API
+// The history queries (closed orders and trades) increase the API counter by 2.
+func (k *Kraken) RateLimits() types.RateLimits {
+	return types.RateLimits{
+		Buckets: map[string]types.RateLimit{
+			publicBucket:  types.NewRateLimit(1, time.Second),
+			privateBucket: types.NewRateLimit(15, time.Second*45),
+		},
+		Weights: map[string]map[string]int{
+			"GetAccountInfo": {privateBucket: 2},
+			"GetSymbolInfo":  {},
+			"PlaceOrder":     {privateBucket: 1},
+			"GetOrder":       {privateBucket: 4},
+			"CancelOrder":    {privateBucket: 1},
+			"OpenOrders":     {privateBucket: 1},
+			"GetOrderTrades": {privateBucket: 2},
+		},
+		DefaultBucket: publicBucket,
+	}
+}
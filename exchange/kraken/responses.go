@@ -0,0 +1,116 @@
+package kraken
+
+// asset represents an entry of the Assets response
+type asset struct {
+	AltName  string `json:"altname"`
+	Decimals int    `json:"decimals"`
+}
+
+// assetPair represents an entry of the AssetPairs response
+type assetPair struct {
+	Name         string `json:"-"`
+	AltName      string `json:"altname"`
+	Base         string `json:"base"`
+	Quote        string `json:"quote"`
+	PairDecimals int    `json:"pair_decimals"`
+	LotDecimals  int    `json:"lot_decimals"`
+	OrderMin     string `json:"ordermin"`
+	TickSize     string `json:"tick_size"`
+	Status       string `json:"status"`
+}
+
+// timeResult represents the Time response
+type timeResult struct {
+	UnixTime int64 `json:"unixtime"`
+}
+
+// systemStatus represents the SystemStatus response
+type systemStatus struct {
+	Status string `json:"status"`
+}
+
+// tickerInfo represents an entry of the Ticker response
+type tickerInfo struct {
+	// Close last trade price and lot volume
+	Close []string `json:"c"`
+}
+
+// depth represents an entry of the Depth response
+// The entries are arrays of price, volume and timestamp.
+type depth struct {
+	Asks [][]interface{} `json:"asks"`
+	Bids [][]interface{} `json:"bids"`
+}
+
+// balance represents an entry of the BalanceEx response
+type balance struct {
+	Balance   string `json:"balance"`
+	HoldTrade string `json:"hold_trade"`
+}
+
+// feeInfo represents the fee of a pair in the TradeVolume response
+type feeInfo struct {
+	Fee string `json:"fee"`
+}
+
+// tradeVolume represents the TradeVolume response
+type tradeVolume struct {
+	Fees      map[string]feeInfo `json:"fees"`
+	FeesMaker map[string]feeInfo `json:"fees_maker"`
+}
+
+// addOrderResult represents the AddOrder response
+type addOrderResult struct {
+	TxID []string `json:"txid"`
+}
+
+// orderDescription represents the description of an order
+type orderDescription struct {
+	Pair      string `json:"pair"`
+	Type      string `json:"type"`
+	OrderType string `json:"ordertype"`
+	Price     string `json:"price"`
+	Price2    string `json:"price2"`
+}
+
+// orderEntry represents an order of the OpenOrders, ClosedOrders and QueryOrders responses
+type orderEntry struct {
+	TxID        string           `json:"-"`
+	ClOrdID     string           `json:"cl_ord_id"`
+	Status      string           `json:"status"`
+	OpenTime    float64          `json:"opentm"`
+	Description orderDescription `json:"descr"`
+	Volume      string           `json:"vol"`
+	VolumeExec  string           `json:"vol_exec"`
+	Price       string           `json:"price"`
+	OFlags      string           `json:"oflags"`
+	Trades      []string         `json:"trades"`
+}
+
+// openOrders represents the OpenOrders response
+type openOrders struct {
+	Open map[string]orderEntry `json:"open"`
+}
+
+// closedOrders represents the ClosedOrders response
+type closedOrders struct {
+	Closed map[string]orderEntry `json:"closed"`
+}
+
+// cancelResult represents the CancelOrder response
+type cancelResult struct {
+	Count int `json:"count"`
+}
+
+// tradeEntry represents a trade of the QueryTrades response
+type tradeEntry struct {
+	OrderTxID string  `json:"ordertxid"`
+	Pair      string  `json:"pair"`
+	Time      float64 `json:"time"`
+	Type      string  `json:"type"`
+	Price     string  `json:"price"`
+	Cost      string  `json:"cost"`
+	Fee       string  `json:"fee"`
+	Volume    string  `json:"vol"`
+	Maker     bool    `json:"maker"`
+}
@@ -0,0 +1,26 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mhereman/cryptotrader/logger"
+)
+
+// TestConnectivity tests exchange connectivity
+// The exchange is only reported as connected when it is online, not during maintenance.
+func (k *Kraken) TestConnectivity(ctx context.Context) (ok bool, err error) {
+	var response systemStatus
+
+	if err = k.publicRequest(ctx, "SystemStatus", nil, &response); err != nil {
+		logger.Errorf("Kraken::TestConnectivity Error: %v\n", err)
+		return
+	}
+	if response.Status != "online" {
+		err = fmt.Errorf("Kraken system status: %s", response.Status)
+		logger.Errorf("Kraken::TestConnectivity Error: %v\n", err)
+		return
+	}
+	ok = true
+	return
+}
@@ -0,0 +1,42 @@
+package kraken
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// Ticker executes the ticker request
+func (k *Kraken) Ticker(ctx context.Context, symbol types.Symbol) (price float64, err error) {
+	var pair assetPair
+	var response map[string]tickerInfo
+	var ticker tickerInfo
+
+	if pair, err = k.symbolToKraken(symbol); err != nil {
+		logger.Errorf("Kraken::Ticker Error: %v\n", err)
+		return
+	}
+
+	if err = k.publicRequest(ctx, "Ticker", url.Values{"pair": {pair.AltName}}, &response); err != nil {
+		logger.Errorf("Kraken::Ticker Error: %v\n", err)
+		return
+	}
+
+	if len(response) != 1 {
+		err = fmt.Errorf("Invalid ticker response, expected 1 value, received %d values", len(response))
+		logger.Errorf("Kraken::Ticker Error: %v\n", err)
+		return
+	}
+	for _, ticker = range response {
+		if len(ticker.Close) == 0 {
+			err = fmt.Errorf("Invalid ticker response, no last trade price")
+			logger.Errorf("Kraken::Ticker Error: %v\n", err)
+			return
+		}
+		price = k.toFloat(ticker.Close[0])
+	}
+	return
+}
@@ -0,0 +1,292 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	defaultRetryDelay    = time.Millisecond * 500
+	defaultMaxRetryDelay = time.Second * 30
+	defaultCooldown      = time.Minute
+	defaultBucket        = "requests"
+)
+
+// defaultRateLimits are the limits of the exchange plugins not declaring their own, 10 requests per second
+var defaultRateLimits = types.RateLimits{
+	Buckets:       map[string]types.RateLimit{defaultBucket: types.NewRateLimit(10, time.Second)},
+	DefaultBucket: defaultBucket,
+}
+
+// LimiterConfig represents the configuration of the Limiter
+type LimiterConfig struct {
+	// RateLimit throttles the requests to the rate limits of the exchange
+	RateLimit bool
+
+	// Retries max number of retries of a failed request
+	Retries int
+
+	// RetryDelay base delay of the exponential backoff between the retries
+	RetryDelay time.Duration
+
+	// MaxRetryDelay max delay between the retries, a request asked to wait longer is not retried
+	MaxRetryDelay time.Duration
+
+	// FailureThreshold number of consecutive failed requests opening the circuit breaker, 0 disables it
+	FailureThreshold int
+
+	// Cooldown min time the circuit breaker stays open
+	Cooldown time.Duration
+}
+
+// NewLimiterConfig creates a new LimiterConfig instance with the default delays
+func NewLimiterConfig(rateLimit bool, retries int, failureThreshold int) LimiterConfig {
+	return LimiterConfig{
+		RateLimit:        rateLimit,
+		Retries:          retries,
+		RetryDelay:       defaultRetryDelay,
+		MaxRetryDelay:    defaultMaxRetryDelay,
+		FailureThreshold: failureThreshold,
+		Cooldown:         defaultCooldown,
+	}
+}
+
+// Enabled returns true if the requests are throttled, retried or guarded by the circuit breaker
+func (lc LimiterConfig) Enabled() bool {
+	return lc.RateLimit || lc.Retries > 0 || lc.FailureThreshold > 0
+}
+
+// String returns the string representation of the LimiterConfig
+func (lc LimiterConfig) String() string {
+	return fmt.Sprintf("rateLimit: %t, retries: %d, circuitBreaker: %d", lc.RateLimit, lc.Retries, lc.FailureThreshold)
+}
+
+// Limiter wraps an exchange plugin, throttling, retrying and guarding its requests
+// The requests take their weight from the token buckets of the rate limits declared by the plugin.
+// Transient failures (network errors, 429, 418 and 5xx responses) are retried with an exponential backoff
+// with jitter, the Retry-After time of a rate limited response pauses all requests. Orders are only
+// retried if the exchange rejected them for the rate limits, so they are never placed twice.
+// Consecutive failures open the circuit breaker, the requests then fail until the cooldown expires.
+type Limiter struct {
+	driver  interfaces.IExchangeDriver
+	config  LimiterConfig
+	limits  types.RateLimits
+	buckets map[string]*tokenBucket
+	breaker *circuitBreaker
+
+	// blockedUntil time the exchange asked to pause the requests until
+	blockedUntil time.Time
+	blockedMux   sync.Mutex
+}
+
+// NewLimiter creates a new Limiter wrapping the exchange plugin
+func NewLimiter(driver interfaces.IExchangeDriver, config LimiterConfig) (l *Limiter) {
+	var rateLimited interfaces.IRateLimitedExchangeDriver
+	var name string
+	var limit types.RateLimit
+	var ok bool
+	var now = time.Now()
+
+	l = new(Limiter)
+	l.driver = driver
+	l.config = config
+	l.limits = defaultRateLimits
+	if rateLimited, ok = driver.(interfaces.IRateLimitedExchangeDriver); ok {
+		l.limits = rateLimited.RateLimits()
+	}
+
+	l.buckets = make(map[string]*tokenBucket)
+	for name, limit = range l.limits.Buckets {
+		if limit.Capacity > 0 && limit.Interval > 0 {
+			l.buckets[name] = newTokenBucket(limit, now)
+		}
+	}
+
+	l.breaker = newCircuitBreaker(config.FailureThreshold, config.Cooldown)
+	l.breaker.addListener(func(state types.CircuitState) {
+		logger.Warningf("Limiter: Circuit breaker of exchange '%s' %s\n", driver.Name(), state.String())
+	})
+	return
+}
+
+// CircuitState returns the state of the circuit breaker
+func (l *Limiter) CircuitState() types.CircuitState {
+	return l.breaker.currentState()
+}
+
+// OnCircuitStateChange registers a function called on every state change of the circuit breaker
+func (l *Limiter) OnCircuitStateChange(fn func(types.CircuitState)) {
+	l.breaker.addListener(fn)
+}
+
+// call executes the request of the driver method
+// Requests which are not idempotent are only retried if the exchange rejected them for the rate limits.
+func (l *Limiter) call(ctx context.Context, method string, idempotent bool, fn func() error) (err error) {
+	var attempt int
+	var transient, rateLimited bool
+	var retryAfter, delay time.Duration
+	var now, until time.Time
+
+	for attempt = 0; ; attempt++ {
+		if err = l.breaker.allow(time.Now()); err != nil {
+			err = fmt.Errorf("%s: %v", method, err)
+			return
+		}
+		if err = l.wait(ctx, method); err != nil {
+			l.breaker.cancel()
+			return
+		}
+
+		if err = fn(); err == nil {
+			l.breaker.success()
+			return
+		}
+
+		if transient, rateLimited, retryAfter = l.classify(ctx, err); !transient {
+			// The exchange answered the request
+			l.breaker.success()
+			return
+		}
+
+		now = time.Now()
+		if rateLimited {
+			if retryAfter <= 0 {
+				retryAfter = l.backoff(attempt)
+			}
+			l.block(now.Add(retryAfter))
+		}
+
+		// A wait longer than the max retry delay (e.g. an IP ban) keeps the circuit open until it expires
+		until = time.Time{}
+		if retryAfter > l.config.MaxRetryDelay {
+			until = now.Add(retryAfter)
+		}
+		l.breaker.failure(now, until)
+
+		if attempt >= l.config.Retries || (!idempotent && !rateLimited) || retryAfter > l.config.MaxRetryDelay {
+			return
+		}
+
+		delay = l.backoff(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		logger.Warningf("Limiter::%s Error %v, retry %d/%d in %s\n", method, err, attempt+1, l.config.Retries, delay.String())
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// classify returns whether the error is transient, whether the exchange rejected the request for the rate limits
+// and the time the exchange asked to wait
+func (l *Limiter) classify(ctx context.Context, err error) (transient bool, rateLimited bool, retryAfter time.Duration) {
+	var httpErr *HTTPError
+	var urlErr *url.Error
+	var ok bool
+
+	if ctx.Err() != nil {
+		return
+	}
+	if urlErr, ok = err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	if httpErr, ok = err.(*HTTPError); ok {
+		transient = httpErr.Transient()
+		rateLimited = httpErr.RateLimited()
+		retryAfter = httpErr.RetryAfter
+		return
+	}
+
+	// Network errors, the request may not have reached the exchange
+	if _, ok = err.(net.Error); ok || urlErr != nil {
+		transient = true
+	}
+	return
+}
+
+// backoff returns the delay before the retry, exponential in the attempt with jitter
+// The delay is picked at random between half and the full exponential delay, so clients do not retry in lock step.
+func (l *Limiter) backoff(attempt int) (delay time.Duration) {
+	delay = l.config.RetryDelay << uint(attempt)
+	if delay <= 0 || delay > l.config.MaxRetryDelay {
+		delay = l.config.MaxRetryDelay
+	}
+	if delay > 1 {
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+	}
+	return
+}
+
+// block pauses the requests until the given time
+func (l *Limiter) block(until time.Time) {
+	l.blockedMux.Lock()
+	defer l.blockedMux.Unlock()
+
+	if until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+// wait reserves the weight of the request and waits until it may be sent
+func (l *Limiter) wait(ctx context.Context, method string) (err error) {
+	var now = time.Now()
+	var delay, bucketDelay time.Duration
+	var name string
+	var weight int
+	var bucket *tokenBucket
+	var ok bool
+
+	l.blockedMux.Lock()
+	delay = l.blockedUntil.Sub(now)
+	l.blockedMux.Unlock()
+
+	if l.config.RateLimit {
+		for name, weight = range l.weights(method) {
+			if bucket, ok = l.buckets[name]; !ok || weight <= 0 {
+				continue
+			}
+			if bucketDelay = bucket.reserve(weight, now); bucketDelay > delay {
+				delay = bucketDelay
+			}
+		}
+	}
+	if delay <= 0 {
+		return
+	}
+
+	logger.Debugf("Limiter::%s Throttled for %s\n", method, delay.String())
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-time.After(delay):
+	}
+	return
+}
+
+// weights returns the weight per bucket of the driver method
+func (l *Limiter) weights(method string) map[string]int {
+	var weights map[string]int
+	var ok bool
+
+	if weights, ok = l.limits.Weights[method]; ok {
+		return weights
+	}
+	if l.limits.DefaultBucket == "" {
+		return nil
+	}
+	return map[string]int{l.limits.DefaultBucket: 1}
+}
@@ -0,0 +1,312 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// fakeDriver is an exchange plugin answering the requests with the queued errors
+// The requests succeed once the queue is empty.
+type fakeDriver struct {
+	interfaces.IExchangeDriver
+	mux   sync.Mutex
+	errs  []error
+	calls int
+}
+
+func (d *fakeDriver) Name() string {
+	return "fake"
+}
+
+// RateLimits disables the rate limits, the tests of the limiter are not throttled
+func (d *fakeDriver) RateLimits() types.RateLimits {
+	return types.RateLimits{}
+}
+
+func (d *fakeDriver) next() (err error) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	d.calls++
+	if len(d.errs) > 0 {
+		err = d.errs[0]
+		d.errs = d.errs[1:]
+	}
+	return
+}
+
+func (d *fakeDriver) callCount() int {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	return d.calls
+}
+
+func (d *fakeDriver) GetServerTime(ctx context.Context) (serverTime time.Time, err error) {
+	err = d.next()
+	return
+}
+
+func (d *fakeDriver) PlaceOrder(ctx context.Context, order types.Order, symbolInfo *types.SymbolInfo) (info types.OrderInfo, err error) {
+	err = d.next()
+	return
+}
+
+func httpError(statusCode int, retryAfter time.Duration) *HTTPError {
+	return &HTTPError{StatusCode: statusCode, RetryAfter: retryAfter, Err: fmt.Errorf("%s", http.StatusText(statusCode))}
+}
+
+func networkError() error {
+	return &net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("connection refused")}
+}
+
+func testLimiterConfig() LimiterConfig {
+	return LimiterConfig{
+		Retries:       3,
+		RetryDelay:    time.Millisecond,
+		MaxRetryDelay: time.Second,
+		Cooldown:      time.Minute,
+	}
+}
+
+func TestTokenBucketReserve(t *testing.T) {
+	// 10 requests per second, refilled at 1 weight per 100ms
+	var tests = []struct {
+		name   string
+		offset time.Duration
+		weight int
+		wait   time.Duration
+	}{
+		{"full bucket", 0, 5, 0},
+		{"empty bucket", 0, 5, 0},
+		{"debt", 0, 5, time.Millisecond * 500},
+		{"refill pays the debt", time.Millisecond * 500, 1, time.Millisecond * 100},
+		{"refill is capped at the capacity", time.Second * 10, 10, 0},
+		{"weight above the capacity", time.Second * 10, 20, time.Second},
+		{"clock going back does not refill", time.Second * 9, 1, time.Millisecond * 1100},
+	}
+	var start = time.Now()
+	var tb = newTokenBucket(types.NewRateLimit(10, time.Second), start)
+	var wait time.Duration
+	var index int
+
+	for index = range tests {
+		if wait = tb.reserve(tests[index].weight, start.Add(tests[index].offset)); wait < tests[index].wait-time.Microsecond || wait > tests[index].wait+time.Microsecond {
+			t.Errorf("%s: got wait %v, want %v", tests[index].name, wait, tests[index].wait)
+		}
+	}
+}
+
+func TestLimiterRetries(t *testing.T) {
+	var tests = []struct {
+		name       string
+		idempotent bool
+		errs       []error
+		calls      int
+		fails      bool
+	}{
+		{"idempotent request retried after a network error", true, []error{networkError()}, 2, false},
+		{"idempotent request retried after a server error", true, []error{httpError(http.StatusBadGateway, 0), httpError(http.StatusServiceUnavailable, 0)}, 3, false},
+		{"idempotent request retries exhausted", true, []error{networkError(), networkError(), networkError(), networkError()}, 4, true},
+		{"client error not retried", true, []error{httpError(http.StatusBadRequest, 0)}, 1, true},
+		{"order not retried after a network error", false, []error{networkError()}, 1, true},
+		{"order not retried after a server error", false, []error{httpError(http.StatusInternalServerError, 0)}, 1, true},
+		{"order retried when rate limited", false, []error{httpError(http.StatusTooManyRequests, 0)}, 2, false},
+	}
+	var driver *fakeDriver
+	var limiter *Limiter
+	var index int
+	var err error
+
+	for index = range tests {
+		driver = &fakeDriver{errs: tests[index].errs}
+		limiter = NewLimiter(driver, testLimiterConfig())
+
+		if tests[index].idempotent {
+			_, err = limiter.GetServerTime(context.Background())
+		} else {
+			_, err = limiter.PlaceOrder(context.Background(), types.Order{}, nil)
+		}
+		if (err != nil) != tests[index].fails {
+			t.Errorf("%s: got error %v, want failure %v", tests[index].name, err, tests[index].fails)
+		}
+		if driver.callCount() != tests[index].calls {
+			t.Errorf("%s: got %d requests, want %d", tests[index].name, driver.callCount(), tests[index].calls)
+		}
+	}
+}
+
+func TestLimiterRetryAfter(t *testing.T) {
+	var driver *fakeDriver
+	var limiter *Limiter
+	var config LimiterConfig
+	var start time.Time
+	var err error
+
+	config = testLimiterConfig()
+	config.Retries = 0
+	driver = &fakeDriver{errs: []error{httpError(http.StatusTooManyRequests, time.Millisecond*300)}}
+	limiter = NewLimiter(driver, config)
+
+	if _, err = limiter.GetServerTime(context.Background()); err == nil {
+		t.Fatalf("GetServerTime: expected the rate limited error")
+	}
+
+	// The next requests wait until the Retry-After time expired
+	start = time.Now()
+	if _, err = limiter.PlaceOrder(context.Background(), types.Order{}, nil); err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if time.Since(start) < time.Millisecond*250 {
+		t.Errorf("request sent after %v, want it blocked for the Retry-After time", time.Since(start))
+	}
+
+	// A retried request waits at least the Retry-After time
+	config.Retries = 1
+	driver = &fakeDriver{errs: []error{httpError(http.StatusTooManyRequests, time.Millisecond*300)}}
+	limiter = NewLimiter(driver, config)
+	start = time.Now()
+	if _, err = limiter.PlaceOrder(context.Background(), types.Order{}, nil); err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if driver.callCount() != 2 || time.Since(start) < time.Millisecond*250 {
+		t.Errorf("got %d requests after %v, want the retry after the Retry-After time", driver.callCount(), time.Since(start))
+	}
+}
+
+func TestLimiterRetryAfterBeyondMaxDelay(t *testing.T) {
+	var driver *fakeDriver
+	var limiter *Limiter
+	var config LimiterConfig
+	var start time.Time
+	var err error
+
+	config = testLimiterConfig()
+	config.MaxRetryDelay = time.Millisecond * 100
+	config.FailureThreshold = 5
+	config.Cooldown = time.Millisecond * 10
+	driver = &fakeDriver{errs: []error{httpError(http.StatusTeapot, time.Second*2)}}
+	limiter = NewLimiter(driver, config)
+
+	start = time.Now()
+	if _, err = limiter.GetServerTime(context.Background()); err == nil {
+		t.Fatalf("GetServerTime: expected the banned error")
+	}
+	if driver.callCount() != 1 || time.Since(start) > time.Millisecond*100 {
+		t.Errorf("got %d requests after %v, want no retry", driver.callCount(), time.Since(start))
+	}
+	if limiter.CircuitState() != types.CircuitOpen {
+		t.Errorf("got circuit %s after a single failure, want open until the ban expires", limiter.CircuitState().String())
+	}
+
+	// The circuit stays open past the cooldown, until the Retry-After time
+	time.Sleep(time.Millisecond * 50)
+	if _, err = limiter.GetServerTime(context.Background()); err == nil || !strings.Contains(err.Error(), "Circuit breaker open") {
+		t.Errorf("GetServerTime: got %v, want the open circuit", err)
+	}
+	if driver.callCount() != 1 {
+		t.Errorf("got %d requests, want none sent while the circuit is open", driver.callCount())
+	}
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	var cb = newCircuitBreaker(2, time.Minute)
+	var start = time.Now()
+	var states []string
+	var err error
+
+	cb.addListener(func(state types.CircuitState) {
+		states = append(states, state.String())
+	})
+
+	cb.failure(start, time.Time{})
+	if cb.currentState() != types.CircuitClosed {
+		t.Errorf("got %s after one failure, want closed", cb.currentState().String())
+	}
+	cb.failure(start, time.Time{})
+	if err = cb.allow(start.Add(time.Second * 30)); err == nil {
+		t.Errorf("open circuit allowed a request during the cooldown")
+	}
+
+	// The cooldown expired, a single test request is allowed
+	if err = cb.allow(start.Add(time.Minute)); err != nil {
+		t.Errorf("half-open circuit refused the test request: %v", err)
+	}
+	if err = cb.allow(start.Add(time.Minute)); err == nil {
+		t.Errorf("half-open circuit allowed a second request")
+	}
+
+	// The failed test request opens the circuit for another cooldown
+	cb.failure(start.Add(time.Minute), time.Time{})
+	if err = cb.allow(start.Add(time.Second * 90)); err == nil {
+		t.Errorf("reopened circuit allowed a request during the cooldown")
+	}
+	if err = cb.allow(start.Add(time.Minute * 2)); err != nil {
+		t.Errorf("half-open circuit refused the test request: %v", err)
+	}
+	cb.success()
+	if err = cb.allow(start.Add(time.Minute * 2)); err != nil {
+		t.Errorf("closed circuit refused a request: %v", err)
+	}
+
+	if strings.Join(states, ",") != strings.Join([]string{
+		types.CircuitOpen.String(),
+		types.CircuitHalfOpen.String(),
+		types.CircuitOpen.String(),
+		types.CircuitHalfOpen.String(),
+		types.CircuitClosed.String(),
+	}, ",") {
+		t.Errorf("got transitions %v", states)
+	}
+}
+
+func TestLimiterCircuitBreaker(t *testing.T) {
+	var driver *fakeDriver
+	var limiter *Limiter
+	var config LimiterConfig
+	var mux sync.Mutex
+	var states []types.CircuitState
+	var err error
+
+	config = testLimiterConfig()
+	config.Retries = 0
+	config.FailureThreshold = 2
+	config.Cooldown = time.Millisecond * 50
+	driver = &fakeDriver{errs: []error{httpError(http.StatusServiceUnavailable, 0), networkError()}}
+	limiter = NewLimiter(driver, config)
+	limiter.OnCircuitStateChange(func(state types.CircuitState) {
+		mux.Lock()
+		defer mux.Unlock()
+
+		states = append(states, state)
+	})
+
+	limiter.GetServerTime(context.Background())
+	limiter.GetServerTime(context.Background())
+	if _, err = limiter.GetServerTime(context.Background()); err == nil || driver.callCount() != 2 {
+		t.Errorf("got %v after %d requests, want the open circuit to refuse the request", err, driver.callCount())
+	}
+
+	time.Sleep(config.Cooldown)
+	if _, err = limiter.GetServerTime(context.Background()); err != nil {
+		t.Errorf("test request: %v", err)
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+	if len(states) != 3 || states[0] != types.CircuitOpen || states[1] != types.CircuitHalfOpen || states[2] != types.CircuitClosed {
+		t.Errorf("got notified of %v, want open, half-open, closed", states)
+	}
+	if limiter.CircuitState() != types.CircuitClosed {
+		t.Errorf("got circuit %s, want closed", limiter.CircuitState().String())
+	}
+}
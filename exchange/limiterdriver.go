@@ -0,0 +1,222 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// Name returns the name of the wrapped exchange plugin
+func (l *Limiter) Name() string {
+	return l.driver.Name()
+}
+
+// GetAccountInfo executes the get account info request
+func (l *Limiter) GetAccountInfo(ctx context.Context) (info types.AccountInfo, err error) {
+	err = l.call(ctx, "GetAccountInfo", true, func() (err error) {
+		info, err = l.driver.GetAccountInfo(ctx)
+		return
+	})
+	return
+}
+
+// TestConnectivity tests exchange connectivity
+func (l *Limiter) TestConnectivity(ctx context.Context) (ok bool, err error) {
+	err = l.call(ctx, "TestConnectivity", true, func() (err error) {
+		ok, err = l.driver.TestConnectivity(ctx)
+		return
+	})
+	return
+}
+
+// GetServerTime executes the get server time request
+func (l *Limiter) GetServerTime(ctx context.Context) (serverTime time.Time, err error) {
+	err = l.call(ctx, "GetServerTime", true, func() (err error) {
+		serverTime, err = l.driver.GetServerTime(ctx)
+		return
+	})
+	return
+}
+
+// GetOrderBook executes the get orderbook request
+func (l *Limiter) GetOrderBook(ctx context.Context, symbol types.Symbol) (book types.OrderBook, err error) {
+	err = l.call(ctx, "GetOrderBook", true, func() (err error) {
+		book, err = l.driver.GetOrderBook(ctx, symbol)
+		return
+	})
+	return
+}
+
+// GetSeries executes the get series request
+func (l *Limiter) GetSeries(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe) (series types.Series, err error) {
+	err = l.call(ctx, "GetSeries", true, func() (err error) {
+		series, err = l.driver.GetSeries(ctx, symbol, timeframe)
+		return
+	})
+	return
+}
+
+// Ticker executes the ticker request
+func (l *Limiter) Ticker(ctx context.Context, symbol types.Symbol) (price float64, err error) {
+	err = l.call(ctx, "Ticker", true, func() (err error) {
+		price, err = l.driver.Ticker(ctx, symbol)
+		return
+	})
+	return
+}
+
+// GetSymbolInfo retrieves the symbol information for trading
+func (l *Limiter) GetSymbolInfo(ctx context.Context, symbol types.Symbol) (info types.SymbolInfo, err error) {
+	err = l.call(ctx, "GetSymbolInfo", true, func() (err error) {
+		info, err = l.driver.GetSymbolInfo(ctx, symbol)
+		return
+	})
+	return
+}
+
+// PlaceOrder executes the place order request
+func (l *Limiter) PlaceOrder(ctx context.Context, order types.Order, symbolInfo *types.SymbolInfo) (info types.OrderInfo, err error) {
+	err = l.call(ctx, "PlaceOrder", false, func() (err error) {
+		info, err = l.driver.PlaceOrder(ctx, order, symbolInfo)
+		return
+	})
+	return
+}
+
+// GetOrder executes the get order request
+func (l *Limiter) GetOrder(ctx context.Context, order types.Order) (info types.OrderInfo, err error) {
+	err = l.call(ctx, "GetOrder", true, func() (err error) {
+		info, err = l.driver.GetOrder(ctx, order)
+		return
+	})
+	return
+}
+
+// CancelOrder executes the cancel order requests
+func (l *Limiter) CancelOrder(ctx context.Context, order types.Order, newUUID uuid.UUID) (info types.OrderInfo, err error) {
+	err = l.call(ctx, "CancelOrder", true, func() (err error) {
+		info, err = l.driver.CancelOrder(ctx, order, newUUID)
+		return
+	})
+	return
+}
+
+// OpenOrders executes the open orders request
+func (l *Limiter) OpenOrders(ctx context.Context, symbol types.Symbol) (orders []types.OrderInfo, err error) {
+	err = l.call(ctx, "OpenOrders", true, func() (err error) {
+		orders, err = l.driver.OpenOrders(ctx, symbol)
+		return
+	})
+	return
+}
+
+// GetOrderTrades executs the get order trades request
+func (l *Limiter) GetOrderTrades(ctx context.Context, orderInfo types.OrderInfo) (trades []types.Trade, err error) {
+	err = l.call(ctx, "GetOrderTrades", true, func() (err error) {
+		trades, err = l.driver.GetOrderTrades(ctx, orderInfo)
+		return
+	})
+	return
+}
+
+// PlaceOCOOrder executes the place one-cancels-other order request of the wrapped plugin
+func (l *Limiter) PlaceOCOOrder(ctx context.Context, order types.OCOOrder, symbolInfo *types.SymbolInfo) (limitMaker types.OrderInfo, stopLoss types.OrderInfo, err error) {
+	var oco interfaces.IOCOExchangeDriver
+	var ok bool
+
+	if oco, ok = l.driver.(interfaces.IOCOExchangeDriver); !ok {
+		err = l.notSupported("one-cancels-other orders")
+		return
+	}
+	err = l.call(ctx, "PlaceOCOOrder", false, func() (err error) {
+		limitMaker, stopLoss, err = oco.PlaceOCOOrder(ctx, order, symbolInfo)
+		return
+	})
+	return
+}
+
+// GetSeriesRange executes the get series request for the candles opened between start and end of the wrapped plugin
+func (l *Limiter) GetSeriesRange(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe, start time.Time, end time.Time) (series types.Series, err error) {
+	var historical interfaces.IHistoricalExchangeDriver
+	var ok bool
+
+	if historical, ok = l.driver.(interfaces.IHistoricalExchangeDriver); !ok {
+		err = l.notSupported("downloading candles")
+		return
+	}
+	err = l.call(ctx, "GetSeriesRange", true, func() (err error) {
+		series, err = historical.GetSeriesRange(ctx, symbol, timeframe, start, end)
+		return
+	})
+	return
+}
+
+// StreamCandles streams the candle updates of the wrapped plugin, only opening the stream is limited
+func (l *Limiter) StreamCandles(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe) (updates types.CandleUpdateChannel, err error) {
+	var streaming interfaces.IStreamingExchangeDriver
+	var ok bool
+
+	if streaming, ok = l.driver.(interfaces.IStreamingExchangeDriver); !ok {
+		err = l.notSupported("streaming candles")
+		return
+	}
+	err = l.call(ctx, "StreamCandles", true, func() (err error) {
+		updates, err = streaming.StreamCandles(ctx, symbol, timeframe)
+		return
+	})
+	return
+}
+
+// SetLeverage executes the change leverage request of the wrapped plugin
+func (l *Limiter) SetLeverage(ctx context.Context, symbol types.Symbol, leverage int) (err error) {
+	var futures interfaces.IFuturesExchangeDriver
+	var ok bool
+
+	if futures, ok = l.driver.(interfaces.IFuturesExchangeDriver); !ok {
+		err = l.notSupported("leverage")
+		return
+	}
+	err = l.call(ctx, "SetLeverage", true, func() error {
+		return futures.SetLeverage(ctx, symbol, leverage)
+	})
+	return
+}
+
+// SetMarginType executes the change margin type request of the wrapped plugin
+func (l *Limiter) SetMarginType(ctx context.Context, symbol types.Symbol, marginType types.MarginType) (err error) {
+	var futures interfaces.IFuturesExchangeDriver
+	var ok bool
+
+	if futures, ok = l.driver.(interfaces.IFuturesExchangeDriver); !ok {
+		err = l.notSupported("margin types")
+		return
+	}
+	err = l.call(ctx, "SetMarginType", true, func() error {
+		return futures.SetMarginType(ctx, symbol, marginType)
+	})
+	return
+}
+
+// GetPosition executes the get position request of the wrapped plugin
+func (l *Limiter) GetPosition(ctx context.Context, symbol types.Symbol) (position types.Position, err error) {
+	var futures interfaces.IFuturesExchangeDriver
+	var ok bool
+
+	if futures, ok = l.driver.(interfaces.IFuturesExchangeDriver); !ok {
+		err = l.notSupported("positions")
+		return
+	}
+	err = l.call(ctx, "GetPosition", true, func() (err error) {
+		position, err = futures.GetPosition(ctx, symbol)
+		return
+	})
+	return
+}
+
+func (l *Limiter) notSupported(feature string) error {
+	return fmt.Errorf("Exchange %s does not support %s", l.driver.Name(), feature)
+}
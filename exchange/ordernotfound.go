@@ -0,0 +1,28 @@
+package exchange
+
+import "fmt"
+
+// OrderNotFoundError is returned by the exchange plugins when the exchange does not know the requested order
+// Unlike a failed request, it tells the order was never placed (or is no longer kept by the exchange).
+type OrderNotFoundError struct {
+	// Reference the client or exchange order id of the requested order
+	Reference string
+}
+
+// NewOrderNotFoundError creates a new OrderNotFoundError instance
+func NewOrderNotFoundError(reference string) *OrderNotFoundError {
+	return &OrderNotFoundError{Reference: reference}
+}
+
+// Error returns the error message
+func (e *OrderNotFoundError) Error() string {
+	return fmt.Sprintf("Order does not exist: %s", e.Reference)
+}
+
+// IsOrderNotFound returns true if the error tells the exchange does not know the order
+func IsOrderNotFound(err error) bool {
+	var ok bool
+
+	_, ok = err.(*OrderNotFoundError)
+	return ok
+}
@@ -0,0 +1,39 @@
+package simulated
+
+import "github.com/mhereman/cryptotrader/types"
+
+type market struct {
+	symbol    types.Symbol
+	timeframe types.Timeframe
+	candles   []types.OHLC
+	cursor    int
+}
+
+func (m *market) candle() types.OHLC {
+	return m.candles[m.cursor]
+}
+
+// price returns the current price of the market
+func (m *market) price() float64 {
+	return m.candles[m.cursor].Close
+}
+
+// orderBook synthesizes an order book around the current price of the market
+func (s *Simulated) orderBook(m *market) (book types.OrderBook) {
+	var price, bidPrice, askPrice float64
+	var bids, asks []types.OrderBookEntry
+	var level int
+
+	price = m.price()
+	bids = make([]types.OrderBookEntry, s.depth, s.depth)
+	asks = make([]types.OrderBookEntry, s.depth, s.depth)
+	for level = 0; level < s.depth; level++ {
+		bidPrice = price * (1.0 - s.spread*(0.5+float64(level)))
+		askPrice = price * (1.0 + s.spread*(0.5+float64(level)))
+		bids[level] = types.NewOrderBookEntry(bidPrice, s.depthQuote/bidPrice)
+		asks[level] = types.NewOrderBookEntry(askPrice, s.depthQuote/askPrice)
+	}
+
+	book = types.NewOrderBook(m.symbol, bids, asks)
+	return
+}
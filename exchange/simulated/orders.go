@@ -0,0 +1,483 @@
+package simulated
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+type simOrder struct {
+	order     types.Order
+	info      types.OrderInfo
+	trades    []types.Trade
+	triggered bool
+	locked    float64
+}
+
+func (o *simOrder) isOpen() bool {
+	return o.info.Status == types.StatusNew || o.info.Status == types.StatusPartiallyFilled
+}
+
+func (o *simOrder) remaining() float64 {
+	return o.order.Quantity - o.info.ExecutedQuantity
+}
+
+func isStopOrder(t types.OrderType) bool {
+	return t == types.StopLoss || t == types.StopLossLimit || t == types.TakeProfit || t == types.TakeProfitLimit
+}
+
+// PlaceOrder executes the place order request
+func (s *Simulated) PlaceOrder(ctx context.Context, order types.Order, symbolInfo *types.SymbolInfo) (info types.OrderInfo, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var m *market
+	var o *simOrder
+	var ok bool
+
+	if m, err = s.market(order.Symbol); err != nil {
+		logger.Errorf("Simulated::PlaceOrder Error %v\n", err)
+		return
+	}
+	if _, ok = s.orders[order.UserReference]; ok {
+		err = fmt.Errorf("Duplicate order sent: %s", order.UserReference.String())
+		logger.Errorf("Simulated::PlaceOrder Error %v\n", err)
+		return
+	}
+	if order.Quantity <= 0.0 {
+		err = fmt.Errorf("Invalid quantity: %f", order.Quantity)
+		logger.Errorf("Simulated::PlaceOrder Error %v\n", err)
+		return
+	}
+
+	s.nextOrderID++
+	o = &simOrder{
+		order: order,
+		info: types.NewOrderInfo(
+			order.UserReference,
+			s.nextOrderID,
+			order.Symbol,
+			s.clock,
+			order.Quantity,
+			0.0,
+			order.Price,
+			order.StopPrice,
+			types.StatusNew,
+			order.TimeInForce,
+			order.Type,
+			order.Side,
+		),
+		trades: make([]types.Trade, 0),
+	}
+
+	switch order.Type {
+	case types.Market:
+		err = s.placeMarket(m, o)
+	case types.Limit, types.LimitMaker:
+		err = s.placeLimit(m, o)
+	case types.StopLoss, types.StopLossLimit, types.TakeProfit, types.TakeProfitLimit:
+		err = s.placeStop(m, o)
+	default:
+		err = fmt.Errorf("Unsupported order type: %d", order.Type)
+	}
+	if err != nil {
+		logger.Errorf("Simulated::PlaceOrder Error %v\n", err)
+		return
+	}
+
+	s.orders[order.UserReference] = o
+	s.orderList = append(s.orderList, o)
+	info = o.info
+	return
+}
+
+// GetOrder executes the get order request
+func (s *Simulated) GetOrder(ctx context.Context, order types.Order) (info types.OrderInfo, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var o *simOrder
+	var ok bool
+
+	if o, ok = s.orders[order.UserReference]; !ok {
+		err = exchange.NewOrderNotFoundError(order.UserReference.String())
+		logger.Errorf("Simulated::GetOrder Error %v\n", err)
+		return
+	}
+
+	info = o.info
+	return
+}
+
+// CancelOrder executes the cancel order request
+func (s *Simulated) CancelOrder(ctx context.Context, order types.Order, newUUID uuid.UUID) (info types.OrderInfo, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var o *simOrder
+	var ok bool
+
+	if o, ok = s.orders[order.UserReference]; !ok || !o.isOpen() {
+		err = fmt.Errorf("Unknown order sent: %s", order.UserReference.String())
+		logger.Errorf("Simulated::CancelOrder Error %v\n", err)
+		return
+	}
+
+	s.unlock(o)
+	o.info.Status = types.StatusCanceled
+	o.info.CancelUserReference = newUUID
+	info = o.info
+	return
+}
+
+// OpenOrders executes the open orders request
+func (s *Simulated) OpenOrders(ctx context.Context, symbol types.Symbol) (orders []types.OrderInfo, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var o *simOrder
+
+	if _, err = s.market(symbol); err != nil {
+		logger.Errorf("Simulated::OpenOrders Error %v\n", err)
+		return
+	}
+
+	orders = make([]types.OrderInfo, 0)
+	for _, o = range s.orderList {
+		if o.order.Symbol == symbol && o.isOpen() {
+			orders = append(orders, o.info)
+		}
+	}
+	return
+}
+
+// GetOrderTrades executes the get order trades request
+func (s *Simulated) GetOrderTrades(ctx context.Context, orderInfo types.OrderInfo) (trades []types.Trade, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var o *simOrder
+	var ok bool
+
+	if o, ok = s.orders[orderInfo.UserReference]; !ok || o.info.ExchangeOrderID != orderInfo.ExchangeOrderID {
+		err = exchange.NewOrderNotFoundError(strconv.FormatInt(orderInfo.ExchangeOrderID, 10))
+		logger.Errorf("Simulated::GetOrderTrades Error %v\n", err)
+		return
+	}
+
+	trades = make([]types.Trade, len(o.trades))
+	copy(trades, o.trades)
+	return
+}
+
+func (s *Simulated) placeMarket(m *market, o *simOrder) (err error) {
+	var book types.OrderBook
+
+	book = s.orderBook(m)
+	if err = s.checkTakerFunds(book, o, math.NaN()); err != nil {
+		return
+	}
+
+	s.takeLiquidity(book, o, math.NaN())
+	if o.remaining() > 0.0 {
+		o.info.Status = types.StatusExpired
+	}
+	return
+}
+
+func (s *Simulated) placeLimit(m *market, o *simOrder) (err error) {
+	var book types.OrderBook
+	var marketable bool
+
+	if o.order.Price <= 0.0 {
+		err = fmt.Errorf("Invalid price: %f", o.order.Price)
+		return
+	}
+
+	book = s.orderBook(m)
+	if o.order.Side == types.Buy {
+		marketable = o.order.Price >= book.Asks[0].Price
+	} else {
+		marketable = o.order.Price <= book.Bids[0].Price
+	}
+
+	if marketable && o.order.Type == types.LimitMaker {
+		err = fmt.Errorf("Order would immediately match and take")
+		return
+	}
+
+	if marketable {
+		if o.order.TimeInForce == types.FillOrCancel && fillableQuantity(book, o.order.Side, o.order.Price) < o.order.Quantity {
+			o.info.Status = types.StatusExpired
+			return
+		}
+		if err = s.checkTakerFunds(book, o, o.order.Price); err != nil {
+			return
+		}
+		s.takeLiquidity(book, o, o.order.Price)
+		if o.remaining() <= 0.0 {
+			return
+		}
+	}
+
+	if o.order.TimeInForce != types.GoodTillCancel {
+		o.info.Status = types.StatusExpired
+		return
+	}
+
+	err = s.lock(o, o.order.Price)
+	return
+}
+
+func (s *Simulated) placeStop(m *market, o *simOrder) (err error) {
+	var price float64
+
+	if o.order.StopPrice <= 0.0 {
+		err = fmt.Errorf("Invalid stop price: %f", o.order.StopPrice)
+		return
+	}
+	if (o.order.Type == types.StopLossLimit || o.order.Type == types.TakeProfitLimit) && o.order.Price <= 0.0 {
+		err = fmt.Errorf("Invalid price: %f", o.order.Price)
+		return
+	}
+
+	price = m.price()
+	if triggersOnFall(o.order) && price <= o.order.StopPrice || !triggersOnFall(o.order) && price >= o.order.StopPrice {
+		err = fmt.Errorf("Order would trigger immediately")
+		return
+	}
+
+	if o.order.Type == types.StopLossLimit || o.order.Type == types.TakeProfitLimit {
+		err = s.lock(o, o.order.Price)
+	} else {
+		err = s.lock(o, o.order.StopPrice)
+	}
+	return
+}
+
+// triggersOnFall returns true if the stop order triggers when the price drops to the stop price
+func triggersOnFall(order types.Order) bool {
+	if order.Type == types.StopLoss || order.Type == types.StopLossLimit {
+		return order.Side == types.Sell
+	}
+	return order.Side == types.Buy
+}
+
+// matchOrders matches the open orders of the market against the price range of its current candle
+func (s *Simulated) matchOrders(m *market) {
+	var o *simOrder
+	var candle types.OHLC
+	var price float64
+
+	candle = m.candle()
+	for _, o = range s.orderList {
+		if o.order.Symbol != m.symbol || !o.isOpen() {
+			continue
+		}
+
+		if isStopOrder(o.order.Type) && !o.triggered {
+			if triggersOnFall(o.order) && candle.Low > o.order.StopPrice || !triggersOnFall(o.order) && candle.High < o.order.StopPrice {
+				continue
+			}
+			o.triggered = true
+			logger.Debugf("Simulated: Order %s triggered at %f\n", o.order.UserReference.String(), o.order.StopPrice)
+
+			if o.order.Type == types.StopLoss || o.order.Type == types.TakeProfit {
+				// On a gap through the stop price the order is filled at the open price
+				price = o.order.StopPrice
+				if triggersOnFall(o.order) && candle.Open < price || !triggersOnFall(o.order) && candle.Open > price {
+					price = candle.Open
+				}
+				s.fillResting(o, price, false)
+				continue
+			}
+		}
+
+		if o.order.Side == types.Buy && candle.Low <= o.order.Price || o.order.Side == types.Sell && candle.High >= o.order.Price {
+			s.fillResting(o, o.order.Price, !isStopOrder(o.order.Type))
+		}
+	}
+}
+
+// fillableQuantity returns the base quantity on the book up to the limit price
+func fillableQuantity(book types.OrderBook, side types.Side, limit float64) (quantity float64) {
+	var entry types.OrderBookEntry
+	var entries []types.OrderBookEntry
+
+	entries = book.Asks
+	if side == types.Sell {
+		entries = book.Bids
+	}
+	for _, entry = range entries {
+		if side == types.Buy && entry.Price > limit || side == types.Sell && entry.Price < limit {
+			break
+		}
+		quantity += entry.Quantity
+	}
+	return
+}
+
+// checkTakerFunds verifies the balance is sufficient to take the order from the book
+// A NaN limit means there is no limit price.
+func (s *Simulated) checkTakerFunds(book types.OrderBook, o *simOrder, limit float64) (err error) {
+	var required, available, remaining, quantity float64
+	var entry types.OrderBookEntry
+
+	if o.order.Side == types.Sell {
+		required = o.order.Quantity
+		available = s.balance(o.order.Symbol.Base()).Free
+	} else {
+		remaining = o.order.Quantity
+		for _, entry = range book.Asks {
+			if remaining <= 0.0 || (!math.IsNaN(limit) && entry.Price > limit) {
+				break
+			}
+			quantity = math.Min(remaining, entry.Quantity)
+			required += quantity * entry.Price
+			remaining -= quantity
+		}
+		available = s.balance(o.order.Symbol.Quote()).Free
+	}
+
+	if required > available {
+		err = fmt.Errorf("Account has insufficient balance for requested action (required: %f, available: %f)", required, available)
+	}
+	return
+}
+
+// takeLiquidity fills the order against the book
+// A NaN limit means there is no limit price.
+func (s *Simulated) takeLiquidity(book types.OrderBook, o *simOrder, limit float64) {
+	var entries []types.OrderBookEntry
+	var entry types.OrderBookEntry
+	var quantity float64
+
+	entries = book.Asks
+	if o.order.Side == types.Sell {
+		entries = book.Bids
+	}
+
+	for _, entry = range entries {
+		if o.remaining() <= 0.0 {
+			break
+		}
+		if !math.IsNaN(limit) && (o.order.Side == types.Buy && entry.Price > limit || o.order.Side == types.Sell && entry.Price < limit) {
+			break
+		}
+
+		quantity = math.Min(o.remaining(), entry.Quantity)
+		if o.order.Side == types.Buy {
+			s.balance(o.order.Symbol.Quote()).Free -= quantity * entry.Price
+		} else {
+			s.balance(o.order.Symbol.Base()).Free -= quantity
+		}
+		s.fill(o, entry.Price, quantity, false)
+	}
+}
+
+// fillResting fills the remaining quantity of an order which reserved its funds
+func (s *Simulated) fillResting(o *simOrder, price float64, isMaker bool) {
+	var quantity float64
+
+	quantity = o.remaining()
+	if o.order.Side == types.Buy {
+		s.balance(o.order.Symbol.Quote()).Locked -= o.locked
+		s.balance(o.order.Symbol.Quote()).Free += o.locked - quantity*price
+	} else {
+		s.balance(o.order.Symbol.Base()).Locked -= o.locked
+		s.balance(o.order.Symbol.Base()).Free += o.locked - quantity
+	}
+	o.locked = 0.0
+
+	s.fill(o, price, quantity, isMaker)
+}
+
+// fill books the fill on the order and credits the received asset minus the commission
+func (s *Simulated) fill(o *simOrder, price float64, quantity float64, isMaker bool) {
+	var commissionRate, commission float64
+	var commissionAsset string
+
+	commissionRate = s.takerCommission
+	if isMaker {
+		commissionRate = s.makerCommission
+	}
+
+	if o.order.Side == types.Buy {
+		commissionAsset = o.order.Symbol.Base()
+		commission = quantity * commissionRate
+		s.balance(commissionAsset).Free += quantity - commission
+	} else {
+		commissionAsset = o.order.Symbol.Quote()
+		commission = quantity * price * commissionRate
+		s.balance(commissionAsset).Free += quantity*price - commission
+	}
+
+	s.nextTradeID++
+	o.trades = append(o.trades, types.NewTrade(
+		o.order.Symbol,
+		s.nextTradeID,
+		o.info.ExchangeOrderID,
+		price,
+		quantity,
+		quantity*price,
+		commission,
+		commissionAsset,
+		s.clock,
+		o.order.Side == types.Buy,
+		isMaker,
+		true,
+	))
+	o.info.AppendFills(types.NewOrderFill(price, quantity, commission, commissionAsset))
+
+	o.info.ExecutedQuantity += quantity
+	o.info.Status = types.StatusPartiallyFilled
+	if o.remaining() <= 0.0 {
+		o.info.Status = types.StatusFilled
+	}
+}
+
+// lock reserves the funds of a resting order
+func (s *Simulated) lock(o *simOrder, price float64) (err error) {
+	var balance *types.AccountBalance
+	var amount float64
+
+	if o.order.Side == types.Buy {
+		balance = s.balance(o.order.Symbol.Quote())
+		amount = o.remaining() * price
+	} else {
+		balance = s.balance(o.order.Symbol.Base())
+		amount = o.remaining()
+	}
+
+	if amount > balance.Free {
+		err = fmt.Errorf("Account has insufficient balance for requested action (required: %f, available: %f)", amount, balance.Free)
+		return
+	}
+
+	balance.Free -= amount
+	balance.Locked += amount
+	o.locked = amount
+	return
+}
+
+// unlock releases the reserved funds of an order
+func (s *Simulated) unlock(o *simOrder) {
+	var balance *types.AccountBalance
+
+	if o.order.Side == types.Buy {
+		balance = s.balance(o.order.Symbol.Quote())
+	} else {
+		balance = s.balance(o.order.Symbol.Base())
+	}
+
+	balance.Locked -= o.locked
+	balance.Free += o.locked
+	o.locked = 0.0
+}
@@ -0,0 +1,491 @@
+package simulated
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/history"
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	exchangeName = "simulated"
+
+	defaultCommission   = 0.001
+	defaultSpread       = 0.001
+	defaultDepth        = 10
+	defaultDepthQuote   = 100000.0
+	defaultWarmup       = 500
+	defaultStepInterval = time.Second
+	defaultMinPrice     = "0.01000000"
+	defaultMinQuantity  = "0.00000100"
+	maxSeriesLength     = 500
+)
+
+func init() {
+	exchange.RegisterExchange(exchangeName, createSimulated)
+}
+
+// Simulated represents an in memory exchange which replays historical candles
+// The clock is at the close time of the current candle, so its close price is the market price and the
+// candle is only visible once it is complete. Orders are matched against the synthesized order book of
+// the current candle and resting orders are filled when the price range of a new candle crosses them.
+type Simulated struct {
+	mux             sync.Mutex
+	markets         map[string]*market
+	clock           time.Time
+	balances        map[string]*types.AccountBalance
+	orders          map[uuid.UUID]*simOrder
+	orderList       []*simOrder
+	nextOrderID     int64
+	nextTradeID     int64
+	makerCommission float64
+	takerCommission float64
+	spread          float64
+	depth           int
+	depthQuote      float64
+	minPrice        string
+	minQuantity     string
+}
+
+// New creates a new Simulated exchange plugin
+//
+// Configuration entries:
+//
+//	data:            comma separated list of SYMBOL@TIMEFRAME:path entries, e.g. BTC/USDT@4h:/data/btcusdt-4h.csv
+//	balances:        comma separated list of ASSET:quantity entries, e.g. USDT:1000,BTC:0.1
+//	makerCommission: commission for maker orders (default 0.001)
+//	takerCommission: commission for taker orders (default 0.001)
+//	spread:          spread between the best bid and ask relative to the price (default 0.001)
+//	depth:           number of levels on each side of the order book (default 10)
+//	depthQuote:      quote asset quantity on each level of the order book (default 100000)
+//	warmup:          number of candles available before the replay starts (default 500)
+//	stepInterval:    real time between two replayed candles, 0 disables automatic replay (default 1s)
+//	minPrice:        price filter of the symbols (default 0.01000000)
+//	minQuantity:     lot size filter of the symbols (default 0.00000100)
+func New(ctx context.Context, config map[string]string) (driver *Simulated, err error) {
+	var dataString string
+	var warmup int
+	var stepInterval time.Duration
+	var ok bool
+
+	driver = &Simulated{
+		markets:         make(map[string]*market),
+		balances:        make(map[string]*types.AccountBalance),
+		orders:          make(map[uuid.UUID]*simOrder),
+		orderList:       make([]*simOrder, 0),
+		makerCommission: defaultCommission,
+		takerCommission: defaultCommission,
+		spread:          defaultSpread,
+		depth:           defaultDepth,
+		depthQuote:      defaultDepthQuote,
+		minPrice:        defaultMinPrice,
+		minQuantity:     defaultMinQuantity,
+	}
+	warmup = defaultWarmup
+	stepInterval = defaultStepInterval
+
+	if dataString, ok = config["data"]; !ok {
+		err = fmt.Errorf("Simulated config error: 'data' entry not found")
+		return
+	}
+
+	if err = driver.parseBalances(config["balances"]); err != nil {
+		err = fmt.Errorf("Simulated config error: 'balances' %v", err)
+		return
+	}
+
+	if err = parseFloatArg(config, "makerCommission", &driver.makerCommission); err != nil {
+		return
+	}
+	if err = parseFloatArg(config, "takerCommission", &driver.takerCommission); err != nil {
+		return
+	}
+	if err = parseFloatArg(config, "spread", &driver.spread); err != nil {
+		return
+	}
+	if err = parseFloatArg(config, "depthQuote", &driver.depthQuote); err != nil {
+		return
+	}
+	if err = parseIntArg(config, "depth", &driver.depth); err != nil {
+		return
+	}
+	if err = parseIntArg(config, "warmup", &warmup); err != nil {
+		return
+	}
+	if _, ok = config["stepInterval"]; ok {
+		if stepInterval, err = time.ParseDuration(config["stepInterval"]); err != nil {
+			err = fmt.Errorf("Simulated config error: 'stepInterval' %v", err)
+			return
+		}
+	}
+	if _, ok = config["minPrice"]; ok {
+		driver.minPrice = config["minPrice"]
+	}
+	if _, ok = config["minQuantity"]; ok {
+		driver.minQuantity = config["minQuantity"]
+	}
+
+	if err = driver.loadMarkets(dataString, warmup); err != nil {
+		logger.Errorf("Simulated::New Error %v\n", err)
+		return
+	}
+
+	if stepInterval > 0 {
+		go stepRoutine(ctx, driver, stepInterval)
+	}
+	return
+}
+
+func createSimulated(ctx context.Context, config map[string]string) (driver interfaces.IExchangeDriver, err error) {
+	driver, err = New(ctx, config)
+	return
+}
+
+// Name returns the name of the exchange plugin
+func (s *Simulated) Name() string {
+	return exchangeName
+}
+
+// RateLimits returns no limits, the simulated exchange does not throttle its requests
+func (s *Simulated) RateLimits() types.RateLimits {
+	return types.RateLimits{}
+}
+
+// Step advances the simulation clock to the close time of the next candle
+// Resting orders are matched against the price range of the new candles.
+// Step returns false when all candles have been replayed.
+func (s *Simulated) Step() bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var m *market
+	var next time.Time
+	var found bool
+
+	for _, m = range s.markets {
+		if m.cursor+1 >= len(m.candles) {
+			continue
+		}
+		if !found || m.candles[m.cursor+1].CloseTime.Before(next) {
+			next = m.candles[m.cursor+1].CloseTime
+			found = true
+		}
+	}
+	if !found {
+		return false
+	}
+
+	s.clock = next
+	for _, m = range s.markets {
+		for m.cursor+1 < len(m.candles) && !m.candles[m.cursor+1].CloseTime.After(s.clock) {
+			m.cursor++
+			s.matchOrders(m)
+		}
+	}
+	return true
+}
+
+// GetAccountInfo executes the get account info request
+func (s *Simulated) GetAccountInfo(ctx context.Context) (info types.AccountInfo, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var assets []string
+	var asset string
+	var balances []types.AccountBalance
+
+	assets = make([]string, 0, len(s.balances))
+	for asset = range s.balances {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+
+	balances = make([]types.AccountBalance, 0, len(assets))
+	for _, asset = range assets {
+		balances = append(balances, *s.balances[asset])
+	}
+
+	info = types.NewAccountInfo(s.makerCommission, s.takerCommission, 0.0, 0.0, balances)
+	return
+}
+
+// TestConnectivity tests exchange connectivity
+func (s *Simulated) TestConnectivity(ctx context.Context) (ok bool, err error) {
+	ok = true
+	return
+}
+
+// GetServerTime executes the get server time request
+// The server time is the close time of the current candle.
+func (s *Simulated) GetServerTime(ctx context.Context) (serverTime time.Time, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	serverTime = s.clock
+	return
+}
+
+// GetOrderBook executes the get orderbook request
+func (s *Simulated) GetOrderBook(ctx context.Context, symbol types.Symbol) (book types.OrderBook, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var m *market
+
+	if m, err = s.market(symbol); err != nil {
+		logger.Errorf("Simulated::GetOrderBook Error %v\n", err)
+		return
+	}
+
+	book = s.orderBook(m)
+	return
+}
+
+// GetSeries executes the get series request
+func (s *Simulated) GetSeries(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe) (series types.Series, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var m *market
+	var start int
+	var candles []types.OHLC
+
+	if m, err = s.market(symbol); err != nil {
+		logger.Errorf("Simulated::GetSeries Error %v\n", err)
+		return
+	}
+	if m.timeframe != timeframe {
+		err = fmt.Errorf("Timeframe %s is not available for symbol %s", timeframe.String(), symbol.String())
+		logger.Errorf("Simulated::GetSeries Error %v\n", err)
+		return
+	}
+
+	start = m.cursor + 1 - maxSeriesLength
+	if start < 0 {
+		start = 0
+	}
+	candles = make([]types.OHLC, m.cursor+1-start)
+	copy(candles, m.candles[start:m.cursor+1])
+
+	series = types.NewSeries(m.symbol, m.timeframe, candles)
+	return
+}
+
+// GetSeriesRange executes the get series request for the candles opened between start and end
+// Only candles up to the current candle are returned, at most 500 per request.
+func (s *Simulated) GetSeriesRange(ctx context.Context, symbol types.Symbol, timeframe types.Timeframe, start time.Time, end time.Time) (series types.Series, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var m *market
+	var first, last int
+
+	if m, err = s.market(symbol); err != nil {
+		logger.Errorf("Simulated::GetSeriesRange Error %v\n", err)
+		return
+	}
+	if m.timeframe != timeframe {
+		err = fmt.Errorf("Timeframe %s is not available for symbol %s", timeframe.String(), symbol.String())
+		logger.Errorf("Simulated::GetSeriesRange Error %v\n", err)
+		return
+	}
+
+	first = sort.Search(m.cursor+1, func(i int) bool {
+		return !m.candles[i].OpenTime.Before(start)
+	})
+	last = first
+	for last <= m.cursor && last-first < maxSeriesLength && !m.candles[last].OpenTime.After(end) {
+		last++
+	}
+
+	series = types.NewSeries(m.symbol, m.timeframe, make([]types.OHLC, last-first))
+	copy(series.Candles, m.candles[first:last])
+	return
+}
+
+// Ticker executes the ticker request
+// The price is the close price of the current candle.
+func (s *Simulated) Ticker(ctx context.Context, symbol types.Symbol) (price float64, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var m *market
+
+	if m, err = s.market(symbol); err != nil {
+		logger.Errorf("Simulated::Ticker Error %v\n", err)
+		return
+	}
+
+	price = m.price()
+	return
+}
+
+// GetSymbolInfo retrieves the symbol information for trading
+func (s *Simulated) GetSymbolInfo(ctx context.Context, symbol types.Symbol) (info types.SymbolInfo, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if _, err = s.market(symbol); err != nil {
+		logger.Errorf("Simulated::GetSymbolInfo Error %v\n", err)
+		return
+	}
+
+	info = types.NewSymbolInfo(symbol, s.minPrice, s.minQuantity)
+	return
+}
+
+func (s *Simulated) market(symbol types.Symbol) (m *market, err error) {
+	var ok bool
+
+	if m, ok = s.markets[symbol.String()]; !ok {
+		err = fmt.Errorf("Symbol '%s' is not available on the simulated exchange", symbol.String())
+		return
+	}
+	return
+}
+
+func (s *Simulated) balance(asset string) (balance *types.AccountBalance) {
+	var ok bool
+
+	asset = strings.ToUpper(asset)
+	if balance, ok = s.balances[asset]; !ok {
+		balance = &types.AccountBalance{Asset: asset}
+		s.balances[asset] = balance
+	}
+	return
+}
+
+func (s *Simulated) loadMarkets(in string, warmup int) (err error) {
+	var entry, marketString, path string
+	var parts []string
+	var symbol types.Symbol
+	var timeframe types.Timeframe
+	var series types.Series
+	var m *market
+
+	for _, entry = range strings.Split(in, ",") {
+		if parts = strings.SplitN(strings.TrimSpace(entry), ":", 2); len(parts) != 2 {
+			err = fmt.Errorf("Invalid data entry '%s', expected SYMBOL@TIMEFRAME:path", entry)
+			return
+		}
+		marketString, path = parts[0], parts[1]
+
+		if parts = strings.Split(marketString, "@"); len(parts) != 2 {
+			err = fmt.Errorf("Invalid data entry '%s', expected SYMBOL@TIMEFRAME:path", entry)
+			return
+		}
+		if symbol, err = types.NewSymbolFromString(parts[0]); err != nil {
+			return
+		}
+		if timeframe, err = types.NewTimeframeFromString(parts[1]); err != nil {
+			return
+		}
+
+		if series, err = history.LoadCSV(path, symbol, timeframe); err != nil {
+			return
+		}
+		if series.Length() == 0 {
+			err = fmt.Errorf("No candles found in %s", path)
+			return
+		}
+
+		m = &market{
+			symbol:    symbol,
+			timeframe: timeframe,
+			candles:   series.Candles,
+			cursor:    warmup - 1,
+		}
+		if m.cursor < 0 {
+			m.cursor = 0
+		}
+		if m.cursor >= len(m.candles) {
+			m.cursor = len(m.candles) - 1
+		}
+		if m.candles[m.cursor].CloseTime.After(s.clock) {
+			s.clock = m.candles[m.cursor].CloseTime
+		}
+		s.markets[symbol.String()] = m
+		logger.Infof("Simulated: Loaded %d candles for %s[%s]\n", len(m.candles), symbol.String(), timeframe.String())
+	}
+	return
+}
+
+func (s *Simulated) parseBalances(in string) (err error) {
+	var entry string
+	var parts []string
+	var free float64
+
+	if strings.TrimSpace(in) == "" {
+		return
+	}
+
+	for _, entry = range strings.Split(in, ",") {
+		if parts = strings.Split(strings.TrimSpace(entry), ":"); len(parts) != 2 {
+			err = fmt.Errorf("Invalid balance entry '%s', expected ASSET:quantity", entry)
+			return
+		}
+		if free, err = strconv.ParseFloat(parts[1], 64); err != nil {
+			return
+		}
+		s.balance(parts[0]).Free = free
+	}
+	return
+}
+
+func parseFloatArg(config map[string]string, key string, out *float64) (err error) {
+	var value string
+	var ok bool
+
+	if value, ok = config[key]; !ok {
+		return
+	}
+	if *out, err = strconv.ParseFloat(value, 64); err != nil {
+		err = fmt.Errorf("Simulated config error: '%s' %v", key, err)
+	}
+	return
+}
+
+func parseIntArg(config map[string]string, key string, out *int) (err error) {
+	var value string
+	var ok bool
+
+	if value, ok = config[key]; !ok {
+		return
+	}
+	if *out, err = strconv.Atoi(value); err != nil {
+		err = fmt.Errorf("Simulated config error: '%s' %v", key, err)
+	}
+	return
+}
+
+func stepRoutine(ctx context.Context, s *Simulated, interval time.Duration) {
+	var ticker *time.Ticker
+	var runLoop bool
+
+	ticker = time.NewTicker(interval)
+	defer ticker.Stop()
+
+	runLoop = true
+	for runLoop {
+		select {
+		case <-ctx.Done():
+			runLoop = false
+		case <-ticker.C:
+			if !s.Step() {
+				logger.Infoln("Simulated: All candles have been replayed")
+				runLoop = false
+			}
+		}
+	}
+}
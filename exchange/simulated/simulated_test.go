@@ -0,0 +1,282 @@
+package simulated
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mhereman/cryptotrader/exchange"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// testCandles are hourly candles, the first two are the warmup and the replay starts at the close of the second
+const testCandles = `open_time,open,high,low,close,volume
+2021-01-01T00:00:00Z,100,101,99,100,10
+2021-01-01T01:00:00Z,100,102,99,101,10
+2021-01-01T02:00:00Z,101,103,95,96,10
+2021-01-01T03:00:00Z,96,120,96,110,10
+2021-01-01T04:00:00Z,110,111,109,110,10
+`
+
+var testSymbol = types.NewSymbol("BTC", "USDT")
+
+// newTestSimulated creates a simulated exchange on the test candles without spread and automatic replay
+func newTestSimulated(t *testing.T) (s *Simulated) {
+	var path string
+	var err error
+
+	path = filepath.Join(t.TempDir(), "btcusdt-1h.csv")
+	if err = os.WriteFile(path, []byte(testCandles), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if s, err = New(context.Background(), map[string]string{
+		"data":            "BTC/USDT@1h:" + path,
+		"balances":        "USDT:10000,BTC:1",
+		"makerCommission": "0.0005",
+		"takerCommission": "0.001",
+		"spread":          "0",
+		"depth":           "1",
+		"depthQuote":      "1000000",
+		"warmup":          "2",
+		"stepInterval":    "0",
+	}); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return
+}
+
+func testOrder(orderType types.OrderType, side types.Side, timeInForce types.TimeInForce, quantity float64, price float64, stopPrice float64) types.Order {
+	return types.Order{
+		UserReference: uuid.New(),
+		Symbol:        testSymbol,
+		Side:          side,
+		Type:          orderType,
+		TimeInForce:   timeInForce,
+		Quantity:      quantity,
+		Price:         price,
+		StopPrice:     stopPrice,
+	}
+}
+
+func equal(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestClock(t *testing.T) {
+	var s *Simulated
+	var serverTime, closeTime time.Time
+	var series types.Series
+	var price float64
+	var err error
+
+	s = newTestSimulated(t)
+
+	// The market is at the close of the second candle, the third candle is not known yet
+	if price, err = s.Ticker(context.Background(), testSymbol); err != nil || price != 101.0 {
+		t.Errorf("Ticker: got %f %v, want the close of the current candle 101", price, err)
+	}
+	if series, err = s.GetSeries(context.Background(), testSymbol, types.NewTimeframe(1, types.TuHour)); err != nil {
+		t.Fatalf("GetSeries: %v", err)
+	}
+	if serverTime, err = s.GetServerTime(context.Background()); err != nil {
+		t.Fatalf("GetServerTime: %v", err)
+	}
+	closeTime = series.Candles[series.Length()-1].CloseTime
+	if series.Length() != 2 || closeTime.After(serverTime) {
+		t.Errorf("series of %d candles closing at %v, server time %v: got candles from the future", series.Length(), closeTime, serverTime)
+	}
+
+	if !s.Step() {
+		t.Fatalf("Step: no candles left")
+	}
+	if price, err = s.Ticker(context.Background(), testSymbol); err != nil || price != 96.0 {
+		t.Errorf("Ticker after step: got %f %v, want 96", price, err)
+	}
+	if serverTime, err = s.GetServerTime(context.Background()); err != nil || !serverTime.After(closeTime) {
+		t.Errorf("GetServerTime after step: got %v, want after %v", serverTime, closeTime)
+	}
+
+	s.Step()
+	s.Step()
+	if s.Step() {
+		t.Errorf("Step: got true after all candles were replayed")
+	}
+}
+
+func TestPlaceOrder(t *testing.T) {
+	var tests = []struct {
+		name       string
+		order      types.Order
+		fails      bool
+		status     types.OrderStatus
+		quoteFree  float64
+		quoteLock  float64
+		baseFree   float64
+		baseLocked float64
+	}{
+		{"market buy", testOrder(types.Market, types.Buy, types.GoodTillCancel, 1.0, 0.0, 0.0), false, types.StatusFilled, 9899.0, 0.0, 1.999, 0.0},
+		{"market sell", testOrder(types.Market, types.Sell, types.GoodTillCancel, 0.5, 0.0, 0.0), false, types.StatusFilled, 10050.4495, 0.0, 0.5, 0.0},
+		{"market buy insufficient balance", testOrder(types.Market, types.Buy, types.GoodTillCancel, 200.0, 0.0, 0.0), true, 0, 10000.0, 0.0, 1.0, 0.0},
+		{"limit buy resting", testOrder(types.Limit, types.Buy, types.GoodTillCancel, 1.0, 90.0, 0.0), false, types.StatusNew, 9910.0, 90.0, 1.0, 0.0},
+		{"limit buy marketable", testOrder(types.Limit, types.Buy, types.GoodTillCancel, 1.0, 105.0, 0.0), false, types.StatusFilled, 9899.0, 0.0, 1.999, 0.0},
+		{"limit sell immediate or cancel", testOrder(types.Limit, types.Sell, types.ImmediateOrCancel, 1.0, 110.0, 0.0), false, types.StatusExpired, 10000.0, 0.0, 1.0, 0.0},
+		{"limit sell without price", testOrder(types.Limit, types.Sell, types.GoodTillCancel, 1.0, 0.0, 0.0), true, 0, 10000.0, 0.0, 1.0, 0.0},
+		{"limit maker resting", testOrder(types.LimitMaker, types.Buy, types.GoodTillCancel, 1.0, 100.0, 0.0), false, types.StatusNew, 9900.0, 100.0, 1.0, 0.0},
+		{"limit maker marketable", testOrder(types.LimitMaker, types.Buy, types.GoodTillCancel, 1.0, 105.0, 0.0), true, 0, 10000.0, 0.0, 1.0, 0.0},
+		{"stop loss", testOrder(types.StopLoss, types.Sell, types.GoodTillCancel, 1.0, 0.0, 98.0), false, types.StatusNew, 10000.0, 0.0, 0.0, 1.0},
+		{"stop loss triggering immediately", testOrder(types.StopLoss, types.Sell, types.GoodTillCancel, 1.0, 0.0, 102.0), true, 0, 10000.0, 0.0, 1.0, 0.0},
+		{"stop loss limit", testOrder(types.StopLossLimit, types.Sell, types.GoodTillCancel, 1.0, 97.0, 98.0), false, types.StatusNew, 10000.0, 0.0, 0.0, 1.0},
+		{"stop loss limit without price", testOrder(types.StopLossLimit, types.Sell, types.GoodTillCancel, 1.0, 0.0, 98.0), true, 0, 10000.0, 0.0, 1.0, 0.0},
+		{"take profit", testOrder(types.TakeProfit, types.Sell, types.GoodTillCancel, 1.0, 0.0, 108.0), false, types.StatusNew, 10000.0, 0.0, 0.0, 1.0},
+		{"take profit buy", testOrder(types.TakeProfit, types.Buy, types.GoodTillCancel, 1.0, 0.0, 95.0), false, types.StatusNew, 9905.0, 95.0, 1.0, 0.0},
+		{"take profit limit", testOrder(types.TakeProfitLimit, types.Sell, types.GoodTillCancel, 1.0, 107.0, 108.0), false, types.StatusNew, 10000.0, 0.0, 0.0, 1.0},
+		{"take profit limit insufficient balance", testOrder(types.TakeProfitLimit, types.Sell, types.GoodTillCancel, 2.0, 107.0, 108.0), true, 0, 10000.0, 0.0, 1.0, 0.0},
+		{"unsupported order type", testOrder(types.OrderType(99), types.Buy, types.GoodTillCancel, 1.0, 100.0, 0.0), true, 0, 10000.0, 0.0, 1.0, 0.0},
+	}
+	var s *Simulated
+	var info types.OrderInfo
+	var quote, base *types.AccountBalance
+	var index int
+	var err error
+
+	for index = range tests {
+		s = newTestSimulated(t)
+
+		info, err = s.PlaceOrder(context.Background(), tests[index].order, nil)
+		if (err != nil) != tests[index].fails {
+			t.Errorf("%s: got error %v, want failure %v", tests[index].name, err, tests[index].fails)
+		}
+		if err == nil && info.Status != tests[index].status {
+			t.Errorf("%s: got status %v, want %v", tests[index].name, info.Status, tests[index].status)
+		}
+		if _, err = s.GetOrder(context.Background(), tests[index].order); (err != nil) != tests[index].fails {
+			t.Errorf("%s: GetOrder: %v", tests[index].name, err)
+		}
+
+		quote, base = s.balance("USDT"), s.balance("BTC")
+		if !equal(quote.Free, tests[index].quoteFree) || !equal(quote.Locked, tests[index].quoteLock) {
+			t.Errorf("%s: got USDT %f (locked %f), want %f (locked %f)", tests[index].name, quote.Free, quote.Locked, tests[index].quoteFree, tests[index].quoteLock)
+		}
+		if !equal(base.Free, tests[index].baseFree) || !equal(base.Locked, tests[index].baseLocked) {
+			t.Errorf("%s: got BTC %f (locked %f), want %f (locked %f)", tests[index].name, base.Free, base.Locked, tests[index].baseFree, tests[index].baseLocked)
+		}
+	}
+}
+
+func TestStopOrdersTrigger(t *testing.T) {
+	var tests = []struct {
+		name      string
+		order     types.Order
+		steps     int
+		status    types.OrderStatus
+		price     float64
+		quoteFree float64
+	}{
+		{"not triggered before the price moves", testOrder(types.StopLossLimit, types.Sell, types.GoodTillCancel, 1.0, 97.0, 98.0), 0, types.StatusNew, 0.0, 10000.0},
+		{"stop loss limit filled on the drop", testOrder(types.StopLossLimit, types.Sell, types.GoodTillCancel, 1.0, 97.0, 98.0), 1, types.StatusFilled, 97.0, 10096.903},
+		{"stop loss limit triggered, limit not reached", testOrder(types.StopLossLimit, types.Sell, types.GoodTillCancel, 1.0, 104.0, 98.0), 1, types.StatusNew, 0.0, 10000.0},
+		{"stop loss limit filled on a later candle", testOrder(types.StopLossLimit, types.Sell, types.GoodTillCancel, 1.0, 104.0, 98.0), 2, types.StatusFilled, 104.0, 10103.896},
+		{"stop loss filled at the stop price", testOrder(types.StopLoss, types.Sell, types.GoodTillCancel, 1.0, 0.0, 98.0), 1, types.StatusFilled, 98.0, 10097.902},
+		{"take profit limit filled on the rise", testOrder(types.TakeProfitLimit, types.Sell, types.GoodTillCancel, 1.0, 107.0, 108.0), 2, types.StatusFilled, 107.0, 10106.893},
+	}
+	var s *Simulated
+	var info types.OrderInfo
+	var trades []types.Trade
+	var serverTime time.Time
+	var step, index int
+	var err error
+
+	for index = range tests {
+		s = newTestSimulated(t)
+		if _, err = s.PlaceOrder(context.Background(), tests[index].order, nil); err != nil {
+			t.Fatalf("%s: PlaceOrder: %v", tests[index].name, err)
+		}
+		for step = 0; step < tests[index].steps; step++ {
+			s.Step()
+		}
+
+		if info, err = s.GetOrder(context.Background(), tests[index].order); err != nil {
+			t.Fatalf("%s: GetOrder: %v", tests[index].name, err)
+		}
+		if info.Status != tests[index].status {
+			t.Errorf("%s: got status %v, want %v", tests[index].name, info.Status, tests[index].status)
+		}
+		if !equal(s.balance("USDT").Free, tests[index].quoteFree) {
+			t.Errorf("%s: got USDT %f, want %f", tests[index].name, s.balance("USDT").Free, tests[index].quoteFree)
+		}
+		if info.Status != types.StatusFilled {
+			if s.balance("BTC").Locked != 1.0 {
+				t.Errorf("%s: got BTC locked %f, want the order quantity", tests[index].name, s.balance("BTC").Locked)
+			}
+			continue
+		}
+
+		// The filled order releases its locked funds
+		if s.balance("BTC").Free != 0.0 || s.balance("BTC").Locked != 0.0 {
+			t.Errorf("%s: got BTC %f (locked %f), want 0", tests[index].name, s.balance("BTC").Free, s.balance("BTC").Locked)
+		}
+		if trades, err = s.GetOrderTrades(context.Background(), info); err != nil || len(trades) != 1 {
+			t.Fatalf("%s: GetOrderTrades: got %d trades %v", tests[index].name, len(trades), err)
+		}
+		serverTime, _ = s.GetServerTime(context.Background())
+		if trades[0].Price != tests[index].price || !trades[0].Time.Equal(serverTime) {
+			t.Errorf("%s: got trade at %f on %v, want %f on %v", tests[index].name, trades[0].Price, trades[0].Time, tests[index].price, serverTime)
+		}
+	}
+}
+
+func TestCancelOrder(t *testing.T) {
+	var s *Simulated
+	var limit, stop types.Order
+	var info types.OrderInfo
+	var orders []types.OrderInfo
+	var cancelReference uuid.UUID
+	var err error
+
+	s = newTestSimulated(t)
+	limit = testOrder(types.Limit, types.Buy, types.GoodTillCancel, 1.0, 90.0, 0.0)
+	stop = testOrder(types.StopLoss, types.Sell, types.GoodTillCancel, 0.5, 0.0, 98.0)
+	if _, err = s.PlaceOrder(context.Background(), limit, nil); err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if _, err = s.PlaceOrder(context.Background(), stop, nil); err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+
+	if orders, err = s.OpenOrders(context.Background(), testSymbol); err != nil || len(orders) != 2 {
+		t.Fatalf("OpenOrders: got %d orders %v, want 2", len(orders), err)
+	}
+
+	cancelReference = uuid.New()
+	if info, err = s.CancelOrder(context.Background(), limit, cancelReference); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if info.Status != types.StatusCanceled || info.CancelUserReference != cancelReference {
+		t.Errorf("CancelOrder: got %+v", info)
+	}
+	if s.balance("USDT").Free != 10000.0 || s.balance("USDT").Locked != 0.0 {
+		t.Errorf("USDT after cancel: got %f (locked %f), want the funds released", s.balance("USDT").Free, s.balance("USDT").Locked)
+	}
+	if s.balance("BTC").Free != 0.5 || s.balance("BTC").Locked != 0.5 {
+		t.Errorf("BTC: got %f (locked %f), want 0.5 locked by the stop loss", s.balance("BTC").Free, s.balance("BTC").Locked)
+	}
+
+	if orders, err = s.OpenOrders(context.Background(), testSymbol); err != nil || len(orders) != 1 || orders[0].UserReference != stop.UserReference {
+		t.Errorf("OpenOrders after cancel: got %+v %v, want the stop loss", orders, err)
+	}
+	if _, err = s.CancelOrder(context.Background(), limit, uuid.New()); err == nil {
+		t.Errorf("CancelOrder of a canceled order: got no error")
+	}
+	if _, err = s.OpenOrders(context.Background(), types.NewSymbol("ETH", "USDT")); err == nil {
+		t.Errorf("OpenOrders of an unknown symbol: got no error")
+	}
+	if _, err = s.GetOrder(context.Background(), testOrder(types.Limit, types.Buy, types.GoodTillCancel, 1.0, 90.0, 0.0)); !exchange.IsOrderNotFound(err) {
+		t.Errorf("GetOrder of an unknown order: got %v, want order not found", err)
+	}
+}
@@ -0,0 +1,56 @@
+package exchange
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// tokenBucket limits the weight of the requests sent over time
+// The weight of a request is reserved up front, the bucket goes negative while requests wait for their turn.
+type tokenBucket struct {
+	capacity float64
+
+	// rate of the refill in weight per second
+	rate float64
+
+	tokens float64
+	last   time.Time
+	mux    sync.Mutex
+}
+
+func newTokenBucket(limit types.RateLimit, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(limit.Capacity),
+		rate:     float64(limit.Capacity) / limit.Interval.Seconds(),
+		tokens:   float64(limit.Capacity),
+		last:     now,
+	}
+}
+
+// reserve takes the weight from the bucket and returns the time to wait before the request may be sent
+// A weight above the capacity of the bucket reserves the full capacity.
+func (tb *tokenBucket) reserve(weight int, now time.Time) time.Duration {
+	var w float64
+
+	tb.mux.Lock()
+	defer tb.mux.Unlock()
+
+	if now.After(tb.last) {
+		tb.tokens += now.Sub(tb.last).Seconds() * tb.rate
+		if tb.tokens > tb.capacity {
+			tb.tokens = tb.capacity
+		}
+		tb.last = now
+	}
+
+	if w = float64(weight); w > tb.capacity {
+		w = tb.capacity
+	}
+	tb.tokens -= w
+	if tb.tokens >= 0.0 {
+		return 0
+	}
+	return time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+}
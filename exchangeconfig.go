@@ -1,6 +1,11 @@
 package cryptotrader
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhereman/cryptotrader/exchange"
+)
 
 // ExchangeConfig represents the config for the exchange to trade on
 type ExchangeConfig struct {
@@ -9,11 +14,37 @@ type ExchangeConfig struct {
 
 	// Exchange specific map of arguments
 	ArgMap map[string]string
+
+	// CandleStore directory of the on disk candle store, empty if the candles are not stored
+	CandleStore string
+
+	// Limiter configuration of the throttling, retries and circuit breaker of the exchange requests
+	Limiter exchange.LimiterConfig
 }
 
 // NewExchangeConfigFromFlags creates a new ExchangeConfig insance from the cmdline argument values
-func NewExchangeConfigFromFlags(name string, args map[string]string) (ec ExchangeConfig, err error) {
+// The ${ENV_VAR} and file:/path references in the arguments are resolved.
+func NewExchangeConfigFromFlags(name string, args map[string]string, candleStore string, rateLimit bool, retries int, circuitBreaker int) (ec ExchangeConfig, err error) {
 	ec.Name = strings.ToLower(name)
-	ec.ArgMap = args
+	if ec.ArgMap, err = resolveSecrets(args); err != nil {
+		err = fmt.Errorf("Invalid exchange args: %v", err)
+		return
+	}
+	ec.CandleStore = candleStore
+
+	if retries < 0 {
+		err = fmt.Errorf("Invalid retries: %d", retries)
+		return
+	}
+	if circuitBreaker < 0 {
+		err = fmt.Errorf("Invalid circuit breaker threshold: %d", circuitBreaker)
+		return
+	}
+	ec.Limiter = exchange.NewLimiterConfig(rateLimit, retries, circuitBreaker)
 	return
 }
+
+// String returns the config with the secrets redacted
+func (ec ExchangeConfig) String() string {
+	return fmt.Sprintf("%s [%s] candleStore: %s, %s", ec.Name, redactedArgs(ec.ArgMap), ec.CandleStore, ec.Limiter.String())
+}
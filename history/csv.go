@@ -0,0 +1,188 @@
+package history
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const csvHeader = "open_time,open,high,low,close,volume,close_time\n"
+
+// LoadCSV loads a series of candles from a csv file
+// See ReadCSV for the expected format of the file.
+func LoadCSV(path string, symbol types.Symbol, timeframe types.Timeframe) (series types.Series, err error) {
+	var file *os.File
+
+	if file, err = os.Open(path); err != nil {
+		logger.Errorf("History::LoadCSV Error %v\n", err)
+		return
+	}
+	defer file.Close()
+
+	series, err = ReadCSV(file, symbol, timeframe)
+	return
+}
+
+// ReadCSV reads a series of candles in csv format
+// Every record has the fields:
+//
+//	open_time,open,high,low,close,volume[,close_time]
+//
+// Times are either unix timestamps in milliseconds or RFC3339 formatted strings,
+// if the close time is omitted it is derived from the timeframe.
+// A header line is optional, the candles must be sorted on open time.
+func ReadCSV(r io.Reader, symbol types.Symbol, timeframe types.Timeframe) (series types.Series, err error) {
+	var reader *csv.Reader
+	var record []string
+	var candle types.OHLC
+	var candles []types.OHLC
+	var line int
+
+	reader = csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	candles = make([]types.OHLC, 0)
+	for {
+		if record, err = reader.Read(); err != nil {
+			if err == io.EOF {
+				err = nil
+				break
+			}
+			logger.Errorf("History::ReadCSV Error %v\n", err)
+			return
+		}
+		line++
+
+		if line == 1 && isCSVHeader(record) {
+			continue
+		}
+
+		if candle, err = parseCSVRecord(record, timeframe); err != nil {
+			err = fmt.Errorf("Line %d: %v", line, err)
+			logger.Errorf("History::ReadCSV Error %v\n", err)
+			return
+		}
+
+		if len(candles) > 0 && !candle.OpenTime.After(candles[len(candles)-1].OpenTime) {
+			err = fmt.Errorf("Line %d: candle at %v is not sorted on open time", line, candle.OpenTime)
+			logger.Errorf("History::ReadCSV Error %v\n", err)
+			return
+		}
+		candles = append(candles, candle)
+	}
+
+	series = types.NewSeries(symbol, timeframe, candles)
+	return
+}
+
+// WriteCSV writes the candles of the series in csv format
+// A header line is written, the times are unix timestamps in milliseconds.
+// See ReadCSV for the format of the records.
+func WriteCSV(w io.Writer, series types.Series) (err error) {
+	if _, err = io.WriteString(w, csvHeader); err != nil {
+		logger.Errorf("History::WriteCSV Error %v\n", err)
+		return
+	}
+	if err = writeCSVRecords(w, series.Candles); err != nil {
+		logger.Errorf("History::WriteCSV Error %v\n", err)
+		return
+	}
+	return
+}
+
+func writeCSVRecords(w io.Writer, candles []types.OHLC) (err error) {
+	var writer *csv.Writer
+	var candle types.OHLC
+
+	writer = csv.NewWriter(w)
+	for _, candle = range candles {
+		if err = writer.Write([]string{
+			formatCSVTime(candle.OpenTime),
+			formatCSVFloat(candle.Open),
+			formatCSVFloat(candle.High),
+			formatCSVFloat(candle.Low),
+			formatCSVFloat(candle.Close),
+			formatCSVFloat(candle.Volume),
+			formatCSVTime(candle.CloseTime),
+		}); err != nil {
+			return
+		}
+	}
+	writer.Flush()
+	err = writer.Error()
+	return
+}
+
+func formatCSVTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
+
+func formatCSVFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func isCSVHeader(record []string) bool {
+	var err error
+
+	if len(record) == 0 {
+		return false
+	}
+	_, err = parseCSVTime(record[0])
+	return err != nil
+}
+
+func parseCSVRecord(record []string, timeframe types.Timeframe) (candle types.OHLC, err error) {
+	var values [5]float64
+	var index int
+
+	if len(record) != 6 && len(record) != 7 {
+		err = fmt.Errorf("Expected 6 or 7 fields, got %d", len(record))
+		return
+	}
+
+	if candle.OpenTime, err = parseCSVTime(record[0]); err != nil {
+		return
+	}
+
+	for index = range values {
+		if values[index], err = strconv.ParseFloat(strings.TrimSpace(record[index+1]), 64); err != nil {
+			return
+		}
+	}
+	candle.Open = values[0]
+	candle.High = values[1]
+	candle.Low = values[2]
+	candle.Close = values[3]
+	candle.Volume = values[4]
+
+	if len(record) == 7 {
+		if candle.CloseTime, err = parseCSVTime(record[6]); err != nil {
+			return
+		}
+	} else {
+		candle.CloseTime = candle.OpenTime.Add(timeframe.Duration() - time.Millisecond)
+	}
+	return
+}
+
+func parseCSVTime(in string) (t time.Time, err error) {
+	var millis int64
+
+	in = strings.TrimSpace(in)
+	if millis, err = strconv.ParseInt(in, 10, 64); err == nil {
+		t = time.Unix(millis/1000, (millis%1000)*1000000).UTC()
+		return
+	}
+
+	t, err = time.Parse(time.RFC3339, in)
+	return
+}
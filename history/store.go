@@ -0,0 +1,294 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// CandleStore represents an on disk store of closed candles
+// The candles are kept in a csv file per exchange, symbol and timeframe:
+//
+//	<dir>/<exchange>/<BASE>-<QUOTE>/<timeframe>.csv
+//
+// The store is filled by downloading the candles from the exchange and
+// topped up with only the candles closed since the last update.
+type CandleStore struct {
+	dir     string
+	candles map[string][]types.OHLC
+	mux     sync.Mutex
+}
+
+// NewCandleStore creates a new CandleStore instance storing its files in dir
+func NewCandleStore(dir string) (cs *CandleStore) {
+	cs = &CandleStore{
+		dir:     dir,
+		candles: make(map[string][]types.OHLC),
+		mux:     sync.Mutex{},
+	}
+	return
+}
+
+// Load returns the stored candles of the symbol and timeframe opened between start and end
+// A zero start or end leaves the range open at that side.
+func (cs *CandleStore) Load(exchangeName string, symbol types.Symbol, timeframe types.Timeframe, start time.Time, end time.Time) (series types.Series, err error) {
+	cs.mux.Lock()
+	defer cs.mux.Unlock()
+
+	var candles []types.OHLC
+
+	if candles, err = cs.load(exchangeName, symbol, timeframe); err != nil {
+		logger.Errorf("CandleStore::Load Error %v\n", err)
+		return
+	}
+
+	series = types.NewSeries(symbol, timeframe, candlesBetween(candles, start, end))
+	return
+}
+
+// Update downloads the candles missing from the store and returns the candles opened between start and end
+// Only candles older than the stored candles and candles newer than the last stored candle are downloaded.
+// A zero start begins at the first stored candle, or at the first candle of the exchange if nothing is stored yet.
+// A zero end updates the store up to now, the candle which is still forming is returned but not stored.
+func (cs *CandleStore) Update(ctx context.Context, exchangeName string, driver interfaces.IHistoricalExchangeDriver, symbol types.Symbol, timeframe types.Timeframe, start time.Time, end time.Time) (series types.Series, err error) {
+	cs.mux.Lock()
+	defer cs.mux.Unlock()
+
+	var candles, older, newer, closed []types.OHLC
+	var first, last types.OHLC
+	var now, downloadEnd time.Time
+	var index int
+
+	if candles, err = cs.load(exchangeName, symbol, timeframe); err != nil {
+		logger.Errorf("CandleStore::Update Error %v\n", err)
+		return
+	}
+
+	now = time.Now()
+	downloadEnd = end
+	if downloadEnd.IsZero() {
+		downloadEnd = now
+	}
+
+	if len(candles) == 0 {
+		if newer, err = download(ctx, driver, symbol, timeframe, start, downloadEnd); err != nil {
+			logger.Errorf("CandleStore::Update Error %v\n", err)
+			return
+		}
+	} else {
+		first = candles[0]
+		if !start.IsZero() && start.Before(first.OpenTime) {
+			if older, err = download(ctx, driver, symbol, timeframe, start, first.OpenTime.Add(-time.Millisecond)); err != nil {
+				logger.Errorf("CandleStore::Update Error %v\n", err)
+				return
+			}
+		}
+
+		last = candles[len(candles)-1]
+		if newer, err = download(ctx, driver, symbol, timeframe, timeframe.NextOpen(last.OpenTime, last.CloseTime), downloadEnd); err != nil {
+			logger.Errorf("CandleStore::Update Error %v\n", err)
+			return
+		}
+	}
+
+	// The last candle of the exchange is still forming when downloading up to now
+	closed = newer
+	for index = range newer {
+		if !newer[index].CloseTime.Before(now) || (end.IsZero() && index == len(newer)-1) {
+			closed = newer[:index]
+			break
+		}
+	}
+
+	if len(older) > 0 {
+		candles = append(older, candles...)
+		if err = cs.write(exchangeName, symbol, timeframe, candles); err != nil {
+			logger.Errorf("CandleStore::Update Error %v\n", err)
+			return
+		}
+		logger.Infof("CandleStore stored %d older candles of %s[%s]\n", len(older), symbol.String(), timeframe.String())
+	}
+	if len(closed) > 0 {
+		if err = cs.append(exchangeName, symbol, timeframe, closed); err != nil {
+			logger.Errorf("CandleStore::Update Error %v\n", err)
+			return
+		}
+		candles = append(candles, closed...)
+		logger.Debugf("CandleStore stored %d new candles of %s[%s]\n", len(closed), symbol.String(), timeframe.String())
+	}
+	cs.candles[cs.path(exchangeName, symbol, timeframe)] = candles
+
+	series = types.NewSeries(symbol, timeframe, candlesBetween(append(candles[:len(candles):len(candles)], newer[len(closed):]...), start, end))
+	return
+}
+
+// download downloads the candles opened between start and end, requesting the range in pages
+func download(ctx context.Context, driver interfaces.IHistoricalExchangeDriver, symbol types.Symbol, timeframe types.Timeframe, start time.Time, end time.Time) (candles []types.OHLC, err error) {
+	var page types.Series
+	var candle types.OHLC
+	var from, pageStart time.Time
+
+	from = start
+	for !from.After(end) {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		if page, err = driver.GetSeriesRange(ctx, symbol, timeframe, from, end); err != nil {
+			return
+		}
+
+		pageStart = from
+		for _, candle = range page.Candles {
+			if candle.OpenTime.Before(from) || candle.OpenTime.After(end) {
+				continue
+			}
+			candles = append(candles, candle)
+			from = timeframe.NextOpen(candle.OpenTime, candle.CloseTime)
+		}
+
+		if !from.After(pageStart) {
+			break
+		}
+		logger.Debugf("Downloaded %d candles of %s[%s] up to %v\n", len(candles), symbol.String(), timeframe.String(), from)
+	}
+	return
+}
+
+// load returns the stored candles, the file is only read the first time
+// The caller must hold the lock.
+func (cs *CandleStore) load(exchangeName string, symbol types.Symbol, timeframe types.Timeframe) (candles []types.OHLC, err error) {
+	var path string
+	var ok bool
+	var series types.Series
+
+	path = cs.path(exchangeName, symbol, timeframe)
+	if candles, ok = cs.candles[path]; ok {
+		return
+	}
+
+	if _, err = os.Stat(path); os.IsNotExist(err) {
+		err = nil
+		cs.candles[path] = candles
+		return
+	}
+
+	if series, err = LoadCSV(path, symbol, timeframe); err != nil {
+		return
+	}
+	candles = series.Candles
+	cs.candles[path] = candles
+	return
+}
+
+// append appends the candles to the file of the symbol and timeframe
+// The caller must hold the lock.
+func (cs *CandleStore) append(exchangeName string, symbol types.Symbol, timeframe types.Timeframe, candles []types.OHLC) (err error) {
+	var path string
+	var file *os.File
+	var info os.FileInfo
+
+	path = cs.path(exchangeName, symbol, timeframe)
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+
+	if file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644); err != nil {
+		return
+	}
+	defer file.Close()
+
+	if info, err = file.Stat(); err != nil {
+		return
+	}
+	if info.Size() == 0 {
+		if _, err = file.WriteString(csvHeader); err != nil {
+			return
+		}
+	}
+
+	if err = writeCSVRecords(file, candles); err != nil {
+		return
+	}
+	err = file.Sync()
+	return
+}
+
+// write replaces the file of the symbol and timeframe with the candles
+// The caller must hold the lock.
+func (cs *CandleStore) write(exchangeName string, symbol types.Symbol, timeframe types.Timeframe, candles []types.OHLC) (err error) {
+	var path, tmpPath string
+	var file *os.File
+
+	path = cs.path(exchangeName, symbol, timeframe)
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+
+	tmpPath = path + ".tmp"
+	if file, err = os.Create(tmpPath); err != nil {
+		return
+	}
+
+	if err = WriteCSV(file, types.NewSeries(symbol, timeframe, candles)); err != nil {
+		file.Close()
+		return
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return
+	}
+	if err = file.Close(); err != nil {
+		return
+	}
+
+	err = os.Rename(tmpPath, path)
+	return
+}
+
+func (cs *CandleStore) path(exchangeName string, symbol types.Symbol, timeframe types.Timeframe) string {
+	var timeframeName string
+
+	// Minutes and months only differ in case, which is lost on case insensitive file systems
+	timeframeName = timeframe.String()
+	if timeframe.Unit == types.TuMonth {
+		timeframeName = fmt.Sprintf("%dmo", timeframe.Value)
+	}
+
+	return filepath.Join(
+		cs.dir,
+		strings.ToLower(exchangeName),
+		fmt.Sprintf("%s-%s", strings.ToUpper(symbol.Base()), strings.ToUpper(symbol.Quote())),
+		fmt.Sprintf("%s.csv", timeframeName),
+	)
+}
+
+// SeriesBetween returns the candles of the series opened between start and end, zero times leave the range open
+func SeriesBetween(series types.Series, start time.Time, end time.Time) types.Series {
+	return types.NewSeries(series.Symbol, series.Timeframe, candlesBetween(series.Candles, start, end))
+}
+
+// candlesBetween returns the candles opened between start and end, zero times leave the range open
+func candlesBetween(candles []types.OHLC, start time.Time, end time.Time) (out []types.OHLC) {
+	var candle types.OHLC
+
+	out = make([]types.OHLC, 0, len(candles))
+	for _, candle = range candles {
+		if !start.IsZero() && candle.OpenTime.Before(start) {
+			continue
+		}
+		if !end.IsZero() && candle.OpenTime.After(end) {
+			continue
+		}
+		out = append(out, candle)
+	}
+	return
+}
@@ -15,6 +15,9 @@ type IAlgorithm interface {
 	// DefaultConfig returns the default configuration of the algorithm
 	DefaultConfig() types.AlgorithmConfig
 
+	// Schema returns the description of the configuration parameters of the algorithm
+	Schema() types.AlgorithmSchema
+
 	// Config returns the current configuration of the algorithm
 	Config() types.AlgorithmConfig
 
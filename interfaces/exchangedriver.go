@@ -49,3 +49,44 @@ type IExchangeDriver interface {
 	// GetOrderTrades executs the get order trades request
 	GetOrderTrades(context.Context, types.OrderInfo) ([]types.Trade, error)
 }
+
+// IOCOExchangeDriver is implemented by exchange plugins supporting one-cancels-other orders
+type IOCOExchangeDriver interface {
+	// PlaceOCOOrder executes the place one-cancels-other order request
+	// It returns the order info of the limit maker and of the stop loss limit order
+	PlaceOCOOrder(context.Context, types.OCOOrder, *types.SymbolInfo) (types.OrderInfo, types.OrderInfo, error)
+}
+
+// IHistoricalExchangeDriver is implemented by exchange plugins able to download the candles of a time range
+type IHistoricalExchangeDriver interface {
+	// GetSeriesRange executes the get series request for the candles opened between start and end
+	// The exchange may return only the first part of the range, the remainder is requested starting after the last returned candle
+	GetSeriesRange(context.Context, types.Symbol, types.Timeframe, time.Time, time.Time) (types.Series, error)
+}
+
+// IStreamingExchangeDriver is implemented by exchange plugins able to stream candle updates
+type IStreamingExchangeDriver interface {
+	// StreamCandles streams the updates of the current candle of the symbol and timeframe until the context is done
+	// The returned channel is closed when the stream ends
+	StreamCandles(context.Context, types.Symbol, types.Timeframe) (types.CandleUpdateChannel, error)
+}
+
+// IFuturesExchangeDriver is implemented by exchange plugins trading futures, supporting short positions and leverage
+// The orders of these exchanges open and close positions instead of exchanging assets.
+type IFuturesExchangeDriver interface {
+	// SetLeverage executes the change leverage request for the positions of the symbol
+	SetLeverage(context.Context, types.Symbol, int) error
+
+	// SetMarginType executes the change margin type request for the positions of the symbol
+	SetMarginType(context.Context, types.Symbol, types.MarginType) error
+
+	// GetPosition executes the get position request, the quantity is 0 if no position is open
+	GetPosition(context.Context, types.Symbol) (types.Position, error)
+}
+
+// IRateLimitedExchangeDriver is implemented by exchange plugins declaring the request limits of the exchange
+// The limits are enforced by the exchange.Limiter wrapping the plugin.
+type IRateLimitedExchangeDriver interface {
+	// RateLimits returns the token buckets and the weights of the requests, no buckets disables the limits
+	RateLimits() types.RateLimits
+}
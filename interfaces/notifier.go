@@ -1,12 +1,35 @@
 package interfaces
 
-import "context"
+import (
+	"context"
+
+	"github.com/mhereman/cryptotrader/types"
+)
 
 // INotifier represents the notifier plugin interface
 type INotifier interface {
 	// Name returs the name of the notifier plugin
 	Name() string
 
-	// Notify executs the notifier with the provided message
-	Notify(context.Context, []byte) error
+	// Notify executs the notifier with the provided event
+	// The notifier renders the event itself.
+	Notify(context.Context, types.Event) error
+}
+
+// IClosableNotifier is implemented by notifier plugins holding back events, e.g. in a digest
+type IClosableNotifier interface {
+	// Close delivers the held back events and waits until they are sent or the context is done
+	Close(context.Context) error
+}
+
+// ICommandHandler executes the commands received by a notifier plugin
+type ICommandHandler interface {
+	// HandleCommand executes the command with its arguments and returns the reply
+	HandleCommand(context.Context, string, []string) (string, error)
+}
+
+// ICommandNotifier is implemented by notifier plugins able to receive commands
+type ICommandNotifier interface {
+	// ListenCommands passes the received commands to the handler until the context is done
+	ListenCommands(context.Context, ICommandHandler) error
 }
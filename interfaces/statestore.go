@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// IStateStore represents the state store plugin interface
+type IStateStore interface {
+	// Name returns the name of the state store plugin
+	Name() string
+
+	// Load returns all stored open trades
+	Load(context.Context) ([]types.OpenTrade, error)
+
+	// Save stores the open trade, replacing the stored trade of the same symbol
+	Save(context.Context, types.OpenTrade) error
+
+	// Delete removes the stored trade of the symbol
+	Delete(context.Context, types.Symbol) error
+}
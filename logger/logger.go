@@ -1,7 +1,10 @@
 package logger
 
 import (
+	"io"
 	"log"
+	"os"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -29,6 +32,15 @@ const (
 var lvl LogLevel = LInfo
 var mux sync.Mutex = sync.Mutex{}
 
+const (
+	redacted        = "******"
+	minSecretLength = 4
+)
+
+var secrets []string
+var secretsMux sync.RWMutex = sync.RWMutex{}
+var redacting bool
+
 // Name returns the name of the loglevel
 func (l LogLevel) Name() string {
 	switch l {
@@ -160,3 +172,59 @@ func Panicln(v ...interface{}) {
 func Panicf(format string, args ...interface{}) {
 	log.Panicf(format, args...)
 }
+
+// AddSecret registers a secret which is redacted from every log line
+// Values shorter than 4 characters are ignored.
+func AddSecret(secret string) {
+	var existing string
+
+	if len(secret) < minSecretLength {
+		return
+	}
+
+	secretsMux.Lock()
+	defer secretsMux.Unlock()
+
+	for _, existing = range secrets {
+		if existing == secret {
+			return
+		}
+	}
+	secrets = append(secrets, secret)
+
+	// Longest first, so a secret containing another secret is redacted completely
+	sort.Slice(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+
+	if !redacting {
+		log.SetOutput(redactWriter{out: os.Stderr})
+		redacting = true
+	}
+}
+
+// Redact returns the string with the registered secrets replaced
+func Redact(in string) string {
+	var secret string
+
+	secretsMux.RLock()
+	defer secretsMux.RUnlock()
+
+	for _, secret = range secrets {
+		in = strings.Replace(in, secret, redacted, -1)
+	}
+	return in
+}
+
+// redactWriter redacts the secrets from the log output
+type redactWriter struct {
+	out io.Writer
+}
+
+func (rw redactWriter) Write(p []byte) (n int, err error) {
+	if _, err = rw.out.Write([]byte(Redact(string(p)))); err != nil {
+		return
+	}
+	n = len(p)
+	return
+}
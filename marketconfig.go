@@ -0,0 +1,105 @@
+package cryptotrader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// MarketConfig represents the config for one market to trade
+type MarketConfig struct {
+	// Asset symbol and timeframe to trade
+	Asset AssetConfig
+
+	// Algorithm to use to make buy and sell descissions on the market
+	Algorithm AlgorithmConfig
+
+	// Volume the volume to trade on the market (depends on TradeConfig.TradeVolumeType)
+	Volume float64
+}
+
+// NewMarketConfig creates a new MarketConfig instance
+func NewMarketConfig(asset AssetConfig, algorithm AlgorithmConfig, volume float64) MarketConfig {
+	return MarketConfig{
+		Asset:     asset,
+		Algorithm: algorithm,
+		Volume:    volume,
+	}
+}
+
+// NewMarketConfigFromFlag creates a new MarketConfig instance from a -market cmdline argument value
+// The value format should be:
+//
+//	base/quote@timeframe[,algorithm[,volume[,algorithmargs]]]
+//
+// Omitted or empty algorithm, volume and algorithm args are taken from the defaults
+func NewMarketConfigFromFlag(in string, defaults MarketConfig) (mc MarketConfig, err error) {
+	var parts, assetParts []string
+	var symbol types.Symbol
+
+	mc = defaults
+
+	parts = strings.SplitN(in, ",", 4)
+	assetParts = strings.Split(parts[0], "@")
+	if len(assetParts) != 2 {
+		err = fmt.Errorf("Invalid market: %s, expected base/quote@timeframe", in)
+		return
+	}
+	if symbol, err = types.NewSymbolFromString(assetParts[0]); err != nil {
+		err = fmt.Errorf("Invalid market: %s %v", in, err)
+		return
+	}
+	if mc.Asset, err = NewAssetConfigFromFlags(symbol.Base(), symbol.Quote(), assetParts[1]); err != nil {
+		err = fmt.Errorf("Invalid market: %s %v", in, err)
+		return
+	}
+
+	if len(parts) > 1 && parts[1] != "" {
+		mc.Algorithm.Name = parts[1]
+	}
+	if len(parts) > 2 && parts[2] != "" {
+		if mc.Volume, err = strconv.ParseFloat(parts[2], 64); err != nil {
+			err = fmt.Errorf("Invalid market volume: %s %v", in, err)
+			return
+		}
+	}
+	if len(parts) > 3 && parts[3] != "" {
+		if mc.Algorithm, err = NewAlgorithmConfigFromFlags(mc.Algorithm.Name, buildArgMap(parts[3])); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// String returns the string representation of the MarketConfig
+func (mc MarketConfig) String() string {
+	return fmt.Sprintf("%s[%s]", mc.Asset.Symbol.String(), mc.Asset.Timeframe.String())
+}
+
+// validateMarketConfigs checks the markets can be traded by a single CryptoTrader instance
+// Positions are kept per symbol, so every symbol can only be traded on one timeframe.
+func validateMarketConfigs(marketConfigs []MarketConfig) (err error) {
+	var marketConfig MarketConfig
+	var symbols map[string]bool
+
+	if len(marketConfigs) == 0 {
+		err = fmt.Errorf("No markets configured")
+		return
+	}
+
+	symbols = make(map[string]bool)
+	for _, marketConfig = range marketConfigs {
+		if symbols[marketConfig.Asset.Symbol.String()] {
+			err = fmt.Errorf("Symbol %s configured on multiple markets, a symbol can only be traded on one timeframe", marketConfig.Asset.Symbol.String())
+			return
+		}
+		if marketConfig.Volume <= 0.0 {
+			err = fmt.Errorf("Invalid volume for market %s: %f", marketConfig.String(), marketConfig.Volume)
+			return
+		}
+		symbols[marketConfig.Asset.Symbol.String()] = true
+	}
+	return
+}
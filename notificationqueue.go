@@ -0,0 +1,125 @@
+package cryptotrader
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	notificationQueueSize                  = 100
+	notificationDrainTimeout time.Duration = (time.Second * 30)
+)
+
+// notificationQueue buffers the events between the trader and the notifiers,
+// so a slow notifier does not delay the execution of the signals
+type notificationQueue struct {
+	events  chan types.Event
+	done    chan struct{}
+	sent    uint64
+	failed  uint64
+	dropped uint64
+}
+
+func newNotificationQueue(size int) *notificationQueue {
+	return &notificationQueue{
+		events: make(chan types.Event, size),
+		done:   make(chan struct{}),
+	}
+}
+
+// notify queues the event for delivery to the notifiers, the event is dropped if the queue is full
+func (ct *CryptoTrader) notify(event types.Event) {
+	select {
+	case ct.notifications.events <- event:
+	default:
+		atomic.AddUint64(&ct.notifications.dropped, 1)
+		logger.Warningf("notify: Notification queue full, dropping event: %s\n", event.String())
+	}
+}
+
+func (ct *CryptoTrader) runNotificationQueueAsync() {
+	ct.wg.Add(1)
+	go notificationQueueRoutine(ct)
+}
+
+// notificationQueueRoutine delivers the queued events until the trader shuts down,
+// the pending and held back events are delivered before the routine exits
+func notificationQueueRoutine(ct *CryptoTrader) {
+	defer ct.wg.Done()
+	defer close(ct.notifications.done)
+
+	var event types.Event
+	var drainCtx context.Context
+	var cancelFn context.CancelFunc
+	var runLoop, drained bool
+
+	// A delivery in progress on shutdown is not cancelled, the deliveries are bounded by the notifier timeouts
+	runLoop = true
+	for runLoop {
+		select {
+		case <-ct.ctx.Done():
+			runLoop = false
+		case event = <-ct.notifications.events:
+			ct.deliverNotification(context.Background(), event)
+		}
+	}
+
+	drainCtx, cancelFn = context.WithTimeout(context.Background(), notificationDrainTimeout)
+	defer cancelFn()
+
+	for !drained && drainCtx.Err() == nil {
+		select {
+		case event = <-ct.notifications.events:
+			ct.deliverNotification(drainCtx, event)
+		default:
+			drained = true
+		}
+	}
+	if !drained {
+		logger.Warningf("notificationQueue: Drain timed out, %d events not delivered\n", len(ct.notifications.events))
+	}
+
+	ct.closeNotifier(drainCtx)
+}
+
+func (ct *CryptoTrader) deliverNotification(ctx context.Context, event types.Event) {
+	var err error
+
+	if err = ct.notifier.Notify(ctx, event); err != nil {
+		atomic.AddUint64(&ct.notifications.failed, 1)
+		logger.Errorf("notificationQueue: Failed to deliver event %s: %v\n", event.String(), err)
+		return
+	}
+	atomic.AddUint64(&ct.notifications.sent, 1)
+}
+
+// closeNotifier delivers the events held back by the notifiers, e.g. the digests
+func (ct *CryptoTrader) closeNotifier(ctx context.Context) {
+	var closable interfaces.IClosableNotifier
+	var ok bool
+	var err error
+
+	if closable, ok = ct.notifier.(interfaces.IClosableNotifier); !ok {
+		return
+	}
+	if err = closable.Close(ctx); err != nil {
+		logger.Errorf("notificationQueue: Failed to close the notifiers: %v\n", err)
+	}
+}
+
+// waitNotifications waits until the pending events are delivered after the trader shut down
+func (ct *CryptoTrader) waitNotifications() {
+	<-ct.notifications.done
+	logger.Infof("Notifications: %s\n", ct.notificationStats())
+}
+
+// notificationStats returns the number of sent, failed and dropped events
+func (ct *CryptoTrader) notificationStats() string {
+	return fmt.Sprintf("sent: %d, failed: %d, dropped: %d", atomic.LoadUint64(&ct.notifications.sent), atomic.LoadUint64(&ct.notifications.failed), atomic.LoadUint64(&ct.notifications.dropped))
+}
@@ -0,0 +1,62 @@
+package cryptotrader
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// digestNotifier holds back the events until it is closed, like a notifier sending digests
+type digestNotifier struct {
+	mux       sync.Mutex
+	held      []types.Event
+	delivered []types.Event
+}
+
+func (n *digestNotifier) Name() string {
+	return "digest"
+}
+
+func (n *digestNotifier) Notify(ctx context.Context, event types.Event) (err error) {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	n.held = append(n.held, event)
+	return
+}
+
+func (n *digestNotifier) Close(ctx context.Context) (err error) {
+	// Sending the digest takes a while
+	time.Sleep(time.Millisecond * 100)
+
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	n.delivered = append(n.delivered, n.held...)
+	n.held = nil
+	return
+}
+
+func TestNotificationQueueClosesNotifier(t *testing.T) {
+	var ct *CryptoTrader
+	var notifier *digestNotifier
+
+	ct = New(nil, ExchangeConfig{}, TradeConfig{}, nil, StateStoreConfig{})
+	notifier = new(digestNotifier)
+	ct.notifier = notifier
+	ct.runNotificationQueueAsync()
+
+	ct.notify(types.NewErrorEvent(testSymbol, "first"))
+	ct.notify(types.NewErrorEvent(testSymbol, "second"))
+	ct.cancelFn()
+	ct.waitNotifications()
+
+	notifier.mux.Lock()
+	defer notifier.mux.Unlock()
+	if len(notifier.delivered) != 2 || len(notifier.held) != 0 {
+		t.Errorf("got %d events delivered and %d held back on shutdown, want all delivered", len(notifier.delivered), len(notifier.held))
+	}
+}
@@ -1,6 +1,26 @@
 package cryptotrader
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	// notifierEventsArg notifier argument listing the event types to send, comma separated
+	notifierEventsArg = "events"
+
+	// notifierMinSeverityArg notifier argument with the min severity of the events to send
+	notifierMinSeverityArg = "minSeverity"
+
+	// notifierTimeoutArg notifier argument with the max duration of the delivery of one event
+	notifierTimeoutArg = "deliveryTimeout"
+
+	// defaultNotifierTimeout covers the default retries of the webhook notifier, 4 requests of 10s and 7s of backoff
+	defaultNotifierTimeout = time.Minute
+)
 
 // NotifierConfig represents the config for the notifier to use
 type NotifierConfig struct {
@@ -9,11 +29,86 @@ type NotifierConfig struct {
 
 	// ArgMap arguments of the notifier
 	ArgMap map[string]string
+
+	// Filter selects the events sent to the notifier
+	Filter types.EventFilter
+
+	// Timeout max duration of the delivery of one event
+	Timeout time.Duration
 }
 
 // NewNotifierConfigFromFlags creates a new NotifierConfig insance from the cmdline argument values
+// The 'events', 'minSeverity' and 'deliveryTimeout' arguments configure the delivery and are not passed to the notifier,
+// by default all events of at least notice severity are sent, or all events of the listed types.
+// The ${ENV_VAR} and file:/path references in the arguments are resolved.
 func NewNotifierConfigFromFlags(name string, args map[string]string) (nc NotifierConfig, err error) {
+	var eventsString, severityString, eventString, timeoutString string
+	var eventType types.EventType
+	var ok bool
+
 	nc.Name = strings.ToLower(name)
-	nc.ArgMap = args
+	if nc.ArgMap, err = resolveSecrets(args); err != nil {
+		return
+	}
+
+	nc.Filter.MinSeverity = types.SeverityNotice
+	if eventsString, ok = nc.ArgMap[notifierEventsArg]; ok {
+		delete(nc.ArgMap, notifierEventsArg)
+		nc.Filter.MinSeverity = types.SeverityInfo
+		for _, eventString = range strings.Split(eventsString, ",") {
+			if eventType, err = types.NewEventTypeFromString(eventString); err != nil {
+				return
+			}
+			nc.Filter.Types = append(nc.Filter.Types, eventType)
+		}
+	}
+
+	if severityString, ok = nc.ArgMap[notifierMinSeverityArg]; ok {
+		delete(nc.ArgMap, notifierMinSeverityArg)
+		if nc.Filter.MinSeverity, err = types.NewSeverityFromString(severityString); err != nil {
+			return
+		}
+	}
+
+	nc.Timeout = defaultNotifierTimeout
+	if timeoutString, ok = nc.ArgMap[notifierTimeoutArg]; ok {
+		delete(nc.ArgMap, notifierTimeoutArg)
+		if nc.Timeout, err = time.ParseDuration(timeoutString); err != nil || nc.Timeout <= 0 {
+			err = fmt.Errorf("Invalid notifier %s: %s", notifierTimeoutArg, timeoutString)
+			return
+		}
+	}
 	return
 }
+
+// NewNotifierConfigFromFlag creates a new NotifierConfig instance from a -notifier cmdline argument value
+// The value format should be:
+//
+//	name[:notifierargs]
+//
+// Omitted notifier args are taken from the default args
+func NewNotifierConfigFromFlag(in string, defaultArgs map[string]string) (nc NotifierConfig, err error) {
+	var parts []string
+	var args map[string]string
+
+	parts = strings.SplitN(in, ":", 2)
+	if parts[0] == "" {
+		err = fmt.Errorf("Invalid notifier: %s, expected name[:notifierargs]", in)
+		return
+	}
+
+	args = defaultArgs
+	if len(parts) > 1 && parts[1] != "" {
+		args = buildArgMap(parts[1])
+	}
+	if nc, err = NewNotifierConfigFromFlags(parts[0], args); err != nil {
+		err = fmt.Errorf("Invalid notifier: %s %v", parts[0], err)
+		return
+	}
+	return
+}
+
+// String returns the config with the secrets redacted
+func (nc NotifierConfig) String() string {
+	return fmt.Sprintf("%s [%s] timeout: %v", nc.Name, redactedArgs(nc.ArgMap), nc.Timeout)
+}
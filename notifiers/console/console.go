@@ -0,0 +1,44 @@
+package console
+
+import (
+	"context"
+
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/notifiers"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const notifierName = "console"
+
+func init() {
+	notifiers.RegisterNotifier(notifierName, createConsole)
+}
+
+// Console represents the console notifier
+// The events are written to the log at the level matching their severity
+type Console struct {
+}
+
+func createConsole(ctx context.Context, config map[string]string) (notifier interfaces.INotifier, err error) {
+	notifier = &Console{}
+	return
+}
+
+// Name returns the name of the notifier
+func (c Console) Name() string {
+	return notifierName
+}
+
+// Notify writes the short description of the event to the log
+func (c Console) Notify(ctx context.Context, event types.Event) (err error) {
+	switch event.Severity {
+	case types.SeverityError:
+		logger.Errorf("Notification: %s\n", event.String())
+	case types.SeverityWarning:
+		logger.Warningf("Notification: %s\n", event.String())
+	default:
+		logger.Infof("Notification: %s\n", event.String())
+	}
+	return
+}
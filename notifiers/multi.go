@@ -0,0 +1,174 @@
+package notifiers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// Multi represents a notifier delivering the events to multiple notifiers concurrently
+// Every notifier has its own event filter and delivery timeout, a slow or failing notifier
+// does not delay the delivery to the others.
+type Multi struct {
+	targets []target
+}
+
+type target struct {
+	notifier interfaces.INotifier
+	filter   types.EventFilter
+	timeout  time.Duration
+}
+
+// NewMulti creates a new Multi notifier without notifiers
+func NewMulti() *Multi {
+	return new(Multi)
+}
+
+// Add adds a notifier receiving the events accepted by the filter
+// A delivery taking longer than the timeout is abandoned.
+func (m *Multi) Add(notifier interfaces.INotifier, filter types.EventFilter, timeout time.Duration) {
+	m.targets = append(m.targets, target{
+		notifier: notifier,
+		filter:   filter,
+		timeout:  timeout,
+	})
+}
+
+// Name returns the names of the notifiers
+func (m *Multi) Name() string {
+	var names []string
+	var t target
+
+	for _, t = range m.targets {
+		names = append(names, t.notifier.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+// Notify delivers the event to the notifiers accepting it and waits for the deliveries to finish or time out
+// The returned error combines the errors of the failed deliveries.
+func (m *Multi) Notify(ctx context.Context, event types.Event) (err error) {
+	var results chan error
+	var t target
+	var count, index int
+	var result error
+	var messages []string
+
+	results = make(chan error, len(m.targets))
+	for _, t = range m.targets {
+		if !t.filter.Accept(event) {
+			continue
+		}
+		count++
+		go deliver(ctx, t, event, results)
+	}
+
+	for index = 0; index < count; index++ {
+		if result = <-results; result != nil {
+			messages = append(messages, result.Error())
+		}
+	}
+
+	if len(messages) > 0 {
+		err = fmt.Errorf("%s", strings.Join(messages, "; "))
+	}
+	return
+}
+
+func deliver(ctx context.Context, t target, event types.Event, results chan<- error) {
+	var deliveryCtx context.Context
+	var cancelFn context.CancelFunc
+	var done chan error
+	var err error
+
+	deliveryCtx, cancelFn = context.WithTimeout(ctx, t.timeout)
+	defer cancelFn()
+
+	// The notifier might not respect the context, its result is not waited for after the timeout
+	done = make(chan error, 1)
+	go func() {
+		done <- t.notifier.Notify(deliveryCtx, event)
+	}()
+
+	select {
+	case err = <-done:
+	case <-deliveryCtx.Done():
+		err = fmt.Errorf("delivery timed out after %v", t.timeout)
+	}
+
+	if err != nil {
+		err = fmt.Errorf("Notifier %s: %v", t.notifier.Name(), err)
+		logger.Warningf("Multi::Notify Error %v\n", err)
+	}
+	results <- err
+}
+
+// Close closes the notifiers holding back events concurrently and waits for them
+// The returned error combines the errors of the notifiers failing to deliver their events.
+func (m *Multi) Close(ctx context.Context) (err error) {
+	var wg sync.WaitGroup
+	var mux sync.Mutex
+	var t target
+	var closable interfaces.IClosableNotifier
+	var messages []string
+	var ok bool
+
+	for _, t = range m.targets {
+		if closable, ok = t.notifier.(interfaces.IClosableNotifier); !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string, closable interfaces.IClosableNotifier) {
+			defer wg.Done()
+
+			if closeErr := closable.Close(ctx); closeErr != nil {
+				mux.Lock()
+				messages = append(messages, fmt.Sprintf("Notifier %s: %v", name, closeErr))
+				mux.Unlock()
+			}
+		}(t.notifier.Name(), closable)
+	}
+
+	wg.Wait()
+	if len(messages) > 0 {
+		err = fmt.Errorf("%s", strings.Join(messages, "; "))
+	}
+	return
+}
+
+// ListenCommands passes the commands of all notifiers accepting commands to the handler
+// It returns when all notifiers stopped listening.
+func (m *Multi) ListenCommands(ctx context.Context, handler interfaces.ICommandHandler) (err error) {
+	var wg sync.WaitGroup
+	var mux sync.Mutex
+	var t target
+	var commandNotifier interfaces.ICommandNotifier
+	var ok bool
+
+	for _, t = range m.targets {
+		if commandNotifier, ok = t.notifier.(interfaces.ICommandNotifier); !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(commandNotifier interfaces.ICommandNotifier) {
+			defer wg.Done()
+
+			if listenErr := commandNotifier.ListenCommands(ctx, handler); listenErr != nil {
+				mux.Lock()
+				err = listenErr
+				mux.Unlock()
+			}
+		}(commandNotifier)
+	}
+
+	wg.Wait()
+	return
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/mhereman/cryptotrader/interfaces"
 	"github.com/mhereman/cryptotrader/notifiers"
+	"github.com/mhereman/cryptotrader/types"
 )
 
 const notifierName = "noop"
@@ -29,6 +30,6 @@ func (noop Noop) Name() string {
 }
 
 // Notify ...
-func (noop Noop) Notify(context.Context, []byte) (err error) {
+func (noop Noop) Notify(context.Context, types.Event) (err error) {
 	return
 }
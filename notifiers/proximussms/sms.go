@@ -10,6 +10,7 @@ import (
 
 	"github.com/mhereman/cryptotrader/interfaces"
 	"github.com/mhereman/cryptotrader/notifiers"
+	"github.com/mhereman/cryptotrader/types"
 
 	"github.com/mhereman/cryptotrader/logger"
 )
@@ -83,8 +84,8 @@ func (sms ProximusSMS) Name() string {
 	return notifierName
 }
 
-// Notify ...
-func (sms ProximusSMS) Notify(ctx context.Context, data []byte) (err error) {
+// Notify sends the short description of the event as sms
+func (sms ProximusSMS) Notify(ctx context.Context, event types.Event) (err error) {
 	var smsMessage message
 	var client *http.Client
 	var url string
@@ -96,7 +97,7 @@ func (sms ProximusSMS) Notify(ctx context.Context, data []byte) (err error) {
 	var deliveryStatus string
 
 	smsMessage = message{
-		Message:      string(data),
+		Message:      event.String(),
 		Binary:       false,
 		Destinations: []string{sms.destination},
 	}
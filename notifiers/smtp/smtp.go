@@ -0,0 +1,474 @@
+package smtp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	netsmtp "net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/notifiers"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	notifierName = "smtp"
+
+	securityNone     = "none"
+	securitySTARTTLS = "starttls"
+	securityTLS      = "tls"
+
+	defaultSubject        = "Cryptotrader"
+	defaultDigestSeverity = types.SeverityWarning
+	defaultTimeout        = time.Second * 30
+)
+
+func init() {
+	notifiers.RegisterNotifier(notifierName, createSMTP)
+}
+
+// SMTP represents the email notifier
+// If a digest interval is configured, the events below the digest severity are collected
+// and sent together in one digest mail per interval, the other events are mailed immediately.
+type SMTP struct {
+	host           string
+	addr           string
+	security       string
+	tlsConfig      *tls.Config
+	auth           netsmtp.Auth
+	from           string
+	to             []string
+	subject        string
+	digestInterval time.Duration
+	digestSeverity types.Severity
+	timeout        time.Duration
+
+	digestMux     sync.Mutex
+	digest        []types.Event
+	digestStopped bool
+	stop          chan struct{}
+	stopOnce      sync.Once
+	digestDone    chan struct{}
+}
+
+// New creates a new SMTP notifier
+// The config needs a 'host', 'from' and 'to' entry, the other entries are optional:
+//
+//	port            port of the server, default 465 for tls and 587 otherwise
+//	security        starttls, tls or none, default starttls
+//	skipVerify      do not verify the certificate of the server, default false
+//	username        username to authenticate with (PLAIN), no authentication if not set
+//	password        password to authenticate with
+//	to              recipients, comma separated
+//	subject         prefix of the subject, default Cryptotrader
+//	digestInterval  if set, the interval to mail the digest of the low priority events at, e.g. 24h
+//	digestSeverity  the events below this severity are added to the digest, default warning
+//	timeout         timeout of sending a digest, default 30s
+func New(ctx context.Context, config map[string]string) (s *SMTP, err error) {
+	var port, value, address string
+	var skipVerify bool
+	var ok bool
+
+	s = new(SMTP)
+	if s.host, ok = config["host"]; !ok {
+		err = fmt.Errorf("SMTP config error: 'host' entry not found")
+		return
+	}
+	if s.from, ok = config["from"]; !ok {
+		err = fmt.Errorf("SMTP config error: 'from' entry not found")
+		return
+	}
+	if value, ok = config["to"]; !ok {
+		err = fmt.Errorf("SMTP config error: 'to' entry not found")
+		return
+	}
+	for _, address = range strings.Split(value, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			s.to = append(s.to, address)
+		}
+	}
+	if len(s.to) == 0 {
+		err = fmt.Errorf("SMTP config error: empty 'to' entry")
+		return
+	}
+
+	s.security = securitySTARTTLS
+	if value, ok = config["security"]; ok {
+		s.security = strings.ToLower(value)
+	}
+	switch s.security {
+	case securityTLS:
+		port = "465"
+	case securitySTARTTLS, securityNone:
+		port = "587"
+	default:
+		err = fmt.Errorf("SMTP config error: invalid 'security' entry: %s, valid: starttls, tls, none", value)
+		return
+	}
+	if value, ok = config["port"]; ok {
+		port = value
+	}
+	s.addr = net.JoinHostPort(s.host, port)
+
+	if value, ok = config["skipVerify"]; ok {
+		if skipVerify, err = strconv.ParseBool(value); err != nil {
+			err = fmt.Errorf("SMTP config error: invalid 'skipVerify' entry: %s", value)
+			return
+		}
+	}
+	s.tlsConfig = &tls.Config{
+		ServerName:         s.host,
+		InsecureSkipVerify: skipVerify,
+	}
+
+	if value, ok = config["username"]; ok {
+		s.auth = netsmtp.PlainAuth("", value, config["password"], s.host)
+	}
+
+	s.subject = defaultSubject
+	if value, ok = config["subject"]; ok {
+		s.subject = value
+	}
+
+	s.digestSeverity = defaultDigestSeverity
+	if value, ok = config["digestSeverity"]; ok {
+		if s.digestSeverity, err = types.NewSeverityFromString(value); err != nil {
+			err = fmt.Errorf("SMTP config error: invalid 'digestSeverity' entry: %v", err)
+			return
+		}
+	}
+
+	s.timeout = defaultTimeout
+	if value, ok = config["timeout"]; ok {
+		if s.timeout, err = time.ParseDuration(value); err != nil || s.timeout <= 0 {
+			err = fmt.Errorf("SMTP config error: invalid 'timeout' entry: %s", value)
+			return
+		}
+	}
+
+	if value, ok = config["digestInterval"]; ok {
+		if s.digestInterval, err = time.ParseDuration(value); err != nil || s.digestInterval <= 0 {
+			err = fmt.Errorf("SMTP config error: invalid 'digestInterval' entry: %s", value)
+			return
+		}
+		s.stop = make(chan struct{})
+		s.digestDone = make(chan struct{})
+		go digestRoutine(ctx, s)
+	}
+	return
+}
+
+func createSMTP(ctx context.Context, config map[string]string) (notifier interfaces.INotifier, err error) {
+	notifier, err = New(ctx, config)
+	return
+}
+
+// Name returns the name of the notifier
+func (s *SMTP) Name() string {
+	return notifierName
+}
+
+// Notify mails the event or adds it to the digest
+// Once the digest is no longer sent, e.g. while the queued events are delivered on shutdown, all events are mailed immediately.
+func (s *SMTP) Notify(ctx context.Context, event types.Event) (err error) {
+	var subject string
+
+	if s.digestInterval > 0 && event.Severity < s.digestSeverity && s.addToDigest(event) {
+		return
+	}
+
+	subject = event.String()
+	if event.Severity >= types.SeverityWarning {
+		subject = fmt.Sprintf("%s: %s", strings.ToUpper(event.Severity.String()), subject)
+	}
+
+	if err = s.send(ctx, subject, eventText(event), eventHTML(event)); err != nil {
+		logger.Errorf("SMTP::Notify Error %v\n", err)
+		return
+	}
+	return
+}
+
+// addToDigest adds the event to the digest, returns false if the digest routine stopped
+func (s *SMTP) addToDigest(event types.Event) (added bool) {
+	s.digestMux.Lock()
+	defer s.digestMux.Unlock()
+
+	if s.digestStopped {
+		return
+	}
+	s.digest = append(s.digest, event)
+	added = true
+	return
+}
+
+// Close mails the remaining events of the digest and waits until they are sent or the context is done
+// The events notified afterwards are mailed immediately.
+func (s *SMTP) Close(ctx context.Context) (err error) {
+	if s.digestDone == nil {
+		return
+	}
+
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	select {
+	case <-s.digestDone:
+	case <-ctx.Done():
+		err = fmt.Errorf("Digest not sent: %v", ctx.Err())
+		logger.Errorf("SMTP::Close Error %v\n", err)
+	}
+	return
+}
+
+// digestRoutine mails the digest every interval, the remaining events are mailed when the context is done or on Close
+func digestRoutine(ctx context.Context, s *SMTP) {
+	defer close(s.digestDone)
+
+	var ticker *time.Ticker
+	var runLoop bool
+
+	ticker = time.NewTicker(s.digestInterval)
+	defer ticker.Stop()
+
+	runLoop = true
+	for runLoop {
+		select {
+		case <-ctx.Done():
+			runLoop = false
+		case <-s.stop:
+			runLoop = false
+		case <-ticker.C:
+			s.sendDigest()
+		}
+	}
+
+	s.digestMux.Lock()
+	s.digestStopped = true
+	s.digestMux.Unlock()
+	s.sendDigest()
+}
+
+func (s *SMTP) sendDigest() {
+	var events []types.Event
+	var event types.Event
+	var ctx context.Context
+	var cancelFn context.CancelFunc
+	var text, rows strings.Builder
+	var err error
+
+	s.digestMux.Lock()
+	events = s.digest
+	s.digest = nil
+	s.digestMux.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+
+	for _, event = range events {
+		fmt.Fprintf(&text, "%s  %s\r\n", event.Time.Format("2006-01-02 15:04:05"), event.String())
+		fmt.Fprintf(&rows, "<tr><td>%s</td><td>%s</td><td>%s</td></tr>\r\n", event.Time.Format("2006-01-02 15:04:05"), html.EscapeString(event.Type.String()), html.EscapeString(event.String()))
+	}
+
+	ctx, cancelFn = context.WithTimeout(context.Background(), s.timeout)
+	defer cancelFn()
+
+	if err = s.send(ctx, fmt.Sprintf("Digest: %d events", len(events)), text.String(), fmt.Sprintf("<table>\r\n<tr><th>Time</th><th>Event</th><th>Description</th></tr>\r\n%s</table>", rows.String())); err != nil {
+		logger.Errorf("SMTP::sendDigest Error %v\n", err)
+	}
+}
+
+func (s *SMTP) send(ctx context.Context, subject string, text string, htmlText string) (err error) {
+	var message []byte
+	var conn net.Conn
+	var client *netsmtp.Client
+	var dialer net.Dialer
+	var deadline time.Time
+	var ok bool
+	var to string
+
+	if message, err = s.buildMessage(subject, text, htmlText); err != nil {
+		return
+	}
+
+	if s.security == securityTLS {
+		conn, err = (&tls.Dialer{NetDialer: &dialer, Config: s.tlsConfig}).DialContext(ctx, "tcp", s.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", s.addr)
+	}
+	if err != nil {
+		return
+	}
+	if deadline, ok = ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if client, err = netsmtp.NewClient(conn, s.host); err != nil {
+		conn.Close()
+		return
+	}
+	defer client.Close()
+
+	if s.security == securitySTARTTLS {
+		if ok, _ = client.Extension("STARTTLS"); !ok {
+			err = fmt.Errorf("Server %s does not support STARTTLS", s.addr)
+			return
+		}
+		if err = client.StartTLS(s.tlsConfig); err != nil {
+			return
+		}
+	}
+
+	if s.auth != nil {
+		if err = client.Auth(s.auth); err != nil {
+			return
+		}
+	}
+
+	if err = client.Mail(s.from); err != nil {
+		return
+	}
+	for _, to = range s.to {
+		if err = client.Rcpt(to); err != nil {
+			return
+		}
+	}
+	if err = writeData(client, message); err != nil {
+		return
+	}
+	err = client.Quit()
+	return
+}
+
+func writeData(client *netsmtp.Client, message []byte) (err error) {
+	var w io.WriteCloser
+
+	if w, err = client.Data(); err != nil {
+		return
+	}
+	if _, err = w.Write(message); err != nil {
+		w.Close()
+		return
+	}
+	err = w.Close()
+	return
+}
+
+// buildMessage builds a multipart/alternative mail with a plain text and html body
+func (s *SMTP) buildMessage(subject string, text string, htmlText string) (message []byte, err error) {
+	var buffer, body bytes.Buffer
+	var mw *multipart.Writer
+
+	mw = multipart.NewWriter(&body)
+	if err = writePart(mw, "text/plain; charset=UTF-8", text); err != nil {
+		return
+	}
+	if err = writePart(mw, "text/html; charset=UTF-8", "<html><body>\r\n"+htmlText+"\r\n</body></html>"); err != nil {
+		return
+	}
+	if err = mw.Close(); err != nil {
+		return
+	}
+
+	fmt.Fprintf(&buffer, "From: %s\r\n", s.from)
+	fmt.Fprintf(&buffer, "To: %s\r\n", strings.Join(s.to, ", "))
+	fmt.Fprintf(&buffer, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", fmt.Sprintf("[%s] %s", s.subject, subject)))
+	fmt.Fprintf(&buffer, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buffer, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buffer, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+	buffer.Write(body.Bytes())
+
+	message = buffer.Bytes()
+	return
+}
+
+func writePart(mw *multipart.Writer, contentType string, content string) (err error) {
+	var header textproto.MIMEHeader
+	var part io.Writer
+	var qp *quotedprintable.Writer
+
+	header = textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	if part, err = mw.CreatePart(header); err != nil {
+		return
+	}
+
+	qp = quotedprintable.NewWriter(part)
+	if _, err = qp.Write([]byte(content)); err != nil {
+		return
+	}
+	err = qp.Close()
+	return
+}
+
+// eventFields returns the names and values of the fields applicable to the event
+func eventFields(event types.Event) (names []string, values []string) {
+	var add = func(name string, value string) {
+		names = append(names, name)
+		values = append(values, value)
+	}
+
+	add("Event", event.Type.String())
+	add("Severity", event.Severity.String())
+	add("Time", event.Time.Format("2006-01-02 15:04:05 MST"))
+	if event.Symbol != (types.Symbol{}) {
+		add("Symbol", event.Symbol.String())
+	}
+	if event.Quantity > 0.0 {
+		add("Quantity", strconv.FormatFloat(event.Quantity, 'f', -1, 64))
+	}
+	if event.Price > 0.0 {
+		add("Price", strconv.FormatFloat(event.Price, 'f', -1, 64))
+	}
+	if event.EntryPrice > 0.0 {
+		add("Entry price", strconv.FormatFloat(event.EntryPrice, 'f', -1, 64))
+		add("PnL", fmt.Sprintf("%f (%.2f%%)", event.PnL, event.PnLPercent*100.0))
+	}
+	if event.Paper {
+		add("Paper trading", "yes")
+	}
+	return
+}
+
+func eventText(event types.Event) string {
+	var text strings.Builder
+	var names, values []string
+	var index int
+
+	text.WriteString(event.String())
+	text.WriteString("\r\n\r\n")
+	names, values = eventFields(event)
+	for index = range names {
+		fmt.Fprintf(&text, "%s: %s\r\n", names[index], values[index])
+	}
+	return text.String()
+}
+
+func eventHTML(event types.Event) string {
+	var text strings.Builder
+	var names, values []string
+	var index int
+
+	fmt.Fprintf(&text, "<p>%s</p>\r\n<table>\r\n", html.EscapeString(event.String()))
+	names, values = eventFields(event)
+	for index = range names {
+		fmt.Fprintf(&text, "<tr><th align=\"left\">%s</th><td>%s</td></tr>\r\n", html.EscapeString(names[index]), html.EscapeString(values[index]))
+	}
+	text.WriteString("</table>")
+	return text.String()
+}
@@ -0,0 +1,484 @@
+package smtp
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mhereman/cryptotrader/notifiers"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// testMail is a mail received by the fake server
+type testMail struct {
+	from    string
+	to      []string
+	auth    string
+	tls     bool
+	subject string
+	data    string
+}
+
+// fakeServer is a local SMTP stand-in accepting every mail
+// If the tls config is set, it offers STARTTLS and PLAIN authentication after the upgrade.
+type fakeServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	mux       sync.Mutex
+	mails     []testMail
+	received  chan struct{}
+}
+
+func newFakeServer(t *testing.T, tlsConfig *tls.Config) (fs *fakeServer) {
+	var err error
+
+	fs = &fakeServer{tlsConfig: tlsConfig, received: make(chan struct{}, 16)}
+	if fs.listener, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go fs.serve()
+	return
+}
+
+func (fs *fakeServer) port() string {
+	return fmt.Sprintf("%d", fs.listener.Addr().(*net.TCPAddr).Port)
+}
+
+func (fs *fakeServer) serve() {
+	var conn net.Conn
+	var err error
+
+	for {
+		if conn, err = fs.listener.Accept(); err != nil {
+			return
+		}
+		go fs.handle(conn)
+	}
+}
+
+func (fs *fakeServer) handle(conn net.Conn) {
+	var reader *bufio.Reader
+	var line, command, data string
+	var current testMail
+	var decoded []byte
+	var message *mail.Message
+	var reply func(format string, args ...interface{})
+	var err error
+
+	defer func() {
+		conn.Close()
+	}()
+
+	reply = func(format string, args ...interface{}) {
+		fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	reader = bufio.NewReader(conn)
+	reply("220 localhost fake SMTP")
+	for {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command = strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch command {
+		case "EHLO", "HELO":
+			if fs.tlsConfig != nil && !current.tls {
+				reply("250-localhost")
+				reply("250 STARTTLS")
+			} else if fs.tlsConfig != nil {
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			} else {
+				reply("250 localhost")
+			}
+		case "STARTTLS":
+			reply("220 Ready to start TLS")
+			conn = tls.Server(conn, fs.tlsConfig)
+			reader = bufio.NewReader(conn)
+			current.tls = true
+		case "AUTH":
+			if decoded, err = base64.StdEncoding.DecodeString(strings.TrimPrefix(line, "AUTH PLAIN ")); err != nil {
+				reply("501 Invalid credentials")
+				continue
+			}
+			current.auth = string(decoded)
+			reply("235 Authenticated")
+		case "MAIL":
+			current.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case "RCPT":
+			current.to = append(current.to, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 Send data")
+			data = ""
+			for {
+				if line, err = reader.ReadString('\n'); err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data += strings.TrimPrefix(line, ".")
+			}
+			current.data = data
+			if message, err = mail.ReadMessage(strings.NewReader(data)); err == nil {
+				current.subject = message.Header.Get("Subject")
+			}
+			fs.mux.Lock()
+			fs.mails = append(fs.mails, current)
+			fs.mux.Unlock()
+			fs.received <- struct{}{}
+			reply("250 Queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Not implemented")
+		}
+	}
+}
+
+func (fs *fakeServer) receivedMails() []testMail {
+	fs.mux.Lock()
+	defer fs.mux.Unlock()
+
+	return append([]testMail(nil), fs.mails...)
+}
+
+// waitMails waits until the server received count mails in total
+func (fs *fakeServer) waitMails(t *testing.T, count int) (mails []testMail) {
+	var timeout = time.After(time.Second * 5)
+
+	for {
+		if mails = fs.receivedMails(); len(mails) >= count {
+			return
+		}
+		select {
+		case <-fs.received:
+		case <-timeout:
+			t.Fatalf("received %d mails, want %d", len(mails), count)
+		}
+	}
+}
+
+// selfSignedConfig returns a tls config with a self signed certificate of 127.0.0.1
+func selfSignedConfig(t *testing.T) *tls.Config {
+	var key *ecdsa.PrivateKey
+	var template x509.Certificate
+	var der []byte
+	var err error
+
+	if key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template = x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if der, err = x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key); err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+}
+
+func newTestSMTP(t *testing.T, ctx context.Context, fs *fakeServer, extra map[string]string) (s *SMTP) {
+	var config map[string]string
+	var key, value string
+	var err error
+
+	config = map[string]string{
+		"host":     "127.0.0.1",
+		"port":     fs.port(),
+		"security": "none",
+		"from":     "bot@example.com",
+		"to":       "a@example.com, b@example.com",
+		"timeout":  "5s",
+	}
+	for key, value = range extra {
+		config[key] = value
+	}
+	if s, err = New(ctx, config); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return
+}
+
+func TestNotifyPlain(t *testing.T) {
+	var fs *fakeServer
+	var s *SMTP
+	var mails []testMail
+	var err error
+
+	fs = newFakeServer(t, nil)
+	defer fs.listener.Close()
+	s = newTestSMTP(t, context.Background(), fs, nil)
+
+	if err = s.Notify(context.Background(), types.NewOrderFilledEvent(types.NewSymbol("BTC", "USDT"), types.Buy, 0.5, 10000.0, false)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	mails = fs.waitMails(t, 1)
+	if mails[0].from != "bot@example.com" {
+		t.Errorf("from: got %s", mails[0].from)
+	}
+	if len(mails[0].to) != 2 || mails[0].to[0] != "a@example.com" || mails[0].to[1] != "b@example.com" {
+		t.Errorf("to: got %v", mails[0].to)
+	}
+	if mails[0].tls || mails[0].auth != "" {
+		t.Errorf("plain delivery: got tls %v auth %q", mails[0].tls, mails[0].auth)
+	}
+	if !strings.HasPrefix(mails[0].subject, "[Cryptotrader] ") || strings.Contains(mails[0].subject, "ERROR") {
+		t.Errorf("subject: got %s", mails[0].subject)
+	}
+	if !strings.Contains(mails[0].data, "multipart/alternative") || !strings.Contains(mails[0].data, "text/html") {
+		t.Errorf("body is not a multipart text and html mail:\n%s", mails[0].data)
+	}
+}
+
+func TestNotifySTARTTLSAuth(t *testing.T) {
+	var fs *fakeServer
+	var s *SMTP
+	var mails []testMail
+	var err error
+
+	fs = newFakeServer(t, selfSignedConfig(t))
+	defer fs.listener.Close()
+	s = newTestSMTP(t, context.Background(), fs, map[string]string{
+		"security":   "starttls",
+		"skipVerify": "true",
+		"username":   "bot",
+		"password":   "secret",
+	})
+
+	if err = s.Notify(context.Background(), types.NewErrorEvent(types.NewSymbol("BTC", "USDT"), "Unable to close position")); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	mails = fs.waitMails(t, 1)
+	if !mails[0].tls {
+		t.Errorf("mail was not sent over TLS")
+	}
+	if mails[0].auth != "\x00bot\x00secret" {
+		t.Errorf("auth: got %q, want PLAIN bot/secret", mails[0].auth)
+	}
+	if !strings.Contains(mails[0].subject, "ERROR: ") {
+		t.Errorf("subject of an error event: got %s", mails[0].subject)
+	}
+}
+
+func TestNotifySTARTTLSNotSupported(t *testing.T) {
+	var fs *fakeServer
+	var s *SMTP
+
+	fs = newFakeServer(t, nil)
+	defer fs.listener.Close()
+	s = newTestSMTP(t, context.Background(), fs, map[string]string{"security": "starttls"})
+
+	if s.Notify(context.Background(), types.NewErrorEvent(types.Symbol{}, "test")) == nil {
+		t.Errorf("expected an error from a server without STARTTLS")
+	}
+}
+
+func TestSeverityFilter(t *testing.T) {
+	var fs *fakeServer
+	var s *SMTP
+	var multi *notifiers.Multi
+	var mails []testMail
+	var err error
+
+	fs = newFakeServer(t, nil)
+	defer fs.listener.Close()
+	s = newTestSMTP(t, context.Background(), fs, nil)
+
+	multi = notifiers.NewMulti()
+	multi.Add(s, types.NewEventFilter(types.SeverityWarning, nil), time.Second*5)
+
+	if err = multi.Notify(context.Background(), types.NewOrderFilledEvent(types.NewSymbol("BTC", "USDT"), types.Buy, 0.5, 10000.0, false)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if err = multi.Notify(context.Background(), types.NewErrorEvent(types.NewSymbol("BTC", "USDT"), "filtered test")); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	mails = fs.waitMails(t, 1)
+	time.Sleep(time.Millisecond * 100)
+	if mails = fs.receivedMails(); len(mails) != 1 {
+		t.Fatalf("mails: got %d, want only the error event", len(mails))
+	}
+	if !strings.Contains(mails[0].subject, "filtered test") {
+		t.Errorf("subject: got %s", mails[0].subject)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	var fs *fakeServer
+	var s *SMTP
+	var ctx context.Context
+	var cancelFn context.CancelFunc
+	var symbol types.Symbol
+	var mails []testMail
+	var err error
+
+	fs = newFakeServer(t, nil)
+	defer fs.listener.Close()
+
+	ctx, cancelFn = context.WithCancel(context.Background())
+	defer cancelFn()
+	s = newTestSMTP(t, ctx, fs, map[string]string{"digestInterval": "200ms", "digestSeverity": "warning"})
+	symbol = types.NewSymbol("BTC", "USDT")
+
+	// The events below the digest severity are batched, the others are mailed immediately
+	if err = s.Notify(ctx, types.NewOrderFilledEvent(symbol, types.Buy, 0.5, 10000.0, false)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if err = s.Notify(ctx, types.NewPositionClosedEvent(symbol, types.Long, 0.5, 10000.0, 11000.0, false)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if err = s.Notify(ctx, types.NewErrorEvent(symbol, "immediate")); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	mails = fs.waitMails(t, 1)
+	if !strings.Contains(mails[0].subject, "immediate") {
+		t.Fatalf("first mail: got %s, want the error event", mails[0].subject)
+	}
+
+	mails = fs.waitMails(t, 2)
+	if !strings.Contains(mails[1].subject, "Digest: 2 events") {
+		t.Errorf("digest subject: got %s", mails[1].subject)
+	}
+
+	// Once the digest routine stopped, the events are mailed immediately instead of being dropped
+	cancelFn()
+	if err = s.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err = s.Notify(context.Background(), types.NewOrderFilledEvent(symbol, types.Sell, 0.5, 11000.0, false)); err != nil {
+		t.Fatalf("Notify after stop: %v", err)
+	}
+	mails = fs.waitMails(t, 3)
+	if strings.Contains(mails[2].subject, "Digest") {
+		t.Errorf("event after stop: got %s, want the event itself", mails[2].subject)
+	}
+}
+
+func TestDigestFlushedOnClose(t *testing.T) {
+	var fs *fakeServer
+	var s *SMTP
+	var multi *notifiers.Multi
+	var ctx, closeCtx context.Context
+	var cancelFn, closeCancelFn context.CancelFunc
+	var symbol types.Symbol
+	var mails []testMail
+	var err error
+
+	fs = newFakeServer(t, nil)
+	defer fs.listener.Close()
+
+	ctx, cancelFn = context.WithCancel(context.Background())
+	defer cancelFn()
+	s = newTestSMTP(t, ctx, fs, map[string]string{"digestInterval": "24h"})
+	symbol = types.NewSymbol("BTC", "USDT")
+
+	multi = notifiers.NewMulti()
+	multi.Add(s, types.NewEventFilter(types.SeverityInfo, nil), time.Second*5)
+	if err = multi.Notify(ctx, types.NewOrderFilledEvent(symbol, types.Buy, 0.5, 10000.0, false)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if mails = fs.receivedMails(); len(mails) != 0 {
+		t.Fatalf("mails: got %d, want the event held back in the digest", len(mails))
+	}
+
+	// The owner cancels the context on shutdown, the digest is sent before Close returns
+	cancelFn()
+	closeCtx, closeCancelFn = context.WithTimeout(context.Background(), time.Second*5)
+	defer closeCancelFn()
+	if err = multi.Close(closeCtx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if mails = fs.receivedMails(); len(mails) != 1 || !strings.Contains(mails[0].subject, "Digest: 1 events") {
+		t.Fatalf("mails on close: got %+v, want the digest", mails)
+	}
+
+	// Closing again does not block
+	if err = s.Close(closeCtx); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+func TestCloseTimeout(t *testing.T) {
+	var listener net.Listener
+	var s *SMTP
+	var ctx context.Context
+	var cancelFn context.CancelFunc
+	var err error
+
+	// A server accepting the connection but never answering
+	if listener, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	if s, err = New(context.Background(), map[string]string{
+		"host":           "127.0.0.1",
+		"port":           fmt.Sprintf("%d", listener.Addr().(*net.TCPAddr).Port),
+		"security":       "none",
+		"from":           "bot@example.com",
+		"to":             "a@example.com",
+		"digestInterval": "24h",
+		"timeout":        "5s",
+	}); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err = s.Notify(context.Background(), types.NewOrderFilledEvent(types.NewSymbol("BTC", "USDT"), types.Buy, 0.5, 10000.0, false)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	ctx, cancelFn = context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancelFn()
+	if err = s.Close(ctx); err == nil {
+		t.Errorf("expected an error when the digest is not sent in time")
+	}
+}
+
+func TestNewConfigErrors(t *testing.T) {
+	var configs = []map[string]string{
+		{"from": "bot@example.com", "to": "a@example.com"},
+		{"host": "localhost", "to": "a@example.com"},
+		{"host": "localhost", "from": "bot@example.com"},
+		{"host": "localhost", "from": "bot@example.com", "to": " , "},
+		{"host": "localhost", "from": "bot@example.com", "to": "a@example.com", "security": "ssl"},
+		{"host": "localhost", "from": "bot@example.com", "to": "a@example.com", "digestInterval": "-1h"},
+		{"host": "localhost", "from": "bot@example.com", "to": "a@example.com", "digestSeverity": "fatal"},
+	}
+	var index int
+	var err error
+
+	for index = range configs {
+		if _, err = New(context.Background(), configs[index]); err == nil {
+			t.Errorf("config %v: expected an error", configs[index])
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/notifiers"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	notifierName   = "telegram"
+	defaultBaseURL = "https://api.telegram.org"
+
+	pollTimeout                 = 30
+	retryInterval time.Duration = (time.Second * 5)
+)
+
+func init() {
+	notifiers.RegisterNotifier(notifierName, createTelegram)
+}
+
+// Telegram represents the Telegram bot notifier
+// If commands are enabled, the messages of the configured chat starting with a '/'
+// are executed as commands and answered in the chat.
+type Telegram struct {
+	baseURL  string
+	token    string
+	chatID   string
+	commands bool
+	client   *http.Client
+}
+
+type sendMessageRequest struct {
+	ChatID string `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+type apiResponse struct {
+	Ok          bool            `json:"ok"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+}
+
+type update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *message `json:"message"`
+}
+
+type message struct {
+	Chat chat   `json:"chat"`
+	Text string `json:"text"`
+}
+
+type chat struct {
+	ID int64 `json:"id"`
+}
+
+// New creates a new Telegram notifier
+// The config needs a 'token' and 'chatId' entry, the optional 'baseURL' entry overrides the Bot API endpoint
+// and commands are accepted if the 'commands' entry is true.
+func New(ctx context.Context, config map[string]string) (tg *Telegram, err error) {
+	var token, chatID, baseURL, commands string
+	var ok bool
+
+	if token, ok = config["token"]; !ok {
+		err = fmt.Errorf("Telegram config error: 'token' entry not found")
+		return
+	}
+
+	if chatID, ok = config["chatId"]; !ok {
+		err = fmt.Errorf("Telegram config error: 'chatId' entry not found")
+		return
+	}
+
+	if baseURL, ok = config["baseURL"]; !ok {
+		baseURL = defaultBaseURL
+	}
+
+	tg = new(Telegram)
+	tg.baseURL = strings.TrimSuffix(baseURL, "/")
+	tg.token = token
+	tg.chatID = chatID
+	tg.client = &http.Client{
+		Timeout: time.Second * (pollTimeout + 10),
+	}
+
+	if commands, ok = config["commands"]; ok {
+		if tg.commands, err = strconv.ParseBool(commands); err != nil {
+			err = fmt.Errorf("Telegram config error: invalid 'commands' entry: %s", commands)
+			return
+		}
+	}
+	return
+}
+
+func createTelegram(ctx context.Context, config map[string]string) (notifier interfaces.INotifier, err error) {
+	notifier, err = New(ctx, config)
+	return
+}
+
+// Name returns the name of the notifier
+func (tg Telegram) Name() string {
+	return notifierName
+}
+
+// Notify sends the event to the configured chat, warnings and errors are marked with their severity
+func (tg Telegram) Notify(ctx context.Context, event types.Event) (err error) {
+	var text string
+
+	text = event.String()
+	if event.Severity >= types.SeverityWarning {
+		text = fmt.Sprintf("%s: %s", strings.ToUpper(event.Severity.String()), text)
+	}
+
+	if err = tg.sendMessage(ctx, text); err != nil {
+		logger.Errorf("Telegram::Notify Error %v\n", err)
+		return
+	}
+	return
+}
+
+// ListenCommands polls the messages of the configured chat and passes the commands to the handler
+// It returns immediately if commands are not enabled.
+func (tg Telegram) ListenCommands(ctx context.Context, handler interfaces.ICommandHandler) (err error) {
+	var updates []update
+	var upd update
+	var offset int64
+	var command, reply string
+	var args []string
+	var ok bool
+
+	if !tg.commands {
+		return
+	}
+	logger.Infoln("Telegram accepting commands")
+
+	for ctx.Err() == nil {
+		if updates, err = tg.getUpdates(ctx, offset); err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			logger.Warningf("Telegram::ListenCommands Error %v\n", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(retryInterval):
+			}
+			continue
+		}
+
+		for _, upd = range updates {
+			offset = upd.UpdateID + 1
+
+			if upd.Message == nil || strconv.FormatInt(upd.Message.Chat.ID, 10) != tg.chatID {
+				continue
+			}
+			if command, args, ok = parseCommand(upd.Message.Text); ok {
+				if reply, err = handler.HandleCommand(ctx, command, args); err != nil {
+					reply = fmt.Sprintf("Error: %v", err)
+				}
+				if err = tg.sendMessage(ctx, reply); err != nil {
+					logger.Errorf("Telegram::ListenCommands Error %v\n", err)
+				}
+			}
+		}
+	}
+
+	err = nil
+	return
+}
+
+// parseCommand splits a '/command@bot arg1 arg2' message in the command and its arguments
+func parseCommand(text string) (command string, args []string, ok bool) {
+	var fields []string
+	var index int
+
+	fields = strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return
+	}
+
+	command = strings.TrimPrefix(fields[0], "/")
+	if index = strings.Index(command, "@"); index >= 0 {
+		command = command[:index]
+	}
+	args = fields[1:]
+	ok = command != ""
+	return
+}
+
+func (tg Telegram) sendMessage(ctx context.Context, text string) (err error) {
+	var jsonData []byte
+	var req *http.Request
+
+	if jsonData, err = json.Marshal(sendMessageRequest{
+		ChatID: tg.chatID,
+		Text:   text,
+	}); err != nil {
+		return
+	}
+
+	if req, err = http.NewRequestWithContext(ctx, "POST", tg.methodURL("sendMessage"), bytes.NewBuffer(jsonData)); err != nil {
+		return
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	_, err = tg.do(req)
+	return
+}
+
+func (tg Telegram) getUpdates(ctx context.Context, offset int64) (updates []update, err error) {
+	var query url.Values
+	var req *http.Request
+	var result json.RawMessage
+
+	query = url.Values{}
+	query.Set("offset", strconv.FormatInt(offset, 10))
+	query.Set("timeout", strconv.Itoa(pollTimeout))
+	query.Set("allowed_updates", `["message"]`)
+
+	if req, err = http.NewRequestWithContext(ctx, "GET", tg.methodURL("getUpdates")+"?"+query.Encode(), nil); err != nil {
+		return
+	}
+
+	if result, err = tg.do(req); err != nil {
+		return
+	}
+	err = json.Unmarshal(result, &updates)
+	return
+}
+
+// do executes the Bot API request and returns the result of a successful response
+func (tg Telegram) do(req *http.Request) (result json.RawMessage, err error) {
+	var resp *http.Response
+	var body []byte
+	var apiResp apiResponse
+	var urlErr *url.Error
+	var ok bool
+
+	if resp, err = tg.client.Do(req); err != nil {
+		// The request url contains the bot token
+		if urlErr, ok = err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	if body, err = ioutil.ReadAll(resp.Body); err != nil {
+		return
+	}
+
+	if err = json.Unmarshal(body, &apiResp); err != nil {
+		err = fmt.Errorf("API Error: Status: %d %v", resp.StatusCode, err)
+		return
+	}
+	if !apiResp.Ok {
+		err = fmt.Errorf("API Error: Status: %d Description: %s", resp.StatusCode, apiResp.Description)
+		return
+	}
+
+	result = apiResp.Result
+	return
+}
+
+func (tg Telegram) methodURL(method string) string {
+	return fmt.Sprintf("%s/bot%s/%s", tg.baseURL, tg.token, method)
+}
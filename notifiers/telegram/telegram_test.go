@@ -0,0 +1,295 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	testToken  = "123:test-token"
+	testChatID = "42"
+)
+
+// fakeBotAPI is a local stand-in for the Telegram Bot API
+// The queued batches of updates are returned by getUpdates, updates below the requested offset are skipped
+// like the Bot API does, an empty poll is answered after a short wait.
+type fakeBotAPI struct {
+	server   *httptest.Server
+	mux      sync.Mutex
+	updates  [][]update
+	offsets  []int64
+	messages []sendMessageRequest
+	fail     bool
+}
+
+func newFakeBotAPI(t *testing.T) (fb *fakeBotAPI) {
+	fb = new(fakeBotAPI)
+	fb.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request sendMessageRequest
+		var batch []update
+		var upd update
+		var offset int64
+		var err error
+
+		fb.mux.Lock()
+		defer fb.mux.Unlock()
+
+		if fb.fail {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"ok":false,"error_code":401,"description":"Unauthorized"}`)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/bot" + testToken + "/sendMessage":
+			if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+				t.Errorf("sendMessage: got %s %s", r.Method, r.Header.Get("Content-Type"))
+			}
+			if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Errorf("sendMessage: invalid body %v", err)
+			}
+			fb.messages = append(fb.messages, request)
+			fmt.Fprint(w, `{"ok":true,"result":{"message_id":1}}`)
+		case "/bot" + testToken + "/getUpdates":
+			if offset, err = strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64); err != nil {
+				t.Errorf("getUpdates: invalid offset %s", r.URL.Query().Get("offset"))
+			}
+			fb.offsets = append(fb.offsets, offset)
+			if len(fb.updates) == 0 {
+				fb.mux.Unlock()
+				time.Sleep(time.Millisecond * 10)
+				fb.mux.Lock()
+			} else {
+				for _, upd = range fb.updates[0] {
+					if upd.UpdateID >= offset {
+						batch = append(batch, upd)
+					}
+				}
+				fb.updates = fb.updates[1:]
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": batch})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"ok":false,"error_code":404,"description":"Not Found"}`)
+		}
+	}))
+	return
+}
+
+func (fb *fakeBotAPI) sentMessages() []sendMessageRequest {
+	fb.mux.Lock()
+	defer fb.mux.Unlock()
+
+	return append([]sendMessageRequest(nil), fb.messages...)
+}
+
+func newTestTelegram(t *testing.T, fb *fakeBotAPI, commands string) (tg *Telegram) {
+	var err error
+
+	if tg, err = New(context.Background(), map[string]string{
+		"token":    testToken,
+		"chatId":   testChatID,
+		"baseURL":  fb.server.URL + "/",
+		"commands": commands,
+	}); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return
+}
+
+func textMessage(updateID int64, chatID int64, text string) update {
+	return update{UpdateID: updateID, Message: &message{Chat: chat{ID: chatID}, Text: text}}
+}
+
+// waitMessages waits until count messages were sent in total
+func (fb *fakeBotAPI) waitMessages(t *testing.T, count int) (messages []sendMessageRequest) {
+	var timeout = time.After(time.Second * 5)
+
+	for {
+		if messages = fb.sentMessages(); len(messages) >= count {
+			return
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("sent %d messages, want %d", len(messages), count)
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+}
+
+// testHandler records the commands and replies with the command name
+type testHandler struct {
+	mux      sync.Mutex
+	commands []string
+}
+
+func (h *testHandler) HandleCommand(ctx context.Context, command string, args []string) (reply string, err error) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	h.commands = append(h.commands, strings.TrimSpace(command+" "+strings.Join(args, " ")))
+	if command == "fail" {
+		err = fmt.Errorf("command failed")
+		return
+	}
+	reply = "reply to " + command
+	return
+}
+
+func TestNotify(t *testing.T) {
+	var fb *fakeBotAPI
+	var tg *Telegram
+	var messages []sendMessageRequest
+	var err error
+
+	fb = newFakeBotAPI(t)
+	defer fb.server.Close()
+	tg = newTestTelegram(t, fb, "false")
+
+	if err = tg.Notify(context.Background(), types.NewOrderFilledEvent(types.NewSymbol("BTC", "USDT"), types.Buy, 0.5, 10000.0, false)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if err = tg.Notify(context.Background(), types.NewErrorEvent(types.NewSymbol("BTC", "USDT"), "Unable to close position")); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if messages = fb.sentMessages(); len(messages) != 2 {
+		t.Fatalf("messages: got %d, want 2", len(messages))
+	}
+	if messages[0].ChatID != testChatID || strings.HasPrefix(messages[0].Text, "ERROR") {
+		t.Errorf("first message: got %+v", messages[0])
+	}
+	if !strings.HasPrefix(messages[1].Text, "ERROR: ") || !strings.Contains(messages[1].Text, "Unable to close position") {
+		t.Errorf("error message: got %s", messages[1].Text)
+	}
+
+	fb.fail = true
+	if err = tg.Notify(context.Background(), types.NewErrorEvent(types.Symbol{}, "test")); err == nil || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Errorf("rejected request: got %v, want the API error", err)
+	}
+	if strings.Contains(fmt.Sprint(err), testToken) {
+		t.Errorf("error leaks the bot token: %v", err)
+	}
+}
+
+func TestListenCommands(t *testing.T) {
+	var fb *fakeBotAPI
+	var tg *Telegram
+	var handler *testHandler
+	var ctx context.Context
+	var cancelFn context.CancelFunc
+	var listenDone chan error
+	var messages []sendMessageRequest
+	var err error
+
+	fb = newFakeBotAPI(t)
+	defer fb.server.Close()
+	tg = newTestTelegram(t, fb, "true")
+
+	fb.updates = [][]update{
+		{
+			textMessage(10, 42, "/status"),
+			textMessage(11, 7, "/pause"),
+			textMessage(12, 42, "hello"),
+			{UpdateID: 13},
+		},
+		{
+			// Updates below the offset were confirmed and are not returned again
+			textMessage(13, 42, "/status"),
+			textMessage(14, 42, "/fail"),
+			textMessage(15, 42, "/close@cryptotrader_bot BTC/USDT"),
+		},
+	}
+	handler = new(testHandler)
+
+	ctx, cancelFn = context.WithCancel(context.Background())
+	defer cancelFn()
+	listenDone = make(chan error, 1)
+	go func() {
+		listenDone <- tg.ListenCommands(ctx, handler)
+	}()
+
+	messages = fb.waitMessages(t, 3)
+	cancelFn()
+	select {
+	case err = <-listenDone:
+		if err != nil {
+			t.Errorf("ListenCommands: %v", err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatalf("ListenCommands did not return after the context was done")
+	}
+
+	if strings.Join(handler.commands, ",") != "status,fail,close BTC/USDT" {
+		t.Errorf("commands: got %v, want the commands of the configured chat only", handler.commands)
+	}
+
+	fb.mux.Lock()
+	if len(fb.offsets) < 3 || fb.offsets[0] != 0 || fb.offsets[1] != 14 || fb.offsets[2] != 16 {
+		t.Errorf("offsets: got %v, want 0, 14, 16", fb.offsets)
+	}
+	fb.mux.Unlock()
+
+	if messages = fb.sentMessages(); len(messages) != 3 {
+		t.Fatalf("replies: got %+v, want 3", messages)
+	}
+	if messages[0].Text != "reply to status" || messages[1].Text != "Error: command failed" || messages[2].Text != "reply to close" {
+		t.Errorf("replies: got %+v", messages)
+	}
+}
+
+func TestListenCommandsDisabled(t *testing.T) {
+	var fb *fakeBotAPI
+	var tg *Telegram
+	var err error
+
+	fb = newFakeBotAPI(t)
+	defer fb.server.Close()
+	tg = newTestTelegram(t, fb, "false")
+
+	if err = tg.ListenCommands(context.Background(), &testHandler{}); err != nil {
+		t.Errorf("ListenCommands: %v", err)
+	}
+	fb.mux.Lock()
+	defer fb.mux.Unlock()
+	if len(fb.offsets) != 0 {
+		t.Errorf("updates polled while commands are disabled")
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	var tests = []struct {
+		text    string
+		command string
+		args    []string
+		ok      bool
+	}{
+		{"/status", "status", nil, true},
+		{"/close BTC/USDT", "close", []string{"BTC/USDT"}, true},
+		{"/close@cryptotrader_bot  BTC/USDT ", "close", []string{"BTC/USDT"}, true},
+		{"status", "", nil, false},
+		{"/", "", nil, false},
+		{"", "", nil, false},
+	}
+	var command string
+	var args []string
+	var index int
+	var ok bool
+
+	for index = range tests {
+		command, args, ok = parseCommand(tests[index].text)
+		if ok != tests[index].ok || (ok && (command != tests[index].command || strings.Join(args, " ") != strings.Join(tests[index].args, " "))) {
+			t.Errorf("%q: got %s %v %v", tests[index].text, command, args, ok)
+		}
+	}
+}
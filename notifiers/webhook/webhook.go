@@ -0,0 +1,310 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/notifiers"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const (
+	notifierName = "webhook"
+
+	defaultTemplate      = `{"text": {{json .Text}}}`
+	defaultContentType   = "application/json"
+	defaultSignHeader    = "X-Signature"
+	defaultRetries       = 3
+	defaultRetryInterval = time.Second
+	defaultTimeout       = time.Second * 10
+
+	headerPrefix = "header."
+)
+
+func init() {
+	notifiers.RegisterNotifier(notifierName, createWebhook)
+}
+
+// Webhook represents the webhook notifier
+// The message is rendered with a text/template and posted to the configured url,
+// the request is retried with exponential backoff on 5xx responses and timeouts.
+// If the delivery has a deadline, the timeout of every request is shortened so the
+// remaining retries and their backoff still fit before it.
+type Webhook struct {
+	url           string
+	template      *template.Template
+	headers       map[string]string
+	secret        []byte
+	signHeader    string
+	retries       int
+	retryInterval time.Duration
+	timeout       time.Duration
+	client        *http.Client
+}
+
+// TemplateData represents the data the body template is executed with
+// The fields of the event are available directly, e.g. {{.Symbol}} or {{.PnL}}.
+type TemplateData struct {
+	types.Event
+
+	// Text short description of the event
+	Text string
+}
+
+type statusError struct {
+	statusCode int
+	body       string
+}
+
+func (se statusError) Error() string {
+	return fmt.Sprintf("Webhook responded with status %d: %s", se.statusCode, se.body)
+}
+
+// New creates a new webhook notifier
+// The config needs an 'url' entry, the other entries are optional:
+//
+//	template       body template, default {"text": {{json .Text}}}
+//	templateFile   file containing the body template, for templates with '=' or ';' characters
+//	contentType    content type of the body, default application/json
+//	header.<Name>  value of the request header <Name>
+//	hmacSecret     secret to sign the body with, the hex HMAC-SHA256 is sent as sha256=<signature>
+//	hmacHeader     header of the signature, default X-Signature
+//	retries        number of retries, default 3
+//	retryInterval  delay before the first retry, doubled for every next retry, default 1s
+//	timeout        timeout of a request, default 10s, shortened to fit the retries in the delivery timeout
+func New(ctx context.Context, config map[string]string) (wh *Webhook, err error) {
+	var templateText, key, value string
+	var templateData []byte
+	var ok bool
+
+	wh = new(Webhook)
+	if wh.url, ok = config["url"]; !ok {
+		err = fmt.Errorf("Webhook config error: 'url' entry not found")
+		return
+	}
+
+	templateText = defaultTemplate
+	if value, ok = config["template"]; ok {
+		templateText = value
+	}
+	if value, ok = config["templateFile"]; ok {
+		if templateData, err = ioutil.ReadFile(value); err != nil {
+			err = fmt.Errorf("Webhook config error: failed to read 'templateFile' %v", err)
+			return
+		}
+		templateText = string(templateData)
+	}
+	if wh.template, err = template.New(notifierName).Funcs(template.FuncMap{"json": toJSON}).Parse(templateText); err != nil {
+		err = fmt.Errorf("Webhook config error: invalid template %v", err)
+		return
+	}
+
+	wh.headers = map[string]string{"Content-Type": defaultContentType}
+	if value, ok = config["contentType"]; ok {
+		wh.headers["Content-Type"] = value
+	}
+	for key, value = range config {
+		if strings.HasPrefix(key, headerPrefix) && len(key) > len(headerPrefix) {
+			wh.headers[strings.TrimPrefix(key, headerPrefix)] = value
+		}
+	}
+
+	if value, ok = config["hmacSecret"]; ok {
+		wh.secret = []byte(value)
+	}
+	wh.signHeader = defaultSignHeader
+	if value, ok = config["hmacHeader"]; ok {
+		wh.signHeader = value
+	}
+
+	wh.retries = defaultRetries
+	if value, ok = config["retries"]; ok {
+		if wh.retries, err = strconv.Atoi(value); err != nil || wh.retries < 0 {
+			err = fmt.Errorf("Webhook config error: invalid 'retries' entry: %s", value)
+			return
+		}
+	}
+
+	wh.retryInterval = defaultRetryInterval
+	if value, ok = config["retryInterval"]; ok {
+		if wh.retryInterval, err = time.ParseDuration(value); err != nil || wh.retryInterval <= 0 {
+			err = fmt.Errorf("Webhook config error: invalid 'retryInterval' entry: %s", value)
+			return
+		}
+	}
+
+	wh.timeout = defaultTimeout
+	if value, ok = config["timeout"]; ok {
+		if wh.timeout, err = time.ParseDuration(value); err != nil || wh.timeout <= 0 {
+			err = fmt.Errorf("Webhook config error: invalid 'timeout' entry: %s", value)
+			return
+		}
+	}
+	wh.client = new(http.Client)
+	return
+}
+
+func createWebhook(ctx context.Context, config map[string]string) (notifier interfaces.INotifier, err error) {
+	notifier, err = New(ctx, config)
+	return
+}
+
+// Name returns the name of the notifier
+func (wh Webhook) Name() string {
+	return notifierName
+}
+
+// Notify renders the event with the body template and posts it to the url
+func (wh Webhook) Notify(ctx context.Context, event types.Event) (err error) {
+	var body bytes.Buffer
+	var attempt int
+	var retryInterval time.Duration
+
+	if err = wh.template.Execute(&body, TemplateData{
+		Event: event,
+		Text:  event.String(),
+	}); err != nil {
+		logger.Errorf("Webhook::Notify Error %v\n", err)
+		return
+	}
+
+	retryInterval = wh.retryInterval
+	for attempt = 0; ; attempt++ {
+		if err = wh.post(ctx, wh.attemptTimeout(ctx, attempt, retryInterval), body.Bytes()); err == nil {
+			return
+		}
+		if attempt >= wh.retries || !retryable(err) || ctx.Err() != nil {
+			break
+		}
+
+		logger.Warningf("Webhook::Notify Attempt %d failed, retrying in %v: %v\n", attempt+1, retryInterval, err)
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			logger.Errorf("Webhook::Notify Error %v\n", err)
+			return
+		case <-time.After(retryInterval):
+		}
+		retryInterval *= 2
+	}
+
+	logger.Errorf("Webhook::Notify Error %v\n", err)
+	return
+}
+
+// attemptTimeout returns the timeout of the request, at most the configured timeout
+// The time left before the deadline of the context is shared by the remaining attempts, after the
+// backoff delays of the retries. If the retries no longer fit, the last attempt gets all the time left.
+func (wh Webhook) attemptTimeout(ctx context.Context, attempt int, retryInterval time.Duration) (timeout time.Duration) {
+	var deadline time.Time
+	var remaining, backoff time.Duration
+	var attempts, index int
+	var ok bool
+
+	timeout = wh.timeout
+	if deadline, ok = ctx.Deadline(); !ok {
+		return
+	}
+
+	attempts = wh.retries - attempt + 1
+	for index = 1; index < attempts; index++ {
+		backoff += retryInterval
+		retryInterval *= 2
+	}
+
+	remaining = time.Until(deadline)
+	if remaining-backoff <= 0 {
+		if remaining < timeout {
+			timeout = remaining
+		}
+		return
+	}
+	if (remaining-backoff)/time.Duration(attempts) < timeout {
+		timeout = (remaining - backoff) / time.Duration(attempts)
+	}
+	return
+}
+
+func (wh Webhook) post(ctx context.Context, timeout time.Duration, body []byte) (err error) {
+	var req *http.Request
+	var resp *http.Response
+	var respBody []byte
+	var mac []byte
+	var key, value string
+	var cancelFn context.CancelFunc
+
+	ctx, cancelFn = context.WithTimeout(ctx, timeout)
+	defer cancelFn()
+
+	if req, err = http.NewRequestWithContext(ctx, "POST", wh.url, bytes.NewReader(body)); err != nil {
+		return
+	}
+	for key, value = range wh.headers {
+		req.Header.Set(key, value)
+	}
+	if len(wh.secret) > 0 {
+		mac = sign(wh.secret, body)
+		req.Header.Set(wh.signHeader, "sha256="+hex.EncodeToString(mac))
+	}
+
+	if resp, err = wh.client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	respBody, _ = ioutil.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err = statusError{
+			statusCode: resp.StatusCode,
+			body:       strings.TrimSpace(string(respBody)),
+		}
+		return
+	}
+	return
+}
+
+// retryable returns true for 5xx responses and timeouts
+func retryable(err error) bool {
+	var se statusError
+	var netErr net.Error
+	var ok bool
+
+	if se, ok = err.(statusError); ok {
+		return se.statusCode >= 500
+	}
+	if netErr, ok = err.(net.Error); ok {
+		return netErr.Timeout()
+	}
+	return false
+}
+
+func sign(secret []byte, body []byte) []byte {
+	var mac = hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// toJSON encodes the value as json, used to escape strings in the template
+func toJSON(v interface{}) (string, error) {
+	var data []byte
+	var err error
+
+	if data, err = json.Marshal(v); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
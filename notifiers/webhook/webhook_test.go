@@ -0,0 +1,246 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// testRequest is a request received by the fake endpoint
+type testRequest struct {
+	header http.Header
+	body   string
+}
+
+// fakeEndpoint is a local webhook receiver answering the requests with the queued statuses
+// A status of 0 does not answer before the delay expires, the remaining requests are answered with 200.
+type fakeEndpoint struct {
+	server   *httptest.Server
+	mux      sync.Mutex
+	statuses []int
+	delay    time.Duration
+	requests []testRequest
+}
+
+func newFakeEndpoint(statuses ...int) (fe *fakeEndpoint) {
+	fe = &fakeEndpoint{statuses: statuses, delay: time.Second}
+	fe.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body []byte
+		var status int
+
+		body, _ = ioutil.ReadAll(r.Body)
+
+		fe.mux.Lock()
+		fe.requests = append(fe.requests, testRequest{header: r.Header, body: string(body)})
+		status = http.StatusOK
+		if len(fe.statuses) > 0 {
+			status = fe.statuses[0]
+			fe.statuses = fe.statuses[1:]
+		}
+		fe.mux.Unlock()
+
+		if status == 0 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(fe.delay):
+			}
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	return
+}
+
+func (fe *fakeEndpoint) received() []testRequest {
+	fe.mux.Lock()
+	defer fe.mux.Unlock()
+
+	return append([]testRequest(nil), fe.requests...)
+}
+
+func newTestWebhook(t *testing.T, fe *fakeEndpoint, config map[string]string) (wh *Webhook) {
+	var ok bool
+	var err error
+
+	config["url"] = fe.server.URL
+	if _, ok = config["retryInterval"]; !ok {
+		config["retryInterval"] = "10ms"
+	}
+	if wh, err = New(context.Background(), config); err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return
+}
+
+func testEvent() types.Event {
+	return types.NewOrderFilledEvent(types.NewSymbol("BTC", "USDT"), types.Buy, 0.5, 10000.0, false)
+}
+
+func TestNotifyTemplate(t *testing.T) {
+	var fe *fakeEndpoint
+	var wh *Webhook
+	var requests []testRequest
+	var event types.Event
+	var err error
+
+	fe = newFakeEndpoint()
+	defer fe.server.Close()
+	event = testEvent()
+
+	// Default template
+	wh = newTestWebhook(t, fe, map[string]string{})
+	if err = wh.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	// Custom template with the event fields and custom headers
+	wh = newTestWebhook(t, fe, map[string]string{
+		"template":             `{"symbol": {{json .Symbol.String}}, "quantity": {{.Quantity}}, "text": {{json .Text}}}`,
+		"contentType":          "application/vnd.test+json",
+		"header.Authorization": "Bearer token",
+		"header.X-Source":      "cryptotrader",
+	})
+	if err = wh.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if requests = fe.received(); len(requests) != 2 {
+		t.Fatalf("requests: got %d, want 2", len(requests))
+	}
+	if requests[0].body != `{"text": "`+event.String()+`"}` {
+		t.Errorf("default template: got %s", requests[0].body)
+	}
+	if requests[0].header.Get("Content-Type") != defaultContentType {
+		t.Errorf("default content type: got %s", requests[0].header.Get("Content-Type"))
+	}
+	if requests[1].body != `{"symbol": "BTC/USDT", "quantity": 0.5, "text": "`+event.String()+`"}` {
+		t.Errorf("custom template: got %s", requests[1].body)
+	}
+	if requests[1].header.Get("Content-Type") != "application/vnd.test+json" || requests[1].header.Get("Authorization") != "Bearer token" || requests[1].header.Get("X-Source") != "cryptotrader" {
+		t.Errorf("custom headers: got %v", requests[1].header)
+	}
+	if requests[1].header.Get(defaultSignHeader) != "" {
+		t.Errorf("unsigned request carries a signature")
+	}
+}
+
+func TestNotifySignature(t *testing.T) {
+	var fe *fakeEndpoint
+	var wh *Webhook
+	var requests []testRequest
+	var mac = hmac.New(sha256.New, []byte("secret"))
+	var err error
+
+	fe = newFakeEndpoint()
+	defer fe.server.Close()
+
+	wh = newTestWebhook(t, fe, map[string]string{"hmacSecret": "secret", "hmacHeader": "X-Hub-Signature-256"})
+	if err = wh.Notify(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if requests = fe.received(); len(requests) != 1 {
+		t.Fatalf("requests: got %d, want 1", len(requests))
+	}
+	mac.Write([]byte(requests[0].body))
+	if requests[0].header.Get("X-Hub-Signature-256") != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+		t.Errorf("signature: got %s", requests[0].header.Get("X-Hub-Signature-256"))
+	}
+}
+
+func TestNotifyRetries(t *testing.T) {
+	var tests = []struct {
+		name     string
+		statuses []int
+		config   map[string]string
+		requests int
+		fails    bool
+	}{
+		{"retry on 5xx", []int{http.StatusInternalServerError, http.StatusBadGateway}, map[string]string{}, 3, false},
+		{"retry on timeout", []int{0, 0}, map[string]string{"timeout": "50ms"}, 3, false},
+		{"no retry on 4xx", []int{http.StatusBadRequest}, map[string]string{}, 1, true},
+		{"retries exhausted", []int{500, 500, 500}, map[string]string{"retries": "2"}, 3, true},
+	}
+	var fe *fakeEndpoint
+	var wh *Webhook
+	var index, count int
+	var err error
+
+	for index = range tests {
+		fe = newFakeEndpoint(tests[index].statuses...)
+		wh = newTestWebhook(t, fe, tests[index].config)
+
+		if err = wh.Notify(context.Background(), testEvent()); (err != nil) != tests[index].fails {
+			t.Errorf("%s: got error %v, want failure %v", tests[index].name, err, tests[index].fails)
+		}
+		if count = len(fe.received()); count != tests[index].requests {
+			t.Errorf("%s: got %d requests, want %d", tests[index].name, count, tests[index].requests)
+		}
+		fe.server.Close()
+	}
+}
+
+func TestNotifyRetriesFitDeadline(t *testing.T) {
+	var fe *fakeEndpoint
+	var wh *Webhook
+	var ctx context.Context
+	var cancelFn context.CancelFunc
+	var start time.Time
+	var err error
+
+	// Every request times out with the default 10s timeout, the retries must still fit the 1s delivery deadline
+	fe = newFakeEndpoint(0, 0, 0)
+	fe.delay = time.Second * 10
+	defer fe.server.Close()
+	wh = newTestWebhook(t, fe, map[string]string{"retryInterval": "50ms"})
+
+	ctx, cancelFn = context.WithTimeout(context.Background(), time.Second)
+	defer cancelFn()
+	start = time.Now()
+	if err = wh.Notify(ctx, testEvent()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(fe.received()) != 4 {
+		t.Errorf("requests: got %d, want 4", len(fe.received()))
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("delivery took %v, longer than the deadline", time.Since(start))
+	}
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	var wh = Webhook{retries: 3, retryInterval: time.Second, timeout: time.Second * 10}
+	var ctx context.Context
+	var cancelFn context.CancelFunc
+	var timeout time.Duration
+
+	if timeout = wh.attemptTimeout(context.Background(), 0, time.Second); timeout != wh.timeout {
+		t.Errorf("without deadline: got %v, want %v", timeout, wh.timeout)
+	}
+
+	// 4 attempts and 1s+2s+4s backoff in 15s leaves 2s per attempt
+	ctx, cancelFn = context.WithTimeout(context.Background(), time.Second*15)
+	defer cancelFn()
+	if timeout = wh.attemptTimeout(ctx, 0, time.Second); timeout > time.Second*2 || timeout < time.Second*2-time.Millisecond*100 {
+		t.Errorf("with deadline: got %v, want 2s", timeout)
+	}
+
+	// The last attempt is only limited by the configured timeout
+	if timeout = wh.attemptTimeout(ctx, 3, time.Second*8); timeout != wh.timeout {
+		t.Errorf("last attempt: got %v, want %v", timeout, wh.timeout)
+	}
+
+	// Once the backoff does not fit anymore, the attempt gets all the time left
+	if timeout = wh.attemptTimeout(ctx, 0, time.Second*8); timeout > time.Second*10 || timeout < time.Second*10-time.Millisecond*100 {
+		t.Errorf("backoff beyond the deadline: got %v, want the 10s timeout", timeout)
+	}
+}
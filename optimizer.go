@@ -0,0 +1,292 @@
+package cryptotrader
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/mhereman/cryptotrader/algorithms"
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// Optimizer backtests every combination of the parameter ranges and ranks the results
+type Optimizer struct {
+	algoCfg      AlgorithmConfig
+	tradeCfg     TradeConfig
+	accountInfo  types.AccountInfo
+	optimizerCfg OptimizerConfig
+}
+
+// OptimizationRun represents the backtest of one parameter combination
+type OptimizationRun struct {
+	// Params the values of the optimized parameters, in the order of the ranges
+	Params []string
+
+	// Statistics summary of the backtest
+	Statistics BacktestStatistics
+
+	// Score value of the objective
+	Score float64
+}
+
+// OptimizationResult represents the outcome of an optimization
+type OptimizationResult struct {
+	// Symbol of the backtested series
+	Symbol types.Symbol
+
+	// Timeframe of the backtested series
+	Timeframe types.Timeframe
+
+	// Objective the runs are ranked by
+	Objective OptimizeObjective
+
+	// ParamNames names of the optimized parameters
+	ParamNames []string
+
+	// Runs the backtests ranked by the objective, best first
+	Runs []OptimizationRun
+
+	// Skipped number of combinations rejected by the algorithm
+	Skipped int
+}
+
+// NewOptimizer creates a new Optimizer instance
+// The parameters without range keep the value of the algorithm config.
+func NewOptimizer(algorithmConfig AlgorithmConfig, tradeConfig TradeConfig, accountInfo types.AccountInfo, optimizerConfig OptimizerConfig) (o *Optimizer) {
+	o = &Optimizer{
+		algoCfg:      algorithmConfig,
+		tradeCfg:     tradeConfig,
+		accountInfo:  accountInfo,
+		optimizerCfg: optimizerConfig,
+	}
+	return
+}
+
+// Run backtests the combinations of the parameter ranges on the series in parallel
+func (o *Optimizer) Run(ctx context.Context, series types.Series) (result OptimizationResult, err error) {
+	var algorithm interfaces.IAlgorithm
+	var paramRange ParamRange
+	var combinations chan []string
+	var runs chan OptimizationRun
+	var runCtx context.Context
+	var cancelFn context.CancelFunc
+	var wg sync.WaitGroup
+	var run OptimizationRun
+	var index, total, done int
+	var ok bool
+
+	if algorithm, err = algorithms.GetAlgorithm(o.algoCfg.Name); err != nil {
+		logger.Errorf("Optimizer::Run Error %v\n", err)
+		return
+	}
+	for _, paramRange = range o.optimizerCfg.Ranges {
+		if _, ok = algorithm.Schema().Param(paramRange.Name); !ok {
+			err = fmt.Errorf("Invalid range: unknown parameter %s of algorithm %s, valid: %s", paramRange.Name, algorithm.Name(), strings.Join(algorithm.Schema().Names(), ", "))
+			logger.Errorf("Optimizer::Run Error %v\n", err)
+			return
+		}
+		result.ParamNames = append(result.ParamNames, paramRange.Name)
+	}
+
+	result.Symbol = series.Symbol
+	result.Timeframe = series.Timeframe
+	result.Objective = o.optimizerCfg.Objective
+
+	total = o.optimizerCfg.Combinations()
+	logger.Infof("Optimizer: Running %d backtests on %d workers\n", total, o.optimizerCfg.Workers)
+
+	runCtx, cancelFn = context.WithCancel(ctx)
+	defer cancelFn()
+
+	combinations = make(chan []string)
+	runs = make(chan OptimizationRun)
+	for index = 0; index < o.optimizerCfg.Workers; index++ {
+		wg.Add(1)
+		go o.workerRoutine(runCtx, &wg, series, combinations, runs)
+	}
+
+	go func() {
+		defer close(combinations)
+		o.generateCombinations(runCtx, combinations, make([]string, 0, len(o.optimizerCfg.Ranges)))
+	}()
+	go func() {
+		wg.Wait()
+		close(runs)
+	}()
+
+	for run = range runs {
+		done++
+		if run.Params == nil {
+			result.Skipped++
+		} else {
+			result.Runs = append(result.Runs, run)
+		}
+		if done%100 == 0 || done == total {
+			logger.Infof("Optimizer: %d/%d backtests done\n", done, total)
+		}
+	}
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	sort.SliceStable(result.Runs, func(i, j int) bool {
+		if result.Runs[i].Score == result.Runs[j].Score {
+			return result.Runs[i].Statistics.NetProfit > result.Runs[j].Statistics.NetProfit
+		}
+		return result.Runs[i].Score > result.Runs[j].Score
+	})
+	return
+}
+
+// generateCombinations sends every combination of the values of the remaining ranges
+func (o *Optimizer) generateCombinations(ctx context.Context, combinations chan<- []string, prefix []string) bool {
+	var value string
+	var combination []string
+
+	if len(prefix) == len(o.optimizerCfg.Ranges) {
+		combination = make([]string, len(prefix))
+		copy(combination, prefix)
+		select {
+		case <-ctx.Done():
+			return false
+		case combinations <- combination:
+			return true
+		}
+	}
+
+	for _, value = range o.optimizerCfg.Ranges[len(prefix)].Values {
+		if !o.generateCombinations(ctx, combinations, append(prefix, value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// workerRoutine backtests the combinations until none are left
+// A combination the algorithm rejects results in a run without params.
+func (o *Optimizer) workerRoutine(ctx context.Context, wg *sync.WaitGroup, series types.Series, combinations <-chan []string, runs chan<- OptimizationRun) {
+	defer wg.Done()
+
+	var combination []string
+	var run OptimizationRun
+	var backtestResult BacktestResult
+	var err error
+
+	for combination = range combinations {
+		run = OptimizationRun{}
+		if backtestResult, err = NewBacktester(o.combinationConfig(combination), o.tradeCfg, o.accountInfo).Run(ctx, series); err != nil {
+			logger.Debugf("Optimizer: Skipping %v: %v\n", combination, err)
+		} else {
+			run.Params = combination
+			run.Statistics = backtestResult.Statistics
+			run.Score = o.optimizerCfg.Objective.Score(backtestResult.Statistics)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case runs <- run:
+		}
+	}
+}
+
+// combinationConfig returns the algorithm config with the values of the combination
+func (o *Optimizer) combinationConfig(combination []string) (algoCfg AlgorithmConfig) {
+	var key, value string
+	var index int
+
+	algoCfg.Name = o.algoCfg.Name
+	algoCfg.Config = make(types.AlgorithmConfig)
+	for key, value = range o.algoCfg.Config {
+		algoCfg.Config[key] = value
+	}
+	for index, value = range combination {
+		algoCfg.Config[o.optimizerCfg.Ranges[index].Name] = value
+	}
+	return
+}
+
+// WriteCSV writes the ranked runs as CSV, one row per run
+func (r OptimizationResult) WriteCSV(w io.Writer) (err error) {
+	var cw *csv.Writer
+	var run OptimizationRun
+	var stats BacktestStatistics
+	var index int
+
+	cw = csv.NewWriter(w)
+	if err = cw.Write(append(append([]string{"rank"}, r.ParamNames...), "score", "net_profit", "net_profit_pct", "trades", "win_rate", "profit_factor", "max_drawdown_pct", "sharpe_ratio", "fees")); err != nil {
+		return
+	}
+	for index, run = range r.Runs {
+		stats = run.Statistics
+		if err = cw.Write(append(append([]string{strconv.Itoa(index + 1)}, run.Params...),
+			formatCSVFloat(run.Score),
+			formatCSVFloat(stats.NetProfit),
+			formatCSVFloat(stats.NetProfitPct),
+			strconv.Itoa(stats.NumTrades),
+			formatCSVFloat(stats.WinRate),
+			formatCSVFloat(stats.ProfitFactor),
+			formatCSVFloat(stats.MaxDrawdownPct),
+			formatCSVFloat(stats.SharpeRatio),
+			formatCSVFloat(stats.TotalFees),
+		)); err != nil {
+			return
+		}
+	}
+	cw.Flush()
+	err = cw.Error()
+	return
+}
+
+// WriteReport writes a human readable report of the best runs
+func (r OptimizationResult) WriteReport(w io.Writer, top int) (err error) {
+	var tw *tabwriter.Writer
+	var run OptimizationRun
+	var name, value string
+	var index int
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Optimization %s[%s], objective: %s\n", r.Symbol.String(), r.Timeframe.String(), r.Objective.String())
+	fmt.Fprintf(tw, "Backtests: %d, skipped: %d\n\n", len(r.Runs), r.Skipped)
+
+	fmt.Fprintf(tw, "#\t")
+	for _, name = range r.ParamNames {
+		fmt.Fprintf(tw, "%s\t", name)
+	}
+	fmt.Fprintf(tw, "Net profit\tTrades\tWin rate\tProfit factor\tMax drawdown\tSharpe ratio\n")
+
+	for index, run = range r.Runs {
+		if top > 0 && index >= top {
+			break
+		}
+		fmt.Fprintf(tw, "%d\t", index+1)
+		for _, value = range run.Params {
+			fmt.Fprintf(tw, "%s\t", value)
+		}
+		fmt.Fprintf(tw, "%f (%.2f%%)\t%d\t%.2f%%\t%.2f\t%.2f%%\t%.2f\n",
+			run.Statistics.NetProfit,
+			run.Statistics.NetProfitPct*100.0,
+			run.Statistics.NumTrades,
+			run.Statistics.WinRate*100.0,
+			run.Statistics.ProfitFactor,
+			run.Statistics.MaxDrawdownPct*100.0,
+			run.Statistics.SharpeRatio,
+		)
+	}
+
+	err = tw.Flush()
+	return
+}
+
+func formatCSVFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
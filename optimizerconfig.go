@@ -0,0 +1,220 @@
+package cryptotrader
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// OptimizeObjective represents the statistic the optimizer ranks the backtests by
+type OptimizeObjective int
+
+const (
+	// ObjectiveNetProfit highest net profit
+	ObjectiveNetProfit OptimizeObjective = iota
+
+	// ObjectiveSharpe highest sharpe ratio
+	ObjectiveSharpe
+
+	// ObjectiveProfitFactor highest profit factor
+	ObjectiveProfitFactor
+
+	// ObjectiveWinRate highest ratio of winning trades
+	ObjectiveWinRate
+
+	// ObjectiveDrawdown lowest max drawdown
+	ObjectiveDrawdown
+)
+
+var objectiveNames = []string{"netprofit", "sharpe", "profitfactor", "winrate", "drawdown"}
+
+// String returns the string name of the OptimizeObjective
+func (o OptimizeObjective) String() string {
+	if int(o) < 0 || int(o) >= len(objectiveNames) {
+		return "unknown"
+	}
+	return objectiveNames[o]
+}
+
+// NewOptimizeObjectiveFromString creates an OptimizeObjective from its string name
+func NewOptimizeObjectiveFromString(in string) (o OptimizeObjective, err error) {
+	var index int
+	var name string
+
+	in = strings.ToLower(strings.TrimSpace(in))
+	for index, name = range objectiveNames {
+		if name == in {
+			o = OptimizeObjective(index)
+			return
+		}
+	}
+	err = fmt.Errorf("Invalid objective: %s, valid: %s", in, strings.Join(objectiveNames, ", "))
+	return
+}
+
+// Score returns the value of the objective for the statistics of a backtest, higher is better
+func (o OptimizeObjective) Score(stats BacktestStatistics) float64 {
+	switch o {
+	case ObjectiveSharpe:
+		return stats.SharpeRatio
+	case ObjectiveProfitFactor:
+		return stats.ProfitFactor
+	case ObjectiveWinRate:
+		return stats.WinRate
+	case ObjectiveDrawdown:
+		return -stats.MaxDrawdownPct
+	default:
+		return stats.NetProfit
+	}
+}
+
+// ParamRange represents the values of an algorithm parameter to optimize
+type ParamRange struct {
+	// Name of the algorithm parameter
+	Name string
+
+	// Values to backtest the parameter with
+	Values []string
+}
+
+// NewParamRangeFromFlag creates a new ParamRange from a -range cmdline argument value
+// The value format should be:
+//
+//	name=from..to[:step]
+//	name=value1,value2,...
+//
+// The step defaults to 1, the range includes the from and to values.
+func NewParamRangeFromFlag(in string) (pr ParamRange, err error) {
+	var parts, bounds []string
+	var bound string
+	var from, to, step, value float64
+	var decimals, index int
+
+	parts = strings.SplitN(in, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		err = fmt.Errorf("Invalid range: %s, expected name=from..to[:step] or name=value1,value2,...", in)
+		return
+	}
+	pr.Name = parts[0]
+
+	if !strings.Contains(parts[1], "..") {
+		pr.Values = strings.Split(parts[1], ",")
+		return
+	}
+
+	step = 1.0
+	bounds = strings.SplitN(parts[1], ":", 2)
+	if len(bounds) == 2 {
+		if step, err = strconv.ParseFloat(bounds[1], 64); err != nil || step <= 0.0 {
+			err = fmt.Errorf("Invalid range: %s, step must be a number greater than 0", in)
+			return
+		}
+		decimals = countDecimals(bounds[1])
+	}
+
+	bounds = strings.SplitN(bounds[0], "..", 2)
+	if from, err = strconv.ParseFloat(bounds[0], 64); err != nil {
+		err = fmt.Errorf("Invalid range: %s, invalid from value %s", in, bounds[0])
+		return
+	}
+	if to, err = strconv.ParseFloat(bounds[1], 64); err != nil {
+		err = fmt.Errorf("Invalid range: %s, invalid to value %s", in, bounds[1])
+		return
+	}
+	if to < from {
+		err = fmt.Errorf("Invalid range: %s, to is below from", in)
+		return
+	}
+	for _, bound = range bounds {
+		if countDecimals(bound) > decimals {
+			decimals = countDecimals(bound)
+		}
+	}
+
+	// The values are computed from the index, adding up the steps accumulates rounding errors
+	for index = 0; ; index++ {
+		value = from + float64(index)*step
+		if value > to+step*1e-9 {
+			break
+		}
+		pr.Values = append(pr.Values, strconv.FormatFloat(value, 'f', decimals, 64))
+	}
+	return
+}
+
+func countDecimals(in string) int {
+	var index int
+
+	if index = strings.Index(in, "."); index < 0 {
+		return 0
+	}
+	return len(in) - index - 1
+}
+
+// OptimizerConfig represents the config of a parameter optimization
+type OptimizerConfig struct {
+	// Ranges of the algorithm parameters to optimize, every combination of the values is backtested
+	Ranges []ParamRange
+
+	// Objective to rank the backtests by
+	Objective OptimizeObjective
+
+	// Workers number of backtests run in parallel
+	Workers int
+
+	// ResultFile path of the CSV file to write the results to
+	ResultFile string
+
+	// Top number of best results to report
+	Top int
+}
+
+// NewOptimizerConfigFromFlags creates a new OptimizerConfig instance from the cmdline argument values
+// Workers defaults to the number of CPUs if not set.
+func NewOptimizerConfigFromFlags(ranges []string, objective string, workers int, resultFile string, top int) (oc OptimizerConfig, err error) {
+	var rangeString string
+	var paramRange ParamRange
+	var names map[string]bool
+
+	if len(ranges) == 0 {
+		err = fmt.Errorf("No parameter ranges to optimize configured")
+		return
+	}
+
+	names = make(map[string]bool)
+	for _, rangeString = range ranges {
+		if paramRange, err = NewParamRangeFromFlag(rangeString); err != nil {
+			return
+		}
+		if names[paramRange.Name] {
+			err = fmt.Errorf("Invalid range: %s, parameter %s has multiple ranges", rangeString, paramRange.Name)
+			return
+		}
+		names[paramRange.Name] = true
+		oc.Ranges = append(oc.Ranges, paramRange)
+	}
+
+	if oc.Objective, err = NewOptimizeObjectiveFromString(objective); err != nil {
+		return
+	}
+
+	oc.Workers = workers
+	if oc.Workers <= 0 {
+		oc.Workers = runtime.NumCPU()
+	}
+	oc.ResultFile = resultFile
+	oc.Top = top
+	return
+}
+
+// Combinations returns the number of parameter combinations to backtest
+func (oc OptimizerConfig) Combinations() (count int) {
+	var paramRange ParamRange
+
+	count = 1
+	for _, paramRange = range oc.Ranges {
+		count *= len(paramRange.Values)
+	}
+	return
+}
@@ -0,0 +1,164 @@
+package cryptotrader
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const positionMonitorInterval time.Duration = (time.Second * 10)
+
+func (ct *CryptoTrader) runPositionMonitorAsync() {
+	ct.wg.Add(1)
+	go positionMonitorRoutine(ct)
+}
+
+func positionMonitorRoutine(ct *CryptoTrader) {
+	defer ct.wg.Done()
+
+	var ticker *time.Ticker
+	var runLoop bool
+
+	ticker = time.NewTicker(positionMonitorInterval)
+	defer ticker.Stop()
+
+	runLoop = true
+	for runLoop {
+		select {
+		case <-ct.ctx.Done():
+			runLoop = false
+		case <-ticker.C:
+			ct.monitorPositions()
+		}
+	}
+}
+
+// monitorPositions closes the positions which reached their take profit or stop loss
+// and moves the trailing stops of the remaining positions
+func (ct *CryptoTrader) monitorPositions() {
+	var symbolString string
+	var symbols []string
+	var symbol types.Symbol
+	var closed, ok bool
+	var err error
+
+	ct.positionMux.Lock()
+	defer ct.positionMux.Unlock()
+
+	for symbolString = range ct.openTrades {
+		symbols = append(symbols, symbolString)
+	}
+
+	for _, symbolString = range symbols {
+		if symbol, err = types.NewSymbolFromString(symbolString); err != nil {
+			logger.Errorf("monitorPositions: Invalid symbol: %s %v\n", symbolString, err)
+			continue
+		}
+
+		if closed, err = ct.checkExitOrders(symbol); err != nil {
+			logger.Errorf("monitorPositions: %v\n", err)
+			continue
+		}
+		if closed {
+			continue
+		}
+
+		if _, ok = ct.stopLossOrders[symbolString]; ok && ct.tradeCfg.TrailingStop > 0.0 {
+			if err = ct.updateTrailingStop(symbol); err != nil {
+				logger.Errorf("monitorPositions: %v\n", err)
+			}
+		}
+	}
+}
+
+// checkExitOrders checks whether the position of the symbol was closed by its stop loss or take profit
+// If the take profit is not placed on the exchange, the position is closed when the market price reaches it.
+// The caller must hold the position lock.
+func (ct *CryptoTrader) checkExitOrders(symbol types.Symbol) (closed bool, err error) {
+	var symbolString, orderID string
+	var price, takeProfit float64
+	var position types.PositionSide
+	var accountInfo types.AccountInfo
+	var orderInfo types.OrderInfo
+	var ok bool
+
+	symbolString = symbol.String()
+	position = ct.positionSides[symbolString]
+	if orderID, ok = ct.stopLossOrders[symbolString]; ok {
+		if closed, orderInfo, err = ct.orderFilled(symbol, orderID); err != nil {
+			return
+		}
+		if closed {
+			logger.Infof("checkExitOrders: Position for symbol %s closed by its stop loss\n", symbolString)
+			ct.notifyExit(types.NewStopLossEvent(symbol, position, orderInfo.ExecutedQuantity, orderInfo.AveragePrice()))
+			ct.forgetPosition(symbol)
+			return
+		}
+	}
+
+	if orderID, ok = ct.takeProfitOrders[symbolString]; ok {
+		if closed, orderInfo, err = ct.orderFilled(symbol, orderID); err != nil {
+			return
+		}
+		if closed {
+			logger.Infof("checkExitOrders: Position for symbol %s closed by its take profit\n", symbolString)
+			ct.notifyExit(types.NewTakeProfitEvent(symbol, position, orderInfo.ExecutedQuantity, orderInfo.AveragePrice()))
+			ct.forgetPosition(symbol)
+		}
+		return
+	}
+
+	if takeProfit, ok = ct.takeProfitPrices[symbolString]; !ok {
+		return
+	}
+	if price, err = ct.exchangeDriver.Ticker(ct.ctx, symbol); err != nil {
+		err = fmt.Errorf("checkExitOrders Failed to retrieve market price for symbol %s %v", symbolString, err)
+		return
+	}
+	if priceImproves(position, takeProfit, price) {
+		return
+	}
+
+	logger.Infof("checkExitOrders: Take profit reached for symbol %s [Price: %f; Target: %f]\n", symbolString, price, takeProfit)
+	ct.notify(types.NewTakeProfitEvent(symbol, position, ct.quantities[symbolString], price))
+	if accountInfo, err = ct.exchangeDriver.GetAccountInfo(ct.ctx); err != nil {
+		err = fmt.Errorf("checkExitOrders Failed to retrieve account info %v", err)
+		return
+	}
+	if err = ct.liveClosePosition(accountInfo, symbol); err != nil {
+		return
+	}
+	closed = true
+	return
+}
+
+// notifyExit sends the stop loss or take profit event and the closed position event of the filled exit order
+func (ct *CryptoTrader) notifyExit(event types.Event) {
+	ct.notify(event)
+	ct.notify(types.NewPositionClosedEvent(event.Symbol, event.Position, event.Quantity, ct.entryPrices[event.Symbol.String()], event.Price, false))
+}
+
+// orderFilled returns true and the order info if the order with the user reference is completely filled
+func (ct *CryptoTrader) orderFilled(symbol types.Symbol, orderID string) (filled bool, orderInfo types.OrderInfo, err error) {
+	var orderUUID uuid.UUID
+
+	if orderUUID, err = uuid.Parse(orderID); err != nil {
+		err = fmt.Errorf("orderFilled Invalid order uuid: %s %v", orderID, err)
+		return
+	}
+
+	if orderInfo, err = ct.exchangeDriver.GetOrder(ct.ctx, types.Order{
+		Symbol:        symbol,
+		UserReference: orderUUID,
+	}); err != nil {
+		err = fmt.Errorf("orderFilled Failed to retrieve order %s for symbol %s %v", orderID, symbol.String(), err)
+		return
+	}
+
+	filled = orderInfo.Status == types.StatusFilled
+	return
+}
@@ -0,0 +1,92 @@
+package cryptotrader
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mhereman/cryptotrader/logger"
+)
+
+const secretFilePrefix = "file:"
+
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// secretKeys parts of the argument names holding a secret
+var secretKeys = []string{"apikey", "privatekey", "secret", "token", "password", "passphrase"}
+
+// resolveSecrets resolves the secret references in the argument values
+// ${NAME} is replaced by the value of the environment variable NAME and a value 'file:/path'
+// is replaced by the contents of the file. The resolved values and the values of arguments
+// named like a secret are redacted from the log.
+func resolveSecrets(args map[string]string) (out map[string]string, err error) {
+	var key, value, resolved string
+	var data []byte
+	var missing []string
+
+	out = make(map[string]string)
+	for key, value = range args {
+		resolved = envReference.ReplaceAllStringFunc(value, func(reference string) string {
+			var name, envValue string
+			var ok bool
+
+			name = envReference.FindStringSubmatch(reference)[1]
+			if envValue, ok = os.LookupEnv(name); !ok {
+				missing = append(missing, name)
+				return reference
+			}
+			logger.AddSecret(envValue)
+			return envValue
+		})
+		if len(missing) > 0 {
+			err = fmt.Errorf("Invalid argument %s: environment variable %s not set", key, strings.Join(missing, ", "))
+			return
+		}
+
+		if strings.HasPrefix(resolved, secretFilePrefix) {
+			if data, err = ioutil.ReadFile(strings.TrimPrefix(resolved, secretFilePrefix)); err != nil {
+				err = fmt.Errorf("Invalid argument %s: failed to read secret %v", key, err)
+				return
+			}
+			resolved = strings.TrimRight(string(data), "\r\n")
+			logger.AddSecret(resolved)
+		}
+
+		if isSecretKey(key) {
+			logger.AddSecret(resolved)
+		}
+		out[key] = resolved
+	}
+	return
+}
+
+func isSecretKey(key string) bool {
+	var part string
+
+	key = strings.ToLower(key)
+	for _, part = range secretKeys {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedArgs returns the arguments as key=value list with the secrets redacted
+func redactedArgs(args map[string]string) string {
+	var keys, parts []string
+	var key string
+
+	for key = range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key = range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", key, logger.Redact(args[key])))
+	}
+	return strings.Join(parts, ";")
+}
@@ -0,0 +1,19 @@
+package cryptotrader
+
+import "strings"
+
+// StateStoreConfig represents the config for the store which persists the open trades
+type StateStoreConfig struct {
+	// Name of the state store to use
+	Name string
+
+	// ArgMap arguments of the state store
+	ArgMap map[string]string
+}
+
+// NewStateStoreConfigFromFlags creates a new StateStoreConfig insance from the cmdline argument values
+func NewStateStoreConfigFromFlags(name string, args map[string]string) (sc StateStoreConfig, err error) {
+	sc.Name = strings.ToLower(name)
+	sc.ArgMap = args
+	return
+}
@@ -0,0 +1,33 @@
+package statestores
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+)
+
+var stateStoreFactory map[string]func(context.Context, map[string]string) (interfaces.IStateStore, error) = make(map[string]func(context.Context, map[string]string) (interfaces.IStateStore, error))
+
+// RegisterStateStore registers a new state store factory function.
+// This function should be called from the init() function of the
+// state store plugin.
+func RegisterStateStore(name string, factory func(context.Context, map[string]string) (interfaces.IStateStore, error)) {
+	stateStoreFactory[name] = factory
+	logger.Printf("Registered state store: %s\n", name)
+}
+
+// GetStateStore returns a state store plugin registered under the provided name or an error
+func GetStateStore(ctx context.Context, name string, args map[string]string) (store interfaces.IStateStore, err error) {
+	var ok bool
+	var fn func(context.Context, map[string]string) (interfaces.IStateStore, error)
+
+	if fn, ok = stateStoreFactory[name]; !ok {
+		err = fmt.Errorf("State store %s does not exist", name)
+		return
+	}
+
+	store, err = fn(ctx, args)
+	return
+}
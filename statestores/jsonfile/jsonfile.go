@@ -0,0 +1,170 @@
+package jsonfile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/statestores"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const stateStoreName = "json"
+
+func init() {
+	statestores.RegisterStateStore(stateStoreName, createJSONFile)
+}
+
+// JSONFile represents the state store which keeps the open trades in a json file
+type JSONFile struct {
+	path string
+	mux  sync.Mutex
+}
+
+// New creates a new JSON file state store
+func New(ctx context.Context, config map[string]string) (store *JSONFile, err error) {
+	var path string
+	var ok bool
+
+	if path, ok = config["path"]; !ok {
+		err = fmt.Errorf("JSONFile config error: 'path' entry not found")
+		return
+	}
+
+	store = new(JSONFile)
+	store.path = path
+	return
+}
+
+func createJSONFile(ctx context.Context, config map[string]string) (store interfaces.IStateStore, err error) {
+	store, err = New(ctx, config)
+	return
+}
+
+// Name returns the name of the state store
+func (j *JSONFile) Name() string {
+	return stateStoreName
+}
+
+// Load returns all stored open trades
+func (j *JSONFile) Load(ctx context.Context) (trades []types.OpenTrade, err error) {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+
+	var tradeMap map[string]types.OpenTrade
+	var keys []string
+	var key string
+
+	if tradeMap, err = j.read(); err != nil {
+		return
+	}
+
+	keys = make([]string, 0, len(tradeMap))
+	for key = range tradeMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	trades = make([]types.OpenTrade, 0, len(keys))
+	for _, key = range keys {
+		trades = append(trades, tradeMap[key])
+	}
+	return
+}
+
+// Save stores the open trade
+func (j *JSONFile) Save(ctx context.Context, trade types.OpenTrade) (err error) {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+
+	var tradeMap map[string]types.OpenTrade
+
+	if tradeMap, err = j.read(); err != nil {
+		return
+	}
+
+	tradeMap[trade.Symbol.String()] = trade
+	err = j.write(tradeMap)
+	return
+}
+
+// Delete removes the stored trade of the symbol
+func (j *JSONFile) Delete(ctx context.Context, symbol types.Symbol) (err error) {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+
+	var tradeMap map[string]types.OpenTrade
+
+	if tradeMap, err = j.read(); err != nil {
+		return
+	}
+
+	delete(tradeMap, symbol.String())
+	err = j.write(tradeMap)
+	return
+}
+
+func (j *JSONFile) read() (tradeMap map[string]types.OpenTrade, err error) {
+	var data []byte
+
+	tradeMap = make(map[string]types.OpenTrade)
+	if data, err = ioutil.ReadFile(j.path); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+			return
+		}
+		logger.Errorf("JSONFile::read Error %v\n", err)
+		return
+	}
+
+	if err = json.Unmarshal(data, &tradeMap); err != nil {
+		logger.Errorf("JSONFile::read Error %v\n", err)
+		return
+	}
+	return
+}
+
+// write replaces the file through a rename so a crash never leaves a partially written file behind
+func (j *JSONFile) write(tradeMap map[string]types.OpenTrade) (err error) {
+	var data []byte
+	var tmpFile *os.File
+
+	if data, err = json.MarshalIndent(tradeMap, "", "  "); err != nil {
+		logger.Errorf("JSONFile::write Error %v\n", err)
+		return
+	}
+
+	if tmpFile, err = ioutil.TempFile(filepath.Dir(j.path), filepath.Base(j.path)+".*.tmp"); err != nil {
+		logger.Errorf("JSONFile::write Error %v\n", err)
+		return
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err = tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		logger.Errorf("JSONFile::write Error %v\n", err)
+		return
+	}
+	if err = tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		logger.Errorf("JSONFile::write Error %v\n", err)
+		return
+	}
+	if err = tmpFile.Close(); err != nil {
+		logger.Errorf("JSONFile::write Error %v\n", err)
+		return
+	}
+
+	if err = os.Rename(tmpFile.Name(), j.path); err != nil {
+		logger.Errorf("JSONFile::write Error %v\n", err)
+		return
+	}
+	return
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mhereman/cryptotrader/interfaces"
+	"github.com/mhereman/cryptotrader/statestores"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+const stateStoreName = "memory"
+
+func init() {
+	statestores.RegisterStateStore(stateStoreName, createMemory)
+}
+
+// Memory represents the in memory state store
+// The state is lost when the process exits
+type Memory struct {
+	trades map[string]types.OpenTrade
+	mux    sync.Mutex
+}
+
+func createMemory(ctx context.Context, config map[string]string) (store interfaces.IStateStore, err error) {
+	store = &Memory{
+		trades: make(map[string]types.OpenTrade),
+	}
+	return
+}
+
+// Name returns the name of the state store
+func (m *Memory) Name() string {
+	return stateStoreName
+}
+
+// Load returns all stored open trades
+func (m *Memory) Load(ctx context.Context) (trades []types.OpenTrade, err error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	var trade types.OpenTrade
+
+	trades = make([]types.OpenTrade, 0, len(m.trades))
+	for _, trade = range m.trades {
+		trades = append(trades, trade)
+	}
+	return
+}
+
+// Save stores the open trade
+func (m *Memory) Save(ctx context.Context, trade types.OpenTrade) (err error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.trades[trade.Symbol.String()] = trade
+	return
+}
+
+// Delete removes the stored trade of the symbol
+func (m *Memory) Delete(ctx context.Context, symbol types.Symbol) (err error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	delete(m.trades, symbol.String())
+	return
+}
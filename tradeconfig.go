@@ -2,7 +2,10 @@ package cryptotrader
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+
+	"github.com/mhereman/cryptotrader/types"
 )
 
 // TradeVolumeType represents the way to calculate the trade volume
@@ -39,6 +42,50 @@ func (tvt TradeVolumeType) String() string {
 	return "percent"
 }
 
+// TakeProfitType represents the way to calculate the take profit target
+type TakeProfitType int
+
+const (
+	// TPTPercent the take profit target is a percentage above the entry price
+	TPTPercent TakeProfitType = iota
+
+	// TPTRMultiple the take profit target is a multiple of the stop loss distance above the entry price
+	TPTRMultiple
+)
+
+// TakeProfitFromString parses the take profit target
+// The target is either a percentage (e.g. 0.1) or a multiple of the stop loss distance (e.g. 2R).
+// An empty string disables the take profit target.
+func TakeProfitFromString(in string) (tpt TakeProfitType, takeProfit float64, err error) {
+	in = strings.ToLower(strings.TrimSpace(in))
+	if in == "" {
+		return
+	}
+
+	tpt = TPTPercent
+	if strings.HasSuffix(in, "r") {
+		tpt = TPTRMultiple
+		in = strings.TrimSuffix(in, "r")
+	}
+	if takeProfit, err = strconv.ParseFloat(in, 64); err != nil {
+		err = fmt.Errorf("Invalid take profit: %s %v", in, err)
+		return
+	}
+	if takeProfit < 0.0 {
+		err = fmt.Errorf("Invalid take profit: %f", takeProfit)
+		return
+	}
+	return
+}
+
+// String return the string representation fo the TakeProfitType
+func (tpt TakeProfitType) String() string {
+	if tpt == TPTRMultiple {
+		return "R-multiple"
+	}
+	return "percent"
+}
+
 // TradeConfig represents the config for the trades to place
 type TradeConfig struct {
 	// TradeVolumeType how to calculate the volume of the buy/sell orders
@@ -61,22 +108,130 @@ type TradeConfig struct {
 
 	// Stop loss un percent
 	StopLoss float64
+
+	// TrailingStop distance in percent below the highest price seen at which the stop loss trails the price
+	// 0 = disabled, the stop loss stays at the initial StopLoss
+	// 2% = 0.02
+	TrailingStop float64
+
+	// TakeProfitType how to calculate the take profit target
+	TakeProfitType TakeProfitType
+
+	// TakeProfit target at which the position is closed (depends on TakeProfitType)
+	// If TakeProfitType == TPTPercent the TakeProfit value is the percentage above the entry price (10% = 0.1)
+	// If TakeProfitType == TPTRMultiple the TakeProfit value is the multiple of the stop loss distance above the entry price
+	// 0 = disabled
+	TakeProfit float64
+
+	// MaxOpenPositions the max number of positions open at the same time over all markets
+	// 0 = unlimited
+	MaxOpenPositions int
+
+	// Shorts if true the short signals of the algorithms open short positions, requires a futures exchange
+	Shorts bool
+
+	// Leverage of the positions on a futures exchange
+	// The Volume is the margin of a position, the position size is the margin times the leverage
+	Leverage int
+
+	// MarginType of the positions on a futures exchange
+	MarginType types.MarginType
 }
 
 // NewTradeConfigFromFlags creates a new TradeConfig insance from the cmdline argument values
-func NewTradeConfigFromFlags(tvt string, volume float64, reduce bool, paper bool, maxSlippage float64, stopLoss float64) (tc TradeConfig, err error) {
+func NewTradeConfigFromFlags(tvt string, volume float64, reduce bool, paper bool, maxSlippage float64, stopLoss float64, trailingStop float64, takeProfit string, maxOpenPositions int, shorts bool, leverage int, marginType string) (tc TradeConfig, err error) {
 	if tc.TradeVolumeType, err = TradeVolumeTypeFromString(tvt); err != nil {
 		return
 	}
-	if tc.TradeVolumeType == TVTPercent {
-		if volume > maxPctVolume {
-			volume = maxPctVolume
-		}
+	if trailingStop < 0.0 || trailingStop >= 1.0 {
+		err = fmt.Errorf("Invalid trailing stop: %f", trailingStop)
+		return
 	}
-	tc.Volume = volume
+	if tc.TakeProfitType, tc.TakeProfit, err = TakeProfitFromString(takeProfit); err != nil {
+		return
+	}
+	if tc.TakeProfitType == TPTRMultiple && tc.TakeProfit > 0.0 && stopLoss <= 0.0 {
+		err = fmt.Errorf("Take profit R-multiple requires a stop loss")
+		return
+	}
+	if maxOpenPositions < 0 {
+		err = fmt.Errorf("Invalid max open positions: %d", maxOpenPositions)
+		return
+	}
+	if leverage < 1 {
+		err = fmt.Errorf("Invalid leverage: %d", leverage)
+		return
+	}
+	if tc.MarginType, err = types.NewMarginTypeFromString(marginType); err != nil {
+		return
+	}
+	tc.Volume = tc.NormalizeVolume(volume)
 	tc.Reduce = reduce
 	tc.Paper = paper
 	tc.MaxSlippage = maxSlippage
 	tc.StopLoss = stopLoss
+	tc.TrailingStop = trailingStop
+	tc.MaxOpenPositions = maxOpenPositions
+	tc.Shorts = shorts
+	tc.Leverage = leverage
 	return
 }
+
+// PositionSize returns the size in quote asset of a position with the margin
+// Without leverage the size equals the margin.
+func (tc TradeConfig) PositionSize(margin float64) float64 {
+	if tc.Leverage <= 1 {
+		return margin
+	}
+	return margin * float64(tc.Leverage)
+}
+
+// Futures returns true if the config requires a futures exchange
+func (tc TradeConfig) Futures() bool {
+	return tc.Shorts || tc.Leverage > 1
+}
+
+// TakeProfitPrice returns the take profit target for a position entered at the entry price
+// The target of a Short position is below the entry price.
+// 0 is returned if no take profit target is configured
+func (tc TradeConfig) TakeProfitPrice(entryPrice float64, position types.PositionSide) float64 {
+	var distance float64
+
+	if tc.TakeProfit <= 0.0 {
+		return 0.0
+	}
+	distance = tc.TakeProfit
+	if tc.TakeProfitType == TPTRMultiple {
+		distance = tc.TakeProfit * tc.StopLoss
+	}
+	if position == types.Short {
+		if distance >= 1.0 {
+			return 0.0
+		}
+		return entryPrice * (1.0 - distance)
+	}
+	return entryPrice * (1.0 + distance)
+}
+
+// StopLossPrices returns the stop price and the limit price of a stop loss at the distance from the price
+// The stop loss of a Short position is above the price.
+func (tc TradeConfig) StopLossPrices(price float64, distance float64, position types.PositionSide) (stopLoss float64, stopLossLimit float64) {
+	if position == types.Short {
+		stopLoss = price * (1.0 + distance)
+		stopLossLimit = stopLoss * (1.0 + tc.MaxSlippage)
+		return
+	}
+	stopLoss = price * (1.0 - distance)
+	stopLossLimit = stopLoss * (1.0 - tc.MaxSlippage)
+	return
+}
+
+// NormalizeVolume limits the volume to the maximum allowed for the TradeVolumeType
+func (tc TradeConfig) NormalizeVolume(volume float64) float64 {
+	if tc.TradeVolumeType == TVTPercent {
+		if volume > maxPctVolume {
+			volume = maxPctVolume
+		}
+	}
+	return volume
+}
@@ -0,0 +1,85 @@
+package cryptotrader
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// updateTrailingStop replaces the stop loss of the symbol when the price made a new high
+// and the trailing stop is above the current stop loss
+// The stop loss of a short position trails the new lows from above.
+// The caller must hold the position lock.
+func (ct *CryptoTrader) updateTrailingStop(symbol types.Symbol) (err error) {
+	var symbolString string
+	var price, highPrice, stopLoss, stopLossLimit, takeProfit, quantity float64
+	var stopLossUUID uuid.UUID
+	var position types.PositionSide
+	var orderInfo, stopInfo types.OrderInfo
+	var closed bool
+
+	symbolString = symbol.String()
+	position = ct.positionSides[symbolString]
+	if price, err = ct.exchangeDriver.Ticker(ct.ctx, symbol); err != nil {
+		err = fmt.Errorf("updateTrailingStop Failed to retrieve market price for symbol %s %v", symbolString, err)
+		return
+	}
+
+	highPrice = ct.highPrices[symbolString]
+	if !priceImproves(position, price, highPrice) {
+		return
+	}
+	ct.highPrices[symbolString] = price
+	defer ct.saveOpenTrade(symbol)
+
+	if stopLossUUID, err = uuid.Parse(ct.stopLossOrders[symbolString]); err != nil {
+		err = fmt.Errorf("updateTrailingStop Invalid stop loss uuid: %s %v", ct.stopLossOrders[symbolString], err)
+		return
+	}
+
+	if orderInfo, err = ct.exchangeDriver.GetOrder(ct.ctx, types.Order{
+		Symbol:        symbol,
+		UserReference: stopLossUUID,
+	}); err != nil {
+		err = fmt.Errorf("updateTrailingStop Failed to retrieve stop loss for symbol %s %v", symbolString, err)
+		return
+	}
+	if orderInfo.Status != types.StatusNew {
+		logger.Debugf("updateTrailingStop: Stop loss for symbol %s is no longer open\n", symbolString)
+		return
+	}
+
+	stopLoss, stopLossLimit = ct.tradeCfg.StopLossPrices(price, ct.tradeCfg.TrailingStop, position)
+	if !priceImproves(position, stopLoss, orderInfo.StopPrice) {
+		return
+	}
+	quantity = normalizeQuantity(orderInfo.OriginalQuantity - orderInfo.ExecutedQuantity)
+
+	// Cancelling the stop loss of a one-cancels-other pair also cancels the take profit,
+	// both are placed again
+	if closed, stopInfo = ct.tryCloseStopLoss(symbol); !closed {
+		ct.stopLossOrders[symbolString] = stopLossUUID.String()
+		if stopInfo.Status == types.StatusFilled {
+			logger.Infof("updateTrailingStop: Stop loss for symbol %s filled while moving it\n", symbolString)
+			return
+		}
+		err = fmt.Errorf("updateTrailingStop Failed to cancel stop loss for symbol %s", symbolString)
+		return
+	}
+
+	takeProfit = ct.takeProfitPrices[symbolString]
+	if err = ct.placeProtectiveOrders(symbol, position, quantity, stopLoss, stopLossLimit, takeProfit); err != nil {
+		logger.Warningf("updateTrailingStop: Failed to place trailing stop loss for symbol %s, restoring previous stop loss: %v\n", symbolString, err)
+		if err = ct.placeProtectiveOrders(symbol, position, quantity, orderInfo.StopPrice, orderInfo.Price, takeProfit); err != nil {
+			err = fmt.Errorf("updateTrailingStop Failed to restore stop loss for symbol %s, the position is unprotected %v", symbolString, err)
+			ct.notify(types.NewErrorEvent(symbol, "Stop loss lost, the position is unprotected"))
+		}
+		return
+	}
+
+	logger.Infof("updateTrailingStop: Moved stop loss for symbol %s to %f [High: %f; UUID: %s]\n", symbolString, stopLoss, price, ct.stopLossOrders[symbolString])
+	return
+}
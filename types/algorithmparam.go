@@ -0,0 +1,176 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParamType of an algorithm parameter
+type ParamType int
+
+const (
+	// ParamInt integer parameter
+	ParamInt ParamType = iota
+
+	// ParamFloat floating point parameter
+	ParamFloat
+
+	// ParamBool boolean parameter, true or false
+	ParamBool
+
+	// ParamString free text parameter
+	ParamString
+)
+
+var paramTypeNames = []string{"int", "float", "bool", "string"}
+
+// String returns the string name of the ParamType
+func (pt ParamType) String() string {
+	if int(pt) < 0 || int(pt) >= len(paramTypeNames) {
+		return "unknown"
+	}
+	return paramTypeNames[pt]
+}
+
+// AlgorithmParam describes a configuration parameter of an algorithm
+type AlgorithmParam struct {
+	// Name of the parameter, the key in the AlgorithmConfig
+	Name string
+
+	// Type of the parameter value
+	Type ParamType
+
+	// Default value of the parameter
+	Default string
+
+	// Min lowest valid value of a numeric parameter, nil if not bounded
+	Min *float64
+
+	// Max highest valid value of a numeric parameter, nil if not bounded
+	Max *float64
+
+	// Description of the parameter
+	Description string
+}
+
+// ParamLimit returns a Min or Max bound of an AlgorithmParam
+func ParamLimit(value float64) *float64 {
+	return &value
+}
+
+// Validate checks the value against the type and bounds of the parameter
+func (ap AlgorithmParam) Validate(value string) (err error) {
+	var number float64
+	var intValue int
+
+	switch ap.Type {
+	case ParamInt:
+		if intValue, err = strconv.Atoi(value); err != nil {
+			err = fmt.Errorf("%s: invalid int: %s", ap.Name, value)
+			return
+		}
+		number = float64(intValue)
+	case ParamFloat:
+		if number, err = strconv.ParseFloat(value, 64); err != nil {
+			err = fmt.Errorf("%s: invalid float: %s", ap.Name, value)
+			return
+		}
+	case ParamBool:
+		if _, err = strconv.ParseBool(value); err != nil {
+			err = fmt.Errorf("%s: invalid bool: %s", ap.Name, value)
+		}
+		return
+	default:
+		return
+	}
+
+	if ap.Min != nil && number < *ap.Min {
+		err = fmt.Errorf("%s: %s is below the minimum %s", ap.Name, value, formatParamLimit(*ap.Min))
+		return
+	}
+	if ap.Max != nil && number > *ap.Max {
+		err = fmt.Errorf("%s: %s is above the maximum %s", ap.Name, value, formatParamLimit(*ap.Max))
+		return
+	}
+	return
+}
+
+// Range returns the bounds of the parameter as text, empty if not bounded
+func (ap AlgorithmParam) Range() string {
+	switch {
+	case ap.Min != nil && ap.Max != nil:
+		return fmt.Sprintf("%s..%s", formatParamLimit(*ap.Min), formatParamLimit(*ap.Max))
+	case ap.Min != nil:
+		return fmt.Sprintf(">= %s", formatParamLimit(*ap.Min))
+	case ap.Max != nil:
+		return fmt.Sprintf("<= %s", formatParamLimit(*ap.Max))
+	}
+	return ""
+}
+
+func formatParamLimit(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// AlgorithmSchema lists the configuration parameters of an algorithm
+type AlgorithmSchema []AlgorithmParam
+
+// Param returns the parameter with the name
+func (as AlgorithmSchema) Param(name string) (param AlgorithmParam, ok bool) {
+	for _, param = range as {
+		if param.Name == name {
+			ok = true
+			return
+		}
+	}
+	param = AlgorithmParam{}
+	return
+}
+
+// Names returns the names of the parameters
+func (as AlgorithmSchema) Names() (names []string) {
+	var param AlgorithmParam
+
+	for _, param = range as {
+		names = append(names, param.Name)
+	}
+	return
+}
+
+// Defaults returns the configuration with the default value of every parameter
+func (as AlgorithmSchema) Defaults() (config AlgorithmConfig) {
+	var param AlgorithmParam
+
+	config = make(AlgorithmConfig)
+	for _, param = range as {
+		config[param.Name] = param.Default
+	}
+	return
+}
+
+// Validate checks every value of the configuration against its parameter
+// Unknown keys are an error, omitted parameters keep their default value.
+func (as AlgorithmSchema) Validate(config AlgorithmConfig) (err error) {
+	var keys []string
+	var key string
+	var param AlgorithmParam
+	var ok bool
+
+	for key = range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key = range keys {
+		if param, ok = as.Param(key); !ok {
+			err = fmt.Errorf("Unknown parameter: %s, valid: %s", key, strings.Join(as.Names(), ", "))
+			return
+		}
+		if err = param.Validate(config[key]); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,21 @@
+package types
+
+// CandleUpdateChannel channel to report candle updates on
+type CandleUpdateChannel chan CandleUpdate
+
+// CandleUpdate represents an update of a candle received from a stream
+type CandleUpdate struct {
+	// Candle the current state of the candle
+	Candle OHLC
+
+	// Final is true if the candle is closed and will not change anymore
+	Final bool
+}
+
+// NewCandleUpdate creates a CandleUpdate instance
+func NewCandleUpdate(candle OHLC, final bool) CandleUpdate {
+	return CandleUpdate{
+		Candle: candle,
+		Final:  final,
+	}
+}
@@ -0,0 +1,26 @@
+package types
+
+// CircuitState state of the circuit breaker guarding the exchange requests
+type CircuitState int
+
+const (
+	// CircuitClosed the requests are sent to the exchange
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen the requests fail without being sent, until the exchange is expected to recover
+	CircuitOpen
+
+	// CircuitHalfOpen a single request is sent to test whether the exchange recovered
+	CircuitHalfOpen
+)
+
+// String returns the string name of the CircuitState
+func (cs CircuitState) String() string {
+	switch cs {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
@@ -0,0 +1,320 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EventType of a notification event
+type EventType int
+
+const (
+	// EventStartup the trader started
+	EventStartup EventType = iota
+
+	// EventSignal an algorithm issued a signal
+	EventSignal
+
+	// EventOrderPlaced an order was placed on the exchange
+	EventOrderPlaced
+
+	// EventOrderFilled an order was filled
+	EventOrderFilled
+
+	// EventStopLoss the stop loss of a position was triggered
+	EventStopLoss
+
+	// EventTakeProfit the take profit of a position was reached
+	EventTakeProfit
+
+	// EventPositionClosed a position was closed
+	EventPositionClosed
+
+	// EventError an error which needs attention occurred
+	EventError
+)
+
+var eventTypeNames = []string{"startup", "signal", "order_placed", "order_filled", "stop_loss", "take_profit", "position_closed", "error"}
+
+// String returns the string name of the EventType
+func (et EventType) String() string {
+	if int(et) < 0 || int(et) >= len(eventTypeNames) {
+		return "unknown"
+	}
+	return eventTypeNames[et]
+}
+
+// NewEventTypeFromString creates an EventType from its string name
+func NewEventTypeFromString(in string) (et EventType, err error) {
+	var index int
+	var name string
+
+	in = strings.ToLower(strings.TrimSpace(in))
+	for index, name = range eventTypeNames {
+		if name == in {
+			et = EventType(index)
+			return
+		}
+	}
+	err = fmt.Errorf("Invalid event type: %s, valid: %s", in, strings.Join(eventTypeNames, ", "))
+	return
+}
+
+// Severity of a notification event
+type Severity int
+
+const (
+	// SeverityInfo informational events
+	SeverityInfo Severity = iota
+
+	// SeverityNotice changes to the positions
+	SeverityNotice
+
+	// SeverityWarning unexpected situations the trader recovered from
+	SeverityWarning
+
+	// SeverityError errors which need attention
+	SeverityError
+)
+
+var severityNames = []string{"info", "notice", "warning", "error"}
+
+// String returns the string name of the Severity
+func (s Severity) String() string {
+	if int(s) < 0 || int(s) >= len(severityNames) {
+		return "unknown"
+	}
+	return severityNames[s]
+}
+
+// NewSeverityFromString creates a Severity from its string name
+func NewSeverityFromString(in string) (s Severity, err error) {
+	var index int
+	var name string
+
+	in = strings.ToLower(strings.TrimSpace(in))
+	for index, name = range severityNames {
+		if name == in {
+			s = Severity(index)
+			return
+		}
+	}
+	err = fmt.Errorf("Invalid severity: %s, valid: %s", in, strings.Join(severityNames, ", "))
+	return
+}
+
+// Event represents a notification event
+// Only the fields applicable to the event type are set.
+type Event struct {
+	// Type of the event
+	Type EventType
+
+	// Severity of the event
+	Severity Severity
+
+	// Time of the event
+	Time time.Time
+
+	// Symbol the event applies to
+	Symbol Symbol
+
+	// Side of the signal or order
+	Side Side
+
+	// Position side of the signal or position
+	Position PositionSide
+
+	// Quantity in base asset of the order or position
+	Quantity float64
+
+	// Price of the order, fill or exit
+	Price float64
+
+	// EntryPrice of the closed position
+	EntryPrice float64
+
+	// PnL profit of the closed position in quote asset, before commissions
+	PnL float64
+
+	// PnLPercent profit of the closed position relative to its entry
+	// 1% = 0.01
+	PnLPercent float64
+
+	// Paper indicates the event was caused by paper trading
+	Paper bool
+
+	// Message free text description, e.g. the algorithm issuing a signal or the error
+	Message string
+}
+
+func newEvent(eventType EventType, severity Severity, symbol Symbol, paper bool) Event {
+	return Event{
+		Type:     eventType,
+		Severity: severity,
+		Time:     time.Now(),
+		Symbol:   symbol,
+		Paper:    paper,
+	}
+}
+
+// NewStartupEvent creates a new startup Event
+func NewStartupEvent(paper bool, message string) (e Event) {
+	e = newEvent(EventStartup, SeverityNotice, Symbol{}, paper)
+	e.Message = message
+	return
+}
+
+// NewSignalEvent creates a new Event for the signal
+func NewSignalEvent(signal Signal, paper bool) (e Event) {
+	e = newEvent(EventSignal, SeverityInfo, signal.Symbol, paper)
+	e.Side = signal.Side
+	e.Position = signal.Position
+	e.Message = signal.AlgorithmName
+	return
+}
+
+// NewOrderPlacedEvent creates a new Event for a placed order
+// The message describes the kind of order, e.g. stop loss.
+func NewOrderPlacedEvent(symbol Symbol, side Side, quantity float64, price float64, paper bool, message string) (e Event) {
+	e = newEvent(EventOrderPlaced, SeverityInfo, symbol, paper)
+	e.Side = side
+	e.Quantity = quantity
+	e.Price = price
+	e.Message = message
+	return
+}
+
+// NewOrderFilledEvent creates a new Event for a filled order
+func NewOrderFilledEvent(symbol Symbol, side Side, quantity float64, price float64, paper bool) (e Event) {
+	e = newEvent(EventOrderFilled, SeverityNotice, symbol, paper)
+	e.Side = side
+	e.Quantity = quantity
+	e.Price = price
+	return
+}
+
+// NewStopLossEvent creates a new Event for a triggered stop loss
+func NewStopLossEvent(symbol Symbol, position PositionSide, quantity float64, price float64) (e Event) {
+	e = newEvent(EventStopLoss, SeverityNotice, symbol, false)
+	e.Side = position.CloseSide()
+	e.Position = position
+	e.Quantity = quantity
+	e.Price = price
+	return
+}
+
+// NewTakeProfitEvent creates a new Event for a reached take profit
+func NewTakeProfitEvent(symbol Symbol, position PositionSide, quantity float64, price float64) (e Event) {
+	e = newEvent(EventTakeProfit, SeverityNotice, symbol, false)
+	e.Side = position.CloseSide()
+	e.Position = position
+	e.Quantity = quantity
+	e.Price = price
+	return
+}
+
+// NewPositionClosedEvent creates a new Event for a closed position
+// The profit is calculated from the entry and exit price, a zero entry price means the entry is unknown.
+// A Short position profits from an exit price below the entry price.
+func NewPositionClosedEvent(symbol Symbol, position PositionSide, quantity float64, entryPrice float64, exitPrice float64, paper bool) (e Event) {
+	e = newEvent(EventPositionClosed, SeverityNotice, symbol, paper)
+	e.Side = position.CloseSide()
+	e.Position = position
+	e.Quantity = quantity
+	e.Price = exitPrice
+	e.EntryPrice = entryPrice
+	if entryPrice > 0.0 {
+		e.PnL = (exitPrice - entryPrice) * quantity
+		e.PnLPercent = exitPrice/entryPrice - 1.0
+		if position == Short {
+			e.PnL = -e.PnL
+			e.PnLPercent = -e.PnLPercent
+		}
+	}
+	return
+}
+
+// NewErrorEvent creates a new error Event, the symbol is optional
+func NewErrorEvent(symbol Symbol, message string) (e Event) {
+	e = newEvent(EventError, SeverityError, symbol, false)
+	e.Message = message
+	return
+}
+
+// String returns a short human readable description of the event
+func (e Event) String() (text string) {
+	switch e.Type {
+	case EventSignal:
+		text = fmt.Sprintf("Signal %s %s [%s]", e.Symbol.String(), e.Side.String(), e.Message)
+		if e.Position == Short {
+			text = fmt.Sprintf("Signal %s %s %s [%s]", e.Symbol.String(), e.Side.String(), e.Position.String(), e.Message)
+		}
+	case EventOrderPlaced:
+		text = fmt.Sprintf("%s order placed %s: Quantity: %f; Price: %f", e.Side.String(), e.Symbol.String(), e.Quantity, e.Price)
+		if e.Message != "" {
+			text += fmt.Sprintf(" [%s]", e.Message)
+		}
+	case EventOrderFilled:
+		text = fmt.Sprintf("%s order filled %s: Quantity: %f; Price: %f", e.Side.String(), e.Symbol.String(), e.Quantity, e.Price)
+	case EventStopLoss:
+		text = fmt.Sprintf("Stop loss triggered %s: Quantity: %f; Price: %f", e.Symbol.String(), e.Quantity, e.Price)
+	case EventTakeProfit:
+		text = fmt.Sprintf("Take profit reached %s: Quantity: %f; Price: %f", e.Symbol.String(), e.Quantity, e.Price)
+	case EventPositionClosed:
+		text = fmt.Sprintf("Position closed %s: Quantity: %f; Exit: %f", e.Symbol.String(), e.Quantity, e.Price)
+		if e.Position == Short {
+			text = fmt.Sprintf("Short position closed %s: Quantity: %f; Exit: %f", e.Symbol.String(), e.Quantity, e.Price)
+		}
+		if e.EntryPrice > 0.0 {
+			text += fmt.Sprintf("; Entry: %f; PnL: %f (%.2f%%)", e.EntryPrice, e.PnL, e.PnLPercent*100.0)
+		}
+	case EventError:
+		text = e.Message
+		if e.Symbol != (Symbol{}) {
+			text = fmt.Sprintf("%s: %s", e.Symbol.String(), e.Message)
+		}
+	default:
+		text = e.Message
+	}
+
+	if e.Paper && e.Type != EventStartup {
+		text = "[Paper] " + text
+	}
+	return
+}
+
+// EventFilter selects the events sent to a notifier
+type EventFilter struct {
+	// MinSeverity minimum severity of the events
+	MinSeverity Severity
+
+	// Types of the events, all types if empty
+	Types []EventType
+}
+
+// NewEventFilter creates a new EventFilter instance
+func NewEventFilter(minSeverity Severity, types []EventType) EventFilter {
+	return EventFilter{
+		MinSeverity: minSeverity,
+		Types:       types,
+	}
+}
+
+// Accept returns true if the event has one of the types of the filter and at least its min severity
+func (ef EventFilter) Accept(e Event) bool {
+	var eventType EventType
+
+	if e.Severity < ef.MinSeverity {
+		return false
+	}
+	if len(ef.Types) == 0 {
+		return true
+	}
+	for _, eventType = range ef.Types {
+		if eventType == e.Type {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package types
+
+import "github.com/google/uuid"
+
+// OCOOrder represents a one-cancels-other order pair on the exchange
+// The pair consists of a LimitMaker order and a StopLossLimit order,
+// when one of both orders fills or is cancelled the other order is cancelled.
+type OCOOrder struct {
+	// ListReference user reference of the order list
+	ListReference uuid.UUID
+
+	// LimitReference user reference of the limit maker order
+	LimitReference uuid.UUID
+
+	// StopReference user reference of the stop loss limit order
+	StopReference uuid.UUID
+
+	// Symbol of the orders
+	Symbol Symbol
+
+	// Side of the orders
+	Side Side
+
+	// Quantity in base asset of the orders
+	Quantity float64
+
+	// Price in quote asset of the limit maker order
+	Price float64
+
+	// StopPrice in quote asset of the stop loss limit order
+	StopPrice float64
+
+	// StopLimitPrice in quote asset of the stop loss limit order
+	StopLimitPrice float64
+}
+
+// NewOCOOrder creates a new OCOOrder instance
+func NewOCOOrder(symbol Symbol, side Side, quantity float64, price float64, stopPrice float64, stopLimitPrice float64) (o OCOOrder) {
+	o.ListReference = uuid.New()
+	o.LimitReference = uuid.New()
+	o.StopReference = uuid.New()
+	o.Symbol = symbol
+	o.Side = side
+	o.Quantity = quantity
+	o.Price = price
+	o.StopPrice = stopPrice
+	o.StopLimitPrice = stopLimitPrice
+	return
+}
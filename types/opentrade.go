@@ -0,0 +1,57 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OpenTrade represents the state of an open position which is persisted
+// so the position can be resumed after a restart
+type OpenTrade struct {
+	// Symbol of the position
+	Symbol Symbol
+
+	// TradeReference user reference of the order opening the position
+	TradeReference uuid.UUID
+
+	// StopLossReference user reference of the stop loss order, uuid.Nil if none
+	StopLossReference uuid.UUID
+
+	// TakeProfitReference user reference of the take profit order, uuid.Nil if none
+	TakeProfitReference uuid.UUID
+
+	// TakeProfitPrice take profit target of the position, 0 if none
+	TakeProfitPrice float64
+
+	// Paper indicates the trade was opened by paper trading
+	Paper bool
+
+	// OpenTime time the position was opened
+	OpenTime time.Time
+
+	// HighPrice highest price seen since the position was opened, used by the trailing stop
+	// For a Short position it is the lowest price seen.
+	HighPrice float64
+
+	// EntryPrice average fill price of the order opening the position, 0 if unknown
+	EntryPrice float64
+
+	// Quantity in base asset of the position, 0 if unknown
+	Quantity float64
+
+	// Position side of the position
+	Position PositionSide
+}
+
+// NewOpenTrade creates a new OpenTrade instance
+func NewOpenTrade(symbol Symbol, tradeReference uuid.UUID, stopLossReference uuid.UUID, paper bool, openTime time.Time, highPrice float64) OpenTrade {
+	return OpenTrade{
+		Symbol:            symbol,
+		TradeReference:    tradeReference,
+		StopLossReference: stopLossReference,
+		Paper:             paper,
+		OpenTime:          openTime,
+		HighPrice:         highPrice,
+	}
+}
@@ -27,6 +27,9 @@ type Order struct {
 
 	// StopPrice in quote asset of the order
 	StopPrice float64
+
+	// ReduceOnly the order only reduces the open position, only used by futures exchanges
+	ReduceOnly bool
 }
 
 // NewLimitOrder creates a new Limit order instance
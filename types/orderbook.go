@@ -47,3 +47,25 @@ func (ob OrderBook) GetBuyVolumeAndAveragePrice(quoteAmount float64) (baseAmount
 
 	return
 }
+
+// GetSellVolumeAndAveragePrice calculates the sell volume in base asset and the average price from the
+// provided volume in quote asset
+func (ob OrderBook) GetSellVolumeAndAveragePrice(quoteAmount float64) (baseAmount float64, averagePrice float64) {
+	var bid OrderBookEntry
+	var quantity, multipliedPrice float64
+
+	for _, bid = range ob.Bids {
+		if (bid.Quantity * bid.Price) >= quoteAmount {
+			quantity = quoteAmount / bid.Price
+			baseAmount += quantity
+			multipliedPrice += quantity * bid.Price
+			break
+		}
+		baseAmount += bid.Quantity
+		quoteAmount -= (bid.Quantity * bid.Price)
+		multipliedPrice += bid.Price * bid.Quantity
+	}
+	averagePrice = multipliedPrice / baseAmount
+
+	return
+}
@@ -72,3 +72,19 @@ func NewOrderInfo(userRef uuid.UUID, exchangeOrderID int64, symbol Symbol, trans
 func (info *OrderInfo) AppendFills(fils ...OrderFill) {
 	info.Fills = append(info.Fills, fils...)
 }
+
+// AveragePrice returns the average price of the fills of the order
+// If the order has no fills the price of the order is returned.
+func (info OrderInfo) AveragePrice() float64 {
+	var fill OrderFill
+	var quantity, quote float64
+
+	for _, fill = range info.Fills {
+		quantity += fill.Quantity
+		quote += fill.Quantity * fill.Price
+	}
+	if quantity <= 0.0 {
+		return info.Price
+	}
+	return quote / quantity
+}
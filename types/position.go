@@ -0,0 +1,103 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PositionSide direction of a position
+type PositionSide int
+
+const (
+	// Long position, opened by buying and closed by selling
+	Long PositionSide = iota
+
+	// Short position, opened by selling and closed by buying back
+	Short
+)
+
+// String returns the string name of the PositionSide
+func (ps PositionSide) String() string {
+	if ps == Short {
+		return "Short"
+	}
+	return "Long"
+}
+
+// OpenSide returns the side of the orders opening the position
+func (ps PositionSide) OpenSide() Side {
+	if ps == Short {
+		return Sell
+	}
+	return Buy
+}
+
+// CloseSide returns the side of the orders closing the position
+func (ps PositionSide) CloseSide() Side {
+	if ps == Short {
+		return Buy
+	}
+	return Sell
+}
+
+// MarginType of the positions on a futures exchange
+type MarginType int
+
+const (
+	// Isolated the loss of a position is limited to the margin assigned to it
+	Isolated MarginType = iota
+
+	// Cross the whole balance is used as margin of all positions
+	Cross
+)
+
+// String returns the string name of the MarginType
+func (mt MarginType) String() string {
+	if mt == Cross {
+		return "cross"
+	}
+	return "isolated"
+}
+
+// NewMarginTypeFromString creates a new MarginType from its string representation
+func NewMarginTypeFromString(in string) (mt MarginType, err error) {
+	switch strings.ToLower(in) {
+	case "isolated":
+		mt = Isolated
+	case "cross", "crossed":
+		mt = Cross
+	default:
+		err = fmt.Errorf("Invalid margin type: %s, valid: isolated, cross", in)
+	}
+	return
+}
+
+// Position represents a position on a futures exchange
+type Position struct {
+	// Symbol of the position
+	Symbol Symbol
+
+	// Side of the position
+	Side PositionSide
+
+	// Quantity in base asset of the position, 0 if no position is open
+	Quantity float64
+
+	// EntryPrice average entry price in quote asset
+	EntryPrice float64
+
+	// MarkPrice price in quote asset the position is valued at
+	MarkPrice float64
+
+	// UnrealizedProfit profit of the position at the mark price in quote asset
+	UnrealizedProfit float64
+
+	// LiquidationPrice price in quote asset at which the position is liquidated, 0 if none
+	LiquidationPrice float64
+
+	// Leverage of the position
+	Leverage int
+
+	// MarginType of the position
+	MarginType MarginType
+}
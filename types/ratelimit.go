@@ -0,0 +1,34 @@
+package types
+
+import "time"
+
+// RateLimit represents a token bucket of request weight
+// The bucket holds at most Capacity weight and is refilled with Capacity weight every Interval.
+type RateLimit struct {
+	// Capacity max weight of the requests in the interval
+	Capacity int
+
+	// Interval the capacity is refilled over
+	Interval time.Duration
+}
+
+// NewRateLimit creates a new RateLimit instance
+func NewRateLimit(capacity int, interval time.Duration) RateLimit {
+	return RateLimit{
+		Capacity: capacity,
+		Interval: interval,
+	}
+}
+
+// RateLimits represents the request limits of an exchange
+type RateLimits struct {
+	// Buckets maps the names of the limits onto their token buckets
+	Buckets map[string]RateLimit
+
+	// Weights maps the exchange driver methods (e.g. PlaceOrder) onto their weight per bucket
+	// The methods not listed take a weight of 1 from the default bucket.
+	Weights map[string]map[string]int
+
+	// DefaultBucket name of the bucket of the methods without weights
+	DefaultBucket string
+}
@@ -9,6 +9,8 @@ import (
 type SignalChannel chan Signal
 
 // Signal represents a buy or sell signal
+// A Buy signal opens a Long position and closes a Short position,
+// a Sell signal closes a Long position and opens a Short position.
 type Signal struct {
 	// AlgorithmName of the algorithm issuing the signal
 	AlgorithmName string
@@ -19,6 +21,9 @@ type Signal struct {
 	// Side of the signal
 	Side Side
 
+	// Position the signal applies to
+	Position PositionSide
+
 	// Time of the signal
 	SignalTime time.Time
 
@@ -26,26 +31,45 @@ type Signal struct {
 	IsBacktest bool
 }
 
-// NewSignal creates a new Signal instance
+// NewSignal creates a new Signal instance for a Long position
 func NewSignal(algoName string, symbol Symbol, side Side) Signal {
+	return NewPositionSignal(algoName, symbol, side, Long)
+}
+
+// NewPositionSignal creates a new Signal instance for the position
+func NewPositionSignal(algoName string, symbol Symbol, side Side, position PositionSide) Signal {
 	return Signal{
 		AlgorithmName: algoName,
 		Symbol:        symbol,
 		Side:          side,
+		Position:      position,
 		SignalTime:    time.Now(),
 		IsBacktest:    false,
 	}
 }
 
 func NewBacktestSignal(algoName string, symbol Symbol, side Side, candleTime time.Time) Signal {
+	return NewBacktestPositionSignal(algoName, symbol, side, Long, candleTime)
+}
+
+// NewBacktestPositionSignal creates a new backtest Signal instance for the position
+func NewBacktestPositionSignal(algoName string, symbol Symbol, side Side, position PositionSide, candleTime time.Time) Signal {
 	var signal Signal
-	signal = NewSignal(algoName, symbol, side)
+	signal = NewPositionSignal(algoName, symbol, side, position)
 	signal.SignalTime = candleTime
 	signal.IsBacktest = true
 	return signal
 }
 
+// Opens returns true if the signal opens its position, false if it closes it
+func (s Signal) Opens() bool {
+	return s.Side == s.Position.OpenSide()
+}
+
 // String returns a string representation of the signal
 func (s Signal) String() string {
+	if s.Position == Short {
+		return fmt.Sprintf("%s - %s: %s %s %v", s.AlgorithmName, s.Symbol.String(), s.Side.String(), s.Position.String(), s.SignalTime)
+	}
 	return fmt.Sprintf("%s - %s: %s %v", s.AlgorithmName, s.Symbol.String(), s.Side.String(), s.SignalTime)
 }
@@ -52,3 +52,14 @@ func (s Symbol) Quote() string {
 func (s Symbol) String() string {
 	return fmt.Sprintf("%s/%s", s.base, s.quote)
 }
+
+// MarshalText implements the encoding.TextMarshaler interface
+func (s Symbol) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface
+func (s *Symbol) UnmarshalText(text []byte) (err error) {
+	*s, err = NewSymbolFromString(string(text))
+	return
+}
@@ -77,17 +77,37 @@ func (tf Timeframe) NextOpen(currentOpen time.Time, currentClose time.Time) (nex
 	case TuSec:
 		nextOpen = currentOpen.Add(time.Second * time.Duration(tf.Value))
 	case TuMin:
-		nextOpen = currentOpen.Add(time.Minute + time.Duration(tf.Value))
+		nextOpen = currentOpen.Add(time.Minute * time.Duration(tf.Value))
 	case TuHour:
 		nextOpen = currentOpen.Add(time.Hour * time.Duration(tf.Value))
 	case TuDay:
-		nextOpen = currentOpen.AddDate(0, 0, 1)
+		nextOpen = currentOpen.AddDate(0, 0, tf.Value)
 	case TuWeek:
-		nextOpen = currentOpen.AddDate(0, 0, 7)
+		nextOpen = currentOpen.AddDate(0, 0, 7*tf.Value)
 	case TuMonth:
-		nextOpen = currentOpen.AddDate(0, 1, 0)
+		nextOpen = currentOpen.AddDate(0, tf.Value, 0)
 	default:
 		nextOpen = currentClose
 	}
 	return
 }
+
+// Duration returns the (approximate) duration of a single candle of the timeframe
+// Months are considered to be 30 days long
+func (tf Timeframe) Duration() (d time.Duration) {
+	switch tf.Unit {
+	case TuSec:
+		d = time.Second * time.Duration(tf.Value)
+	case TuMin:
+		d = time.Minute * time.Duration(tf.Value)
+	case TuHour:
+		d = time.Hour * time.Duration(tf.Value)
+	case TuDay:
+		d = time.Hour * 24 * time.Duration(tf.Value)
+	case TuWeek:
+		d = time.Hour * 24 * 7 * time.Duration(tf.Value)
+	case TuMonth:
+		d = time.Hour * 24 * 30 * time.Duration(tf.Value)
+	}
+	return
+}
@@ -11,61 +11,362 @@ import (
 	"syscall"
 
 	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
 )
 
-func ReadFlags() (assetCfg AssetConfig, exchangeCfg ExchangeConfig, algoConfig AlgorithmConfig, tradeConfig TradeConfig, notifierConfig NotifierConfig, err error) {
-	var base, quote, timeFrame, exchange, exchangeArgsString, algo, algoConfigString, tradeType, logLevel, notifier, notifierConfigString *string
-	var volume, maxSlippage, stopLoss *float64
-	var reduce, paperTrading *bool
+type flagValues struct {
+	base, quote, timeFrame                        *string
+	exchange, exchangeArgsString, candleStore     *string
+	rateLimit                                     *bool
+	retries, circuitBreaker                       *int
+	algo, algoConfigString                        *string
+	tradeType, takeProfit, marginType             *string
+	volume, maxSlippage, stopLoss, trailingStop   *float64
+	reduce, paperTrading, shorts                  *bool
+	leverage                                      *int
+	logLevel                                      *string
+	notifierConfigString                          *string
+	notifiers                                     *listFlags
+	stateStore, stateStoreConfigString            *string
+	markets                                       *listFlags
+	maxOpenPositions                              *int
+	backtestData, backtestStart, backtestEnd      *string
+	backtestDownload                              *bool
+	backtestCapital, backtestMaker, backtestTaker *float64
+	configFile                                    *string
+	optimizeRanges                                *listFlags
+	optimizeObjective, optimizeOut                *string
+	optimizeWorkers, optimizeTop                  *int
+	walkForwardInSample, walkForwardOutOfSample   *int
+	walkForwardAnchored                           *bool
 
-	base = flag.String("base", "btc", "Base asset to trade")
-	quote = flag.String("quote", "usdt", "Quote asset to trade")
-	timeFrame = flag.String("timeframe", "4h", "Timeframe to trade, unit in ['s', 'm', 'h', 'd', 'w', 'M']")
+	// Values of the -config file
+	setFlags                       map[string]bool
+	fileExchangeArgs, fileAlgoArgs map[string]string
+	fileStateStoreArgs             map[string]string
+	fileMarkets                    []marketFileConfig
+	fileNotifiers                  []NotifierConfig
+}
+
+func defineTradingFlags(fs *flag.FlagSet) (fv *flagValues) {
+	fv = new(flagValues)
+
+	fv.configFile = fs.String("config", "", "If set, YAML or JSON file to read the configuration from, the cmdline arguments override the values of the file")
+
+	fv.base = fs.String("base", "btc", "Base asset to trade")
+	fv.quote = fs.String("quote", "usdt", "Quote asset to trade")
+	fv.timeFrame = fs.String("timeframe", "4h", "Timeframe to trade, unit in ['s', 'm', 'h', 'd', 'w', 'M']")
+
+	fv.algo = fs.String("algo", "Ema/Sma", "Algorithm to trade, valid algorithms: ['Ema/Sma']")
+	fv.algoConfigString = fs.String("algoargs", "", "Algorithm arguments, run 'cryptotrader list-algorithms' for the parameters of the algorithms")
+
+	fv.tradeType = fs.String("tradetype", "pct", "How to calculate trade volume, valid: ['pct', 'fixed']")
+	fv.volume = fs.Float64("volume", 1.0, "Trade volume. If tradetype = pct, the volume is the percentage of the availabel quote asset, otherwise the fixed volume of the trade asset.")
+	fv.reduce = fs.Bool("reduce", true, "Reduce the trade volume if not sufficient funds are available")
+	fv.paperTrading = fs.Bool("papertrading", false, "Papertrading enabled or not")
+	fv.maxSlippage = fs.Float64("maxslippage", 0.001, "Max slippage on buy orders; if set to 0 no max slippage is configured. Sell orders are always market orders.")
+	fv.stopLoss = fs.Float64("stoploss", 0.05, "Stop loss percentage")
+	fv.takeProfit = fs.String("takeprofit", "", "If set, the take profit target placed together with the stop loss as one-cancels-other pair; either a percentage above the entry price (e.g. 0.1) or a multiple of the stop loss distance (e.g. 2R)")
+	fv.trailingStop = fs.Float64("trailingstop", 0.0, "If set, the stop loss trails the highest price seen at this percentage below it; if set to 0 the stop loss does not move. Live trading only.")
+	fv.shorts = fs.Bool("shorts", false, "Open short positions on the short signals of the algorithms, requires a futures exchange")
+	fv.leverage = fs.Int("leverage", 1, "Leverage of the positions, the volume is the margin of a position. Leverage above 1 requires a futures exchange.")
+	fv.marginType = fs.String("margintype", "isolated", "Margin type of the positions on a futures exchange, valid: ['isolated', 'cross']")
+
+	fv.exchange = fs.String("exchange", "binance", "Exchange to trade on, valid exchanges: ['binance', 'binance-futures', 'coinbase', 'kraken', 'simulated']")
+	fv.exchangeArgsString = fs.String("exchangeargs", "apiKey=abc;apiSecret=def", "Exchange arguments, e.g. apiKey, apiSecret, ..., values can reference ${ENV_VAR} or file:/path")
+	fv.candleStore = fs.String("candlestore", "", "If set, the directory to store the candles of the exchange in, only the new candles are downloaded")
+	fv.rateLimit = fs.Bool("ratelimit", true, "Throttle the exchange requests to the rate limits of the exchange")
+	fv.retries = fs.Int("retries", 3, "Max number of retries of exchange requests failing with a network error, 429, 418 or 5xx response; 0 disables the retries")
+	fv.circuitBreaker = fs.Int("circuitbreaker", 5, "Number of consecutive failed exchange requests pausing the requests for a minute, or until the ban of the exchange expires; 0 disables the circuit breaker")
+
+	fv.logLevel = fs.String("loglevel", "info", "Log leve to use, valid (most verbose to less): ['debug', 'error', warning', 'info', 'none'")
+	return
+}
+
+func defineLiveFlags(fs *flag.FlagSet) (fv *flagValues) {
+	fv = defineTradingFlags(fs)
+
+	fv.markets = new(listFlags)
+	fs.Var(fv.markets, "market", "Market to trade, format: base/quote@timeframe[,algo[,volume[,algoargs]]], can be repeated, every symbol only once: the exchange holds a single position per symbol. If not set the base, quote, timeframe, algo, algoargs and volume flags define the market to trade, otherwise they are used as defaults.")
+	fv.maxOpenPositions = fs.Int("maxpositions", 0, "Max number of positions open at the same time over all markets, 0 = unlimited")
+
+	fv.notifiers = new(listFlags)
+	fs.Var(fv.notifiers, "notifier", "Notifier service to send the events to, format: name[:notifierargs], can be repeated to send to multiple notifiers. Valid notifiers: ['console', 'proximus-sms', 'smtp', 'telegram', 'webhook']")
+	fv.notifierConfigString = fs.String("notifierargs", "Key=value;key2=value", "Notifier arguments of the notifiers without arguments")
+
+	fv.stateStore = fs.String("statestore", "", "If set, the state store to persist open trades in, valid state stores: ['', 'memory', 'json']")
+	fv.stateStoreConfigString = fs.String("statestoreargs", "path=cryptotrader-state.json", "State store arguments")
+	return
+}
+
+func defineBacktestFlags(fs *flag.FlagSet) (fv *flagValues) {
+	fv = defineTradingFlags(fs)
+
+	fv.backtestData = fs.String("data", "", "CSV file with the candles to backtest on, fields: open_time,open,high,low,close,volume[,close_time]. If not set the candles are read from the candle store.")
+	fv.backtestStart = fs.String("start", "", "If set, the open time of the first candle to backtest on, format: 2006-01-02 or RFC3339")
+	fv.backtestEnd = fs.String("end", "", "If set, the open time of the last candle to backtest on, format: 2006-01-02 or RFC3339")
+	fv.backtestDownload = fs.Bool("download", true, "Download the missing candles from the exchange into the candle store before backtesting")
+	fv.backtestCapital = fs.Float64("capital", 1000.0, "Starting balance in quote asset")
+	fv.backtestMaker = fs.Float64("makercommission", 0.001, "Commission for maker orders")
+	fv.backtestTaker = fs.Float64("takercommission", 0.001, "Commission for taker orders")
+	return
+}
+
+func defineOptimizeFlags(fs *flag.FlagSet) (fv *flagValues) {
+	fv = defineBacktestFlags(fs)
+	fv.defineOptimizerFlags(fs)
+
+	fv.optimizeOut = fs.String("out", "optimization.csv", "CSV file to write the ranked results to")
+	fv.optimizeTop = fs.Int("top", 10, "Number of best results to print, 0 = all")
+	return
+}
+
+func defineWalkForwardFlags(fs *flag.FlagSet) (fv *flagValues) {
+	fv = defineBacktestFlags(fs)
+	fv.defineOptimizerFlags(fs)
+
+	fv.walkForwardInSample = fs.Int("insample", 500, "Number of candles of the in-sample windows to optimize the parameters on")
+	fv.walkForwardOutOfSample = fs.Int("outofsample", 100, "Number of candles of the out-of-sample windows to evaluate the parameters on, the windows move forward by this number of candles")
+	fv.walkForwardAnchored = fs.Bool("anchored", false, "Start every in-sample window at the first candle instead of rolling the windows forward")
+	fv.optimizeOut = fs.String("out", "walkforward.csv", "CSV file to write the results of the windows to")
+	return
+}
+
+func (fv *flagValues) defineOptimizerFlags(fs *flag.FlagSet) {
+	fv.optimizeRanges = new(listFlags)
+	fs.Var(fv.optimizeRanges, "range", "Algorithm parameter to optimize, format: name=from..to[:step] or name=value1,value2,..., can be repeated. Every combination of the values is backtested.")
+	fv.optimizeObjective = fs.String("objective", "netprofit", "Statistic to rank the backtests by, valid: ['netprofit', 'sharpe', 'profitfactor', 'winrate', 'drawdown']")
+	fv.optimizeWorkers = fs.Int("workers", 0, "Number of backtests to run in parallel, 0 = number of CPUs")
+}
+
+func (fv *flagValues) tradingConfigs() (assetCfg AssetConfig, algoConfig AlgorithmConfig, tradeConfig TradeConfig, err error) {
+	var maxOpenPositions int
+
+	logger.SetLogLevel(logger.NewLogLevelFromString(*fv.logLevel))
+
+	if assetCfg, err = NewAssetConfigFromFlags(*fv.base, *fv.quote, *fv.timeFrame); err != nil {
+		return
+	}
+
+	if algoConfig, err = NewAlgorithmConfigFromFlags(*fv.algo, fv.argMap(fv.fileAlgoArgs, "algoargs", *fv.algoConfigString)); err != nil {
+		return
+	}
+
+	if fv.maxOpenPositions != nil {
+		maxOpenPositions = *fv.maxOpenPositions
+	}
+
+	if tradeConfig, err = NewTradeConfigFromFlags(*fv.tradeType, *fv.volume, *fv.reduce, *fv.paperTrading, *fv.maxSlippage, *fv.stopLoss, *fv.trailingStop, *fv.takeProfit, maxOpenPositions, *fv.shorts, *fv.leverage, *fv.marginType); err != nil {
+		return
+	}
+	return
+}
 
-	exchange = flag.String("exchange", "binance", "Exchange to trade on, valid exchanges: ['binance']")
-	exchangeArgsString = flag.String("exchangeargs", "apiKey=abc;apiSecret=def", "Exchange arguments, e.g. apiKey, apiSecret, ...")
+func (fv *flagValues) marketConfigs(assetCfg AssetConfig, algoConfig AlgorithmConfig, tradeConfig TradeConfig) (marketConfigs []MarketConfig, err error) {
+	var defaults, marketConfig MarketConfig
+	var market string
+	var fileMarket marketFileConfig
+	var index int
+
+	defaults = NewMarketConfig(assetCfg, algoConfig, tradeConfig.Volume)
+	if len(*fv.markets) == 0 && len(fv.fileMarkets) > 0 {
+		for index, fileMarket = range fv.fileMarkets {
+			if marketConfig, err = fileMarket.marketConfig(defaults); err != nil {
+				err = fmt.Errorf("Invalid config file %s: markets[%d]: %v", *fv.configFile, index, err)
+				return
+			}
+			marketConfig.Volume = tradeConfig.NormalizeVolume(marketConfig.Volume)
+			marketConfigs = append(marketConfigs, marketConfig)
+		}
+		return
+	}
+	if len(*fv.markets) == 0 {
+		marketConfigs = []MarketConfig{defaults}
+		return
+	}
+
+	for _, market = range *fv.markets {
+		if marketConfig, err = NewMarketConfigFromFlag(market, defaults); err != nil {
+			return
+		}
+		marketConfig.Volume = tradeConfig.NormalizeVolume(marketConfig.Volume)
+		marketConfigs = append(marketConfigs, marketConfig)
+	}
+	return
+}
 
-	algo = flag.String("algo", "Ema/Sma", "Algorithm to trade, valid algorithms: ['Ema/Sma']")
-	algoConfigString = flag.String("algoargs", "Key=value;Key2=value2", "Algorithm arguments")
+func (fv *flagValues) notifierConfigs() (notifierConfigs []NotifierConfig, err error) {
+	var defaultArgs map[string]string
+	var notifierConfig NotifierConfig
+	var notifier string
 
-	tradeType = flag.String("tradetype", "pct", "How to calculate trade volume, valid: ['pct', 'fixed']")
-	volume = flag.Float64("volume", 1.0, "Trade volume. If tradetype = pct, the volume is the percentage of the availabel quote asset, otherwise the fixed volume of the trade asset.")
-	reduce = flag.Bool("reduce", true, "Reduce the trade volume if not sufficient funds are available")
-	paperTrading = flag.Bool("papertrading", false, "Papertrading enabled or not")
-	maxSlippage = flag.Float64("maxslippage", 0.001, "Max slippage on buy orders; if set to 0 no max slippage is configured. Sell orders are always market orders.")
-	stopLoss = flag.Float64("stoploss", 0.05, "Stop loss percentage")
+	if len(*fv.notifiers) == 0 {
+		notifierConfigs = fv.fileNotifiers
+		return
+	}
 
-	logLevel = flag.String("loglevel", "info", "Log leve to use, valid (most verbose to less): ['debug', 'error', warning', 'info', 'none'")
+	defaultArgs = buildArgMap(*fv.notifierConfigString)
+	for _, notifier = range *fv.notifiers {
+		if notifier == "" {
+			continue
+		}
+		if notifierConfig, err = NewNotifierConfigFromFlag(notifier, defaultArgs); err != nil {
+			return
+		}
+		notifierConfigs = append(notifierConfigs, notifierConfig)
+	}
+	return
+}
 
-	notifier = flag.String("notifier", "", "If set, the notifier service to use, valid notifiers: ['', 'proximus-sms']")
-	notifierConfigString = flag.String("notifierargs", "Key=value;key2=value", "Notifier arguments")
+// ReadFlags reads the configuration of the trader from the cmdline arguments
+func ReadFlags() (marketConfigs []MarketConfig, exchangeCfg ExchangeConfig, tradeConfig TradeConfig, notifierConfigs []NotifierConfig, stateStoreConfig StateStoreConfig, err error) {
+	var fv *flagValues
+	var assetCfg AssetConfig
+	var algoConfig AlgorithmConfig
 
+	fv = defineLiveFlags(flag.CommandLine)
 	flag.Parse()
+	if err = fv.applyConfigFile(flag.CommandLine); err != nil {
+		return
+	}
 
-	logger.SetLogLevel(logger.NewLogLevelFromString(*logLevel))
+	if assetCfg, algoConfig, tradeConfig, err = fv.tradingConfigs(); err != nil {
+		return
+	}
 
-	if assetCfg, err = NewAssetConfigFromFlags(*base, *quote, *timeFrame); err != nil {
+	if marketConfigs, err = fv.marketConfigs(assetCfg, algoConfig, tradeConfig); err != nil {
+		return
+	}
+	if err = validateMarketConfigs(marketConfigs); err != nil {
 		return
 	}
 
-	if exchangeCfg, err = NewExchangeConfigFromFlags(*exchange, buildArgMap(*exchangeArgsString)); err != nil {
+	if exchangeCfg, err = NewExchangeConfigFromFlags(*fv.exchange, fv.argMap(fv.fileExchangeArgs, "exchangeargs", *fv.exchangeArgsString), *fv.candleStore, *fv.rateLimit, *fv.retries, *fv.circuitBreaker); err != nil {
 		return
 	}
 
-	if algoConfig, err = NewAlgorithmConfigFromFlags(*algo, buildArgMap(*algoConfigString)); err != nil {
+	if notifierConfigs, err = fv.notifierConfigs(); err != nil {
 		return
 	}
 
-	if tradeConfig, err = NewTradeConfigFromFlags(*tradeType, *volume, *reduce, *paperTrading, *maxSlippage, *stopLoss); err != nil {
+	if stateStoreConfig, err = NewStateStoreConfigFromFlags(*fv.stateStore, fv.argMap(fv.fileStateStoreArgs, "statestoreargs", *fv.stateStoreConfigString)); err != nil {
 		return
 	}
+	return
+}
+
+// ReadBacktestFlags reads the configuration of a backtest from the provided arguments
+func ReadBacktestFlags(args []string) (assetCfg AssetConfig, algoConfig AlgorithmConfig, tradeConfig TradeConfig, backtestConfig BacktestConfig, err error) {
+	var fs *flag.FlagSet
+	var fv *flagValues
+	var exchangeCfg ExchangeConfig
 
-	if notifierConfig, err = NewNotifierConfigFromFlags(*notifier, buildArgMap(*notifierConfigString)); err != nil {
+	fs = flag.NewFlagSet("backtest", flag.ExitOnError)
+	fv = defineBacktestFlags(fs)
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if err = fv.applyConfigFile(fs); err != nil {
+		return
+	}
+
+	if assetCfg, algoConfig, tradeConfig, err = fv.tradingConfigs(); err != nil {
+		return
+	}
+
+	if exchangeCfg, err = NewExchangeConfigFromFlags(*fv.exchange, fv.argMap(fv.fileExchangeArgs, "exchangeargs", *fv.exchangeArgsString), *fv.candleStore, *fv.rateLimit, *fv.retries, *fv.circuitBreaker); err != nil {
+		return
+	}
+
+	if backtestConfig, err = NewBacktestConfigFromFlags(*fv.backtestData, exchangeCfg, *fv.backtestDownload, *fv.backtestStart, *fv.backtestEnd, *fv.backtestCapital, *fv.backtestMaker, *fv.backtestTaker); err != nil {
+		return
+	}
+	return
+}
+
+// ReadOptimizeFlags reads the configuration of a parameter optimization from the provided arguments
+func ReadOptimizeFlags(args []string) (assetCfg AssetConfig, algoConfig AlgorithmConfig, tradeConfig TradeConfig, backtestConfig BacktestConfig, optimizerConfig OptimizerConfig, err error) {
+	var fs *flag.FlagSet
+	var fv *flagValues
+	var exchangeCfg ExchangeConfig
+
+	fs = flag.NewFlagSet("optimize", flag.ExitOnError)
+	fv = defineOptimizeFlags(fs)
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if err = fv.applyConfigFile(fs); err != nil {
+		return
+	}
+
+	if assetCfg, algoConfig, tradeConfig, err = fv.tradingConfigs(); err != nil {
+		return
+	}
+
+	if exchangeCfg, err = NewExchangeConfigFromFlags(*fv.exchange, fv.argMap(fv.fileExchangeArgs, "exchangeargs", *fv.exchangeArgsString), *fv.candleStore, *fv.rateLimit, *fv.retries, *fv.circuitBreaker); err != nil {
+		return
+	}
+
+	if backtestConfig, err = NewBacktestConfigFromFlags(*fv.backtestData, exchangeCfg, *fv.backtestDownload, *fv.backtestStart, *fv.backtestEnd, *fv.backtestCapital, *fv.backtestMaker, *fv.backtestTaker); err != nil {
+		return
+	}
+
+	if optimizerConfig, err = NewOptimizerConfigFromFlags(*fv.optimizeRanges, *fv.optimizeObjective, *fv.optimizeWorkers, *fv.optimizeOut, *fv.optimizeTop); err != nil {
 		return
 	}
 	return
 }
 
+// ReadWalkForwardFlags reads the configuration of a walk-forward analysis from the provided arguments
+func ReadWalkForwardFlags(args []string) (assetCfg AssetConfig, algoConfig AlgorithmConfig, tradeConfig TradeConfig, backtestConfig BacktestConfig, walkForwardConfig WalkForwardConfig, err error) {
+	var fs *flag.FlagSet
+	var fv *flagValues
+	var exchangeCfg ExchangeConfig
+	var optimizerConfig OptimizerConfig
+
+	fs = flag.NewFlagSet("walkforward", flag.ExitOnError)
+	fv = defineWalkForwardFlags(fs)
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if err = fv.applyConfigFile(fs); err != nil {
+		return
+	}
+
+	if assetCfg, algoConfig, tradeConfig, err = fv.tradingConfigs(); err != nil {
+		return
+	}
+
+	if exchangeCfg, err = NewExchangeConfigFromFlags(*fv.exchange, fv.argMap(fv.fileExchangeArgs, "exchangeargs", *fv.exchangeArgsString), *fv.candleStore, *fv.rateLimit, *fv.retries, *fv.circuitBreaker); err != nil {
+		return
+	}
+
+	if backtestConfig, err = NewBacktestConfigFromFlags(*fv.backtestData, exchangeCfg, *fv.backtestDownload, *fv.backtestStart, *fv.backtestEnd, *fv.backtestCapital, *fv.backtestMaker, *fv.backtestTaker); err != nil {
+		return
+	}
+
+	if optimizerConfig, err = NewOptimizerConfigFromFlags(*fv.optimizeRanges, *fv.optimizeObjective, *fv.optimizeWorkers, *fv.optimizeOut, 0); err != nil {
+		return
+	}
+
+	if walkForwardConfig, err = NewWalkForwardConfigFromFlags(*fv.walkForwardInSample, *fv.walkForwardOutOfSample, *fv.walkForwardAnchored, optimizerConfig); err != nil {
+		return
+	}
+	return
+}
+
+// listFlags collects the values of a repeatable cmdline argument
+type listFlags []string
+
+func (mf *listFlags) String() string {
+	return strings.Join(*mf, " ")
+}
+
+func (mf *listFlags) Set(value string) error {
+	*mf = append(*mf, value)
+	return nil
+}
+
 func buildArgMap(in string) (out map[string]string) {
 	var parts, kv []string
 	var part string
@@ -74,7 +375,7 @@ func buildArgMap(in string) (out map[string]string) {
 
 	parts = strings.Split(in, ";")
 	for _, part = range parts {
-		kv = strings.Split(part, "=")
+		kv = strings.SplitN(part, "=", 2)
 		if len(kv) == 2 {
 			out[kv[0]] = kv[1]
 		}
@@ -112,7 +413,38 @@ func showDonations() {
 	fmt.Println()
 }
 
+// priceImproves returns true if the price is more favorable for the position than the reference price
+func priceImproves(position types.PositionSide, price float64, reference float64) bool {
+	if position == types.Short {
+		return price < reference
+	}
+	return price > reference
+}
+
+// netQuantity returns the executed base quantity of a buy order minus the commission payed in base asset
+func netQuantity(orderInfo types.OrderInfo) (quantity float64) {
+	var fill types.OrderFill
+
+	quantity = orderInfo.ExecutedQuantity
+	for _, fill = range orderInfo.Fills {
+		if fill.CommissionAsset == orderInfo.Symbol.Base() {
+			quantity -= fill.Commission
+		}
+	}
+	quantity = normalizeQuantity(quantity)
+	return
+}
+
 func normalizeQuantity(in float64) (out float64) {
 	out = math.Floor(in*1000000) / 1000000
 	return
 }
+
+// isFinalStatus returns true if the status of the order does not change anymore
+func isFinalStatus(status types.OrderStatus) bool {
+	switch status {
+	case types.StatusFilled, types.StatusCanceled, types.StatusExpired, types.StatusRejected:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,324 @@
+package cryptotrader
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mhereman/cryptotrader/logger"
+	"github.com/mhereman/cryptotrader/types"
+)
+
+// WalkForward optimizes the algorithm parameters on rolling in-sample windows
+// and evaluates them on the out-of-sample window following each in-sample window
+type WalkForward struct {
+	algoCfg        AlgorithmConfig
+	tradeCfg       TradeConfig
+	accountInfo    types.AccountInfo
+	walkForwardCfg WalkForwardConfig
+}
+
+// WalkForwardWindow represents the optimization and evaluation of one window
+type WalkForwardWindow struct {
+	// InSampleStart open time of the first in-sample candle
+	InSampleStart time.Time
+
+	// OutOfSampleStart open time of the first out-of-sample candle, the in-sample window ends here
+	OutOfSampleStart time.Time
+
+	// OutOfSampleEnd close time of the last out-of-sample candle
+	OutOfSampleEnd time.Time
+
+	// Params the best values of the optimized parameters on the in-sample window
+	Params []string
+
+	// InSample statistics of the best backtest on the in-sample window
+	InSample BacktestStatistics
+
+	// OutOfSample statistics of the backtest with the params on the out-of-sample window
+	OutOfSample BacktestStatistics
+}
+
+// ParamStability represents how much the optimized value of a parameter varies between the windows
+type ParamStability struct {
+	// Name of the parameter
+	Name string
+
+	// Distinct number of different values chosen
+	Distinct int
+
+	// Changes number of windows choosing another value than the previous window
+	Changes int
+
+	// Numeric the values are numbers, Mean and StdDev are only set for numeric parameters
+	Numeric bool
+
+	// Mean of the chosen values
+	Mean float64
+
+	// StdDev standard deviation of the chosen values
+	StdDev float64
+}
+
+// WalkForwardResult represents the outcome of a walk-forward analysis
+type WalkForwardResult struct {
+	// Symbol of the analysed series
+	Symbol types.Symbol
+
+	// Timeframe of the analysed series
+	Timeframe types.Timeframe
+
+	// Objective the in-sample backtests are ranked by
+	Objective OptimizeObjective
+
+	// ParamNames names of the optimized parameters
+	ParamNames []string
+
+	// Windows the evaluated windows
+	Windows []WalkForwardWindow
+
+	// Trades the out-of-sample trades of all windows
+	Trades []BacktestTrade
+
+	// EquityCurve the out-of-sample equity of all windows, each window starts with the final equity of the previous one
+	EquityCurve []EquityPoint
+
+	// Statistics summary of the stitched out-of-sample backtests
+	Statistics BacktestStatistics
+
+	// Efficiency out-of-sample return per candle relative to the in-sample return per candle,
+	// values well below 1 indicate the parameters are overfitted to the in-sample data
+	// NaN if the in-sample return is not positive, the efficiency is then undefined.
+	Efficiency float64
+
+	// Stability of the optimized parameters
+	Stability []ParamStability
+}
+
+// NewWalkForward creates a new WalkForward instance
+func NewWalkForward(algorithmConfig AlgorithmConfig, tradeConfig TradeConfig, accountInfo types.AccountInfo, walkForwardConfig WalkForwardConfig) (wf *WalkForward) {
+	wf = &WalkForward{
+		algoCfg:        algorithmConfig,
+		tradeCfg:       tradeConfig,
+		accountInfo:    accountInfo,
+		walkForwardCfg: walkForwardConfig,
+	}
+	return
+}
+
+// Run runs the walk-forward analysis on the series
+func (wf *WalkForward) Run(ctx context.Context, series types.Series) (result WalkForwardResult, err error) {
+	var optimizer *Optimizer
+	var optimization OptimizationResult
+	var backtestResult BacktestResult
+	var window WalkForwardWindow
+	var paramRange ParamRange
+	var initialCapital, equity, inSampleRate, outOfSampleRate float64
+	var inSampleStart, outOfSampleStart, outOfSampleEnd, warmupStart, numWindows, index int
+
+	numWindows = (series.Length() - wf.walkForwardCfg.InSample) / wf.walkForwardCfg.OutOfSample
+	if numWindows < 1 {
+		err = fmt.Errorf("Series %s[%s] of %d candles is too short for an in-sample window of %d and an out-of-sample window of %d candles", series.Symbol.String(), series.Timeframe.String(), series.Length(), wf.walkForwardCfg.InSample, wf.walkForwardCfg.OutOfSample)
+		logger.Errorf("WalkForward::Run Error %v\n", err)
+		return
+	}
+
+	result.Symbol = series.Symbol
+	result.Timeframe = series.Timeframe
+	result.Objective = wf.walkForwardCfg.Optimizer.Objective
+	for _, paramRange = range wf.walkForwardCfg.Optimizer.Ranges {
+		result.ParamNames = append(result.ParamNames, paramRange.Name)
+	}
+
+	initialCapital, _ = wf.accountInfo.GetAssetQuantity(series.Symbol.Quote())
+	equity = initialCapital
+	optimizer = NewOptimizer(wf.algoCfg, wf.tradeCfg, wf.accountInfo, wf.walkForwardCfg.Optimizer)
+
+	for index = 0; index < numWindows; index++ {
+		outOfSampleStart = wf.walkForwardCfg.InSample + index*wf.walkForwardCfg.OutOfSample
+		outOfSampleEnd = outOfSampleStart + wf.walkForwardCfg.OutOfSample
+		inSampleStart = outOfSampleStart - wf.walkForwardCfg.InSample
+		if wf.walkForwardCfg.Anchored {
+			inSampleStart = 0
+		}
+
+		window = WalkForwardWindow{
+			InSampleStart:    series.Candles[inSampleStart].OpenTime,
+			OutOfSampleStart: series.Candles[outOfSampleStart].OpenTime,
+			OutOfSampleEnd:   series.Candles[outOfSampleEnd-1].CloseTime,
+		}
+		logger.Infof("WalkForward: Window %d/%d, in-sample from %s, out-of-sample from %s\n", index+1, numWindows, window.InSampleStart.UTC().Format(time.RFC3339), window.OutOfSampleStart.UTC().Format(time.RFC3339))
+
+		if optimization, err = optimizer.Run(ctx, series.SubSeries(inSampleStart, outOfSampleStart-inSampleStart)); err != nil {
+			return
+		}
+		if len(optimization.Runs) == 0 {
+			logger.Warningf("WalkForward: No valid parameter combination for window %d, skipping\n", index+1)
+			continue
+		}
+		window.Params = optimization.Runs[0].Params
+		window.InSample = optimization.Runs[0].Statistics
+
+		// The candles before the out-of-sample window warm up the indicators
+		warmupStart = outOfSampleStart - backtestLookback
+		if warmupStart < 0 {
+			warmupStart = 0
+		}
+		if backtestResult, err = NewBacktester(optimizer.combinationConfig(window.Params), wf.tradeCfg, accountInfoWithCapital(wf.accountInfo, series.Symbol, equity)).RunFrom(ctx, series.SubSeries(warmupStart, outOfSampleEnd-warmupStart), outOfSampleStart-warmupStart); err != nil {
+			return
+		}
+		window.OutOfSample = backtestResult.Statistics
+		equity = backtestResult.Statistics.FinalEquity
+
+		result.Windows = append(result.Windows, window)
+		result.Trades = append(result.Trades, backtestResult.Trades...)
+		result.EquityCurve = append(result.EquityCurve, backtestResult.EquityCurve...)
+
+		inSampleRate += window.InSample.NetProfitPct / float64(outOfSampleStart-inSampleStart)
+		outOfSampleRate += window.OutOfSample.NetProfitPct / float64(wf.walkForwardCfg.OutOfSample)
+	}
+
+	result.Statistics = calculateStatistics(initialCapital, result.Trades, result.EquityCurve, series.Timeframe)
+	result.Efficiency = math.NaN()
+	if inSampleRate > 0.0 {
+		result.Efficiency = outOfSampleRate / inSampleRate
+	}
+	result.Stability = paramStability(result.ParamNames, result.Windows)
+	return
+}
+
+// accountInfoWithCapital returns the account info with the capital as only balance
+func accountInfoWithCapital(accountInfo types.AccountInfo, symbol types.Symbol, capital float64) types.AccountInfo {
+	return types.NewAccountInfo(
+		accountInfo.MakerCommission,
+		accountInfo.TakerCommission,
+		accountInfo.BuyerCommission,
+		accountInfo.SellerCommission,
+		[]types.AccountBalance{types.NewAccountBalance(symbol.Quote(), capital, 0.0)},
+	)
+}
+
+func paramStability(names []string, windows []WalkForwardWindow) (stability []ParamStability) {
+	var name string
+	var window WalkForwardWindow
+	var ps ParamStability
+	var distinct map[string]bool
+	var value, previous string
+	var number, sum, sumSquares float64
+	var index int
+	var err error
+
+	for index, name = range names {
+		ps = ParamStability{Name: name, Numeric: len(windows) > 0}
+		distinct = make(map[string]bool)
+		sum = 0.0
+		sumSquares = 0.0
+		previous = ""
+
+		for _, window = range windows {
+			value = window.Params[index]
+			distinct[value] = true
+			if previous != "" && value != previous {
+				ps.Changes++
+			}
+			previous = value
+
+			if number, err = strconv.ParseFloat(value, 64); err != nil {
+				ps.Numeric = false
+			}
+			sum += number
+			sumSquares += number * number
+		}
+
+		ps.Distinct = len(distinct)
+		if ps.Numeric {
+			ps.Mean = sum / float64(len(windows))
+			ps.StdDev = math.Sqrt(math.Max(sumSquares/float64(len(windows))-ps.Mean*ps.Mean, 0.0))
+		}
+		stability = append(stability, ps)
+	}
+	return
+}
+
+// WriteCSV writes the windows as CSV, one row per window
+func (r WalkForwardResult) WriteCSV(w io.Writer) (err error) {
+	var cw *csv.Writer
+	var window WalkForwardWindow
+	var index int
+
+	cw = csv.NewWriter(w)
+	if err = cw.Write(append(append([]string{"window", "in_sample_start", "out_of_sample_start", "out_of_sample_end"}, r.ParamNames...), "in_sample_net_profit_pct", "in_sample_score", "out_of_sample_net_profit_pct", "out_of_sample_score", "out_of_sample_trades")); err != nil {
+		return
+	}
+	for index, window = range r.Windows {
+		if err = cw.Write(append(append([]string{
+			strconv.Itoa(index + 1),
+			window.InSampleStart.UTC().Format(time.RFC3339),
+			window.OutOfSampleStart.UTC().Format(time.RFC3339),
+			window.OutOfSampleEnd.UTC().Format(time.RFC3339),
+		}, window.Params...),
+			formatCSVFloat(window.InSample.NetProfitPct),
+			formatCSVFloat(r.Objective.Score(window.InSample)),
+			formatCSVFloat(window.OutOfSample.NetProfitPct),
+			formatCSVFloat(r.Objective.Score(window.OutOfSample)),
+			strconv.Itoa(window.OutOfSample.NumTrades),
+		)); err != nil {
+			return
+		}
+	}
+	cw.Flush()
+	err = cw.Error()
+	return
+}
+
+// WriteReport writes a human readable report of the walk-forward analysis
+func (r WalkForwardResult) WriteReport(w io.Writer) (err error) {
+	var tw *tabwriter.Writer
+	var window WalkForwardWindow
+	var ps ParamStability
+	var index int
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Walk-forward %s[%s], objective: %s\n\n", r.Symbol.String(), r.Timeframe.String(), r.Objective.String())
+	fmt.Fprintf(tw, "#\tIn-sample start\tOut-of-sample start\t%s\tIn-sample return\tOut-of-sample return\tOut-of-sample trades\n", strings.Join(r.ParamNames, "\t"))
+	for index, window = range r.Windows {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%.2f%%\t%.2f%%\t%d\n",
+			index+1,
+			window.InSampleStart.UTC().Format(time.RFC3339),
+			window.OutOfSampleStart.UTC().Format(time.RFC3339),
+			strings.Join(window.Params, "\t"),
+			window.InSample.NetProfitPct*100.0,
+			window.OutOfSample.NetProfitPct*100.0,
+			window.OutOfSample.NumTrades,
+		)
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintf(tw, "Out-of-sample performance\n")
+	writeStatistics(tw, r.Statistics)
+	if math.IsNaN(r.Efficiency) {
+		fmt.Fprintf(tw, "Walk-forward efficiency:\t-\n")
+	} else {
+		fmt.Fprintf(tw, "Walk-forward efficiency:\t%.2f\n", r.Efficiency)
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintf(tw, "Parameter\tDistinct values\tChanges\tMean\tStd dev\n")
+	for _, ps = range r.Stability {
+		if ps.Numeric {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%f\t%f\n", ps.Name, ps.Distinct, ps.Changes, ps.Mean, ps.StdDev)
+		} else {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t-\t-\n", ps.Name, ps.Distinct, ps.Changes)
+		}
+	}
+
+	err = tw.Flush()
+	return
+}
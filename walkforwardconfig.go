@@ -0,0 +1,37 @@
+package cryptotrader
+
+import "fmt"
+
+// WalkForwardConfig represents the config of a walk-forward analysis
+type WalkForwardConfig struct {
+	// InSample number of candles of the windows to optimize the parameters on
+	InSample int
+
+	// OutOfSample number of candles of the windows to evaluate the optimized parameters on,
+	// the windows move forward by this number of candles
+	OutOfSample int
+
+	// Anchored all in-sample windows start at the first candle instead of rolling forward
+	Anchored bool
+
+	// Optimizer config of the optimization of the in-sample windows
+	Optimizer OptimizerConfig
+}
+
+// NewWalkForwardConfigFromFlags creates a new WalkForwardConfig instance from the cmdline argument values
+func NewWalkForwardConfigFromFlags(inSample int, outOfSample int, anchored bool, optimizerConfig OptimizerConfig) (wc WalkForwardConfig, err error) {
+	if inSample < 2 {
+		err = fmt.Errorf("Invalid in-sample window: %d, must contain at least 2 candles", inSample)
+		return
+	}
+	if outOfSample < 1 {
+		err = fmt.Errorf("Invalid out-of-sample window: %d, must contain at least 1 candle", outOfSample)
+		return
+	}
+
+	wc.InSample = inSample
+	wc.OutOfSample = outOfSample
+	wc.Anchored = anchored
+	wc.Optimizer = optimizerConfig
+	return
+}